@@ -47,7 +47,12 @@ func (a AccountService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Account]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := a.requestClient.startPagination(ctx, a.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
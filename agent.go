@@ -64,7 +64,12 @@ func (c AgentService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Agent]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := c.requestClient.startPagination(ctx, c.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -76,7 +76,12 @@ func (a AlertService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Alert]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := a.requestClient.startPagination(ctx, a.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
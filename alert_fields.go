@@ -0,0 +1,88 @@
+package goslide
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type DeviceNotCheckingInFields struct {
+	DeviceID   string    `json:"device_id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type DeviceOutOfDateFields struct {
+	DeviceID       string `json:"device_id"`
+	CurrentVersion string `json:"current_version"`
+	LatestVersion  string `json:"latest_version"`
+}
+
+type DeviceStorageNotHealthyFields struct {
+	DeviceID string `json:"device_id"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+// DeviceStorageSpaceFields is used by both the low and critical storage space alerts.
+type DeviceStorageSpaceFields struct {
+	DeviceID         string  `json:"device_id"`
+	StorageUsedBytes uint    `json:"storage_used_bytes"`
+	StorageFreeBytes uint    `json:"storage_free_bytes"`
+	ThresholdPercent float64 `json:"threshold_percent"`
+}
+
+type AgentNotCheckingInFields struct {
+	AgentID    string    `json:"agent_id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type AgentNotBackingUpFields struct {
+	AgentID        string     `json:"agent_id"`
+	LastBackupAt   *time.Time `json:"last_backup_at"`
+	ThresholdHours uint       `json:"threshold_hours"`
+}
+
+type AgentBackupFailedFields struct {
+	AgentID  string `json:"agent_id"`
+	BackupID string `json:"backup_id"`
+	Error    string `json:"error"`
+}
+
+// Fields decodes AlertFields into the struct matching the AlertType, such as
+// AgentBackupFailedFields for AlertType_AGENT_BACKUP_FAILED. Unknown alert
+// types are decoded into a map[string]any.
+func (a Alert) Fields() (any, error) {
+	switch a.AlertType {
+	case AlertType_DEVICE_NOT_CHECKING_IN:
+		return decodeAlertFields[DeviceNotCheckingInFields](a)
+	case AlertType_DEVICE_OUT_OF_DATE:
+		return decodeAlertFields[DeviceOutOfDateFields](a)
+	case AlertType_DEVICE_STORAGE_NOT_HEALTHY:
+		return decodeAlertFields[DeviceStorageNotHealthyFields](a)
+	case AlertType_DEVICE_STORAGE_SPACE_LOW, AlertType_DEVICE_STORAGE_SPACE_CRITICAL:
+		return decodeAlertFields[DeviceStorageSpaceFields](a)
+	case AlertType_AGENT_NOT_CHECKING_IN:
+		return decodeAlertFields[AgentNotCheckingInFields](a)
+	case AlertType_AGENT_NOT_BACKING_UP:
+		return decodeAlertFields[AgentNotBackingUpFields](a)
+	case AlertType_AGENT_BACKUP_FAILED:
+		return decodeAlertFields[AgentBackupFailedFields](a)
+	default:
+		return decodeAlertFields[map[string]any](a)
+	}
+}
+
+func decodeAlertFields[Fields any](alert Alert) (Fields, error) {
+	var target Fields
+
+	// Alerts without fields decode to the zero value of their type
+	if alert.AlertFields == "" {
+		return target, nil
+	}
+
+	if err := json.Unmarshal([]byte(alert.AlertFields), &target); err != nil {
+		return target, fmt.Errorf("decoding alert_fields of %s alert %s - %w", alert.AlertType, alert.AlertID, err)
+	}
+
+	return target, nil
+}
@@ -0,0 +1,147 @@
+package goslide_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAlert_Fields(t *testing.T) {
+	lastSeenAt := time.Date(2024, time.August, 23, 1, 25, 8, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		alertType   goslide.AlertType
+		alertFields string
+		expected    any
+	}{
+		{
+			name:        "device not checking in",
+			alertType:   goslide.AlertType_DEVICE_NOT_CHECKING_IN,
+			alertFields: `{"device_id":"d_0123456789ab","last_seen_at":"2024-08-23T01:25:08Z"}`,
+			expected: goslide.DeviceNotCheckingInFields{
+				DeviceID:   "d_0123456789ab",
+				LastSeenAt: lastSeenAt,
+			},
+		},
+		{
+			name:        "device out of date",
+			alertType:   goslide.AlertType_DEVICE_OUT_OF_DATE,
+			alertFields: `{"device_id":"d_0123456789ab","current_version":"1.2.0","latest_version":"1.3.1"}`,
+			expected: goslide.DeviceOutOfDateFields{
+				DeviceID:       "d_0123456789ab",
+				CurrentVersion: "1.2.0",
+				LatestVersion:  "1.3.1",
+			},
+		},
+		{
+			name:        "device storage not healthy",
+			alertType:   goslide.AlertType_DEVICE_STORAGE_NOT_HEALTHY,
+			alertFields: `{"device_id":"d_0123456789ab","status":"degraded","message":"disk 2 failed"}`,
+			expected: goslide.DeviceStorageNotHealthyFields{
+				DeviceID: "d_0123456789ab",
+				Status:   "degraded",
+				Message:  "disk 2 failed",
+			},
+		},
+		{
+			name:        "device storage space low",
+			alertType:   goslide.AlertType_DEVICE_STORAGE_SPACE_LOW,
+			alertFields: `{"device_id":"d_0123456789ab","storage_used_bytes":800,"storage_free_bytes":200,"threshold_percent":80}`,
+			expected: goslide.DeviceStorageSpaceFields{
+				DeviceID:         "d_0123456789ab",
+				StorageUsedBytes: 800,
+				StorageFreeBytes: 200,
+				ThresholdPercent: 80,
+			},
+		},
+		{
+			name:        "device storage space critical",
+			alertType:   goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL,
+			alertFields: `{"device_id":"d_0123456789ab","storage_used_bytes":950,"storage_free_bytes":50,"threshold_percent":95}`,
+			expected: goslide.DeviceStorageSpaceFields{
+				DeviceID:         "d_0123456789ab",
+				StorageUsedBytes: 950,
+				StorageFreeBytes: 50,
+				ThresholdPercent: 95,
+			},
+		},
+		{
+			name:        "agent not checking in",
+			alertType:   goslide.AlertType_AGENT_NOT_CHECKING_IN,
+			alertFields: `{"agent_id":"a_0123456789ab","last_seen_at":"2024-08-23T01:25:08Z"}`,
+			expected: goslide.AgentNotCheckingInFields{
+				AgentID:    "a_0123456789ab",
+				LastSeenAt: lastSeenAt,
+			},
+		},
+		{
+			name:        "agent not backing up",
+			alertType:   goslide.AlertType_AGENT_NOT_BACKING_UP,
+			alertFields: `{"agent_id":"a_0123456789ab","last_backup_at":"2024-08-23T01:25:08Z","threshold_hours":24}`,
+			expected: goslide.AgentNotBackingUpFields{
+				AgentID:        "a_0123456789ab",
+				LastBackupAt:   &lastSeenAt,
+				ThresholdHours: 24,
+			},
+		},
+		{
+			name:        "agent backup failed",
+			alertType:   goslide.AlertType_AGENT_BACKUP_FAILED,
+			alertFields: `{"agent_id":"a_0123456789ab","backup_id":"b_0123456789ab","error":"VSS snapshot failed"}`,
+			expected: goslide.AgentBackupFailedFields{
+				AgentID:  "a_0123456789ab",
+				BackupID: "b_0123456789ab",
+				Error:    "VSS snapshot failed",
+			},
+		},
+		{
+			name:        "no fields",
+			alertType:   goslide.AlertType_AGENT_BACKUP_FAILED,
+			alertFields: "",
+			expected:    goslide.AgentBackupFailedFields{},
+		},
+		{
+			name:        "unknown type",
+			alertType:   goslide.AlertType("device_on_fire"),
+			alertFields: `{"device_id":"d_0123456789ab","temperature":451}`,
+			expected: map[string]any{
+				"device_id":   "d_0123456789ab",
+				"temperature": float64(451),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			alert := goslide.Alert{
+				AlertID:     "al_0123456789ab",
+				AlertType:   testCase.alertType,
+				AlertFields: testCase.alertFields,
+			}
+
+			actual, err := alert.Fields()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Fatalf("%s Alert fields mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+}
+
+func TestAlert_Fields_Invalid(t *testing.T) {
+	alert := goslide.Alert{
+		AlertID:     "al_0123456789ab",
+		AlertType:   goslide.AlertType_AGENT_BACKUP_FAILED,
+		AlertFields: "string",
+	}
+
+	if _, err := alert.Fields(); err == nil {
+		t.Fatalf("%s expected an error for fields that are not JSON", t.Name())
+	}
+}
@@ -0,0 +1,207 @@
+// Package alertbulk resolves many alerts at once, such as the stale alerts
+// left behind after a site move, and keeps an audit trail of every run.
+package alertbulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/user"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/owner"
+)
+
+const defaultConcurrency = 4
+
+// Selector picks unresolved alerts. Empty fields match everything.
+type Selector struct {
+	AlertTypes []goslide.AlertType
+	DeviceIDs  []string
+	AgentIDs   []string
+	ClientIDs  []string
+
+	// OlderThan only selects alerts created at least this long ago
+	OlderThan time.Duration
+}
+
+type Resolver struct {
+	service     goslide.Service
+	concurrency int
+	auditPath   string
+	actor       string
+	now         func() time.Time
+}
+
+type resolverOption func(r *Resolver)
+
+// WithConcurrency sets how many alerts are resolved at the same time.
+func WithConcurrency(concurrency int) resolverOption {
+	return func(r *Resolver) {
+		r.concurrency = concurrency
+	}
+}
+
+// WithAuditFile appends an AuditRecord for every Resolve to a JSON lines file.
+func WithAuditFile(path string) resolverOption {
+	return func(r *Resolver) {
+		r.auditPath = path
+	}
+}
+
+// WithActor sets who is recorded in the audit trail, by default the user
+// running the process.
+func WithActor(actor string) resolverOption {
+	return func(r *Resolver) {
+		r.actor = actor
+	}
+}
+
+func NewResolver(service goslide.Service, options ...resolverOption) *Resolver {
+	resolver := &Resolver{
+		service:     service,
+		concurrency: defaultConcurrency,
+		now:         time.Now,
+	}
+
+	if current, err := user.Current(); err == nil {
+		resolver.actor = current.Username
+	}
+
+	for _, option := range options {
+		option(resolver)
+	}
+
+	if resolver.concurrency < 1 {
+		resolver.concurrency = 1
+	}
+
+	return resolver
+}
+
+// Preview lists the unresolved alerts matching the selector, oldest first,
+// without changing them.
+func (r *Resolver) Preview(ctx context.Context, selector Selector) ([]goslide.Alert, error) {
+	alerts := []goslide.Alert{}
+	if err := r.service.Alerts().ListWithQueryParameters(ctx, func(response goslide.ListResponse[goslide.Alert]) error {
+		alerts = append(alerts, response.Data...)
+
+		return nil
+	}, goslide.WithIncludeResolvedAlerts(false)); err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	owners := owner.NewCache(r.service)
+
+	matches := []goslide.Alert{}
+	for _, alert := range alerts {
+		if alert.Resolved ||
+			(len(selector.AlertTypes) > 0 && !slices.Contains(selector.AlertTypes, alert.AlertType)) ||
+			(len(selector.DeviceIDs) > 0 && !slices.Contains(selector.DeviceIDs, alert.DeviceID)) ||
+			(len(selector.AgentIDs) > 0 && !slices.Contains(selector.AgentIDs, alert.AgentID)) ||
+			(selector.OlderThan > 0 && now.Sub(alert.CreatedAt) < selector.OlderThan) {
+			continue
+		}
+
+		if len(selector.ClientIDs) > 0 {
+			clientID, err := owners.ClientID(ctx, alert)
+			if err != nil {
+				return nil, err
+			}
+
+			if !slices.Contains(selector.ClientIDs, clientID) {
+				continue
+			}
+		}
+
+		matches = append(matches, alert)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].CreatedAt.Before(matches[j].CreatedAt)
+	})
+
+	return matches, nil
+}
+
+// Resolve resolves the alerts, usually the result of Preview, and records
+// why in the audit file. Alerts that fail are reported in the result and do
+// not stop the others.
+func (r *Resolver) Resolve(ctx context.Context, alerts []goslide.Alert, reason string) (Result, error) {
+	if reason == "" {
+		return Result{}, errors.New("alertbulk: a reason is required")
+	}
+
+	result := Result{
+		Resolved: []string{},
+		Failed:   []Failure{},
+	}
+	startedAt := r.now().UTC()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, r.concurrency)
+
+	for _, alert := range alerts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				mu.Lock()
+				result.Failed = append(result.Failed, Failure{AlertID: alert.AlertID, Error: ctx.Err().Error()})
+				mu.Unlock()
+
+				return
+			}
+
+			_, err := r.service.Alerts().Update(ctx, alert.AlertID, true)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				result.Failed = append(result.Failed, Failure{AlertID: alert.AlertID, Error: err.Error()})
+
+				return
+			}
+
+			result.Resolved = append(result.Resolved, alert.AlertID)
+		}()
+	}
+
+	wg.Wait()
+
+	sort.Strings(result.Resolved)
+	sort.Slice(result.Failed, func(i, j int) bool {
+		return result.Failed[i].AlertID < result.Failed[j].AlertID
+	})
+
+	if r.auditPath != "" {
+		if err := appendAudit(r.auditPath, AuditRecord{
+			Actor:      r.actor,
+			Reason:     reason,
+			StartedAt:  startedAt,
+			FinishedAt: r.now().UTC(),
+			Resolved:   result.Resolved,
+			Failed:     result.Failed,
+		}); err != nil {
+			return result, fmt.Errorf("alertbulk: writing audit record - %w", err)
+		}
+	}
+
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("alertbulk: %d of %d alerts could not be resolved", len(result.Failed), len(alerts))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,201 @@
+package alertbulk_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertbulk"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testAlerts returns alerts of the fleet of several ages, types, devices and
+// clients, with one already resolved.
+func testAlerts() []goslide.Alert {
+	now := time.Now()
+
+	return []goslide.Alert{
+		{AlertID: "al_1", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{AlertID: "al_2", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", CreatedAt: now.Add(-20 * 24 * time.Hour)},
+		{AlertID: "al_3", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", CreatedAt: now.Add(-time.Hour)},
+		{AlertID: "al_4", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_globex", CreatedAt: now.Add(-40 * 24 * time.Hour)},
+		{AlertID: "al_5", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, DeviceID: "d_acme", CreatedAt: now.Add(-40 * 24 * time.Hour)},
+		{AlertID: "al_7", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, DeviceID: "d_acme_branch", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+		{AlertID: "al_6", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", CreatedAt: now.Add(-50 * 24 * time.Hour), Resolved: true},
+	}
+}
+
+func alertIDs(alerts []goslide.Alert) []string {
+	ids := []string{}
+	for _, alert := range alerts {
+		ids = append(ids, alert.AlertID)
+	}
+
+	return ids
+}
+
+func TestResolver_Preview(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	resolver := alertbulk.NewResolver(server.Service())
+
+	testCases := []struct {
+		name     string
+		selector alertbulk.Selector
+		expected []string
+	}{
+		{
+			name:     "everything",
+			selector: alertbulk.Selector{},
+			expected: []string{"al_4", "al_5", "al_1", "al_2", "al_7", "al_3"},
+		},
+		{
+			name: "stale by client",
+			selector: alertbulk.Selector{
+				AlertTypes: []goslide.AlertType{goslide.AlertType_DEVICE_NOT_CHECKING_IN},
+				ClientIDs:  []string{"c_acme"},
+				OlderThan:  7 * 24 * time.Hour,
+			},
+			expected: []string{"al_1", "al_2"},
+		},
+		{
+			name:     "device",
+			selector: alertbulk.Selector{DeviceIDs: []string{"d_globex"}},
+			expected: []string{"al_4"},
+		},
+		{
+			name:     "devices",
+			selector: alertbulk.Selector{DeviceIDs: []string{"d_acme", "d_globex"}},
+			expected: []string{"al_4", "al_5", "al_1", "al_2", "al_3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			alerts, err := resolver.Preview(context.Background(), testCase.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, alertIDs(alerts)); diff != "" {
+				t.Fatalf("%s Preview mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+
+	// Previews never change anything
+	for _, alert := range server.Alerts[:6] {
+		if alert.Resolved {
+			t.Fatalf("%s expected %s to stay unresolved", t.Name(), alert.AlertID)
+		}
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	server.FailNext("PATCH /v1/alert/{alert_id}", 1)
+
+	// Track how many updates are in flight, to check the concurrency bound
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		server.ServeHTTP(w, r)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	resolver := alertbulk.NewResolver(
+		goslide.NewService("fakeToken", goslide.WithCustomRoundtripper(roundtripper.HandlerNetwork(handler))),
+		alertbulk.WithConcurrency(2),
+		alertbulk.WithAuditFile(auditPath),
+		alertbulk.WithActor("jsmith"),
+	)
+
+	ctx := context.Background()
+	alerts, err := resolver.Preview(ctx, alertbulk.Selector{AlertTypes: []goslide.AlertType{goslide.AlertType_DEVICE_NOT_CHECKING_IN}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := resolver.Resolve(ctx, alerts, "Site move to new office")
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 alerts could not be resolved") {
+		t.Fatalf("%s expected one failure, got %v", t.Name(), err)
+	}
+
+	if len(result.Resolved) != 3 || len(result.Failed) != 1 {
+		t.Fatalf("%s unexpected result %+v", t.Name(), result)
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("%s expected at most 2 concurrent requests, got %d", t.Name(), maxInFlight)
+	}
+
+	// The failed alert is resolved by a second run
+	retry := []goslide.Alert{{AlertID: result.Failed[0].AlertID}}
+	if _, err := resolver.Resolve(ctx, retry, "Retry after failure"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, alert := range server.Alerts {
+		if expected := alert.AlertType == goslide.AlertType_DEVICE_NOT_CHECKING_IN; alert.Resolved != expected {
+			t.Errorf("%s expected %s resolved to be %t", t.Name(), alert.AlertID, expected)
+		}
+	}
+
+	file, err := os.Open(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records := []alertbulk.AuditRecord{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := alertbulk.AuditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+
+		records = append(records, record)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("%s expected two audit records, got %d", t.Name(), len(records))
+	}
+
+	if records[0].Actor != "jsmith" || records[0].Reason != "Site move to new office" || len(records[0].Resolved) != 3 || len(records[0].Failed) != 1 {
+		t.Errorf("%s unexpected audit record %+v", t.Name(), records[0])
+	}
+
+	if diff := cmp.Diff(result.Failed[0].AlertID, records[1].Resolved[0]); diff != "" {
+		t.Errorf("%s Retried alert mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestResolver_ResolveWithoutReason(t *testing.T) {
+	resolver := alertbulk.NewResolver(fakeslide.NewFleet().Service())
+
+	if _, err := resolver.Resolve(context.Background(), []goslide.Alert{{AlertID: "al_1"}}, ""); err == nil {
+		t.Fatalf("%s expected an error without a reason", t.Name())
+	}
+}
@@ -0,0 +1,47 @@
+package alertbulk
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+type Result struct {
+	Resolved []string
+	Failed   []Failure
+}
+
+type Failure struct {
+	AlertID string `json:"alert_id"`
+	Error   string `json:"error"`
+}
+
+// AuditRecord is a line of the audit file.
+type AuditRecord struct {
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Resolved   []string  `json:"resolved"`
+	Failed     []Failure `json:"failed"`
+}
+
+func appendAudit(path string, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,315 @@
+// Package alertwatch polls the Slide API for alerts and turns changes into
+// events, since the API has no webhooks.
+package alertwatch
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/owner"
+)
+
+const (
+	defaultPollInterval      = time.Minute
+	defaultResolvedRetention = 7 * 24 * time.Hour
+)
+
+type EventType string
+
+const (
+	EventType_ALERT_RAISED   EventType = "alert_raised"
+	EventType_ALERT_RESOLVED EventType = "alert_resolved"
+	EventType_ALERT_REOPENED EventType = "alert_reopened"
+)
+
+type Event struct {
+	Type       EventType
+	Alert      goslide.Alert
+	ObservedAt time.Time
+}
+
+// Filter limits the events to matching alerts. Empty fields match everything.
+type Filter struct {
+	AlertTypes []goslide.AlertType
+	DeviceIDs  []string
+	AgentIDs   []string
+	ClientIDs  []string
+}
+
+type Watcher struct {
+	service           goslide.Service
+	store             Store
+	filter            Filter
+	pollInterval      time.Duration
+	resolvedRetention time.Duration
+	initialEvents     bool
+	now               func() time.Time
+	owners            *owner.Cache
+}
+
+type watcherOption func(w *Watcher)
+
+// WithStore sets where the state is kept. The default MemoryStore does not
+// survive a restart: the first poll of a new watcher records the alerts that
+// exist as its baseline, without events unless WithInitialEvents is set, so
+// changes while it was down are lost. Long running watchers should use a
+// FileStore or their own Store.
+func WithStore(store Store) watcherOption {
+	return func(w *Watcher) {
+		w.store = store
+	}
+}
+
+func WithFilter(filter Filter) watcherOption {
+	return func(w *Watcher) {
+		w.filter = filter
+	}
+}
+
+func WithPollInterval(interval time.Duration) watcherOption {
+	return func(w *Watcher) {
+		w.pollInterval = interval
+	}
+}
+
+// WithResolvedRetention sets how long resolved alerts are remembered, so
+// that they are reported as reopened rather than raised again. Defaults to a
+// week.
+func WithResolvedRetention(retention time.Duration) watcherOption {
+	return func(w *Watcher) {
+		w.resolvedRetention = retention
+	}
+}
+
+// WithInitialEvents emits AlertRaised for every open alert on the first poll.
+// By default the first poll only records the alerts that already exist.
+func WithInitialEvents() watcherOption {
+	return func(w *Watcher) {
+		w.initialEvents = true
+	}
+}
+
+func NewWatcher(service goslide.Service, options ...watcherOption) *Watcher {
+	watcher := &Watcher{
+		service:           service,
+		store:             &MemoryStore{},
+		pollInterval:      defaultPollInterval,
+		resolvedRetention: defaultResolvedRetention,
+		now:               time.Now,
+		owners:            owner.NewCache(service),
+	}
+
+	for _, option := range options {
+		option(watcher)
+	}
+
+	return watcher
+}
+
+// Run polls until ctx is done, calling handler for every event. The state is
+// saved after all events of a poll were handled, so events of a poll whose
+// handler fails are delivered again by the next Run.
+func (w *Watcher) Run(ctx context.Context, handler func(event Event) error) error {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx, handler); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events runs the watcher in the background and delivers its events on a
+// channel. The error channel receives the reason the watcher stopped, after
+// which both channels are closed.
+func (w *Watcher) Events(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		errs <- w.Run(ctx, func(event Event) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return events, errs
+}
+
+// Poll lists the open alerts once and calls handler for every change since
+// the previous poll. Alerts that are no longer open are looked up one by one,
+// so resolved alerts are not listed on every poll.
+func (w *Watcher) Poll(ctx context.Context, handler func(event Event) error) error {
+	state, err := w.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state.Open == nil {
+		state.Open = map[string]bool{}
+	}
+
+	if state.Resolved == nil {
+		state.Resolved = map[string]time.Time{}
+	}
+
+	observedAt := w.now().UTC()
+
+	// The baseline also records recently resolved alerts, so that they are
+	// reported as reopened rather than raised
+	alerts := []goslide.Alert{}
+	if err := w.service.Alerts().ListWithQueryParameters(ctx, func(response goslide.ListResponse[goslide.Alert]) error {
+		alerts = append(alerts, response.Data...)
+
+		return nil
+	}, goslide.WithIncludeResolvedAlerts(!state.Initialized)); err != nil {
+		return err
+	}
+
+	emit := func(eventType EventType, alert goslide.Alert) error {
+		matches, err := w.matches(ctx, alert)
+		if err != nil || !matches {
+			return err
+		}
+
+		return handler(Event{
+			Type:       eventType,
+			Alert:      alert,
+			ObservedAt: observedAt,
+		})
+	}
+
+	open := map[string]bool{}
+	for _, alert := range alerts {
+		if !alert.Resolved {
+			open[alert.AlertID] = true
+		}
+	}
+
+	// Alerts open at the previous poll but not listed now were resolved, or
+	// deleted
+	closed := []string{}
+	for alertID := range state.Open {
+		if !open[alertID] {
+			closed = append(closed, alertID)
+		}
+	}
+	slices.Sort(closed)
+
+	for _, alertID := range closed {
+		alert, err := w.service.Alerts().Get(ctx, alertID)
+		if owner.IsNotFound(err) {
+			delete(state.Open, alertID)
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// Reopened between the list and the lookup, picked up by the next poll
+		if !alert.Resolved {
+			continue
+		}
+
+		if err := emit(EventType_ALERT_RESOLVED, alert); err != nil {
+			return err
+		}
+
+		delete(state.Open, alertID)
+		state.Resolved[alertID] = observedAt
+	}
+
+	for _, alert := range alerts {
+		if alert.Resolved {
+			resolvedAt := observedAt
+			if alert.ResolvedAt != nil {
+				resolvedAt = alert.ResolvedAt.UTC()
+			}
+
+			state.Resolved[alert.AlertID] = resolvedAt
+
+			continue
+		}
+
+		eventType, ok := w.change(state, alert)
+		state.Open[alert.AlertID] = true
+		delete(state.Resolved, alert.AlertID)
+
+		if !ok {
+			continue
+		}
+
+		if err := emit(eventType, alert); err != nil {
+			return err
+		}
+	}
+
+	for alertID, resolvedAt := range state.Resolved {
+		if observedAt.Sub(resolvedAt) > w.resolvedRetention {
+			delete(state.Resolved, alertID)
+		}
+	}
+
+	state.Initialized = true
+
+	return w.store.Save(ctx, state)
+}
+
+// change tells the event of an open alert, if any.
+func (w *Watcher) change(state State, alert goslide.Alert) (EventType, bool) {
+	_, wasResolved := state.Resolved[alert.AlertID]
+
+	switch {
+	case state.Open[alert.AlertID]:
+		return "", false
+	case wasResolved:
+		return EventType_ALERT_REOPENED, true
+	case !state.Initialized:
+		return EventType_ALERT_RAISED, w.initialEvents
+	default:
+		return EventType_ALERT_RAISED, true
+	}
+}
+
+func (w *Watcher) matches(ctx context.Context, alert goslide.Alert) (bool, error) {
+	if len(w.filter.AlertTypes) > 0 && !slices.Contains(w.filter.AlertTypes, alert.AlertType) {
+		return false, nil
+	}
+
+	if len(w.filter.DeviceIDs) > 0 && !slices.Contains(w.filter.DeviceIDs, alert.DeviceID) {
+		return false, nil
+	}
+
+	if len(w.filter.AgentIDs) > 0 && !slices.Contains(w.filter.AgentIDs, alert.AgentID) {
+		return false, nil
+	}
+
+	if len(w.filter.ClientIDs) == 0 {
+		return true, nil
+	}
+
+	clientID, err := w.owners.ClientID(ctx, alert)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(w.filter.ClientIDs, clientID), nil
+}
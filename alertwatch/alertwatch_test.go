@@ -0,0 +1,244 @@
+package alertwatch_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/google/go-cmp/cmp"
+)
+
+type observed struct {
+	Type    alertwatch.EventType
+	AlertID string
+}
+
+// testAlerts returns an open and a resolved alert of the fleet.
+func testAlerts() []goslide.Alert {
+	return []goslide.Alert{
+		{AlertID: "al_existing", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, AgentID: "a_dc", DeviceID: "d_acme"},
+		{AlertID: "al_old", AlertType: goslide.AlertType_AGENT_NOT_BACKING_UP, AgentID: "a_dc", DeviceID: "d_acme", Resolved: true},
+	}
+}
+
+func poll(t *testing.T, watcher *alertwatch.Watcher) []observed {
+	t.Helper()
+
+	events := []observed{}
+	if err := watcher.Poll(context.Background(), func(event alertwatch.Event) error {
+		events = append(events, observed{Type: event.Type, AlertID: event.Alert.AlertID})
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
+
+func setResolved(server *fakeslide.Server, alertID string, resolved bool) {
+	for i := range server.Alerts {
+		if server.Alerts[i].AlertID == alertID {
+			server.Alerts[i].Resolved = resolved
+		}
+	}
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	watcher := alertwatch.NewWatcher(server.Service())
+
+	// The first poll only records what already exists
+	if events := poll(t, watcher); len(events) != 0 {
+		t.Fatalf("%s expected no events for existing alerts, got %v", t.Name(), events)
+	}
+
+	server.Alerts = append(server.Alerts,
+		goslide.Alert{AlertID: "al_new", AlertType: goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, DeviceID: "d_acme"},
+		goslide.Alert{AlertID: "al_flapped", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", Resolved: true},
+	)
+	setResolved(server, "al_existing", true)
+	setResolved(server, "al_old", false)
+
+	expected := []observed{
+		{Type: alertwatch.EventType_ALERT_RESOLVED, AlertID: "al_existing"},
+		{Type: alertwatch.EventType_ALERT_REOPENED, AlertID: "al_old"},
+		{Type: alertwatch.EventType_ALERT_RAISED, AlertID: "al_new"},
+	}
+	if diff := cmp.Diff(expected, poll(t, watcher)); diff != "" {
+		t.Fatalf("%s Events mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if events := poll(t, watcher); len(events) != 0 {
+		t.Fatalf("%s expected no events without changes, got %v", t.Name(), events)
+	}
+}
+
+func TestWatcher_InitialEvents(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+
+	watcher := alertwatch.NewWatcher(server.Service(), alertwatch.WithInitialEvents())
+
+	expected := []observed{
+		{Type: alertwatch.EventType_ALERT_RAISED, AlertID: "al_existing"},
+	}
+	if diff := cmp.Diff(expected, poll(t, watcher)); diff != "" {
+		t.Fatalf("%s Events mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestWatcher_Restart(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	store := alertwatch.FileStore{Path: filepath.Join(t.TempDir(), "alerts.json")}
+
+	poll(t, alertwatch.NewWatcher(server.Service(), alertwatch.WithStore(store), alertwatch.WithInitialEvents()))
+
+	server.Alerts = append(server.Alerts, goslide.Alert{AlertID: "al_new", AlertType: goslide.AlertType_DEVICE_OUT_OF_DATE, DeviceID: "d_acme"})
+
+	// A new watcher on the same store only reports what changed while it was down
+	expected := []observed{
+		{Type: alertwatch.EventType_ALERT_RAISED, AlertID: "al_new"},
+	}
+	if diff := cmp.Diff(expected, poll(t, alertwatch.NewWatcher(server.Service(), alertwatch.WithStore(store), alertwatch.WithInitialEvents()))); diff != "" {
+		t.Fatalf("%s Events mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestWatcher_ResolvedRetention(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	resolvedAt := time.Now().Add(-2 * time.Hour)
+	server.Alerts[1].ResolvedAt = &resolvedAt
+
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	watcher := alertwatch.NewWatcher(server.Service(),
+		alertwatch.WithStore(alertwatch.FileStore{Path: path}),
+		alertwatch.WithResolvedRetention(time.Hour),
+	)
+
+	// Alerts resolved before the retention are not part of the baseline
+	poll(t, watcher)
+
+	state, err := alertwatch.FileStore{Path: path}.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := alertwatch.State{
+		Initialized: true,
+		Open:        map[string]bool{"al_existing": true},
+		Resolved:    map[string]time.Time{},
+	}
+	if diff := cmp.Diff(expected, state); diff != "" {
+		t.Fatalf("%s State mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Resolved alerts are looked up once, not listed on every poll
+	setResolved(server, "al_existing", true)
+	poll(t, watcher)
+	poll(t, watcher)
+
+	if count := server.Count("GET /v1/alert/al_existing"); count != 1 {
+		t.Errorf("%s expected one lookup of the resolved alert, got %d", t.Name(), count)
+	}
+
+	// An alert reopened after the retention is raised again
+	setResolved(server, "al_old", false)
+
+	expectedEvents := []observed{
+		{Type: alertwatch.EventType_ALERT_RAISED, AlertID: "al_old"},
+	}
+	if diff := cmp.Diff(expectedEvents, poll(t, watcher)); diff != "" {
+		t.Fatalf("%s Events mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestWatcher_HandlerError(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	watcher := alertwatch.NewWatcher(server.Service())
+	poll(t, watcher)
+
+	setResolved(server, "al_existing", true)
+
+	handlerErr := errors.New("notification failed")
+	err := watcher.Poll(context.Background(), func(event alertwatch.Event) error {
+		return handlerErr
+	})
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("%s expected the handler error, got %v", t.Name(), err)
+	}
+
+	// The event is delivered again, since the failed poll was not saved
+	expected := []observed{
+		{Type: alertwatch.EventType_ALERT_RESOLVED, AlertID: "al_existing"},
+	}
+	if diff := cmp.Diff(expected, poll(t, watcher)); diff != "" {
+		t.Fatalf("%s Events mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestWatcher_Filter(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	watcher := alertwatch.NewWatcher(server.Service(), alertwatch.WithFilter(alertwatch.Filter{
+		AlertTypes: []goslide.AlertType{goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, goslide.AlertType_AGENT_BACKUP_FAILED},
+		ClientIDs:  []string{"c_acme"},
+	}))
+	poll(t, watcher)
+
+	server.Alerts = append(server.Alerts,
+		goslide.Alert{AlertID: "al_acme_storage", AlertType: goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, DeviceID: "d_acme"},
+		goslide.Alert{AlertID: "al_acme_outdated", AlertType: goslide.AlertType_DEVICE_OUT_OF_DATE, DeviceID: "d_acme"},
+		goslide.Alert{AlertID: "al_globex_storage", AlertType: goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, DeviceID: "d_globex"},
+		goslide.Alert{AlertID: "al_acme_agent", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, AgentID: "a_dc"},
+	)
+
+	expected := []observed{
+		{Type: alertwatch.EventType_ALERT_RAISED, AlertID: "al_acme_storage"},
+		{Type: alertwatch.EventType_ALERT_RAISED, AlertID: "al_acme_agent"},
+	}
+	if diff := cmp.Diff(expected, poll(t, watcher)); diff != "" {
+		t.Fatalf("%s Events mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Clients are looked up once per device or agent
+	if count := server.Count("GET /v1/device/d_acme"); count != 1 {
+		t.Errorf("%s expected one device lookup, got %d", t.Name(), count)
+	}
+}
+
+func TestWatcher_Events(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := alertwatch.NewWatcher(server.Service(),
+		alertwatch.WithInitialEvents(),
+		alertwatch.WithPollInterval(time.Millisecond),
+	).Events(ctx)
+
+	select {
+	case event := <-events:
+		if event.Type != alertwatch.EventType_ALERT_RAISED || event.Alert.AlertID != "al_existing" {
+			t.Fatalf("%s unexpected event %+v", t.Name(), event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s timed out waiting for an event", t.Name())
+	}
+
+	cancel()
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("%s expected the watcher to stop with the context, got %v", t.Name(), err)
+	}
+}
@@ -0,0 +1,92 @@
+package alertwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/equalsgibson/goslide/internal/jsonfile"
+)
+
+// State is what the watcher knows about alerts it has already seen.
+type State struct {
+	// Initialized is set once the first poll has recorded a baseline
+	Initialized bool `json:"initialized"`
+
+	// Open holds the IDs of the alerts open at the last poll
+	Open map[string]bool `json:"open"`
+
+	// Resolved holds when alerts were resolved, by alert ID, for as long as
+	// the resolved retention of the watcher
+	Resolved map[string]time.Time `json:"resolved_at"`
+}
+
+// Store persists the watcher state between polls and restarts.
+type Store interface {
+	Load(ctx context.Context) (State, error)
+	Save(ctx context.Context, state State) error
+}
+
+// MemoryStore keeps the state in memory, so it does not survive a restart.
+type MemoryStore struct {
+	mu    sync.Mutex
+	state State
+}
+
+func (m *MemoryStore) Load(ctx context.Context) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.state.clone(), nil
+}
+
+func (m *MemoryStore) Save(ctx context.Context, state State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state = state.clone()
+
+	return nil
+}
+
+// FileStore keeps the state in a JSON file.
+type FileStore struct {
+	Path string
+}
+
+func (f FileStore) Load(ctx context.Context) (State, error) {
+	data, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return State{}, nil
+	}
+
+	if err != nil {
+		return State{}, err
+	}
+
+	state := State{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, err
+	}
+
+	return state, nil
+}
+
+// Save replaces the file, so an interrupted save never leaves a truncated
+// state file.
+func (f FileStore) Save(ctx context.Context, state State) error {
+	return jsonfile.Write(f.Path, state)
+}
+
+func (s State) clone() State {
+	return State{
+		Initialized: s.Initialized,
+		Open:        maps.Clone(s.Open),
+		Resolved:    maps.Clone(s.Resolved),
+	}
+}
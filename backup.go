@@ -60,7 +60,12 @@ func (b BackupService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Backup]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := b.requestClient.startPagination(ctx, b.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -39,7 +39,12 @@ func (c ClientService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Client]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := c.requestClient.startPagination(ctx, c.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -0,0 +1,88 @@
+// Command slide-exporter serves Prometheus metrics about the devices, agents,
+// backups, snapshots and alerts of a Slide account.
+//
+// The API token is read from the SLIDE_AUTH_TOKEN environment variable. The
+// API is polled every --refresh, independent of how often Prometheus scrapes.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/exporter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "slide-exporter: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	listen := flag.String("listen", ":9854", "address to serve metrics on")
+	refresh := flag.Duration("refresh", 5*time.Minute, "how often the Slide API is polled")
+	apiURL := flag.String("api-url", "", "host of the Slide API, such as api.slide.tech")
+	flag.Parse()
+
+	token := os.Getenv("SLIDE_AUTH_TOKEN")
+	if token == "" {
+		return errors.New("SLIDE_AUTH_TOKEN is not set")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	service := goslide.NewService(token)
+	if *apiURL != "" {
+		service = goslide.NewService(token, goslide.WithAPIURL(*apiURL))
+	}
+
+	slideExporter := exporter.New(service, exporter.WithRefreshInterval(*refresh))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		slideExporter,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	go func() {
+		_ = slideExporter.Run(ctx)
+	}()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var accountColumns = []column[goslide.Account]{
+	{"account_id", func(a goslide.Account) string { return a.AccountID }},
+	{"account_name", func(a goslide.Account) string { return a.AccountName }},
+	{"primary_contact", func(a goslide.Account) string { return a.PrimaryContact }},
+	{"primary_email", func(a goslide.Account) string { return a.PrimaryEmail }},
+	{"alert_emails", func(a goslide.Account) string { return strings.Join(a.AlertEmails, ",") }},
+}
+
+func accountsCommand() *command {
+	list := &listFlags{}
+	alertEmails := ""
+
+	return &command{
+		name:    "accounts",
+		summary: "List, show and update accounts.",
+		subcommands: []*command{
+			{
+				name:    "list",
+				summary: "List accounts.",
+				flags:   list.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					options := list.options()
+
+					accounts, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Account]) error) error {
+						return app.service.Accounts().ListWithQueryParameters(ctx, pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, accounts, accountColumns)
+				},
+			},
+			{
+				name:    "get",
+				args:    "<account-id>",
+				summary: "Show an account.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<account-id>"); err != nil {
+						return err
+					}
+
+					account, err := app.service.Accounts().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, account, accountColumns)
+				},
+			},
+			{
+				name:    "update",
+				args:    "<account-id>",
+				summary: "Replace the alert emails of an account.",
+				flags: func(flags *flag.FlagSet) {
+					flags.StringVar(&alertEmails, "alert-emails", "", "comma separated addresses that receive alerts (required)")
+				},
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<account-id>"); err != nil {
+						return err
+					}
+
+					emails := splitList(alertEmails)
+					if len(emails) == 0 {
+						return errors.New("--alert-emails is required")
+					}
+
+					account, err := app.service.Accounts().Update(ctx, args[0], emails)
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, account, accountColumns)
+				},
+			},
+		},
+	}
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var agentColumns = []column[goslide.Agent]{
+	{"agent_id", func(a goslide.Agent) string { return a.AgentID }},
+	{"display_name", func(a goslide.Agent) string { return a.DisplayName }},
+	{"hostname", func(a goslide.Agent) string { return a.Hostname }},
+	{"device_id", func(a goslide.Agent) string { return a.DeviceID }},
+	{"client_id", func(a goslide.Agent) string { return a.ClientID }},
+	{"os", func(a goslide.Agent) string { return a.OS }},
+	{"agent_version", func(a goslide.Agent) string { return a.AgentVersion }},
+	{"last_seen_at", func(a goslide.Agent) string { return formatTime(a.LastSeenAt) }},
+}
+
+func agentsCommand() *command {
+	return &command{
+		name:    "agents",
+		summary: "List, show, update and pair agents.",
+		subcommands: []*command{
+			agentsListCommand(),
+			{
+				name:    "get",
+				args:    "<agent-id>",
+				summary: "Show an agent.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<agent-id>"); err != nil {
+						return err
+					}
+
+					agent, err := app.service.Agents().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, agent, agentColumns)
+				},
+			},
+			agentsUpdateCommand(),
+			agentsAutoPairCommand(),
+			agentsPairCommand(),
+		},
+	}
+}
+
+func agentsListCommand() *command {
+	list := &listFlags{}
+	deviceID, clientID := "", ""
+
+	return &command{
+		name:    "list",
+		summary: "List agents.",
+		flags: func(flags *flag.FlagSet) {
+			list.register(flags)
+			flags.StringVar(&deviceID, "device", "", "only agents of this device")
+			flags.StringVar(&clientID, "client", "", "only agents of this client, all for every client")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+			if deviceID != "" {
+				options = append(options, goslide.WithDeviceID(deviceID))
+			}
+
+			if clientID := app.clientFilter(clientID); clientID != "" {
+				options = append(options, goslide.WithClientID(clientID))
+			}
+
+			agents, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Agent]) error) error {
+				return app.service.Agents().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, agents, agentColumns)
+		},
+	}
+}
+
+func agentsUpdateCommand() *command {
+	displayName := ""
+
+	return &command{
+		name:    "update",
+		args:    "<agent-id>",
+		summary: "Rename an agent.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&displayName, "display-name", "", "new display name")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<agent-id>"); err != nil {
+				return err
+			}
+
+			if displayName == "" {
+				return errors.New("nothing to update, set --display-name")
+			}
+
+			agent, err := app.service.Agents().Update(ctx, args[0], displayName)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, agent, agentColumns)
+		},
+	}
+}
+
+func agentsAutoPairCommand() *command {
+	payload := goslide.AgentAutoPairPayload{}
+
+	return &command{
+		name:    "auto-pair",
+		summary: "Create an agent and print the code to pair it with.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&payload.DeviceID, "device", "", "device the agent backs up to (required)")
+			flags.StringVar(&payload.DisplayName, "display-name", "", "display name of the agent")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			if payload.DeviceID == "" {
+				return errors.New("--device is required")
+			}
+
+			response, err := app.service.Agents().AutoPair(ctx, payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, response, []column[goslide.AgentAutoPairResponse]{
+				{"agent_id", func(r goslide.AgentAutoPairResponse) string { return r.AgentID }},
+				{"display_name", func(r goslide.AgentAutoPairResponse) string { return r.DisplayName }},
+				{"pair_code", func(r goslide.AgentAutoPairResponse) string { return r.PairCode }},
+			})
+		},
+	}
+}
+
+func agentsPairCommand() *command {
+	payload := goslide.AgentPairPayload{}
+
+	return &command{
+		name:    "pair",
+		summary: "Pair an installed agent with a device using its pair code.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&payload.DeviceID, "device", "", "device to pair with (required)")
+			flags.StringVar(&payload.PairCode, "code", "", "pair code of the agent (required)")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			if payload.DeviceID == "" || payload.PairCode == "" {
+				return errors.New("--device and --code are required")
+			}
+
+			agent, err := app.service.Agents().Pair(ctx, payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, agent, agentColumns)
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var alertColumns = []column[goslide.Alert]{
+	{"alert_id", func(a goslide.Alert) string { return a.AlertID }},
+	{"alert_type", func(a goslide.Alert) string { return string(a.AlertType) }},
+	{"device_id", func(a goslide.Alert) string { return a.DeviceID }},
+	{"agent_id", func(a goslide.Alert) string { return a.AgentID }},
+	{"created_at", func(a goslide.Alert) string { return formatTime(a.CreatedAt) }},
+	{"resolved", func(a goslide.Alert) string { return strconv.FormatBool(a.Resolved) }},
+	{"resolved_at", func(a goslide.Alert) string { return formatTimePointer(a.ResolvedAt) }},
+}
+
+func alertsCommand() *command {
+	return &command{
+		name:    "alerts",
+		summary: "List, show and resolve alerts.",
+		subcommands: []*command{
+			alertsListCommand(),
+			{
+				name:    "get",
+				args:    "<alert-id>",
+				summary: "Show an alert.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<alert-id>"); err != nil {
+						return err
+					}
+
+					alert, err := app.service.Alerts().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, alert, alertColumns)
+				},
+			},
+			alertsUpdateCommand("resolve", "Mark an alert as resolved.", true),
+			alertsUpdateCommand("unresolve", "Mark a resolved alert as unresolved.", false),
+		},
+	}
+}
+
+func alertsListCommand() *command {
+	list := &listFlags{}
+	deviceID, agentID := "", ""
+	resolved := false
+
+	return &command{
+		name:    "list",
+		summary: "List unresolved alerts.",
+		flags: func(flags *flag.FlagSet) {
+			list.register(flags)
+			flags.StringVar(&deviceID, "device", "", "only alerts of this device")
+			flags.StringVar(&agentID, "agent", "", "only alerts of this agent")
+			flags.BoolVar(&resolved, "resolved", false, "include resolved alerts")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+			if deviceID != "" {
+				options = append(options, goslide.WithDeviceID(deviceID))
+			}
+
+			if agentID != "" {
+				options = append(options, goslide.WithAgentID(agentID))
+			}
+
+			if resolved {
+				options = append(options, goslide.WithIncludeResolvedAlerts(true))
+			}
+
+			alerts, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Alert]) error) error {
+				return app.service.Alerts().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, alerts, alertColumns)
+		},
+	}
+}
+
+func alertsUpdateCommand(name, summary string, resolved bool) *command {
+	return &command{
+		name:    name,
+		args:    "<alert-id>",
+		summary: summary,
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<alert-id>"); err != nil {
+				return err
+			}
+
+			alert, err := app.service.Alerts().Update(ctx, args[0], resolved)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, alert, alertColumns)
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var backupColumns = []column[goslide.Backup]{
+	{"backup_id", func(b goslide.Backup) string { return b.BackupID }},
+	{"agent_id", func(b goslide.Backup) string { return b.AgentID }},
+	{"status", func(b goslide.Backup) string { return string(b.Status) }},
+	{"started_at", func(b goslide.Backup) string { return formatTime(b.StartedAt) }},
+	{"ended_at", func(b goslide.Backup) string { return formatTime(b.EndedAt) }},
+	{"snapshot_id", func(b goslide.Backup) string { return b.SnapshotID }},
+	{"error", func(b goslide.Backup) string { return b.ErrorMessage }},
+}
+
+func backupsCommand() *command {
+	return &command{
+		name:    "backups",
+		summary: "List, show and start backups.",
+		subcommands: []*command{
+			backupsListCommand(),
+			{
+				name:    "get",
+				args:    "<backup-id>",
+				summary: "Show a backup.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<backup-id>"); err != nil {
+						return err
+					}
+
+					backup, err := app.service.Backups().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, backup, backupColumns)
+				},
+			},
+			{
+				name:    "start",
+				args:    "<agent-id>",
+				summary: "Start a backup of an agent.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<agent-id>"); err != nil {
+						return err
+					}
+
+					if err := app.service.Backups().StartBackup(ctx, args[0]); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(app.stderr, "Started a backup of agent %s\n", args[0])
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func backupsListCommand() *command {
+	list := &listFlags{}
+	agentID, deviceID := "", ""
+
+	return &command{
+		name:    "list",
+		summary: "List backups.",
+		flags: func(flags *flag.FlagSet) {
+			list.register(flags)
+			flags.StringVar(&agentID, "agent", "", "only backups of this agent")
+			flags.StringVar(&deviceID, "device", "", "only backups to this device")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+			if agentID != "" {
+				options = append(options, goslide.WithAgentID(agentID))
+			}
+
+			if deviceID != "" {
+				options = append(options, goslide.WithDeviceID(deviceID))
+			}
+
+			backups, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Backup]) error) error {
+				return app.service.Backups().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, backups, backupColumns)
+		},
+	}
+}
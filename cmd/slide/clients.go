@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var clientColumns = []column[goslide.Client]{
+	{"client_id", func(c goslide.Client) string { return c.ClientID }},
+	{"name", func(c goslide.Client) string { return c.Name }},
+	{"comments", func(c goslide.Client) string { return c.Comments }},
+}
+
+func clientsCommand() *command {
+	return &command{
+		name:    "clients",
+		summary: "List, show, create, update and delete clients.",
+		subcommands: []*command{
+			clientsListCommand(),
+			{
+				name:    "get",
+				args:    "<client-id>",
+				summary: "Show a client.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<client-id>"); err != nil {
+						return err
+					}
+
+					client, err := app.service.Clients().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, client, clientColumns)
+				},
+			},
+			clientsCreateCommand(),
+			clientsUpdateCommand(),
+			{
+				name:    "delete",
+				args:    "<client-id>",
+				summary: "Delete a client.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<client-id>"); err != nil {
+						return err
+					}
+
+					if err := app.service.Clients().Delete(ctx, args[0]); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(app.stderr, "Deleted client %s\n", args[0])
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func clientsListCommand() *command {
+	list := &listFlags{}
+
+	return &command{
+		name:    "list",
+		summary: "List clients.",
+		flags:   list.register,
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+
+			clients, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Client]) error) error {
+				return app.service.Clients().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, clients, clientColumns)
+		},
+	}
+}
+
+func clientsCreateCommand() *command {
+	payload := goslide.ClientPayload{}
+
+	return &command{
+		name:    "create",
+		summary: "Create a client.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&payload.Name, "name", "", "name of the client (required)")
+			flags.StringVar(&payload.Comments, "comments", "", "comments about the client")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			if payload.Name == "" {
+				return errors.New("--name is required")
+			}
+
+			client, err := app.service.Clients().Create(ctx, payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, client, clientColumns)
+		},
+	}
+}
+
+func clientsUpdateCommand() *command {
+	payload := goslide.ClientPayload{}
+
+	return &command{
+		name:    "update",
+		args:    "<client-id>",
+		summary: "Update the name or comments of a client.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&payload.Name, "name", "", "new name")
+			flags.StringVar(&payload.Comments, "comments", "", "new comments")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<client-id>"); err != nil {
+				return err
+			}
+
+			if payload == (goslide.ClientPayload{}) {
+				return errors.New("nothing to update, set --name or --comments")
+			}
+
+			client, err := app.service.Clients().Update(ctx, args[0], payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, client, clientColumns)
+		},
+	}
+}
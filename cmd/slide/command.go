@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// command is a node of the command tree. Commands with subcommands only
+// dispatch, the others run.
+type command struct {
+	name    string
+	args    string
+	summary string
+
+	flags       func(flags *flag.FlagSet)
+	run         func(ctx context.Context, app *app, args []string) error
+	subcommands []*command
+
+	// local commands run without connecting to the API
+	local bool
+}
+
+// errUsage is returned after the usage of a command was printed.
+var errUsage = errors.New("invalid usage")
+
+func (c *command) subcommand(name string) *command {
+	for _, subcommand := range c.subcommands {
+		if subcommand.name == name {
+			return subcommand
+		}
+	}
+
+	return nil
+}
+
+// execute walks down the tree along args and runs the command it ends at.
+// Flags may appear anywhere after the command they belong to.
+func (c *command) execute(ctx context.Context, app *app, path []string, args []string) error {
+	path = append(path, c.name)
+	flags := c.flagSet(app, path)
+
+	if len(c.subcommands) > 0 {
+		// Global flags may come before the subcommand
+		if err := flags.Parse(args); err != nil {
+			return c.usageError(app, flags, err)
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			c.usage(app.stderr, flags)
+
+			return errUsage
+		}
+
+		if args[0] == "help" {
+			c.usage(app.stdout, flags)
+
+			return nil
+		}
+
+		subcommand := c.subcommand(args[0])
+		if subcommand == nil {
+			return c.usageError(app, flags, fmt.Errorf("unknown command %q", args[0]))
+		}
+
+		return subcommand.execute(ctx, app, path, args[1:])
+	}
+
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return c.usageError(app, flags, err)
+	}
+
+	if err := app.init(); err != nil {
+		return err
+	}
+
+	if !c.local {
+		if err := app.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	return c.run(ctx, app, positional)
+}
+
+// flagSet registers the global flags and the flags of the command.
+func (c *command) flagSet(app *app, path []string) *flag.FlagSet {
+	flags := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	app.globalFlags(flags)
+	if c.flags != nil {
+		c.flags(flags)
+	}
+
+	return flags
+}
+
+func (c *command) usageError(app *app, flags *flag.FlagSet, err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		c.usage(app.stdout, flags)
+
+		return nil
+	}
+
+	fmt.Fprintf(app.stderr, "%s\n\n", err)
+	c.usage(app.stderr, flags)
+
+	return errUsage
+}
+
+func (c *command) usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s", flags.Name())
+	if len(c.subcommands) > 0 {
+		fmt.Fprint(w, " <command>")
+	}
+	if c.args != "" {
+		fmt.Fprintf(w, " %s", c.args)
+	}
+	fmt.Fprint(w, " [flags]\n")
+
+	if c.summary != "" {
+		fmt.Fprintf(w, "\n%s\n", c.summary)
+	}
+
+	if len(c.subcommands) > 0 {
+		fmt.Fprint(w, "\nCommands:\n")
+
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		for _, subcommand := range c.subcommands {
+			fmt.Fprintf(tw, "  %s\t%s\n", subcommand.name, subcommand.summary)
+		}
+		tw.Flush()
+	}
+
+	names := []string{}
+	flags.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		fmt.Fprint(w, "\nFlags:\n")
+
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		for _, name := range names {
+			f := flags.Lookup(name)
+
+			prefix := "--"
+			if len(f.Name) == 1 {
+				prefix = "-"
+			}
+			fmt.Fprintf(tw, "  %s%s\t%s\n", prefix, f.Name, f.Usage)
+		}
+		tw.Flush()
+	}
+}
+
+// parseInterspersed parses flags that appear before, between and after the
+// positional arguments, which the flag package stops at.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// exactArgs checks the number of positional arguments of a command.
+func exactArgs(args []string, names ...string) error {
+	if len(args) != len(names) {
+		return fmt.Errorf("expected %d argument(s): %s", len(names), strings.Join(names, " "))
+	}
+
+	return nil
+}
+
+// optionalBool is a boolean flag that tells whether it was set, for payloads
+// where false and unset differ.
+type optionalBool struct {
+	value *bool
+}
+
+func (o *optionalBool) String() string {
+	if o == nil || o.value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(*o.value)
+}
+
+func (o *optionalBool) Set(s string) error {
+	switch s {
+	case "true", "1":
+		o.value = new(bool)
+		*o.value = true
+	case "false", "0":
+		o.value = new(bool)
+	default:
+		return fmt.Errorf("invalid boolean %q", s)
+	}
+
+	return nil
+}
+
+func (o *optionalBool) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// completeCommand is run by the completion scripts with the words of the
+// command line, the last one being the word to complete. It prints one
+// candidate per line, optionally followed by a tab and a description.
+const completeCommand = "__complete"
+
+const bashCompletion = `# bash completion for slide
+_slide() {
+    local IFS=$'\n'
+    local candidates
+    candidates=$(slide __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null) || return
+    COMPREPLY=($(compgen -W "$(printf '%s\n' "$candidates" | cut -f1)" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+
+complete -o default -F _slide slide
+`
+
+const zshCompletion = `#compdef slide
+
+_slide() {
+    local -a candidates
+    local line
+    for line in "${(@f)$(slide __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] || continue
+        if [[ $line == *$'\t'* ]]; then
+            candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${line//:/\\:}")
+        fi
+    done
+    _describe 'slide' candidates
+}
+
+compdef _slide slide
+`
+
+const fishCompletion = `# fish completion for slide
+function __slide_complete
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    slide __complete $tokens[2..-1] "$current" 2>/dev/null
+end
+
+complete -c slide -f -a '(__slide_complete)'
+`
+
+func completionCommand() *command {
+	scripts := map[string]string{
+		"bash": bashCompletion,
+		"zsh":  zshCompletion,
+		"fish": fishCompletion,
+	}
+
+	return &command{
+		name:    "completion",
+		args:    "bash|zsh|fish",
+		summary: "Print the shell completion script, for example: source <(slide completion bash)",
+		local:   true,
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "bash|zsh|fish"); err != nil {
+				return err
+			}
+
+			script, ok := scripts[args[0]]
+			if !ok {
+				return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", args[0])
+			}
+
+			_, err := fmt.Fprint(app.stdout, script)
+
+			return err
+		},
+	}
+}
+
+type completion struct {
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+// complete prints the candidates for the last word. Errors are written to
+// stderr, which the completion scripts discard, so a failed lookup only
+// means no candidates.
+func (a *app) complete(ctx context.Context, words []string) error {
+	completions, err := a.completions(ctx, words)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "slide: %s\n", err)
+
+		return nil
+	}
+
+	for _, completion := range completions {
+		if completion.Description == "" {
+			fmt.Fprintln(a.stdout, completion.Value)
+
+			continue
+		}
+
+		// Descriptions are a single line
+		description := strings.Join(strings.Fields(completion.Description), " ")
+		fmt.Fprintf(a.stdout, "%s\t%s\n", completion.Value, description)
+	}
+
+	return nil
+}
+
+func (a *app) completions(ctx context.Context, words []string) ([]completion, error) {
+	current := ""
+	if len(words) > 0 {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	// Walk down the tree the way execute does, applying the flags so that
+	// the global flags and filters such as --agent take effect
+	c := rootCommand()
+	path := []string{c.name}
+	flags := c.flagSet(a, path)
+	positional := []string{}
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		if strings.HasPrefix(word, "-") && word != "-" {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+
+			f := flags.Lookup(name)
+			if f == nil {
+				continue
+			}
+
+			if !hasValue {
+				if isBoolFlag(f) {
+					value = "true"
+				} else if i+1 < len(words) {
+					i++
+					value = words[i]
+				} else {
+					// The word to complete is the value of this flag
+					completions, err := a.completeFlagValue(ctx, flags, f.Name)
+
+					return filterCompletions(completions, current), err
+				}
+			}
+
+			// An invalid value only loses that filter
+			_ = flags.Set(name, value)
+
+			continue
+		}
+
+		if subcommand := c.subcommand(word); subcommand != nil && len(positional) == 0 {
+			c = subcommand
+			path = append(path, c.name)
+			flags = c.flagSet(a, path)
+
+			continue
+		}
+
+		positional = append(positional, word)
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return filterCompletions(flagCompletions(flags), current), nil
+	}
+
+	if len(c.subcommands) > 0 {
+		completions := []completion{}
+		for _, subcommand := range c.subcommands {
+			completions = append(completions, completion{Value: subcommand.name, Description: subcommand.summary})
+		}
+
+		return filterCompletions(completions, current), nil
+	}
+
+	resource := argResource(c.args, len(positional))
+	if resource == "" {
+		return nil, nil
+	}
+
+	completions, err := a.completeResource(ctx, flags, resource)
+
+	return filterCompletions(completions, current), err
+}
+
+// completeFlagValue returns the candidates for the value of a flag.
+func (a *app) completeFlagValue(ctx context.Context, flags *flag.FlagSet, name string) ([]completion, error) {
+	switch name {
+	case "output", "o", "default-output":
+		return []completion{
+			{Value: string(outputFormat_TABLE)},
+			{Value: string(outputFormat_JSON)},
+			{Value: string(outputFormat_YAML)},
+			{Value: string(outputFormat_CSV)},
+		}, nil
+	case "profile":
+		return a.completeProfiles()
+	}
+
+	if _, ok := resources[name]; ok {
+		return a.completeResource(ctx, flags, name)
+	}
+
+	return nil, nil
+}
+
+func (a *app) completeProfiles() ([]completion, error) {
+	config, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	completions := []completion{}
+	for name, profile := range config.Profiles {
+		description := profile.Token.String()
+		if name == config.DefaultProfile {
+			description += " (default)"
+		}
+
+		completions = append(completions, completion{Value: name, Description: description})
+	}
+
+	sort.Slice(completions, func(i, j int) bool {
+		return completions[i].Value < completions[j].Value
+	})
+
+	return completions, nil
+}
+
+func flagCompletions(flags *flag.FlagSet) []completion {
+	completions := []completion{}
+	flags.VisitAll(func(f *flag.Flag) {
+		prefix := "--"
+		if len(f.Name) == 1 {
+			prefix = "-"
+		}
+
+		completions = append(completions, completion{Value: prefix + f.Name, Description: f.Usage})
+	})
+
+	return completions
+}
+
+// argResource returns the resource of the positional argument at index,
+// from placeholders such as <agent-id>.
+func argResource(args string, index int) string {
+	placeholders := strings.Fields(args)
+	if index >= len(placeholders) {
+		return ""
+	}
+
+	name, ok := strings.CutSuffix(strings.Trim(placeholders[index], "[]<>."), "-id")
+	if !ok {
+		return ""
+	}
+
+	if _, ok := resources[name]; !ok {
+		return ""
+	}
+
+	return name
+}
+
+func filterCompletions(completions []completion, prefix string) []completion {
+	filtered := []completion{}
+	for _, completion := range completions {
+		if strings.HasPrefix(completion.Value, prefix) {
+			filtered = append(filtered, completion)
+		}
+	}
+
+	return filtered
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+
+	return ok && boolFlag.IsBoolFlag()
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	// maxCompletions keeps lookups of large lists such as snapshots fast
+	maxCompletions     = 200
+	completionTimeout  = 10 * time.Second
+	completionCacheTTL = time.Minute
+)
+
+type resourceCompleter struct {
+	// filters are the flags of the command line that narrow the list
+	filters []string
+	list    func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error)
+}
+
+// completionFilters turn the value of a filter flag into a query option.
+var completionFilters = map[string]func(id string) func(u url.Values){
+	"agent": func(id string) func(u url.Values) {
+		return goslide.WithAgentID(id)
+	},
+	"device": func(id string) func(u url.Values) {
+		return goslide.WithDeviceID(id)
+	},
+}
+
+// resources are completed by flag name, such as --agent, and by positional
+// argument placeholder, such as <agent-id>.
+var resources = map[string]resourceCompleter{
+	"account": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Account]) error) error {
+				return service.Accounts().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(account goslide.Account) completion {
+				return completion{Value: account.AccountID, Description: account.AccountName}
+			})
+		},
+	},
+	"agent": {
+		filters: []string{"device"},
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Agent]) error) error {
+				return service.Agents().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(agent goslide.Agent) completion {
+				return completion{Value: agent.AgentID, Description: agentName(agent)}
+			})
+		},
+	},
+	"alert": {
+		filters: []string{"agent", "device"},
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Alert]) error) error {
+				return service.Alerts().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(alert goslide.Alert) completion {
+				return completion{Value: alert.AlertID, Description: string(alert.AlertType) + " " + formatTime(alert.CreatedAt)}
+			})
+		},
+	},
+	"backup": {
+		filters: []string{"agent", "device"},
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Backup]) error) error {
+				return service.Backups().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(backup goslide.Backup) completion {
+				return completion{Value: backup.BackupID, Description: formatTime(backup.StartedAt) + " " + string(backup.Status)}
+			})
+		},
+	},
+	"client": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Client]) error) error {
+				return service.Clients().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(client goslide.Client) completion {
+				return completion{Value: client.ClientID, Description: client.Name}
+			})
+		},
+	},
+	"device": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Device]) error) error {
+				return service.Devices().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(device goslide.Device) completion {
+				return completion{Value: device.DeviceID, Description: deviceName(device)}
+			})
+		},
+	},
+	"file-restore": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.FileRestore]) error) error {
+				return service.FileRestores().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(restore goslide.FileRestore) completion {
+				return completion{Value: restore.FileRestoreID, Description: "snapshot " + restore.SnapshotID + " created " + formatTime(restore.CreatedAt)}
+			})
+		},
+	},
+	"image-export": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.ImageExportRestore]) error) error {
+				return service.ImageExportRestores().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(export goslide.ImageExportRestore) completion {
+				return completion{Value: export.ImageExportID, Description: string(export.ImageType) + " of snapshot " + export.SnapshotID}
+			})
+		},
+	},
+	"network": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Network]) error) error {
+				return service.Networks().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(network goslide.Network) completion {
+				return completion{Value: network.NetworkID, Description: network.Name}
+			})
+		},
+	},
+	"snapshot": {
+		filters: []string{"agent"},
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.Snapshot]) error) error {
+				return service.Snapshots().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(snapshot goslide.Snapshot) completion {
+				return completion{Value: snapshot.SnapshotID, Description: "backup ended " + formatTime(snapshot.BackupEndedAt)}
+			})
+		},
+	},
+	"user": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.User]) error) error {
+				return service.Users().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(user goslide.User) completion {
+				return completion{Value: user.UserID, Description: strings.TrimSpace(user.DisplayName + " " + user.Email)}
+			})
+		},
+	},
+	"virt": {
+		list: func(ctx context.Context, service goslide.Service, options listOptions) ([]completion, error) {
+			return listCompletions(func(pageHandler func(goslide.ListResponse[goslide.VirtualMachineRestore]) error) error {
+				return service.VirtualMachineRestores().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			}, func(vm goslide.VirtualMachineRestore) completion {
+				return completion{Value: vm.VirtID, Description: string(vm.State) + " snapshot " + vm.SnapshotID}
+			})
+		},
+	},
+}
+
+func listCompletions[T any](
+	list func(pageHandler func(response goslide.ListResponse[T]) error) error,
+	describe func(record T) completion,
+) ([]completion, error) {
+	records, err := collect(maxCompletions, list)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := make([]completion, 0, len(records))
+	for _, record := range records {
+		completions = append(completions, describe(record))
+	}
+
+	return completions, nil
+}
+
+// completeResource lists the IDs of a resource, narrowed by the filter flags
+// already on the command line. Results are cached briefly, as every press of
+// tab runs a new process.
+func (a *app) completeResource(ctx context.Context, flags *flag.FlagSet, name string) ([]completion, error) {
+	resource := resources[name]
+
+	options := listOptions{}
+	key := []string{name}
+	for _, filter := range resource.filters {
+		f := flags.Lookup(filter)
+		if f == nil || f.Value.String() == "" {
+			continue
+		}
+
+		options = append(options, completionFilters[filter](f.Value.String()))
+		key = append(key, filter+"="+f.Value.String())
+	}
+
+	if err := a.init(); err != nil {
+		return nil, err
+	}
+
+	// The cache is per account, which is the profile or the token itself
+	if a.profile != nil {
+		configPath, err := a.configFile()
+		if err != nil {
+			return nil, err
+		}
+
+		key = append(key, "profile="+a.profileName, "config="+configPath)
+	} else {
+		key = append(key, "token="+a.getenv("SLIDE_AUTH_TOKEN"))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(key, "\x00")))
+	cachePath, err := a.completionCachePath(hex.EncodeToString(sum[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	if completions, ok := readCompletionCache(cachePath); ok {
+		return completions, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	if err := a.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	completions, err := resource.list(ctx, a.service, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cache that cannot be written only makes the next completion slower
+	_ = writeCompletionCache(cachePath, completions)
+
+	return completions, nil
+}
+
+func (a *app) completionCachePath(key string) (string, error) {
+	dir := a.cacheDir
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		dir = filepath.Join(userCacheDir, "slide")
+	}
+
+	return filepath.Join(dir, "completion", key+".json"), nil
+}
+
+func readCompletionCache(path string) ([]completion, bool) {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > completionCacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	completions := []completion{}
+	if err := json.Unmarshal(data, &completions); err != nil {
+		return nil, false
+	}
+
+	return completions, true
+}
+
+func writeCompletionCache(path string, completions []completion) error {
+	data, err := json.Marshal(completions)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newCompletionServer returns the shared fleet with a snapshot of each Acme
+// agent.
+func newCompletionServer() *fakeslide.Server {
+	server := fakeslide.NewFleet()
+	server.Snapshots = []goslide.Snapshot{
+		{SnapshotID: "s_1", AgentID: "a_dc", BackupEndedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{SnapshotID: "s_2", AgentID: "a_app", BackupEndedAt: time.Date(2025, 1, 3, 3, 4, 5, 0, time.UTC)},
+	}
+
+	return server
+}
+
+func TestCompletion(t *testing.T) {
+	testCases := []struct {
+		name     string
+		words    []string
+		expected []string
+	}{
+		{name: "Commands", words: []string{"de"}, expected: []string{"devices"}},
+		{name: "Subcommands", words: []string{"devices", ""}, expected: []string{"list", "get", "update"}},
+		{name: "Flags", words: []string{"snapshots", "list", "--a"}, expected: []string{"--agent", "--asc"}},
+		{name: "FlagValues", words: []string{"-o", ""}, expected: []string{"table", "json", "yaml", "csv"}},
+		{name: "ArgumentIDs", words: []string{"backups", "start", ""}, expected: []string{"a_dc", "a_app", "a_mail"}},
+		{name: "OnlyOneArgument", words: []string{"backups", "start", "a_dc", ""}, expected: []string{}},
+		{name: "FlagIDs", words: []string{"--output", "json", "alerts", "list", "--device", "d_"}, expected: []string{"d_acme", "d_acme_branch", "d_globex"}},
+		{name: "FilteredByAgent", words: []string{"restores", "file", "create", "--agent", "a_dc", "--snapshot", ""}, expected: []string{"s_1"}},
+		{name: "FilteredByDevice", words: []string{"alerts", "list", "--device=d_globex", "--limit", "1", "--agent", ""}, expected: []string{"a_mail"}},
+		{name: "Unfiltered", words: []string{"snapshots", "get", "s"}, expected: []string{"s_1", "s_2"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			stdout, stderr, err := runSlide(t, newCompletionServer(), tokenEnv, append([]string{completeCommand}, testCase.words...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if stderr != "" {
+				t.Fatalf("%s unexpected stderr: %s", t.Name(), stderr)
+			}
+
+			values := []string{}
+			for _, line := range strings.Split(strings.TrimSuffix(stdout, "\n"), "\n") {
+				if value, _, _ := strings.Cut(line, "\t"); value != "" {
+					values = append(values, value)
+				}
+			}
+
+			if diff := cmp.Diff(testCase.expected, values); diff != "" {
+				t.Fatalf("%s Completions mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+}
+
+func TestCompletion_DescriptionsAndCache(t *testing.T) {
+	server := newCompletionServer()
+	app, stdout, _ := newTestApp(t, server, tokenEnv, "")
+
+	if err := app.run(context.Background(), []string{completeCommand, "snapshots", "list", "--agent", ""}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a_dc\tAcme DC01\na_app\tacme-app\na_mail\tglobex-mail\n"
+	if diff := cmp.Diff(expected, stdout.String()); diff != "" {
+		t.Fatalf("%s Completion output mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// A second shell within the cache lifetime does not call the API
+	requests := len(server.Requests)
+	server.Agents = server.Agents[:1]
+
+	cached, _, _ := newTestApp(t, server, tokenEnv, "")
+	cached.cacheDir = app.cacheDir
+	stdout.Reset()
+	cached.stdout = stdout
+
+	if err := cached.run(context.Background(), []string{completeCommand, "backups", "start", "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, stdout.String()); diff != "" {
+		t.Fatalf("%s Cached completion mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if len(server.Requests) != requests {
+		t.Fatalf("%s expected no API requests, got %v", t.Name(), server.Requests[requests:])
+	}
+
+	// Another account does not share the cache
+	other, stdout, _ := newTestApp(t, server, testEnv{"SLIDE_AUTH_TOKEN": "otherToken"}, "")
+	other.cacheDir = app.cacheDir
+
+	if err := other.run(context.Background(), []string{completeCommand, "backups", "start", ""}); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff("a_dc\tAcme DC01\n", stdout.String()); diff != "" {
+		t.Fatalf("%s Completion of another account mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestCompletion_Scripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		stdout, _, err := runSlide(t, fakeslide.NewFleet(), testEnv{}, "completion", shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(stdout, "slide __complete") {
+			t.Errorf("%s expected the %s script to call slide __complete, got:\n%s", t.Name(), shell, stdout)
+		}
+	}
+
+	if _, _, err := runSlide(t, fakeslide.NewFleet(), testEnv{}, "completion", "powershell"); err == nil {
+		t.Fatalf("%s expected an error for an unsupported shell", t.Name())
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+// keyringService is the service name tokens are stored under in the OS keyring.
+const keyringService = "slide"
+
+// config is the file profiles are read from. It never holds a token, only
+// where to find one.
+type config struct {
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]profile `yaml:"profiles,omitempty"`
+}
+
+type profile struct {
+	Token   tokenSource  `yaml:"token"`
+	BaseURL string       `yaml:"base_url,omitempty"`
+	Output  outputFormat `yaml:"output,omitempty"`
+	Client  string       `yaml:"client,omitempty"`
+}
+
+// tokenSource tells where the token of a profile is read from. Exactly one
+// field is set.
+type tokenSource struct {
+	// Env is the name of an environment variable
+	Env string `yaml:"env,omitempty"`
+
+	// File is the path of a file holding only the token
+	File string `yaml:"file,omitempty"`
+
+	// Keyring is the account the token is stored under in the OS keyring
+	Keyring string `yaml:"keyring,omitempty"`
+
+	// Command is run without a shell, and prints the token on stdout
+	Command []string `yaml:"command,omitempty"`
+}
+
+func (s *tokenSource) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return fmt.Errorf("line %d: tokens cannot be stored in the config, use env, file, keyring or command", node.Line)
+	}
+
+	type plain tokenSource
+
+	return node.Decode((*plain)(s))
+}
+
+func (s tokenSource) validate() error {
+	set := 0
+	for _, ok := range []bool{s.Env != "", s.File != "", s.Keyring != "", len(s.Command) > 0} {
+		if ok {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return errors.New("the token source needs exactly one of env, file, keyring or command")
+	}
+
+	return nil
+}
+
+func (s tokenSource) String() string {
+	switch {
+	case s.Env != "":
+		return "env:" + s.Env
+	case s.File != "":
+		return "file:" + s.File
+	case s.Keyring != "":
+		return "keyring:" + s.Keyring
+	case len(s.Command) > 0:
+		return "command:" + strings.Join(s.Command, " ")
+	default:
+		return ""
+	}
+}
+
+func (s tokenSource) resolve(ctx context.Context, app *app) (string, error) {
+	if err := s.validate(); err != nil {
+		return "", err
+	}
+
+	token := ""
+	switch {
+	case s.Env != "":
+		token = app.getenv(s.Env)
+	case s.File != "":
+		data, err := os.ReadFile(expandHome(s.File))
+		if err != nil {
+			return "", fmt.Errorf("reading token file - %w", err)
+		}
+
+		token = string(data)
+	case s.Keyring != "":
+		secret, err := keyring.Get(keyringService, s.Keyring)
+		if err != nil {
+			return "", fmt.Errorf("reading token from keyring - %w", err)
+		}
+
+		token = secret
+	default:
+		stdout := &bytes.Buffer{}
+
+		command := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+		command.Stdout = stdout
+		command.Stderr = app.stderr
+		if err := command.Run(); err != nil {
+			return "", fmt.Errorf("running token command - %w", err)
+		}
+
+		token = stdout.String()
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token found in %s", s)
+	}
+
+	return token, nil
+}
+
+// apiHost returns the host of a base URL such as https://api.slide.tech. A
+// bare host is accepted too, and an empty URL selects the default host.
+func apiHost(baseURL string) (string, error) {
+	if baseURL == "" || !strings.Contains(baseURL, "://") {
+		return baseURL, nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL - %w", err)
+	}
+
+	if u.Scheme != "https" || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		return "", fmt.Errorf("invalid base URL %q, expected https://host", baseURL)
+	}
+
+	return u.Host, nil
+}
+
+// configFile returns the path of the config file.
+func (a *app) configFile() (string, error) {
+	if a.configPath != "" {
+		return a.configPath, nil
+	}
+
+	if path := a.getenv("SLIDE_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "slide", "config.yaml"), nil
+}
+
+// loadConfig reads the config file. A missing file is an empty config, so
+// the first profile can be added.
+func (a *app) loadConfig() (config, error) {
+	path, err := a.configFile()
+	if err != nil {
+		// Without a home directory there is no default config
+		return config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config{}, nil
+	}
+
+	if err != nil {
+		return config{}, fmt.Errorf("reading config - %w", err)
+	}
+
+	result := config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return config{}, fmt.Errorf("parsing config %s - %w", path, err)
+	}
+
+	return result, nil
+}
+
+// saveConfig replaces the config file atomically.
+func (a *app) saveConfig(config config) (string, error) {
+	path, err := a.configFile()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return path, os.Rename(file.Name(), path)
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var deviceColumns = []column[goslide.Device]{
+	{"device_id", func(d goslide.Device) string { return d.DeviceID }},
+	{"display_name", func(d goslide.Device) string { return d.DisplayName }},
+	{"hostname", func(d goslide.Device) string { return d.Hostname }},
+	{"client_id", func(d goslide.Device) string { return d.ClientID }},
+	{"model", func(d goslide.Device) string { return d.HardwareModelName }},
+	{"storage_used", func(d goslide.Device) string { return formatBytes(d.StorageUsedBytes) }},
+	{"storage_total", func(d goslide.Device) string { return formatBytes(d.StorageTotalBytes) }},
+	{"nfr", func(d goslide.Device) string { return strconv.FormatBool(d.NFR) }},
+	{"last_seen_at", func(d goslide.Device) string { return formatTime(d.LastSeenAt) }},
+}
+
+func devicesCommand() *command {
+	return &command{
+		name:    "devices",
+		summary: "List, show and update devices.",
+		subcommands: []*command{
+			devicesListCommand(),
+			{
+				name:    "get",
+				args:    "<device-id>",
+				summary: "Show a device.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<device-id>"); err != nil {
+						return err
+					}
+
+					device, err := app.service.Devices().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, device, deviceColumns)
+				},
+			},
+			devicesUpdateCommand(),
+		},
+	}
+}
+
+func devicesListCommand() *command {
+	list := &listFlags{}
+	clientID := ""
+
+	return &command{
+		name:    "list",
+		summary: "List devices.",
+		flags: func(flags *flag.FlagSet) {
+			list.register(flags)
+			flags.StringVar(&clientID, "client", "", "only devices of this client, all for every client")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+			if clientID := app.clientFilter(clientID); clientID != "" {
+				options = append(options, goslide.WithClientID(clientID))
+			}
+
+			devices, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Device]) error) error {
+				return app.service.Devices().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, devices, deviceColumns)
+		},
+	}
+}
+
+func devicesUpdateCommand() *command {
+	payload := goslide.DevicePayload{}
+
+	return &command{
+		name:    "update",
+		args:    "<device-id>",
+		summary: "Update the display name, hostname or client of a device.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&payload.DisplayName, "display-name", "", "new display name")
+			flags.StringVar(&payload.Hostname, "hostname", "", "new hostname")
+			flags.StringVar(&payload.ClientID, "client", "", "move the device to this client")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<device-id>"); err != nil {
+				return err
+			}
+
+			if payload == (goslide.DevicePayload{}) {
+				return errors.New("nothing to update, set --display-name, --hostname or --client")
+			}
+
+			device, err := app.service.Devices().Update(ctx, args[0], payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, device, deviceColumns)
+		},
+	}
+}
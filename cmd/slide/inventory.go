@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/equalsgibson/goslide/inventory"
+)
+
+func inventoryCommand() *command {
+	return &command{
+		name:    "inventory",
+		summary: "Export the inventory of devices and agents per client.",
+		subcommands: []*command{
+			inventoryExportCommand(),
+			{
+				name:    "columns",
+				summary: "List the columns of devices and agents.",
+				local:   true,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					return printList(app, inventoryColumns(), inventoryColumnColumns)
+				},
+			},
+		},
+	}
+}
+
+func inventoryExportCommand() *command {
+	format := "json"
+	out := ""
+	previous := ""
+	deviceColumns := ""
+	agentColumns := ""
+
+	return &command{
+		name:    "export",
+		summary: "Export the inventory as JSON, CSV or XLSX, with the changes since a previous JSON export.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&format, "format", format, "export format: json, csv or xlsx")
+			flags.StringVar(&out, "out", "", "file to write, a directory of a file per table for csv, standard output for json when empty")
+			flags.StringVar(&previous, "previous", "", "previous JSON export to list the devices and agents added and removed since")
+			flags.StringVar(&deviceColumns, "device-columns", "", "comma separated device columns, see slide inventory columns")
+			flags.StringVar(&agentColumns, "agent-columns", "", "comma separated agent columns, see slide inventory columns")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			switch format {
+			case "json":
+			case "csv", "xlsx":
+				if out == "" {
+					return fmt.Errorf("--out is required for %s", format)
+				}
+			default:
+				return fmt.Errorf("unsupported format %q, expected json, csv or xlsx", format)
+			}
+
+			baseline, err := readBaseline(previous)
+			if err != nil {
+				return err
+			}
+
+			inv, err := inventory.Build(ctx, app.service)
+			if err != nil {
+				return err
+			}
+
+			if baseline != nil {
+				inv.Compare(*baseline)
+			}
+
+			report, err := inv.Report(
+				inventory.WithDeviceColumns(splitColumns(deviceColumns, inventory.DefaultDeviceColumns())...),
+				inventory.WithAgentColumns(splitColumns(agentColumns, inventory.DefaultAgentColumns())...),
+			)
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "csv":
+				return writeInventoryCSV(app, report, out)
+			case "xlsx":
+				return writeInventoryFile(app, out, report.WriteXLSX)
+			default:
+				if out == "" {
+					return report.WriteJSON(app.stdout)
+				}
+
+				return writeInventoryFile(app, out, report.WriteJSON)
+			}
+		},
+	}
+}
+
+func readBaseline(path string) (*inventory.Baseline, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	baseline, err := inventory.ReadBaseline(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s - %w", path, err)
+	}
+
+	return &baseline, nil
+}
+
+// splitColumns returns the columns of a flag, defaults when it is empty.
+func splitColumns(value string, defaults []string) []string {
+	if value == "" {
+		return defaults
+	}
+
+	columns := []string{}
+	for _, column := range strings.Split(value, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns
+}
+
+func writeInventoryCSV(app *app, report *inventory.Report, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, table := range report.Tables {
+		if err := writeInventoryFile(app, filepath.Join(dir, table.Name+".csv"), table.WriteCSV); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeInventoryFile(app *app, path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := errors.Join(write(file), file.Close()); err != nil {
+		return fmt.Errorf("unable to write %s - %w", path, err)
+	}
+
+	fmt.Fprintf(app.stderr, "Wrote %s\n", path)
+
+	return nil
+}
+
+type inventoryColumn struct {
+	table     string
+	name      string
+	isDefault bool
+}
+
+var inventoryColumnColumns = []column[inventoryColumn]{
+	{"table", func(c inventoryColumn) string { return c.table }},
+	{"column", func(c inventoryColumn) string { return c.name }},
+	{"default", func(c inventoryColumn) string { return strconv.FormatBool(c.isDefault) }},
+}
+
+func inventoryColumns() []inventoryColumn {
+	columns := []inventoryColumn{}
+
+	defaults := inventory.DefaultDeviceColumns()
+	for _, name := range inventory.DeviceColumns() {
+		columns = append(columns, inventoryColumn{inventory.Table_DEVICES, name, slices.Contains(defaults, name)})
+	}
+
+	defaults = inventory.DefaultAgentColumns()
+	for _, name := range inventory.AgentColumns() {
+		columns = append(columns, inventoryColumn{inventory.Table_AGENTS, name, slices.Contains(defaults, name)})
+	}
+
+	return columns
+}
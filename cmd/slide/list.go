@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/url"
+
+	"github.com/equalsgibson/goslide"
+)
+
+// maxPageSize is the largest page the API returns.
+const maxPageSize = 50
+
+// listOptions holds goslide query parameter options. Its apply method is
+// passed as a single option to the ListWithQueryParameters methods.
+type listOptions []func(u url.Values)
+
+func (o listOptions) apply(u url.Values) {
+	for _, option := range o {
+		option(u)
+	}
+}
+
+// listFlags are the flags shared by every list command.
+type listFlags struct {
+	limit  uint
+	sortBy string
+	asc    optionalBool
+}
+
+func (l *listFlags) register(flags *flag.FlagSet) {
+	flags.UintVar(&l.limit, "limit", 0, "maximum number of records, 0 for all")
+	flags.StringVar(&l.sortBy, "sort-by", "", "field to sort by")
+	flags.Var(&l.asc, "asc", "sort ascending, or descending with --asc=false")
+}
+
+func (l *listFlags) options() listOptions {
+	options := listOptions{}
+
+	if l.limit > 0 && l.limit < maxPageSize {
+		options = append(options, goslide.WithLimit(l.limit))
+	}
+
+	if l.sortBy != "" {
+		options = append(options, goslide.WithSortBy(l.sortBy))
+	}
+
+	if l.asc.value != nil {
+		options = append(options, goslide.WithSortDirection(*l.asc.value))
+	}
+
+	return options
+}
+
+// errLimitReached stops paging once enough records were collected.
+var errLimitReached = errors.New("limit reached")
+
+// collect gathers the records of every page, or the first limit records.
+func collect[T any](limit uint, list func(pageHandler func(response goslide.ListResponse[T]) error) error) ([]T, error) {
+	records := []T{}
+
+	err := list(func(response goslide.ListResponse[T]) error {
+		for _, record := range response.Data {
+			if limit > 0 && uint(len(records)) >= limit {
+				return errLimitReached
+			}
+
+			records = append(records, record)
+		}
+
+		if limit > 0 && uint(len(records)) >= limit {
+			return errLimitReached
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,206 @@
+// Command slide manages Slide devices, agents, backups and restores from the
+// command line.
+//
+// The API token is read from the profile named by --profile or SLIDE_PROFILE,
+// then from the SLIDE_AUTH_TOKEN environment variable, then from the default
+// profile of the config file at $SLIDE_CONFIG or
+// $XDG_CONFIG_HOME/slide/config.yaml. Profiles are managed with
+// "slide profile".
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	app := &app{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+		newService: func(token, apiURL string) goslide.Service {
+			if apiURL == "" {
+				return goslide.NewService(token)
+			}
+
+			return goslide.NewService(token, goslide.WithAPIURL(apiURL))
+		},
+	}
+
+	if err := app.run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "slide: %s\n", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	output      outputFormat
+	configPath  string
+	profileName string
+
+	config config
+
+	// profile is the selected profile, nil when the token comes from
+	// SLIDE_AUTH_TOKEN or no profile exists
+	profile *profile
+
+	// clientID is the default client filter of the profile
+	clientID string
+
+	// cacheDir holds cached completions, the user cache directory when empty
+	cacheDir string
+
+	newService func(token, apiURL string) goslide.Service
+	service    goslide.Service
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	// Called by the completion scripts, so it is not listed in the usage
+	if len(args) > 0 && args[0] == completeCommand {
+		return a.complete(ctx, args[1:])
+	}
+
+	return rootCommand().execute(ctx, a, nil, args)
+}
+
+func (a *app) globalFlags(flags *flag.FlagSet) {
+	flags.Var(&a.output, "output", "output format: table, json, yaml or csv (default table)")
+	flags.Var(&a.output, "o", "shorthand for --output")
+	flags.StringVar(&a.configPath, "config", a.configPath, "path of the config file")
+	flags.StringVar(&a.profileName, "profile", a.profileName, "profile to use instead of the default")
+}
+
+// init loads the config and selects the profile, once the flags are parsed.
+func (a *app) init() error {
+	config, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	a.config = config
+
+	name := a.profileName
+	if name == "" {
+		name = a.getenv("SLIDE_PROFILE")
+	}
+
+	// A token in the environment wins over the default profile, but not over
+	// a profile that was asked for
+	if name == "" && a.getenv("SLIDE_AUTH_TOKEN") == "" {
+		name = config.DefaultProfile
+	}
+
+	if name != "" {
+		profile, ok := config.Profiles[name]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+
+		a.profileName = name
+		a.profile = &profile
+		a.clientID = profile.Client
+
+		if a.output == "" {
+			a.output = profile.Output
+		}
+	}
+
+	if a.output == "" {
+		a.output = outputFormat_TABLE
+	}
+
+	return a.output.Set(string(a.output))
+}
+
+// connect creates the service of the selected profile, or of the token in
+// SLIDE_AUTH_TOKEN.
+func (a *app) connect(ctx context.Context) error {
+	if a.profile != nil {
+		service, err := a.serviceFor(ctx, *a.profile)
+		if err != nil {
+			return fmt.Errorf("profile %q - %w", a.profileName, err)
+		}
+
+		a.service = service
+
+		return nil
+	}
+
+	token := a.getenv("SLIDE_AUTH_TOKEN")
+	if token == "" {
+		return errors.New("no API token, set SLIDE_AUTH_TOKEN or add a profile with slide profile add")
+	}
+
+	a.service = a.newService(token, "")
+
+	return nil
+}
+
+func (a *app) serviceFor(ctx context.Context, profile profile) (goslide.Service, error) {
+	apiURL, err := apiHost(profile.BaseURL)
+	if err != nil {
+		return goslide.Service{}, err
+	}
+
+	token, err := profile.Token.resolve(ctx, a)
+	if err != nil {
+		return goslide.Service{}, err
+	}
+
+	return a.newService(token, apiURL), nil
+}
+
+// clientFilter returns the client a command is limited to: the flag value,
+// the default client of the profile, or every client for "all".
+func (a *app) clientFilter(flagValue string) string {
+	switch flagValue {
+	case "all":
+		return ""
+	case "":
+		return a.clientID
+	default:
+		return flagValue
+	}
+}
+
+func rootCommand() *command {
+	return &command{
+		name:    "slide",
+		summary: "Manage Slide devices, agents, backups and restores.",
+		subcommands: []*command{
+			accountsCommand(),
+			agentsCommand(),
+			alertsCommand(),
+			backupsCommand(),
+			clientsCommand(),
+			completionCommand(),
+			devicesCommand(),
+			inventoryCommand(),
+			networksCommand(),
+			profileCommand(),
+			restoresCommand(),
+			searchCommand(),
+			snapshotsCommand(),
+			tuiCommand(),
+			usersCommand(),
+		},
+	}
+}
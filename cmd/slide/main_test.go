@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/google/go-cmp/cmp"
+)
+
+type testEnv map[string]string
+
+func (e testEnv) getenv(key string) string {
+	return e[key]
+}
+
+// newTestApp returns an app whose requests are served by the server. Without
+// SLIDE_CONFIG in env the config file is in a temporary directory.
+func newTestApp(t *testing.T, server *fakeslide.Server, env testEnv, stdin string) (*app, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	app := &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+		getenv: env.getenv,
+		newService: func(token, apiURL string) goslide.Service {
+			return goslide.NewService(token, goslide.WithCustomRoundtripper(roundtripper.HandlerNetwork(server)))
+		},
+	}
+
+	app.cacheDir = t.TempDir()
+
+	if env["SLIDE_CONFIG"] == "" {
+		app.configPath = filepath.Join(t.TempDir(), "config.yaml")
+	}
+
+	return app, stdout, stderr
+}
+
+// runSlide runs the command line and returns stdout and stderr.
+func runSlide(t *testing.T, server *fakeslide.Server, env testEnv, args ...string) (string, string, error) {
+	t.Helper()
+
+	app, stdout, stderr := newTestApp(t, server, env, "")
+	err := app.run(context.Background(), args)
+
+	return stdout.String(), stderr.String(), err
+}
+
+var tokenEnv = testEnv{"SLIDE_AUTH_TOKEN": "fakeToken"}
+
+func TestDevicesList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "table",
+			args: []string{"devices", "list"},
+			expected: `DEVICE_ID      DISPLAY_NAME  HOSTNAME      CLIENT_ID  MODEL  STORAGE_USED  STORAGE_TOTAL  NFR    LAST_SEEN_AT
+d_acme         Acme HQ       acme-slide    c_acme            1.5 KiB       4.0 GiB        false  
+d_acme_branch  Acme Branch   acme-branch   c_acme            0 B           0 B            false  
+d_globex                     globex-slide  c_globex          0 B           0 B            true   
+`,
+		},
+		{
+			name: "csv with client filter",
+			args: []string{"devices", "list", "--client", "c_acme", "-o", "csv"},
+			expected: `device_id,display_name,hostname,client_id,model,storage_used,storage_total,nfr,last_seen_at
+d_acme,Acme HQ,acme-slide,c_acme,,1.5 KiB,4.0 GiB,false,
+d_acme_branch,Acme Branch,acme-branch,c_acme,,0 B,0 B,false,
+`,
+		},
+		{
+			name: "limit across pages",
+			args: []string{"--output=csv", "devices", "list", "--limit", "3"},
+			expected: `device_id,display_name,hostname,client_id,model,storage_used,storage_total,nfr,last_seen_at
+d_acme,Acme HQ,acme-slide,c_acme,,1.5 KiB,4.0 GiB,false,
+d_acme_branch,Acme Branch,acme-branch,c_acme,,0 B,0 B,false,
+d_globex,,globex-slide,c_globex,,0 B,0 B,true,
+`,
+		},
+		{
+			name: "limit within a page",
+			args: []string{"devices", "list", "--limit=1", "-o", "csv"},
+			expected: `device_id,display_name,hostname,client_id,model,storage_used,storage_total,nfr,last_seen_at
+d_acme,Acme HQ,acme-slide,c_acme,,1.5 KiB,4.0 GiB,false,
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := fakeslide.NewFleet()
+			server.Devices[0].StorageUsedBytes = 1536
+			server.Devices[0].StorageTotalBytes = 4 << 30
+			server.Devices[2].NFR = true
+
+			stdout, _, err := runSlide(t, server, tokenEnv, testCase.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, stdout); diff != "" {
+				t.Fatalf("%s output mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+}
+
+func TestDevicesGet_Formats(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Devices[2].NFR = true
+
+	stdout, _, err := runSlide(t, server, tokenEnv, "devices", "get", "d_globex", "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(stdout, "{\n") || !strings.Contains(stdout, `"device_id": "d_globex"`) {
+		t.Errorf("%s expected a JSON object, got:\n%s", t.Name(), stdout)
+	}
+
+	stdout, _, err = runSlide(t, server, tokenEnv, "devices", "get", "d_globex", "-o", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"device_id: d_globex\n", "nfr: true\n", "addresses: null\n"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("%s expected YAML to contain %q, got:\n%s", t.Name(), expected, stdout)
+		}
+	}
+
+	_, _, err = runSlide(t, server, tokenEnv, "devices", "get", "d_missing")
+	slideError := &goslide.SlideError{}
+	if !errors.As(err, &slideError) {
+		t.Fatalf("%s expected a SlideError, got %v", t.Name(), err)
+	}
+}
+
+func TestAlertsResolve(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = []goslide.Alert{
+		{AlertID: "al_1", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	stdout, _, err := runSlide(t, server, tokenEnv, "alerts", "resolve", "al_1", "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stdout, `"resolved": true`) {
+		t.Errorf("%s expected the resolved alert, got:\n%s", t.Name(), stdout)
+	}
+
+	if !server.Alerts[0].Resolved {
+		t.Errorf("%s expected the alert to be resolved", t.Name())
+	}
+
+	stdout, _, err = runSlide(t, server, tokenEnv, "alerts", "list", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(stdout, "al_1") {
+		t.Errorf("%s expected resolved alerts to be hidden, got:\n%s", t.Name(), stdout)
+	}
+}
+
+func TestRestoresVMList(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.VirtualMachines = []goslide.VirtualMachineRestore{
+		{VirtID: "virt_1", State: goslide.VirtualMachineState_RUNNING, CPUCount: 2, MemoryInMB: 4096},
+	}
+
+	stdout, _, err := runSlide(t, server, tokenEnv, "restores", "vm", "list", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `virt_id,state,agent_id,device_id,snapshot_id,cpus,memory_mb,expires_at
+virt_1,running,,,,2,4096,
+`
+
+	if diff := cmp.Diff(expected, stdout); diff != "" {
+		t.Fatalf("%s output mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Devices[0].Addresses = []goslide.Address{{MAC: "00:1a:2b:3c:4d:5e", IPs: []string{"10.2.0.2"}}}
+	server.Agents[0].Addresses = []goslide.Address{{IPs: []string{"10.2.3.4"}}}
+	server.Agents[2].Addresses = []goslide.Address{{IPs: []string{"10.2.3.5"}}}
+
+	stdout, _, err := runSlide(t, server, tokenEnv, "search", "10.2.0.0/16", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `type,id,name,client_id,field,match,score,value
+device,d_acme,Acme HQ,c_acme,ip,cidr,75,[10.2.0.2]
+agent,a_dc,Acme DC01,c_acme,ip,cidr,75,[10.2.3.4]
+agent,a_mail,globex-mail,c_globex,ip,cidr,75,[10.2.3.5]
+`
+
+	if diff := cmp.Diff(expected, stdout); diff != "" {
+		t.Fatalf("%s output mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	stdout, _, err = runSlide(t, server, tokenEnv, "search", "--client", "c_acme", "--type", "agent", "-o", "csv", "acme", "dc01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `type,id,name,client_id,field,match,score,value
+agent,a_dc,Acme DC01,c_acme,display_name,exact,100,[Acme DC01]
+`
+
+	if diff := cmp.Diff(expected, stdout); diff != "" {
+		t.Fatalf("%s filtered output mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	server := fakeslide.NewFleet()
+
+	_, _, err := runSlide(t, server, testEnv{}, "devices", "list")
+	if err == nil || !strings.Contains(err.Error(), "no API token") {
+		t.Errorf("%s expected a missing token error, got %v", t.Name(), err)
+	}
+
+	_, stderr, err := runSlide(t, server, tokenEnv, "devices", "frobnicate")
+	if !errors.Is(err, errUsage) || !strings.Contains(stderr, `unknown command "frobnicate"`) {
+		t.Errorf("%s expected a usage error, got %v:\n%s", t.Name(), err, stderr)
+	}
+
+	_, stderr, err = runSlide(t, server, tokenEnv, "devices", "get")
+	if err == nil || !strings.Contains(err.Error(), "expected 1 argument(s): <device-id>") {
+		t.Errorf("%s expected an argument error, got %v:\n%s", t.Name(), err, stderr)
+	}
+
+	_, stderr, err = runSlide(t, server, tokenEnv, "devices", "list", "-o", "xml")
+	if !errors.Is(err, errUsage) || !strings.Contains(stderr, `unknown output format "xml"`) {
+		t.Errorf("%s expected an output format error, got %v:\n%s", t.Name(), err, stderr)
+	}
+
+	stdout, _, err := runSlide(t, server, tokenEnv, "restores", "help")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Usage: slide restores <command> [flags]", "file", "image", "vm", "--output"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("%s expected usage to contain %q, got:\n%s", t.Name(), expected, stdout)
+		}
+	}
+}
+
+func TestInventoryExport(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Agents[0].OS, server.Agents[0].AgentVersion = "Windows Server 2022", "1.4.2"
+	server.Agents[1].OS, server.Agents[1].AgentVersion = "Windows 11", "1.4.2"
+	server.Agents[2].OS, server.Agents[2].AgentVersion = "Windows Server 2022", "1.4.1"
+
+	dir := t.TempDir()
+	previous := filepath.Join(dir, "previous.json")
+
+	if _, _, err := runSlide(t, server, tokenEnv, "inventory", "export", "--out", previous); err != nil {
+		t.Fatal(err)
+	}
+
+	// Globex left and Acme got a new agent since the previous export
+	server.Devices = server.Devices[:2]
+	server.Agents = []goslide.Agent{
+		server.Agents[0],
+		server.Agents[1],
+		{AgentID: "a_warehouse", DisplayName: "Warehouse PC", ClientID: "c_acme", DeviceID: "d_acme_branch", OS: "Windows 10", AgentVersion: "1.4.2"},
+	}
+
+	out := filepath.Join(dir, "csv")
+	_, stderr, err := runSlide(t, server, tokenEnv, "inventory", "export", "--format", "csv", "--out", out, "--previous", previous, "--agent-columns", "device_name,os,agent_version")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stderr, filepath.Join(out, "changes.csv")) {
+		t.Fatalf("%s expected the changes file to be written:\n%s", t.Name(), stderr)
+	}
+
+	expected := map[string]string{
+		"agents.csv": `client_id,client_name,agent_id,agent_name,device_name,os,agent_version
+c_acme,Acme,a_dc,Acme DC01,Acme HQ,Windows Server 2022,1.4.2
+c_acme,Acme,a_warehouse,Warehouse PC,Acme Branch,Windows 10,1.4.2
+c_acme,Acme,a_app,acme-app,Acme HQ,Windows 11,1.4.2
+`,
+		"changes.csv": `change,type,client_id,client_name,id,name
+removed,device,c_globex,Globex,d_globex,globex-slide
+added,agent,c_acme,Acme,a_warehouse,Warehouse PC
+removed,agent,c_globex,Globex,a_mail,globex-mail
+`,
+	}
+
+	for name, want := range expected {
+		got, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Fatalf("%s %s mismatch (-want +got):\n%s", t.Name(), name, diff)
+		}
+	}
+
+	_, _, err = runSlide(t, server, tokenEnv, "inventory", "export", "--device-columns", "colour")
+	if err == nil || !strings.Contains(err.Error(), `unknown column "colour"`) {
+		t.Fatalf("%s expected an unknown column error, got %v", t.Name(), err)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var networkColumns = []column[goslide.Network]{
+	{"network_id", func(n goslide.Network) string { return n.NetworkID }},
+	{"name", func(n goslide.Network) string { return n.Name }},
+	{"type", func(n goslide.Network) string { return string(n.Type) }},
+	{"client_id", func(n goslide.Network) string { return n.ClientID }},
+	{"router_prefix", func(n goslide.Network) string { return n.RouterPrefix }},
+	{"dhcp", func(n goslide.Network) string { return strconv.FormatBool(n.DHCP) }},
+	{"internet", func(n goslide.Network) string { return strconv.FormatBool(n.Internet) }},
+	{"connected_vms", func(n goslide.Network) string { return strconv.Itoa(len(n.ConnectedVirtIDs)) }},
+}
+
+func networksCommand() *command {
+	return &command{
+		name:    "networks",
+		summary: "List, show, create, update and delete disaster recovery networks.",
+		subcommands: []*command{
+			networksListCommand(),
+			{
+				name:    "get",
+				args:    "<network-id>",
+				summary: "Show a network.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<network-id>"); err != nil {
+						return err
+					}
+
+					network, err := app.service.Networks().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, network, networkColumns)
+				},
+			},
+			networksCreateCommand(),
+			networksUpdateCommand(),
+			{
+				name:    "delete",
+				args:    "<network-id>",
+				summary: "Delete a network.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<network-id>"); err != nil {
+						return err
+					}
+
+					if err := app.service.Networks().Delete(ctx, args[0]); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(app.stderr, "Deleted network %s\n", args[0])
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func networksListCommand() *command {
+	list := &listFlags{}
+	clientID := ""
+
+	return &command{
+		name:    "list",
+		summary: "List networks.",
+		flags: func(flags *flag.FlagSet) {
+			list.register(flags)
+			flags.StringVar(&clientID, "client", "", "only networks of this client, all for every client")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+			if clientID := app.clientFilter(clientID); clientID != "" {
+				options = append(options, goslide.WithClientID(clientID))
+			}
+
+			networks, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Network]) error) error {
+				return app.service.Networks().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, networks, networkColumns)
+		},
+	}
+}
+
+// networkFlags are the settings shared by network create and update.
+type networkFlags struct {
+	networkType    string
+	comments       string
+	dhcp           optionalBool
+	dhcpRangeStart string
+	dhcpRangeEnd   string
+	internet       optionalBool
+	nameservers    string
+	routerPrefix   string
+}
+
+func (n *networkFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&n.networkType, "type", "", "standard or bridge-lan")
+	flags.StringVar(&n.comments, "comments", "", "comments about the network")
+	flags.Var(&n.dhcp, "dhcp", "serve DHCP, or not with --dhcp=false")
+	flags.StringVar(&n.dhcpRangeStart, "dhcp-range-start", "", "first address handed out by DHCP")
+	flags.StringVar(&n.dhcpRangeEnd, "dhcp-range-end", "", "last address handed out by DHCP")
+	flags.Var(&n.internet, "internet", "allow internet access, or not with --internet=false")
+	flags.StringVar(&n.nameservers, "nameservers", "", "comma separated DNS servers")
+	flags.StringVar(&n.routerPrefix, "router-prefix", "", "router address and prefix, such as 10.0.0.1/24")
+}
+
+func networksCreateCommand() *command {
+	settings := &networkFlags{}
+	name, clientID, bridgeDeviceID := "", "", ""
+
+	return &command{
+		name:    "create",
+		summary: "Create a network.",
+		flags: func(flags *flag.FlagSet) {
+			settings.register(flags)
+			flags.StringVar(&name, "name", "", "name of the network (required)")
+			flags.StringVar(&clientID, "client", "", "client the network belongs to")
+			flags.StringVar(&bridgeDeviceID, "bridge-device", "", "device whose LAN a bridge-lan network bridges to")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			networkType := goslide.NetworkTypeDisaster(settings.networkType)
+			if networkType == "" {
+				networkType = goslide.NetworkTypeDisaster_STANDARD
+			}
+
+			network, err := app.service.Networks().Create(ctx, goslide.NetworkCreatePayload{
+				Name:           name,
+				Type:           networkType,
+				BridgeDeviceID: bridgeDeviceID,
+				ClientID:       app.clientFilter(clientID),
+				Comments:       settings.comments,
+				DHCP:           settings.dhcp.value,
+				DHCPRangeEnd:   settings.dhcpRangeEnd,
+				DHCPRangeStart: settings.dhcpRangeStart,
+				Internet:       settings.internet.value,
+				Nameservers:    settings.nameservers,
+				RouterPrefix:   settings.routerPrefix,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, network, networkColumns)
+		},
+	}
+}
+
+func networksUpdateCommand() *command {
+	settings := &networkFlags{}
+	name := ""
+
+	return &command{
+		name:    "update",
+		args:    "<network-id>",
+		summary: "Update the settings of a network. Only the given flags change.",
+		flags: func(flags *flag.FlagSet) {
+			settings.register(flags)
+			flags.StringVar(&name, "name", "", "new name")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<network-id>"); err != nil {
+				return err
+			}
+
+			payload := goslide.NetworkUpdatePayload{
+				Name:           name,
+				Type:           goslide.NetworkTypeDisaster(settings.networkType),
+				Comments:       settings.comments,
+				DHCP:           settings.dhcp.value,
+				DHCPRangeEnd:   settings.dhcpRangeEnd,
+				DHCPRangeStart: settings.dhcpRangeStart,
+				Internet:       settings.internet.value,
+				Nameservers:    settings.nameservers,
+				RouterPrefix:   settings.routerPrefix,
+			}
+
+			if payload == (goslide.NetworkUpdatePayload{}) {
+				return errors.New("nothing to update")
+			}
+
+			network, err := app.service.Networks().Update(ctx, args[0], payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, network, networkColumns)
+		},
+	}
+}
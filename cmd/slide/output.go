@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	outputFormat_TABLE outputFormat = "table"
+	outputFormat_JSON  outputFormat = "json"
+	outputFormat_YAML  outputFormat = "yaml"
+	outputFormat_CSV   outputFormat = "csv"
+)
+
+func (o *outputFormat) String() string {
+	return string(*o)
+}
+
+func (o *outputFormat) Set(s string) error {
+	switch format := outputFormat(s); format {
+	case outputFormat_TABLE, outputFormat_JSON, outputFormat_YAML, outputFormat_CSV:
+		*o = format
+
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, json, yaml or csv", s)
+	}
+}
+
+// column is a field of a record in table and CSV output.
+type column[T any] struct {
+	header string
+	value  func(record T) string
+}
+
+// printList writes the records in the output format of the app. JSON and
+// YAML contain every field, tables and CSV only the columns.
+func printList[T any](app *app, records []T, columns []column[T]) error {
+	switch app.output {
+	case outputFormat_JSON:
+		return printJSON(app.stdout, records)
+	case outputFormat_YAML:
+		return printYAML(app.stdout, records)
+	case outputFormat_CSV:
+		return printCSV(app.stdout, records, columns)
+	default:
+		return printTable(app.stdout, records, columns)
+	}
+}
+
+// printRecord writes a single record, as an object in JSON and YAML.
+func printRecord[T any](app *app, record T, columns []column[T]) error {
+	switch app.output {
+	case outputFormat_JSON:
+		return printJSON(app.stdout, record)
+	case outputFormat_YAML:
+		return printYAML(app.stdout, record)
+	default:
+		return printList(app, []T{record}, columns)
+	}
+}
+
+func printJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+
+	return encoder.Encode(value)
+}
+
+// printYAML goes through JSON, so YAML uses the same field names.
+func printYAML(w io.Writer, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+func printCSV[T any](w io.Writer, records []T, columns []column[T]) error {
+	writer := csv.NewWriter(w)
+
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, column.header)
+	}
+
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, column.value(record))
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func printTable[T any](w io.Writer, records []T, columns []column[T]) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, strings.ToUpper(column.header))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, record := range records {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			// Tabs and newlines would break the table layout
+			row = append(row, strings.NewReplacer("\t", " ", "\n", " ").Replace(column.value(record)))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatTimePointer(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return formatTime(*t)
+}
+
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatUint(size, 10) + " B"
+	}
+
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+type profileRow struct {
+	Name        string       `json:"name"`
+	Default     bool         `json:"default"`
+	TokenSource string       `json:"token_source"`
+	BaseURL     string       `json:"base_url"`
+	Output      outputFormat `json:"output"`
+	Client      string       `json:"client"`
+}
+
+var profileColumns = []column[profileRow]{
+	{"name", func(p profileRow) string { return p.Name }},
+	{"default", func(p profileRow) string {
+		if p.Default {
+			return "*"
+		}
+
+		return ""
+	}},
+	{"token_source", func(p profileRow) string { return p.TokenSource }},
+	{"base_url", func(p profileRow) string { return p.BaseURL }},
+	{"output", func(p profileRow) string { return string(p.Output) }},
+	{"client", func(p profileRow) string { return p.Client }},
+}
+
+type profileTestResult struct {
+	Name          string `json:"name"`
+	Authenticated bool   `json:"authenticated"`
+	Error         string `json:"error,omitempty"`
+}
+
+var profileTestColumns = []column[profileTestResult]{
+	{"name", func(r profileTestResult) string { return r.Name }},
+	{"status", func(r profileTestResult) string {
+		if r.Authenticated {
+			return "ok"
+		}
+
+		return r.Error
+	}},
+}
+
+func profileCommand() *command {
+	return &command{
+		name:    "profile",
+		summary: "Manage the profiles of Slide accounts and their tokens.",
+		subcommands: []*command{
+			profileAddCommand(),
+			{
+				name:    "list",
+				summary: "List profiles.",
+				local:   true,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					rows := []profileRow{}
+					for _, name := range app.config.profileNames() {
+						profile := app.config.Profiles[name]
+
+						rows = append(rows, profileRow{
+							Name:        name,
+							Default:     name == app.config.DefaultProfile,
+							TokenSource: profile.Token.String(),
+							BaseURL:     profile.BaseURL,
+							Output:      profile.Output,
+							Client:      profile.Client,
+						})
+					}
+
+					return printList(app, rows, profileColumns)
+				},
+			},
+			{
+				name:    "test",
+				args:    "[<name>...]",
+				summary: "Check that the tokens of profiles are accepted, all profiles by default.",
+				local:   true,
+				run: func(ctx context.Context, app *app, args []string) error {
+					names := args
+					if len(names) == 0 {
+						names = app.config.profileNames()
+					}
+
+					results := []profileTestResult{}
+					failed := 0
+					for _, name := range names {
+						result := app.testProfile(ctx, name)
+						if !result.Authenticated {
+							failed++
+						}
+
+						results = append(results, result)
+					}
+
+					if err := printList(app, results, profileTestColumns); err != nil {
+						return err
+					}
+
+					if failed > 0 {
+						return fmt.Errorf("%d of %d profiles failed", failed, len(results))
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func profileAddCommand() *command {
+	source := tokenSource{}
+	tokenCommand := ""
+	useKeyring := false
+	settings := profile{}
+	makeDefault := false
+
+	return &command{
+		name:    "add",
+		args:    "<name>",
+		summary: "Add or replace a profile. The token is read from one of the --token flags.",
+		local:   true,
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&source.Env, "token-env", "", "read the token from this environment variable")
+			flags.StringVar(&source.File, "token-file", "", "read the token from this file")
+			flags.BoolVar(&useKeyring, "token-keyring", false, "store the token read from stdin in the OS keyring")
+			flags.StringVar(&tokenCommand, "token-command", "", "read the token from the output of this command, split on spaces")
+			flags.StringVar(&settings.BaseURL, "base-url", "", "base URL of the API, such as https://api.slide.tech")
+			flags.Var(&settings.Output, "default-output", "default output format of the profile")
+			flags.StringVar(&settings.Client, "client", "", "default client filter of the profile")
+			flags.BoolVar(&makeDefault, "default", false, "make this the default profile")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<name>"); err != nil {
+				return err
+			}
+
+			name := args[0]
+			source.Command = strings.Fields(tokenCommand)
+			if useKeyring {
+				source.Keyring = name
+			}
+
+			if err := source.validate(); err != nil {
+				return errors.New("set exactly one of --token-env, --token-file, --token-keyring or --token-command")
+			}
+
+			if _, err := apiHost(settings.BaseURL); err != nil {
+				return err
+			}
+
+			if useKeyring {
+				token, err := app.readSecret("Slide API token for " + name + ": ")
+				if err != nil {
+					return err
+				}
+
+				if err := keyring.Set(keyringService, name, token); err != nil {
+					return fmt.Errorf("storing token in keyring - %w", err)
+				}
+			}
+
+			config := app.config
+			if config.Profiles == nil {
+				config.Profiles = map[string]profile{}
+			}
+
+			settings.Token = source
+			config.Profiles[name] = settings
+
+			if makeDefault || config.DefaultProfile == "" {
+				config.DefaultProfile = name
+			}
+
+			path, err := app.saveConfig(config)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(app.stderr, "Saved profile %q to %s\n", name, path)
+
+			return nil
+		},
+	}
+}
+
+func (c config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (a *app) testProfile(ctx context.Context, name string) profileTestResult {
+	result := profileTestResult{Name: name}
+
+	profile, ok := a.config.Profiles[name]
+	if !ok {
+		result.Error = "unknown profile"
+
+		return result
+	}
+
+	service, err := a.serviceFor(ctx, profile)
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	authenticated, err := service.CheckAuthenticationToken(ctx)
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Authenticated = authenticated
+	if !authenticated {
+		result.Error = "token was not accepted"
+	}
+
+	return result
+}
+
+// readSecret reads a line from stdin, without echo when it is a terminal.
+func (a *app) readSecret(prompt string) (string, error) {
+	if file, ok := a.stdin.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		fmt.Fprint(a.stderr, prompt)
+		secret, err := term.ReadPassword(int(file.Fd()))
+		fmt.Fprintln(a.stderr)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(string(secret)), nil
+	}
+
+	line, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading token from stdin - %w", err)
+	}
+
+	secret := strings.TrimSpace(line)
+	if secret == "" {
+		return "", errors.New("no token on stdin")
+	}
+
+	return secret, nil
+}
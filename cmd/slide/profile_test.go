@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/google/go-cmp/cmp"
+	"github.com/zalando/go-keyring"
+)
+
+func TestProfile_AddListTest(t *testing.T) {
+	keyring.MockInit()
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "initech.token")
+	if err := os.WriteFile(tokenFile, []byte("acmeToken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := fakeslide.NewFleet()
+	server.Token = "acmeToken"
+
+	env := testEnv{
+		"SLIDE_CONFIG": filepath.Join(dir, "slide", "config.yaml"),
+		"GLOBEX_TOKEN": "wrongToken",
+	}
+
+	for _, args := range [][]string{
+		{"profile", "add", "acme", "--token-keyring", "--base-url", "https://api.eu.example.com", "--client", "c_acme", "--default-output", "csv"},
+		{"profile", "add", "globex", "--token-env", "GLOBEX_TOKEN"},
+		{"profile", "add", "initech", "--token-file", tokenFile},
+	} {
+		app, _, _ := newTestApp(t, server, env, "acmeToken\n")
+		if err := app.run(context.Background(), args); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := os.ReadFile(env["SLIDE_CONFIG"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(config), "acmeToken") {
+		t.Fatalf("%s expected no token in the config, got:\n%s", t.Name(), config)
+	}
+
+	stdout, _, err := runSlide(t, server, env, "profile", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first profile added became the default, with its output format
+	expected := "name,default,token_source,base_url,output,client\n" +
+		"acme,*,keyring:acme,https://api.eu.example.com,csv,c_acme\n" +
+		"globex,,env:GLOBEX_TOKEN,,,\n" +
+		"initech,,file:" + tokenFile + ",,,\n"
+
+	if diff := cmp.Diff(expected, stdout); diff != "" {
+		t.Fatalf("%s profile list mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	app, appStdout, _ := newTestApp(t, server, env, "")
+	apiURLs := []string{}
+	newService := app.newService
+	app.newService = func(token, apiURL string) goslide.Service {
+		apiURLs = append(apiURLs, apiURL)
+
+		return newService(token, apiURL)
+	}
+
+	err = app.run(context.Background(), []string{"profile", "test"})
+	if err == nil || err.Error() != "1 of 3 profiles failed" {
+		t.Fatalf("%s expected one failed profile, got %v", t.Name(), err)
+	}
+
+	expected = "name,status\n" +
+		"acme,ok\n" +
+		"globex,slide api request error err_unauthorized \n" +
+		"initech,ok\n"
+
+	if diff := cmp.Diff(expected, appStdout.String()); diff != "" {
+		t.Fatalf("%s profile test mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if diff := cmp.Diff([]string{"api.eu.example.com", "", ""}, apiURLs); diff != "" {
+		t.Fatalf("%s API URL mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if err := runProfileAdd(t, server, env, "--token-env", "A", "--token-file", "B"); err == nil || !strings.Contains(err.Error(), "exactly one") {
+		t.Errorf("%s expected a token source error, got %v", t.Name(), err)
+	}
+
+	if err := runProfileAdd(t, server, env, "--token-env", "A", "--base-url", "http://api.slide.tech"); err == nil || !strings.Contains(err.Error(), "expected https://host") {
+		t.Errorf("%s expected a base URL error, got %v", t.Name(), err)
+	}
+}
+
+func runProfileAdd(t *testing.T, server *fakeslide.Server, env testEnv, flags ...string) error {
+	t.Helper()
+
+	_, _, err := runSlide(t, server, env, append([]string{"profile", "add", "invalid"}, flags...)...)
+
+	return err
+}
+
+func TestProfile_Selection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`default_profile: acme
+profiles:
+  acme:
+    token:
+      env: ACME_TOKEN
+    output: csv
+    client: c_acme
+  globex:
+    token:
+      command: [does-not-exist]
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := fakeslide.NewFleet()
+	env := testEnv{"SLIDE_CONFIG": path, "ACME_TOKEN": "fakeToken"}
+
+	testCases := []struct {
+		name     string
+		env      testEnv
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "default profile and client filter",
+			args:     []string{"devices", "list"},
+			expected: []string{"device_id", "d_acme", "d_acme_branch"},
+		},
+		{
+			name:     "every client",
+			args:     []string{"devices", "list", "--client", "all"},
+			expected: []string{"device_id", "d_acme", "d_acme_branch", "d_globex"},
+		},
+		{
+			name:     "explicit client",
+			args:     []string{"devices", "list", "--client", "c_globex"},
+			expected: []string{"device_id", "d_globex"},
+		},
+		{
+			name:     "token in the environment skips the default profile",
+			env:      testEnv{"SLIDE_AUTH_TOKEN": "fakeToken"},
+			args:     []string{"devices", "list"},
+			expected: []string{"DEVICE_ID", "d_acme", "d_acme_branch", "d_globex"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testEnv := testEnv{}
+			for key, value := range env {
+				testEnv[key] = value
+			}
+
+			for key, value := range testCase.env {
+				testEnv[key] = value
+			}
+
+			stdout, _, err := runSlide(t, server, testEnv, testCase.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []string{}
+			for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+				ids = append(ids, strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' })[0])
+			}
+
+			if diff := cmp.Diff(testCase.expected, ids); diff != "" {
+				t.Fatalf("%s output mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+
+	_, _, err := runSlide(t, server, env, "--profile", "globex", "devices", "list")
+	if err == nil || !strings.Contains(err.Error(), `profile "globex" - running token command`) {
+		t.Errorf("%s expected a token command error, got %v", t.Name(), err)
+	}
+
+	_, _, err = runSlide(t, server, testEnv{"SLIDE_CONFIG": path, "SLIDE_PROFILE": "initech"}, "devices", "list")
+	if err == nil || err.Error() != `unknown profile "initech"` {
+		t.Errorf("%s expected an unknown profile error, got %v", t.Name(), err)
+	}
+}
+
+func TestConfig_PlainTextToken(t *testing.T) {
+	for _, document := range []string{
+		"profiles:\n  acme:\n    token: abc123\n",
+		"token: abc123\n",
+	} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(document), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err := runSlide(t, fakeslide.NewFleet(), testEnv{"SLIDE_CONFIG": path}, "devices", "list")
+		if err == nil || !strings.Contains(err.Error(), "parsing config") {
+			t.Errorf("%s expected the config to be rejected, got %v", t.Name(), err)
+		}
+	}
+}
+
+func TestTokenSource_Command(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo is not available")
+	}
+
+	app, _, _ := newTestApp(t, fakeslide.NewFleet(), testEnv{}, "")
+
+	token, err := tokenSource{Command: []string{"echo", "commandToken"}}.resolve(context.Background(), app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "commandToken" {
+		t.Fatalf("%s expected the token printed by the command, got %q", t.Name(), token)
+	}
+}
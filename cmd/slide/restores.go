@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func restoresCommand() *command {
+	return &command{
+		name:    "restores",
+		summary: "Manage file, image export and virtual machine restores.",
+		subcommands: []*command{
+			fileRestoresCommand(),
+			imageRestoresCommand(),
+			vmRestoresCommand(),
+		},
+	}
+}
+
+// restoreFlags are the flags of every restore create command.
+type restoreFlags struct {
+	deviceID   string
+	agentID    string
+	snapshotID string
+}
+
+func (r *restoreFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&r.deviceID, "device", "", "device holding the snapshot (required without --agent)")
+	flags.StringVar(&r.agentID, "agent", "", "agent of the snapshot, whose device is used without --device")
+	flags.StringVar(&r.snapshotID, "snapshot", "", "snapshot to restore (required)")
+}
+
+// resolve checks the flags, looking up the device of the agent when no
+// device was given.
+func (r *restoreFlags) resolve(ctx context.Context, app *app) error {
+	if r.snapshotID == "" || (r.deviceID == "" && r.agentID == "") {
+		return errors.New("--snapshot and --device or --agent are required")
+	}
+
+	if r.deviceID != "" {
+		return nil
+	}
+
+	agent, err := app.service.Agents().Get(ctx, r.agentID)
+	if err != nil {
+		return err
+	}
+
+	r.deviceID = agent.DeviceID
+
+	return nil
+}
+
+func deleteCommand(summary, argName string, remove func(ctx context.Context, app *app, id string) error) *command {
+	return &command{
+		name:    "delete",
+		args:    argName,
+		summary: summary,
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, argName); err != nil {
+				return err
+			}
+
+			if err := remove(ctx, app, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(app.stderr, "Deleted %s\n", args[0])
+
+			return nil
+		},
+	}
+}
+
+var fileRestoreColumns = []column[goslide.FileRestore]{
+	{"file_restore_id", func(f goslide.FileRestore) string { return f.FileRestoreID }},
+	{"agent_id", func(f goslide.FileRestore) string { return f.AgentID }},
+	{"device_id", func(f goslide.FileRestore) string { return f.DeviceID }},
+	{"snapshot_id", func(f goslide.FileRestore) string { return f.SnapshotID }},
+	{"created_at", func(f goslide.FileRestore) string { return formatTime(f.CreatedAt) }},
+	{"expires_at", func(f goslide.FileRestore) string { return formatTime(f.ExpiresAt) }},
+}
+
+var fileRestoreDataColumns = []column[goslide.FileRestoreData]{
+	{"type", func(f goslide.FileRestoreData) string { return string(f.Type) }},
+	{"name", func(f goslide.FileRestoreData) string { return f.Name }},
+	{"size", func(f goslide.FileRestoreData) string { return formatBytes(uint64(f.Size)) }},
+	{"modified_at", func(f goslide.FileRestoreData) string { return f.ModifiedAt }},
+	{"path", func(f goslide.FileRestoreData) string { return f.Path }},
+}
+
+func fileRestoresCommand() *command {
+	list := &listFlags{}
+	create := &restoreFlags{}
+	browse := &listFlags{}
+	path := ""
+
+	return &command{
+		name:    "file",
+		summary: "Mount snapshots to browse and download files.",
+		subcommands: []*command{
+			{
+				name:    "list",
+				summary: "List file restores.",
+				flags:   list.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					options := list.options()
+
+					restores, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.FileRestore]) error) error {
+						return app.service.FileRestores().ListWithQueryParameters(ctx, pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, restores, fileRestoreColumns)
+				},
+			},
+			{
+				name:    "get",
+				args:    "<file-restore-id>",
+				summary: "Show a file restore.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<file-restore-id>"); err != nil {
+						return err
+					}
+
+					restore, err := app.service.FileRestores().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, restore, fileRestoreColumns)
+				},
+			},
+			{
+				name:    "create",
+				summary: "Mount a snapshot for file restore.",
+				flags:   create.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					if err := create.resolve(ctx, app); err != nil {
+						return err
+					}
+
+					restore, err := app.service.FileRestores().Create(ctx, goslide.FileRestorePayload{
+						DeviceID:   create.deviceID,
+						SnapshotID: create.snapshotID,
+					})
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, restore, fileRestoreColumns)
+				},
+			},
+			{
+				name:    "browse",
+				args:    "<file-restore-id>",
+				summary: "List the files in a directory of a file restore.",
+				flags: func(flags *flag.FlagSet) {
+					browse.register(flags)
+					flags.StringVar(&path, "path", "", "directory to list, the root by default")
+				},
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<file-restore-id>"); err != nil {
+						return err
+					}
+
+					options := browse.options()
+					if path != "" {
+						options = append(options, goslide.WithPath(path))
+					}
+
+					files, err := collect(browse.limit, func(pageHandler func(goslide.ListResponse[goslide.FileRestoreData]) error) error {
+						return app.service.FileRestores().BrowseWithQueryParameters(ctx, args[0], pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, files, fileRestoreDataColumns)
+				},
+			},
+			deleteCommand("Unmount a file restore.", "<file-restore-id>", func(ctx context.Context, app *app, id string) error {
+				return app.service.FileRestores().Delete(ctx, id)
+			}),
+		},
+	}
+}
+
+var imageRestoreColumns = []column[goslide.ImageExportRestore]{
+	{"image_export_id", func(i goslide.ImageExportRestore) string { return i.ImageExportID }},
+	{"image_type", func(i goslide.ImageExportRestore) string { return string(i.ImageType) }},
+	{"agent_id", func(i goslide.ImageExportRestore) string { return i.AgentID }},
+	{"device_id", func(i goslide.ImageExportRestore) string { return i.DeviceID }},
+	{"snapshot_id", func(i goslide.ImageExportRestore) string { return i.SnapshotID }},
+	{"created_at", func(i goslide.ImageExportRestore) string { return formatTime(i.CreatedAt) }},
+}
+
+var imageRestoreDataColumns = []column[goslide.ImageExportRestoreData]{
+	{"disk_id", func(i goslide.ImageExportRestoreData) string { return i.DiskID }},
+	{"name", func(i goslide.ImageExportRestoreData) string { return i.Name }},
+	{"size", func(i goslide.ImageExportRestoreData) string { return formatBytes(uint64(i.Size)) }},
+}
+
+func imageRestoresCommand() *command {
+	list := &listFlags{}
+	create := &restoreFlags{}
+	imageType := ""
+	browse := &listFlags{}
+
+	return &command{
+		name:    "image",
+		summary: "Export snapshots as disk images.",
+		subcommands: []*command{
+			{
+				name:    "list",
+				summary: "List image exports.",
+				flags:   list.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					options := list.options()
+
+					restores, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.ImageExportRestore]) error) error {
+						return app.service.ImageExportRestores().ListWithQueryParameters(ctx, pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, restores, imageRestoreColumns)
+				},
+			},
+			{
+				name:    "get",
+				args:    "<image-export-id>",
+				summary: "Show an image export.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<image-export-id>"); err != nil {
+						return err
+					}
+
+					restore, err := app.service.ImageExportRestores().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, restore, imageRestoreColumns)
+				},
+			},
+			{
+				name:    "create",
+				summary: "Export a snapshot as disk images.",
+				flags: func(flags *flag.FlagSet) {
+					create.register(flags)
+					flags.StringVar(&imageType, "type", string(goslide.ImageExportType_VHDX), "vhdx, vhdx-dynamic, vhd or raw")
+				},
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					if err := create.resolve(ctx, app); err != nil {
+						return err
+					}
+
+					restore, err := app.service.ImageExportRestores().Create(ctx, goslide.ImageExportRestorePayload{
+						DeviceID:   create.deviceID,
+						SnapshotID: create.snapshotID,
+						ImageType:  goslide.ImageExportType(imageType),
+					})
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, restore, imageRestoreColumns)
+				},
+			},
+			{
+				name:    "browse",
+				args:    "<image-export-id>",
+				summary: "List the disk images of an image export.",
+				flags:   browse.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<image-export-id>"); err != nil {
+						return err
+					}
+
+					options := browse.options()
+
+					images, err := collect(browse.limit, func(pageHandler func(goslide.ListResponse[goslide.ImageExportRestoreData]) error) error {
+						return app.service.ImageExportRestores().BrowseWithQueryParameters(ctx, args[0], pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, images, imageRestoreDataColumns)
+				},
+			},
+			deleteCommand("Delete an image export.", "<image-export-id>", func(ctx context.Context, app *app, id string) error {
+				return app.service.ImageExportRestores().Delete(ctx, id)
+			}),
+		},
+	}
+}
+
+var vmRestoreColumns = []column[goslide.VirtualMachineRestore]{
+	{"virt_id", func(v goslide.VirtualMachineRestore) string { return v.VirtID }},
+	{"state", func(v goslide.VirtualMachineRestore) string { return string(v.State) }},
+	{"agent_id", func(v goslide.VirtualMachineRestore) string { return v.AgentID }},
+	{"device_id", func(v goslide.VirtualMachineRestore) string { return v.DeviceID }},
+	{"snapshot_id", func(v goslide.VirtualMachineRestore) string { return v.SnapshotID }},
+	{"cpus", func(v goslide.VirtualMachineRestore) string { return strconv.FormatUint(uint64(v.CPUCount), 10) }},
+	{"memory_mb", func(v goslide.VirtualMachineRestore) string { return strconv.FormatUint(uint64(v.MemoryInMB), 10) }},
+	{"expires_at", func(v goslide.VirtualMachineRestore) string { return formatTime(v.ExpiresAt) }},
+}
+
+func vmRestoresCommand() *command {
+	list := &listFlags{}
+
+	return &command{
+		name:    "vm",
+		summary: "Boot snapshots as virtual machines.",
+		subcommands: []*command{
+			{
+				name:    "list",
+				summary: "List virtual machines.",
+				flags:   list.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					options := list.options()
+
+					restores, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.VirtualMachineRestore]) error) error {
+						return app.service.VirtualMachineRestores().ListWithQueryParameters(ctx, pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, restores, vmRestoreColumns)
+				},
+			},
+			{
+				name:    "get",
+				args:    "<virt-id>",
+				summary: "Show a virtual machine.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<virt-id>"); err != nil {
+						return err
+					}
+
+					restore, err := app.service.VirtualMachineRestores().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, restore, vmRestoreColumns)
+				},
+			},
+			vmRestoresCreateCommand(),
+			vmRestoresUpdateCommand(),
+			deleteCommand("Delete a virtual machine.", "<virt-id>", func(ctx context.Context, app *app, id string) error {
+				return app.service.VirtualMachineRestores().Delete(ctx, id)
+			}),
+		},
+	}
+}
+
+func vmRestoresCreateCommand() *command {
+	create := &restoreFlags{}
+	payload := goslide.VirtualMachineRestoreCreatePayload{}
+	diskBus, networkModel, networkType := "", "", ""
+
+	return &command{
+		name:    "create",
+		summary: "Boot a snapshot as a virtual machine.",
+		flags: func(flags *flag.FlagSet) {
+			create.register(flags)
+			flags.UintVar(&payload.CPUCount, "cpus", 0, "number of CPUs")
+			flags.UintVar(&payload.MemoryInMB, "memory", 0, "memory in MB")
+			flags.StringVar(&diskBus, "disk-bus", "", "sata or virtio")
+			flags.StringVar(&networkModel, "network-model", "", "hypervisor_default, e1000 or rtl8139")
+			flags.StringVar(&networkType, "network-type", "", "network, network-isolated, bridge or network-id")
+			flags.StringVar(&payload.NetworkSource, "network", "", "disaster recovery network to attach to, with --network-type network-id")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			if err := create.resolve(ctx, app); err != nil {
+				return err
+			}
+
+			payload.DeviceID = create.deviceID
+			payload.SnapshotID = create.snapshotID
+			payload.DiskBus = goslide.DiskBus(diskBus)
+			payload.NetworkModel = goslide.VirtualMachineNetworkModel(networkModel)
+			payload.NetworkType = goslide.VirtualMachineNetworkType(networkType)
+
+			restore, err := app.service.VirtualMachineRestores().Create(ctx, payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, restore, vmRestoreColumns)
+		},
+	}
+}
+
+func vmRestoresUpdateCommand() *command {
+	payload := goslide.VirtualMachineRestoreUpdatePayload{}
+	state := ""
+	expiresIn := time.Duration(0)
+
+	return &command{
+		name:    "update",
+		args:    "<virt-id>",
+		summary: "Change the state, size or expiry of a virtual machine.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&state, "state", "", "running, stopped or paused")
+			flags.UintVar(&payload.CPUCount, "cpus", 0, "number of CPUs")
+			flags.UintVar(&payload.MemoryInMB, "memory", 0, "memory in MB")
+			flags.DurationVar(&expiresIn, "expires-in", 0, "expire the virtual machine this long from now")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args, "<virt-id>"); err != nil {
+				return err
+			}
+
+			payload.State = goslide.VirtualMachineState(state)
+			if expiresIn > 0 {
+				expiresAt := time.Now().Add(expiresIn).UTC()
+				payload.ExpiresAt = &expiresAt
+			}
+
+			if payload == (goslide.VirtualMachineRestoreUpdatePayload{}) {
+				return errors.New("nothing to update, set --state, --cpus, --memory or --expires-in")
+			}
+
+			restore, err := app.service.VirtualMachineRestores().Update(ctx, args[0], payload)
+			if err != nil {
+				return err
+			}
+
+			return printRecord(app, restore, vmRestoreColumns)
+		},
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/search"
+	"golang.org/x/term"
+)
+
+func searchCommand() *command {
+	clientID := ""
+	recordType := ""
+	limit := 20
+
+	return &command{
+		name:    "search",
+		args:    "<query>...",
+		summary: "Find devices and agents by hostname, name, IP, CIDR, MAC or serial number.",
+		flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&clientID, "client", "", "only devices and agents of this client, all for every client")
+			flags.StringVar(&recordType, "type", "", "only device or agent results")
+			flags.IntVar(&limit, "limit", limit, "maximum number of results, 0 for all")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if len(args) == 0 {
+				return errors.New("expected a query")
+			}
+
+			recordTypes := []search.RecordType{}
+			switch search.RecordType(recordType) {
+			case "":
+			case search.RecordType_DEVICE, search.RecordType_AGENT:
+				recordTypes = append(recordTypes, search.RecordType(recordType))
+			default:
+				return fmt.Errorf("unsupported type %q, expected device or agent", recordType)
+			}
+
+			listOptions := listOptions{}
+			if clientID := app.clientFilter(clientID); clientID != "" {
+				listOptions = append(listOptions, goslide.WithClientID(clientID))
+			}
+
+			devices, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Device]) error) error {
+				return app.service.Devices().ListWithQueryParameters(ctx, pageHandler, listOptions.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			agents, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Agent]) error) error {
+				return app.service.Agents().ListWithQueryParameters(ctx, pageHandler, listOptions.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			// Queries such as "Reception PC" need not be quoted
+			hits := search.NewIndex(devices, agents).Search(strings.Join(args, " "), search.WithLimit(limit), search.WithRecordTypes(recordTypes...))
+
+			before, after := "[", "]"
+			if file, ok := app.stdout.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+				before, after = "\x1b[1;4m", "\x1b[0m"
+			}
+
+			return printList(app, hits, searchColumns(before, after))
+		},
+	}
+}
+
+func searchColumns(before, after string) []column[search.Hit] {
+	return []column[search.Hit]{
+		{"type", func(h search.Hit) string { return string(h.Type) }},
+		{"id", func(h search.Hit) string { return h.ID }},
+		{"name", func(h search.Hit) string { return h.Name }},
+		{"client_id", func(h search.Hit) string { return hitClientID(h) }},
+		{"field", func(h search.Hit) string { return string(h.Field) }},
+		{"match", func(h search.Hit) string { return string(h.Match) }},
+		{"score", func(h search.Hit) string { return strconv.Itoa(h.Score) }},
+		{"value", func(h search.Hit) string { return h.Highlight(before, after) }},
+	}
+}
+
+func hitClientID(hit search.Hit) string {
+	switch {
+	case hit.Device != nil:
+		return hit.Device.ClientID
+	case hit.Agent != nil:
+		return hit.Agent.ClientID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var snapshotColumns = []column[goslide.Snapshot]{
+	{"snapshot_id", func(s goslide.Snapshot) string { return s.SnapshotID }},
+	{"agent_id", func(s goslide.Snapshot) string { return s.AgentID }},
+	{"backup_ended_at", func(s goslide.Snapshot) string { return formatTime(s.BackupEndedAt) }},
+	{"locations", func(s goslide.Snapshot) string {
+		locations := []string{}
+		for _, location := range s.Locations {
+			locations = append(locations, string(location.Type))
+		}
+
+		return strings.Join(locations, ",")
+	}},
+	{"verify_boot", func(s goslide.Snapshot) string { return string(s.VerifyBootStatus) }},
+	{"verify_fs", func(s goslide.Snapshot) string { return string(s.VerifyFSStatus) }},
+	{"deleted", func(s goslide.Snapshot) string { return formatTimePointer(s.Deleted) }},
+}
+
+func snapshotsCommand() *command {
+	return &command{
+		name:    "snapshots",
+		summary: "List and show snapshots.",
+		subcommands: []*command{
+			snapshotsListCommand(),
+			{
+				name:    "get",
+				args:    "<snapshot-id>",
+				summary: "Show a snapshot.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<snapshot-id>"); err != nil {
+						return err
+					}
+
+					snapshot, err := app.service.Snapshots().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, snapshot, snapshotColumns)
+				},
+			},
+		},
+	}
+}
+
+func snapshotsListCommand() *command {
+	list := &listFlags{}
+	agentID, location := "", ""
+
+	return &command{
+		name:    "list",
+		summary: "List snapshots.",
+		flags: func(flags *flag.FlagSet) {
+			list.register(flags)
+			flags.StringVar(&agentID, "agent", "", "only snapshots of this agent")
+			flags.StringVar(&location, "location", "", "only snapshots in this location, such as exists_local or exists_cloud")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options := list.options()
+			if agentID != "" {
+				options = append(options, goslide.WithAgentID(agentID))
+			}
+
+			if location != "" {
+				options = append(options, goslide.WithSnapshotLocationFilter(goslide.SnapshotLocationFilter(location)))
+			}
+
+			snapshots, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.Snapshot]) error) error {
+				return app.service.Snapshots().ListWithQueryParameters(ctx, pageHandler, options.apply)
+			})
+			if err != nil {
+				return err
+			}
+
+			return printList(app, snapshots, snapshotColumns)
+		},
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/equalsgibson/goslide"
+)
+
+func tuiCommand() *command {
+	options := tuiOptions{
+		refresh:      30 * time.Second,
+		downloadDir:  ".",
+		downloadFrom: goslide.FileRestoreDownloadType_CLOUD,
+		httpClient:   http.DefaultClient,
+	}
+	downloadFrom := string(options.downloadFrom)
+
+	return &command{
+		name:    "tui",
+		summary: "Browse clients, devices, agents and snapshots in a full-screen terminal UI.",
+		flags: func(flags *flag.FlagSet) {
+			flags.DurationVar(&options.refresh, "refresh", options.refresh, "how often the screen is reloaded, 0 to disable")
+			flags.StringVar(&options.downloadDir, "download-dir", options.downloadDir, "directory restored files are downloaded to")
+			flags.StringVar(&downloadFrom, "download-from", downloadFrom, "cloud or local, the download URI used when a file offers both")
+		},
+		run: func(ctx context.Context, app *app, args []string) error {
+			if err := exactArgs(args); err != nil {
+				return err
+			}
+
+			options.downloadFrom = goslide.FileRestoreDownloadType(downloadFrom)
+
+			model := newTUI(ctx, app.service, options)
+			program := tea.NewProgram(
+				model,
+				tea.WithContext(ctx),
+				tea.WithAltScreen(),
+				tea.WithInput(app.stdin),
+				tea.WithOutput(app.stdout),
+			)
+
+			_, err := program.Run()
+
+			// File restores are removed on the way out, also after Ctrl+C
+			cleanupErr := model.cleanup(context.WithoutCancel(ctx))
+
+			if errors.Is(err, tea.ErrProgramKilled) {
+				err = nil
+			}
+
+			return errors.Join(err, cleanupErr)
+		},
+	}
+}
+
+type tuiOptions struct {
+	refresh      time.Duration
+	downloadDir  string
+	downloadFrom goslide.FileRestoreDownloadType
+	httpClient   *http.Client
+}
+
+// tuiModel is a stack of screens. Enter pushes the screen of the selected
+// row, escape pops back to the previous one.
+type tuiModel struct {
+	ctx     context.Context
+	service goslide.Service
+	options tuiOptions
+	now     func() time.Time
+
+	stack  []*screen
+	width  int
+	height int
+
+	status   string
+	err      error
+	confirm  *confirmation
+	showHelp bool
+
+	// restores are the file restores created by this session
+	restores map[string]bool
+}
+
+type confirmation struct {
+	prompt string
+	action tea.Cmd
+}
+
+// loadedMsg carries the rows of a screen.
+type loadedMsg struct {
+	screen *screen
+	rows   []tuiRow
+	err    error
+}
+
+type tickMsg time.Time
+
+type statusMsg struct {
+	text string
+	err  error
+}
+
+type restoreCreatedMsg struct {
+	parent  *screen
+	restore goslide.FileRestore
+	err     error
+}
+
+func newTUI(ctx context.Context, service goslide.Service, options tuiOptions) *tuiModel {
+	return &tuiModel{
+		ctx:      ctx,
+		service:  service,
+		options:  options,
+		now:      time.Now,
+		stack:    []*screen{{kind: screenClients, title: "Clients"}},
+		restores: map[string]bool{},
+	}
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return tea.Batch(m.load(m.top()), m.tick())
+}
+
+func (m *tuiModel) tick() tea.Cmd {
+	if m.options.refresh <= 0 {
+		return nil
+	}
+
+	return tea.Tick(m.options.refresh, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m *tuiModel) top() *screen {
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+		return m, nil
+	case tickMsg:
+		// Browse results of a restore do not change
+		if m.top().kind == screenFiles {
+			return m, m.tick()
+		}
+
+		return m, tea.Batch(m.load(m.top()), m.tick())
+	case loadedMsg:
+		msg.screen.setRows(msg.rows, msg.err)
+
+		return m, nil
+	case statusMsg:
+		m.status, m.err = msg.text, msg.err
+
+		return m, nil
+	case restoreCreatedMsg:
+		if msg.err != nil {
+			m.status, m.err = "", fmt.Errorf("creating file restore - %w", msg.err)
+
+			return m, nil
+		}
+
+		m.restores[msg.restore.FileRestoreID] = true
+		m.status, m.err = "", nil
+
+		// The user may have moved on while the restore was created
+		if m.top() != msg.parent {
+			return m, nil
+		}
+
+		return m, m.push(&screen{
+			kind:     screenFiles,
+			title:    "Files",
+			restore:  msg.restore,
+			selected: map[string]goslide.FileRestoreData{},
+		})
+	case tea.KeyMsg:
+		return m, m.handleKey(msg.String())
+	}
+
+	return m, nil
+}
+
+func (m *tuiModel) handleKey(key string) tea.Cmd {
+	if m.confirm != nil {
+		confirm := m.confirm
+		m.confirm = nil
+
+		if key == "y" || key == "Y" {
+			return confirm.action
+		}
+
+		m.status = "Cancelled"
+
+		return nil
+	}
+
+	current := m.top()
+
+	switch key {
+	case "ctrl+c", "q":
+		return tea.Quit
+	case "up", "k":
+		current.move(-1)
+	case "down", "j":
+		current.move(1)
+	case "pgup":
+		current.move(-m.pageSize())
+	case "pgdown":
+		current.move(m.pageSize())
+	case "home", "g":
+		current.move(-len(current.rows))
+	case "end", "G":
+		current.move(len(current.rows))
+	case "enter", "right", "l":
+		return m.open()
+	case "esc", "left", "h", "backspace":
+		return m.back()
+	case "r":
+		return m.load(current)
+	case "?":
+		m.showHelp = !m.showHelp
+	default:
+		return m.action(key)
+	}
+
+	return nil
+}
+
+func (m *tuiModel) push(next *screen) tea.Cmd {
+	next.scope(m.top())
+	m.stack = append(m.stack, next)
+	m.status, m.err = "", nil
+
+	return m.load(next)
+}
+
+// back pops the current screen. Leaving the root of a file restore removes
+// the restore.
+func (m *tuiModel) back() tea.Cmd {
+	if len(m.stack) == 1 {
+		return nil
+	}
+
+	current := m.top()
+	m.stack = m.stack[:len(m.stack)-1]
+	m.status, m.err = "", nil
+
+	if current.kind == screenFiles && current.path == "" {
+		return m.deleteRestore(current.restore.FileRestoreID)
+	}
+
+	return nil
+}
+
+func (m *tuiModel) open() tea.Cmd {
+	current := m.top()
+
+	row, ok := current.selectedRow()
+	if !ok {
+		return nil
+	}
+
+	switch value := row.value.(type) {
+	case goslide.Client:
+		return m.push(&screen{kind: screenDevices, title: value.Name, clientID: value.ClientID})
+	case goslide.Device:
+		return m.push(&screen{kind: screenAgents, title: deviceName(value), device: value})
+	case goslide.Agent:
+		return m.push(&screen{kind: screenSnapshots, title: agentName(value), agent: value})
+	case goslide.Snapshot:
+		return m.createRestore(current, value)
+	case goslide.FileRestoreData:
+		if value.Type != goslide.FileRestoreDataType_DIR {
+			return nil
+		}
+
+		return m.push(&screen{kind: screenFiles, title: value.Name, path: value.Path})
+	}
+
+	return nil
+}
+
+// action runs the keys specific to a screen.
+func (m *tuiModel) action(key string) tea.Cmd {
+	current := m.top()
+
+	switch current.kind {
+	case screenDevices:
+		row, ok := current.selectedRow()
+		if key == "a" && ok {
+			device := row.value.(goslide.Device)
+
+			return m.push(&screen{kind: screenAlerts, title: "Alerts of " + deviceName(device), device: device})
+		}
+	case screenAgents:
+		row, ok := current.selectedRow()
+		if !ok {
+			return nil
+		}
+
+		agent := row.value.(goslide.Agent)
+		switch key {
+		case "s":
+			return m.push(&screen{kind: screenSnapshots, title: agentName(agent), agent: agent})
+		case "b":
+			return m.push(&screen{kind: screenBackups, title: "Backups of " + agentName(agent), agent: agent})
+		case "a":
+			return m.push(&screen{kind: screenAlerts, title: "Alerts of " + agentName(agent), agent: agent})
+		case "B":
+			m.confirm = &confirmation{
+				prompt: fmt.Sprintf("Start a backup of %s? (y/n)", agentName(agent)),
+				action: m.startBackup(agent),
+			}
+		}
+	case screenSnapshots:
+		if key == "b" {
+			return m.push(&screen{kind: screenBackups, title: "Backups of " + agentName(current.agent), agent: current.agent})
+		}
+	case screenFiles:
+		switch key {
+		case " ", "space":
+			current.toggle()
+			current.move(1)
+		case "d":
+			return m.download(current)
+		}
+	}
+
+	return nil
+}
+
+func (m *tuiModel) pageSize() int {
+	return max(m.listHeight()-1, 1)
+}
+
+func (m *tuiModel) startBackup(agent goslide.Agent) tea.Cmd {
+	m.status = ""
+
+	return func() tea.Msg {
+		if err := m.service.Backups().StartBackup(m.ctx, agent.AgentID); err != nil {
+			return statusMsg{err: fmt.Errorf("starting backup - %w", err)}
+		}
+
+		return statusMsg{text: "Started a backup of " + agentName(agent)}
+	}
+}
+
+func (m *tuiModel) createRestore(parent *screen, snapshot goslide.Snapshot) tea.Cmd {
+	deviceID := parent.agent.DeviceID
+	for _, location := range snapshot.Locations {
+		if location.Type == goslide.SnapshotLocationType_LOCAL {
+			deviceID = location.DeviceID
+		}
+	}
+
+	m.status, m.err = "Mounting snapshot for file restore...", nil
+
+	return func() tea.Msg {
+		restore, err := m.service.FileRestores().Create(m.ctx, goslide.FileRestorePayload{
+			DeviceID:   deviceID,
+			SnapshotID: snapshot.SnapshotID,
+		})
+
+		return restoreCreatedMsg{parent: parent, restore: restore, err: err}
+	}
+}
+
+func (m *tuiModel) deleteRestore(fileRestoreID string) tea.Cmd {
+	delete(m.restores, fileRestoreID)
+
+	return func() tea.Msg {
+		if err := m.service.FileRestores().Delete(m.ctx, fileRestoreID); err != nil {
+			return statusMsg{err: fmt.Errorf("removing file restore - %w", err)}
+		}
+
+		return statusMsg{text: "Removed file restore " + fileRestoreID}
+	}
+}
+
+// cleanup removes the file restores that are still open.
+func (m *tuiModel) cleanup(ctx context.Context) error {
+	errs := []error{}
+	for fileRestoreID := range m.restores {
+		if err := m.service.FileRestores().Delete(ctx, fileRestoreID); err != nil {
+			errs = append(errs, fmt.Errorf("removing file restore %s - %w", fileRestoreID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/equalsgibson/goslide"
+)
+
+// download fetches the selected files of the restore, or the file under the
+// cursor when none are selected.
+func (m *tuiModel) download(s *screen) tea.Cmd {
+	files := []goslide.FileRestoreData{}
+	for _, file := range s.selected {
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	if len(files) == 0 {
+		row, ok := s.selectedRow()
+		if !ok || row.value.(goslide.FileRestoreData).Type != goslide.FileRestoreDataType_FILE {
+			m.status = "Select files with space, or move to a file"
+
+			return nil
+		}
+
+		files = append(files, row.value.(goslide.FileRestoreData))
+	}
+
+	// The selection is handed to the download
+	clear(s.selected)
+	for _, row := range s.rows {
+		row.cells[0] = s.mark(row.value.(goslide.FileRestoreData))
+	}
+
+	m.status, m.err = fmt.Sprintf("Downloading %d file(s)...", len(files)), nil
+
+	return func() tea.Msg {
+		for _, file := range files {
+			if err := m.downloadFile(m.ctx, file); err != nil {
+				return statusMsg{err: fmt.Errorf("downloading %s - %w", file.Path, err)}
+			}
+		}
+
+		return statusMsg{text: fmt.Sprintf("Downloaded %d file(s) to %s", len(files), m.options.downloadDir)}
+	}
+}
+
+func (m *tuiModel) downloadFile(ctx context.Context, file goslide.FileRestoreData) error {
+	downloadURI, ok := selectFileDownloadURI(file.DownloadURIs, m.options.downloadFrom)
+	if !ok {
+		return fmt.Errorf("no download URI")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURI.URI, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	response, err := m.options.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %d", response.StatusCode)
+	}
+
+	// Partial downloads never replace an existing file
+	temp, err := os.CreateTemp(m.options.downloadDir, ".slide-download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, response.Body); err != nil {
+		temp.Close()
+
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), filepath.Join(m.options.downloadDir, filepath.Base(file.Name)))
+}
+
+// selectFileDownloadURI returns the URI of the preferred type, falling back
+// to the first URI available.
+func selectFileDownloadURI(
+	downloadURIs []goslide.FileRestoreDownloadURI,
+	preferred goslide.FileRestoreDownloadType,
+) (goslide.FileRestoreDownloadURI, bool) {
+	for _, downloadURI := range downloadURIs {
+		if downloadURI.Type == preferred {
+			return downloadURI, true
+		}
+	}
+
+	if len(downloadURIs) > 0 {
+		return downloadURIs[0], true
+	}
+
+	return goslide.FileRestoreDownloadURI{}, false
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/equalsgibson/goslide"
+)
+
+type screenKind int
+
+const (
+	screenClients screenKind = iota
+	screenDevices
+	screenAgents
+	screenSnapshots
+	screenBackups
+	screenAlerts
+	screenFiles
+)
+
+// allClients is the clients row that lists every device, including devices
+// without a client.
+const allClients = "*"
+
+// screenColumns are the column headers of each kind of screen.
+var screenColumns = map[screenKind][]string{
+	screenClients:   {"NAME", "CLIENT", "COMMENTS"},
+	screenDevices:   {"NAME", "HOSTNAME", "LAST SEEN", "STORAGE", "MODEL"},
+	screenAgents:    {"NAME", "HOSTNAME", "OS", "LAST SEEN", "VERSION"},
+	screenSnapshots: {"BACKUP ENDED", "LOCATIONS", "BOOT CHECK", "FS CHECK"},
+	screenBackups:   {"STARTED", "DURATION", "STATUS", "ERROR"},
+	screenAlerts:    {"RAISED", "TYPE", "RESOLVED"},
+	screenFiles:     {"", "NAME", "SIZE", "MODIFIED"},
+}
+
+type screen struct {
+	kind   screenKind
+	title  string
+	rows   []tuiRow
+	cursor int
+	offset int
+	loaded bool
+	err    error
+
+	// The scope is inherited from the screen below
+	clientID string
+	device   goslide.Device
+	agent    goslide.Agent
+	restore  goslide.FileRestore
+	path     string
+
+	// selected files, by path, shared by every directory of a restore
+	selected map[string]goslide.FileRestoreData
+}
+
+type tuiRow struct {
+	id    string
+	cells []string
+	value any
+}
+
+func (s *screen) scope(parent *screen) {
+	if s.clientID == "" {
+		s.clientID = parent.clientID
+	}
+
+	if s.device.DeviceID == "" {
+		s.device = parent.device
+	}
+
+	if s.agent.AgentID == "" {
+		s.agent = parent.agent
+	}
+
+	if s.restore.FileRestoreID == "" {
+		s.restore = parent.restore
+	}
+
+	if s.selected == nil {
+		s.selected = parent.selected
+	}
+}
+
+// setRows replaces the rows and keeps the cursor on the same record.
+func (s *screen) setRows(rows []tuiRow, err error) {
+	s.loaded = true
+	s.err = err
+	if err != nil {
+		return
+	}
+
+	selectedID := ""
+	if row, ok := s.selectedRow(); ok {
+		selectedID = row.id
+	}
+
+	// Selections live in the screen, the rows are fetched in the background
+	if s.kind == screenFiles {
+		for _, row := range rows {
+			row.cells[0] = s.mark(row.value.(goslide.FileRestoreData))
+		}
+	}
+
+	s.rows = rows
+	s.cursor = min(s.cursor, max(len(rows)-1, 0))
+
+	for i, row := range rows {
+		if row.id == selectedID {
+			s.cursor = i
+		}
+	}
+}
+
+func (s *screen) selectedRow() (tuiRow, bool) {
+	if s.cursor < 0 || s.cursor >= len(s.rows) {
+		return tuiRow{}, false
+	}
+
+	return s.rows[s.cursor], true
+}
+
+func (s *screen) move(delta int) {
+	s.cursor = max(min(s.cursor+delta, len(s.rows)-1), 0)
+}
+
+// toggle selects or deselects the file under the cursor.
+func (s *screen) toggle() {
+	row, ok := s.selectedRow()
+	if !ok {
+		return
+	}
+
+	file := row.value.(goslide.FileRestoreData)
+	if file.Type != goslide.FileRestoreDataType_FILE {
+		return
+	}
+
+	if _, ok := s.selected[file.Path]; ok {
+		delete(s.selected, file.Path)
+	} else {
+		s.selected[file.Path] = file
+	}
+
+	row.cells[0] = s.mark(file)
+}
+
+func (s *screen) mark(file goslide.FileRestoreData) string {
+	if _, ok := s.selected[file.Path]; ok {
+		return "[x]"
+	}
+
+	if file.Type == goslide.FileRestoreDataType_FILE {
+		return "[ ]"
+	}
+
+	return ""
+}
+
+func (s *screen) help() string {
+	switch s.kind {
+	case screenClients:
+		return "enter devices · r refresh · q quit"
+	case screenDevices:
+		return "enter agents · a alerts · esc back · q quit"
+	case screenAgents:
+		return "enter snapshots · b backups · a alerts · B start backup · esc back"
+	case screenSnapshots:
+		return "enter browse files · b backups · esc back"
+	case screenFiles:
+		return "enter open · space select · d download · esc back"
+	default:
+		return "r refresh · esc back · q quit"
+	}
+}
+
+// load fetches the rows of a screen in the background.
+func (m *tuiModel) load(s *screen) tea.Cmd {
+	return func() tea.Msg {
+		rows, err := m.fetch(s)
+
+		return loadedMsg{screen: s, rows: rows, err: err}
+	}
+}
+
+func (m *tuiModel) fetch(s *screen) ([]tuiRow, error) {
+	switch s.kind {
+	case screenClients:
+		clients, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Client]) error) error {
+			return m.service.Clients().List(m.ctx, pageHandler)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(clients, func(i, j int) bool {
+			return strings.ToLower(clients[i].Name) < strings.ToLower(clients[j].Name)
+		})
+
+		rows := []tuiRow{{id: allClients, cells: []string{"All devices", "", ""}, value: goslide.Client{ClientID: allClients, Name: "All devices"}}}
+		for _, client := range clients {
+			rows = append(rows, tuiRow{id: client.ClientID, cells: []string{client.Name, client.ClientID, client.Comments}, value: client})
+		}
+
+		return rows, nil
+	case screenDevices:
+		options := listOptions{}
+		if s.clientID != allClients {
+			options = append(options, goslide.WithClientID(s.clientID))
+		}
+
+		devices, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Device]) error) error {
+			return m.service.Devices().ListWithQueryParameters(m.ctx, pageHandler, options.apply)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rows := []tuiRow{}
+		for _, device := range devices {
+			storage := fmt.Sprintf("%s / %s", formatBytes(device.StorageUsedBytes), formatBytes(device.StorageTotalBytes))
+			rows = append(rows, tuiRow{
+				id:    device.DeviceID,
+				cells: []string{deviceName(device), device.Hostname, m.ago(device.LastSeenAt), storage, device.HardwareModelName},
+				value: device,
+			})
+		}
+
+		return rows, nil
+	case screenAgents:
+		agents, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Agent]) error) error {
+			return m.service.Agents().ListWithQueryParameters(m.ctx, pageHandler, goslide.WithDeviceID(s.device.DeviceID))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rows := []tuiRow{}
+		for _, agent := range agents {
+			rows = append(rows, tuiRow{
+				id:    agent.AgentID,
+				cells: []string{agentName(agent), agent.Hostname, agent.OS, m.ago(agent.LastSeenAt), agent.AgentVersion},
+				value: agent,
+			})
+		}
+
+		return rows, nil
+	case screenSnapshots:
+		snapshots, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Snapshot]) error) error {
+			return m.service.Snapshots().ListWithQueryParameters(m.ctx, pageHandler, goslide.WithAgentID(s.agent.AgentID))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(snapshots, func(i, j int) bool {
+			return snapshots[i].BackupEndedAt.After(snapshots[j].BackupEndedAt)
+		})
+
+		rows := []tuiRow{}
+		for _, snapshot := range snapshots {
+			if snapshot.Deleted != nil {
+				continue
+			}
+
+			locations := []string{}
+			for _, location := range snapshot.Locations {
+				locations = append(locations, string(location.Type))
+			}
+
+			rows = append(rows, tuiRow{
+				id:    snapshot.SnapshotID,
+				cells: []string{m.timestamp(snapshot.BackupEndedAt), strings.Join(locations, ", "), string(snapshot.VerifyBootStatus), string(snapshot.VerifyFSStatus)},
+				value: snapshot,
+			})
+		}
+
+		return rows, nil
+	case screenBackups:
+		backups, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Backup]) error) error {
+			return m.service.Backups().ListWithQueryParameters(m.ctx, pageHandler, goslide.WithAgentID(s.agent.AgentID))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(backups, func(i, j int) bool {
+			return backups[i].StartedAt.After(backups[j].StartedAt)
+		})
+
+		rows := []tuiRow{}
+		for _, backup := range backups {
+			duration := ""
+			if !backup.EndedAt.IsZero() {
+				duration = backup.EndedAt.Sub(backup.StartedAt).Round(time.Second).String()
+			}
+
+			rows = append(rows, tuiRow{
+				id:    backup.BackupID,
+				cells: []string{m.timestamp(backup.StartedAt), duration, string(backup.Status), backup.ErrorMessage},
+				value: backup,
+			})
+		}
+
+		return rows, nil
+	case screenAlerts:
+		option := goslide.WithDeviceID(s.device.DeviceID)
+		if s.agent.AgentID != "" {
+			option = goslide.WithAgentID(s.agent.AgentID)
+		}
+
+		alerts, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.Alert]) error) error {
+			return m.service.Alerts().ListWithQueryParameters(m.ctx, pageHandler, option)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(alerts, func(i, j int) bool {
+			return alerts[i].CreatedAt.After(alerts[j].CreatedAt)
+		})
+
+		rows := []tuiRow{}
+		for _, alert := range alerts {
+			resolved := ""
+			if alert.ResolvedAt != nil {
+				resolved = m.ago(*alert.ResolvedAt)
+			}
+
+			rows = append(rows, tuiRow{
+				id:    alert.AlertID,
+				cells: []string{m.ago(alert.CreatedAt), string(alert.AlertType), resolved},
+				value: alert,
+			})
+		}
+
+		return rows, nil
+	case screenFiles:
+		options := listOptions{}
+		if s.path != "" {
+			options = append(options, goslide.WithPath(s.path))
+		}
+
+		files, err := collect(0, func(pageHandler func(goslide.ListResponse[goslide.FileRestoreData]) error) error {
+			return m.service.FileRestores().BrowseWithQueryParameters(m.ctx, s.restore.FileRestoreID, pageHandler, options.apply)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Directories first, like a file manager
+		sort.SliceStable(files, func(i, j int) bool {
+			iDir, jDir := files[i].Type == goslide.FileRestoreDataType_DIR, files[j].Type == goslide.FileRestoreDataType_DIR
+			if iDir != jDir {
+				return iDir
+			}
+
+			return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
+		})
+
+		rows := []tuiRow{}
+		for _, file := range files {
+			name, size := file.Name, formatBytes(uint64(file.Size))
+			switch file.Type {
+			case goslide.FileRestoreDataType_DIR:
+				name, size = name+"/", ""
+			case goslide.FileRestoreDataType_SYMLINK:
+				name = name + " -> " + file.SymlinkTargetPath
+			}
+
+			rows = append(rows, tuiRow{
+				id:    file.Path,
+				cells: []string{"", name, size, file.ModifiedAt},
+				value: file,
+			})
+		}
+
+		return rows, nil
+	}
+
+	return nil, nil
+}
+
+func (m *tuiModel) timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// ago formats how long ago t was, such as 5m ago.
+func (m *tuiModel) ago(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	elapsed := m.now().Sub(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	}
+}
+
+func deviceName(device goslide.Device) string {
+	if device.DisplayName != "" {
+		return device.DisplayName
+	}
+
+	return device.Hostname
+}
+
+func agentName(agent goslide.Agent) string {
+	if agent.DisplayName != "" {
+		return agent.DisplayName
+	}
+
+	return agent.Hostname
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+)
+
+// runCmd executes a command and feeds its messages back into the model, the
+// way the bubbletea runtime would.
+func runCmd(t *testing.T, model *tuiModel, cmd tea.Cmd) {
+	t.Helper()
+
+	if cmd == nil {
+		return
+	}
+
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			runCmd(t, model, cmd)
+		}
+	default:
+		_, next := model.Update(msg)
+		runCmd(t, model, next)
+	}
+}
+
+func press(t *testing.T, model *tuiModel, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "space":
+			msg = tea.KeyMsg{Type: tea.KeySpace}
+		}
+
+		_, cmd := model.Update(msg)
+		runCmd(t, model, cmd)
+	}
+}
+
+func expectView(t *testing.T, model *tuiModel, expected ...string) {
+	t.Helper()
+
+	view := model.View()
+	for _, text := range expected {
+		if !strings.Contains(view, text) {
+			t.Fatalf("%s expected the screen to contain %q, got:\n%s", t.Name(), text, view)
+		}
+	}
+}
+
+func TestTUI(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("contents of " + r.URL.Path))
+	}))
+	defer files.Close()
+
+	server := fakeslide.NewFleet()
+	server.Snapshots = []goslide.Snapshot{{
+		SnapshotID:    "s_1",
+		AgentID:       "a_dc",
+		BackupEndedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Locations:     []goslide.SnapshotLocation{{Type: goslide.SnapshotLocationType_LOCAL, DeviceID: "d_acme"}},
+	}}
+	server.Files[""] = []goslide.FileRestoreData{
+		{Name: "C", Path: "C", Type: goslide.FileRestoreDataType_DIR},
+	}
+	server.Files["C"] = []goslide.FileRestoreData{
+		{Name: "a.txt", Path: "C/a.txt", Type: goslide.FileRestoreDataType_FILE, Size: 12, DownloadURIs: []goslide.FileRestoreDownloadURI{
+			{Type: goslide.FileRestoreDownloadType_LOCAL, URI: files.URL + "/local/a.txt"},
+			{Type: goslide.FileRestoreDownloadType_CLOUD, URI: files.URL + "/cloud/a.txt"},
+		}},
+		{Name: "b.txt", Path: "C/b.txt", Type: goslide.FileRestoreDataType_FILE, Size: 12, DownloadURIs: []goslide.FileRestoreDownloadURI{
+			{Type: goslide.FileRestoreDownloadType_LOCAL, URI: files.URL + "/local/b.txt"},
+		}},
+	}
+
+	downloadDir := t.TempDir()
+	service := goslide.NewService("fakeToken", goslide.WithCustomRoundtripper(roundtripper.HandlerNetwork(server)))
+	model := newTUI(context.Background(), service, tuiOptions{
+		downloadDir:  downloadDir,
+		downloadFrom: goslide.FileRestoreDownloadType_CLOUD,
+		httpClient:   files.Client(),
+	})
+
+	runCmd(t, model, model.Init())
+	expectView(t, model, "All devices", "Acme", "Globex")
+
+	// Clients, then devices of Acme, then agents of Acme HQ
+	press(t, model, "down", "enter")
+	expectView(t, model, "Slide › Acme", "Acme HQ", "Acme Branch")
+
+	press(t, model, "enter")
+	expectView(t, model, "Acme HQ", "Acme DC01")
+
+	// A backup only starts after confirmation
+	press(t, model, "B")
+	expectView(t, model, "Start a backup of Acme DC01? (y/n)")
+
+	press(t, model, "n")
+	if len(server.Backups) != 0 {
+		t.Fatalf("%s expected no backup after cancelling, got %d", t.Name(), len(server.Backups))
+	}
+
+	press(t, model, "B", "y")
+	expectView(t, model, "Started a backup of Acme DC01")
+	if len(server.Backups) != 1 || server.Backups[0].AgentID != "a_dc" {
+		t.Fatalf("%s expected a backup of a_dc, got %+v", t.Name(), server.Backups)
+	}
+
+	press(t, model, "b")
+	expectView(t, model, "Backups of Acme DC01")
+	press(t, model, "esc")
+
+	// Opening a snapshot creates a file restore to browse
+	press(t, model, "enter")
+	expectView(t, model, "2025-01-02")
+
+	press(t, model, "enter")
+	if len(server.FileRestores) != 1 {
+		t.Fatalf("%s expected a file restore, got %d", t.Name(), len(server.FileRestores))
+	}
+	expectView(t, model, "Files", "C")
+
+	press(t, model, "enter")
+	expectView(t, model, "a.txt", "b.txt")
+
+	press(t, model, "space", "space", "d")
+	expectView(t, model, "Downloaded 2 file(s) to "+downloadDir)
+
+	for name, expected := range map[string]string{
+		"a.txt": "contents of /cloud/a.txt",
+		"b.txt": "contents of /local/b.txt",
+	} {
+		data, err := os.ReadFile(filepath.Join(downloadDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != expected {
+			t.Errorf("%s expected %s to contain %q, got %q", t.Name(), name, expected, data)
+		}
+	}
+
+	// Leaving the restore removes it
+	press(t, model, "esc", "esc")
+	if len(server.FileRestores) != 0 {
+		t.Fatalf("%s expected the file restore to be removed, got %+v", t.Name(), server.FileRestores)
+	}
+
+	// Polling picks up new agents
+	server.Agents = append(server.Agents, goslide.Agent{AgentID: "a_files", DeviceID: "d_acme", DisplayName: "File Server"})
+	press(t, model, "esc")
+	expectView(t, model, "Acme DC01")
+
+	_, cmd := model.Update(tickMsg(time.Now()))
+	runCmd(t, model, cmd)
+	expectView(t, model, "Acme DC01", "File Server")
+
+	if err := model.cleanup(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Styles only use attributes and the 16 basic colors, which every terminal
+// reached over SSH supports.
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true).Reverse(true)
+	headerStyle = lipgloss.NewStyle().Bold(true)
+	cursorStyle = lipgloss.NewStyle().Reverse(true)
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	helpStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+const (
+	defaultWidth    = 80
+	defaultHeight   = 24
+	maxColumnWidth  = 40
+	columnSeparator = "  "
+)
+
+func (m *tuiModel) size() (int, int) {
+	width, height := m.width, m.height
+	if width <= 0 {
+		width = defaultWidth
+	}
+
+	if height <= 0 {
+		height = defaultHeight
+	}
+
+	return width, height
+}
+
+// listHeight is the number of rows that fit between the title, the column
+// headers, the status line and the key help.
+func (m *tuiModel) listHeight() int {
+	_, height := m.size()
+
+	return max(height-4, 1)
+}
+
+func (m *tuiModel) View() string {
+	width, _ := m.size()
+	current := m.top()
+
+	titles := []string{"Slide"}
+	for _, s := range m.stack[1:] {
+		titles = append(titles, s.title)
+	}
+
+	lines := []string{titleStyle.Render(pad(" "+strings.Join(titles, " › "), width))}
+
+	columns := screenColumns[current.kind]
+	widths := columnWidths(columns, current.rows)
+	lines = append(lines, headerStyle.Render(pad(formatRow(columns, widths), width)))
+
+	listHeight := m.listHeight()
+	switch {
+	case current.err != nil:
+		lines = append(lines, errorStyle.Render(truncate(current.err.Error(), width)))
+	case !current.loaded:
+		lines = append(lines, "Loading...")
+	case len(current.rows) == 0:
+		lines = append(lines, "Nothing here")
+	default:
+		// Scroll just enough to keep the cursor in view
+		if current.cursor < current.offset {
+			current.offset = current.cursor
+		}
+
+		if current.cursor >= current.offset+listHeight {
+			current.offset = current.cursor - listHeight + 1
+		}
+
+		end := min(current.offset+listHeight, len(current.rows))
+		for i := current.offset; i < end; i++ {
+			line := pad(formatRow(current.rows[i].cells, widths), width)
+			if i == current.cursor {
+				line = cursorStyle.Render(line)
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	for len(lines) < listHeight+2 {
+		lines = append(lines, "")
+	}
+
+	switch {
+	case m.confirm != nil:
+		lines = append(lines, headerStyle.Render(truncate(m.confirm.prompt, width)))
+	case m.err != nil:
+		lines = append(lines, errorStyle.Render(truncate(m.err.Error(), width)))
+	default:
+		lines = append(lines, truncate(m.status, width))
+	}
+
+	help := "? keys · q quit"
+	if m.showHelp {
+		help = current.help() + " · ↑↓ pgup pgdn move"
+	}
+	lines = append(lines, helpStyle.Render(truncate(help, width)))
+
+	return strings.Join(lines, "\n")
+}
+
+func columnWidths(columns []string, rows []tuiRow) []int {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = runewidth.StringWidth(column)
+	}
+
+	for _, row := range rows {
+		for i, cell := range row.cells {
+			widths[i] = min(max(widths[i], runewidth.StringWidth(cell)), maxColumnWidth)
+		}
+	}
+
+	return widths
+}
+
+func formatRow(cells []string, widths []int) string {
+	formatted := make([]string, 0, len(cells))
+	for i, cell := range cells {
+		formatted = append(formatted, runewidth.FillRight(truncate(cell, widths[i]), widths[i]))
+	}
+
+	return " " + strings.Join(formatted, columnSeparator)
+}
+
+func truncate(s string, width int) string {
+	return runewidth.Truncate(s, width, "…")
+}
+
+// pad fills a line to the full width, so highlighted rows span the screen.
+func pad(s string, width int) string {
+	return runewidth.FillRight(truncate(s, width), width)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+
+	"github.com/equalsgibson/goslide"
+)
+
+var userColumns = []column[goslide.User]{
+	{"user_id", func(u goslide.User) string { return u.UserID }},
+	{"display_name", func(u goslide.User) string { return u.DisplayName }},
+	{"email", func(u goslide.User) string { return u.Email }},
+	{"role", func(u goslide.User) string { return string(u.RoleID) }},
+}
+
+func usersCommand() *command {
+	list := &listFlags{}
+
+	return &command{
+		name:    "users",
+		summary: "List and show users.",
+		subcommands: []*command{
+			{
+				name:    "list",
+				summary: "List users.",
+				flags:   list.register,
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args); err != nil {
+						return err
+					}
+
+					options := list.options()
+
+					users, err := collect(list.limit, func(pageHandler func(goslide.ListResponse[goslide.User]) error) error {
+						return app.service.Users().ListWithQueryParameters(ctx, pageHandler, options.apply)
+					})
+					if err != nil {
+						return err
+					}
+
+					return printList(app, users, userColumns)
+				},
+			},
+			{
+				name:    "get",
+				args:    "<user-id>",
+				summary: "Show a user.",
+				run: func(ctx context.Context, app *app, args []string) error {
+					if err := exactArgs(args, "<user-id>"); err != nil {
+						return err
+					}
+
+					user, err := app.service.Users().Get(ctx, args[0])
+					if err != nil {
+						return err
+					}
+
+					return printRecord(app, user, userColumns)
+				},
+			},
+		},
+	}
+}
@@ -61,7 +61,12 @@ func (d DeviceService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Device]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := d.requestClient.startPagination(ctx, d.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -0,0 +1,390 @@
+// Package drtest proves that agents can be restored, by collecting the boot
+// verification results of their latest snapshots and optionally booting them
+// as virtual machines on an isolated network. The results are written as an
+// HTML and JSON evidence bundle.
+package drtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	defaultSampleSize   = 1
+	defaultPollInterval = 10 * time.Second
+	defaultBootTimeout  = 15 * time.Minute
+)
+
+type Tester struct {
+	service      goslide.Service
+	clientIDs    []string
+	sampleSize   int
+	bootMachines bool
+	bootTimeout  time.Duration
+	pollInterval time.Duration
+	httpClient   *http.Client
+	random       *rand.Rand
+	now          func() time.Time
+}
+
+type testerOption func(t *Tester)
+
+// WithClientIDs limits the test to agents of the given clients.
+func WithClientIDs(clientIDs ...string) testerOption {
+	return func(t *Tester) {
+		t.clientIDs = clientIDs
+	}
+}
+
+// WithSampleSize sets how many agents are tested per client.
+func WithSampleSize(size int) testerOption {
+	return func(t *Tester) {
+		t.sampleSize = size
+	}
+}
+
+// WithVirtualMachineBoot restores each sampled snapshot as a virtual machine
+// on an isolated network, and waits up to timeout for it to be running, and
+// later for it to be stopped before it is deleted.
+func WithVirtualMachineBoot(timeout time.Duration) testerOption {
+	return func(t *Tester) {
+		t.bootMachines = true
+		t.bootTimeout = timeout
+	}
+}
+
+// WithPollInterval sets how often the API is polled while a virtual machine
+// boots or stops.
+func WithPollInterval(interval time.Duration) testerOption {
+	return func(t *Tester) {
+		t.pollInterval = interval
+	}
+}
+
+// WithHTTPClient sets the client used to download verification screenshots.
+func WithHTTPClient(httpClient *http.Client) testerOption {
+	return func(t *Tester) {
+		t.httpClient = httpClient
+	}
+}
+
+// WithRandom sets the source used to sample agents, so a run can be repeated.
+func WithRandom(random *rand.Rand) testerOption {
+	return func(t *Tester) {
+		t.random = random
+	}
+}
+
+func NewTester(service goslide.Service, options ...testerOption) *Tester {
+	tester := &Tester{
+		service:      service,
+		sampleSize:   defaultSampleSize,
+		bootTimeout:  defaultBootTimeout,
+		pollInterval: defaultPollInterval,
+		httpClient:   http.DefaultClient,
+		random:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		now:          time.Now,
+	}
+
+	for _, option := range options {
+		option(tester)
+	}
+
+	return tester
+}
+
+// Run tests a sample of agents per client and writes the evidence bundle to
+// dir. Every virtual machine created by the run is stopped and removed before
+// Run returns, including when the run fails part way through.
+func (t *Tester) Run(ctx context.Context, dir string) (report Report, err error) {
+	if err := os.MkdirAll(filepath.Join(dir, screenshotDir), 0o755); err != nil {
+		return Report{}, err
+	}
+
+	report = Report{
+		StartedAt: t.now().UTC(),
+		Results:   []AgentResult{},
+		Teardown:  []TeardownResult{},
+	}
+
+	cleanup := &teardown{}
+	defer func() {
+		// Teardown runs on a fresh context, so a cancelled run still cleans up
+		report.Teardown = cleanup.run(context.WithoutCancel(ctx), t)
+		report.FinishedAt = t.now().UTC()
+
+		err = errors.Join(err, report.write(dir))
+	}()
+
+	samples, err := t.sample(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, agent := range samples {
+		result := t.testAgent(ctx, dir, agent, cleanup)
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// sample picks up to sampleSize agents from each client, in client order.
+func (t *Tester) sample(ctx context.Context) ([]goslide.Agent, error) {
+	byClient := map[string][]goslide.Agent{}
+	if err := t.service.Agents().List(ctx, func(response goslide.ListResponse[goslide.Agent]) error {
+		for _, agent := range response.Data {
+			if len(t.clientIDs) > 0 && !slices.Contains(t.clientIDs, agent.ClientID) {
+				continue
+			}
+
+			byClient[agent.ClientID] = append(byClient[agent.ClientID], agent)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	clientIDs := make([]string, 0, len(byClient))
+	for clientID := range byClient {
+		clientIDs = append(clientIDs, clientID)
+	}
+	sort.Strings(clientIDs)
+
+	samples := []goslide.Agent{}
+	for _, clientID := range clientIDs {
+		agents := byClient[clientID]
+		sort.Slice(agents, func(i, j int) bool {
+			return agents[i].AgentID < agents[j].AgentID
+		})
+
+		t.random.Shuffle(len(agents), func(i, j int) {
+			agents[i], agents[j] = agents[j], agents[i]
+		})
+
+		samples = append(samples, agents[:min(t.sampleSize, len(agents))]...)
+	}
+
+	return samples, nil
+}
+
+func (t *Tester) testAgent(ctx context.Context, dir string, agent goslide.Agent, cleanup *teardown) AgentResult {
+	result := AgentResult{
+		ClientID: agent.ClientID,
+		AgentID:  agent.AgentID,
+		Hostname: agent.Hostname,
+		TestedAt: t.now().UTC(),
+	}
+
+	snapshot, found, err := t.latestSnapshot(ctx, agent.AgentID)
+	if err != nil {
+		result.Error = err.Error()
+
+		return result
+	}
+
+	if !found {
+		result.Error = "no local snapshot found"
+
+		return result
+	}
+
+	result.SnapshotID = snapshot.SnapshotID
+	result.BackupEndedAt = snapshot.BackupEndedAt
+	result.VerifyBootStatus = snapshot.VerifyBootStatus
+	result.VerifyFSStatus = snapshot.VerifyFSStatus
+	result.ScreenshotURL = snapshot.VerifyBootScreenshotURL
+
+	if snapshot.VerifyBootScreenshotURL != "" {
+		screenshot, err := t.downloadScreenshot(ctx, dir, snapshot)
+		if err != nil {
+			result.Error = fmt.Sprintf("downloading screenshot - %s", err)
+		}
+
+		result.Screenshot = screenshot
+	}
+
+	if t.bootMachines {
+		result.VirtualMachine = t.bootVirtualMachine(ctx, agent, snapshot, cleanup)
+	}
+
+	result.Passed = result.Error == "" &&
+		result.VerifyBootStatus == goslide.SnapshotBootStatus_SUCCESS &&
+		result.VerifyFSStatus == goslide.SnapshotFSStatus_SUCCESS &&
+		(result.VirtualMachine == nil || result.VirtualMachine.Error == "")
+
+	return result
+}
+
+func (t *Tester) latestSnapshot(ctx context.Context, agentID string) (goslide.Snapshot, bool, error) {
+	latest := goslide.Snapshot{}
+	found := false
+
+	err := t.service.Snapshots().ListWithQueryParameters(ctx, func(response goslide.ListResponse[goslide.Snapshot]) error {
+		for _, snapshot := range response.Data {
+			if snapshot.AgentID != agentID || snapshot.Deleted != nil {
+				continue
+			}
+
+			if !found || snapshot.BackupEndedAt.After(latest.BackupEndedAt) {
+				latest = snapshot
+				found = true
+			}
+		}
+
+		return nil
+	},
+		goslide.WithAgentID(agentID),
+		goslide.WithSnapshotLocationFilter(goslide.SnapshotLocationFilter_EXISTS_LOCAL),
+	)
+
+	return latest, found, err
+}
+
+func (t *Tester) downloadScreenshot(ctx context.Context, dir string, snapshot goslide.Snapshot) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, snapshot.VerifyBootScreenshotURL, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := t.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected HTTP status %d", response.StatusCode)
+	}
+
+	extension := ".png"
+	if mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type")); err == nil && mediaType == "image/jpeg" {
+		extension = ".jpg"
+	}
+
+	name := filepath.Join(screenshotDir, snapshot.AgentID+"-"+snapshot.SnapshotID+extension)
+
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, response.Body); err != nil {
+		file.Close()
+
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(name), nil
+}
+
+func (t *Tester) bootVirtualMachine(
+	ctx context.Context,
+	agent goslide.Agent,
+	snapshot goslide.Snapshot,
+	cleanup *teardown,
+) *VirtualMachineResult {
+	result := &VirtualMachineResult{}
+
+	deviceID := agent.DeviceID
+	for _, location := range snapshot.Locations {
+		if location.Type == goslide.SnapshotLocationType_LOCAL {
+			deviceID = location.DeviceID
+		}
+	}
+
+	vm, err := t.service.VirtualMachineRestores().Create(ctx, goslide.VirtualMachineRestoreCreatePayload{
+		DeviceID:    deviceID,
+		SnapshotID:  snapshot.SnapshotID,
+		NetworkType: goslide.VirtualMachineNetworkType_NETWORK_ISOLATED,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("creating virtual machine - %s", err)
+
+		return result
+	}
+
+	result.VirtID = vm.VirtID
+	result.CreatedAt = vm.CreatedAt
+	cleanup.virtIDs = append(cleanup.virtIDs, vm.VirtID)
+
+	bootCtx, cancel := context.WithTimeout(ctx, t.bootTimeout)
+	defer cancel()
+
+	vm, err = t.service.VirtualMachineRestores().Start(bootCtx, vm.VirtID, goslide.WithVirtualMachinePollInterval(t.pollInterval))
+	if err != nil {
+		result.Error = fmt.Sprintf("booting virtual machine - %s", err)
+
+		return result
+	}
+
+	result.State = vm.State
+	result.RunningAt = t.now().UTC()
+
+	return result
+}
+
+// teardown tracks the virtual machines created by a run.
+type teardown struct {
+	virtIDs []string
+}
+
+func (c *teardown) run(ctx context.Context, t *Tester) []TeardownResult {
+	results := []TeardownResult{}
+	for _, virtID := range c.virtIDs {
+		results = append(results, newTeardownResult("virtual_machine", virtID, t.removeVirtualMachine(ctx, virtID)))
+	}
+
+	return results
+}
+
+// removeVirtualMachine stops a running or paused virtual machine, waiting up
+// to the boot timeout for it to be stopped, and then deletes it.
+func (t *Tester) removeVirtualMachine(ctx context.Context, virtID string) error {
+	vm, err := t.service.VirtualMachineRestores().Get(ctx, virtID)
+	if err != nil {
+		return err
+	}
+
+	if vm.State == goslide.VirtualMachineState_RUNNING || vm.State == goslide.VirtualMachineState_PAUSED {
+		stopCtx, cancel := context.WithTimeout(ctx, t.bootTimeout)
+		defer cancel()
+
+		if _, err := t.service.VirtualMachineRestores().Stop(stopCtx, virtID, goslide.WithVirtualMachinePollInterval(t.pollInterval)); err != nil {
+			return fmt.Errorf("stopping virtual machine - %w", err)
+		}
+	}
+
+	return t.service.VirtualMachineRestores().Delete(ctx, virtID)
+}
+
+func newTeardownResult(kind, id string, err error) TeardownResult {
+	result := TeardownResult{
+		Kind:    kind,
+		ID:      id,
+		Removed: err == nil,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
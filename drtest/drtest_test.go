@@ -0,0 +1,210 @@
+package drtest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/drtest"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/google/go-cmp/cmp"
+)
+
+var screenshotPNG = []byte("\x89PNG\r\n\x1a\nscreenshot")
+
+// addSnapshots gives every agent of the fleet a snapshot that failed its boot
+// verification, and a later one with a boot screenshot.
+func addSnapshots(t *testing.T, server *fakeslide.Server) {
+	t.Helper()
+
+	screenshots := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(screenshotPNG)
+	}))
+	t.Cleanup(screenshots.Close)
+
+	backupEndedAt := time.Date(2024, time.August, 23, 1, 0, 0, 0, time.UTC)
+
+	for _, agent := range server.Agents {
+		local := []goslide.SnapshotLocation{{DeviceID: agent.DeviceID, Type: goslide.SnapshotLocationType_LOCAL}}
+
+		server.Snapshots = append(server.Snapshots,
+			goslide.Snapshot{
+				AgentID:          agent.AgentID,
+				SnapshotID:       "s_" + agent.AgentID + "_old",
+				BackupEndedAt:    backupEndedAt.Add(-24 * time.Hour),
+				Locations:        local,
+				VerifyBootStatus: goslide.SnapshotBootStatus_ERROR,
+				VerifyFSStatus:   goslide.SnapshotFSStatus_SUCCESS,
+			},
+			goslide.Snapshot{
+				AgentID:                 agent.AgentID,
+				SnapshotID:              "s_" + agent.AgentID,
+				BackupEndedAt:           backupEndedAt,
+				Locations:               local,
+				VerifyBootStatus:        goslide.SnapshotBootStatus_SUCCESS,
+				VerifyFSStatus:          goslide.SnapshotFSStatus_SUCCESS,
+				VerifyBootScreenshotURL: screenshots.URL + "/" + agent.AgentID + ".png",
+			},
+		)
+	}
+}
+
+func TestTester_Run(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addSnapshots(t, server)
+	dir := t.TempDir()
+
+	tester := drtest.NewTester(
+		server.Service(),
+		drtest.WithVirtualMachineBoot(time.Second),
+		drtest.WithPollInterval(time.Millisecond),
+		drtest.WithRandom(rand.New(rand.NewPCG(1, 2))),
+	)
+
+	report, err := tester.Run(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One agent is sampled from each of the two clients
+	if len(report.Results) != 2 {
+		t.Fatalf("%s expected 2 results, got %d", t.Name(), len(report.Results))
+	}
+
+	if report.Results[0].ClientID != "c_acme" || report.Results[1].ClientID != "c_globex" {
+		t.Errorf("%s expected one result per client, got %s and %s", t.Name(), report.Results[0].ClientID, report.Results[1].ClientID)
+	}
+
+	for _, result := range report.Results {
+		if !result.Passed {
+			t.Errorf("%s expected agent %s to pass, got error %q", t.Name(), result.AgentID, result.Error)
+		}
+
+		if result.SnapshotID != "s_"+result.AgentID {
+			t.Errorf("%s expected the latest snapshot of %s to be tested, got %s", t.Name(), result.AgentID, result.SnapshotID)
+		}
+
+		screenshot, err := os.ReadFile(filepath.Join(dir, result.Screenshot))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(screenshot, screenshotPNG) {
+			t.Errorf("%s unexpected screenshot contents for %s", t.Name(), result.AgentID)
+		}
+
+		if result.VirtualMachine == nil || result.VirtualMachine.State != goslide.VirtualMachineState_RUNNING {
+			t.Errorf("%s expected a running virtual machine for %s, got %+v", t.Name(), result.AgentID, result.VirtualMachine)
+		}
+	}
+
+	// Machines boot on isolated networks, and are stopped before they are deleted
+	if len(report.Teardown) != 2 {
+		t.Errorf("%s expected 2 virtual machines to be torn down, got %+v", t.Name(), report.Teardown)
+	}
+
+	for _, teardown := range report.Teardown {
+		// Machines are created running, so the only update stops them
+		updates := []string{}
+		for _, request := range server.Requests {
+			if strings.HasSuffix(request, "/v1/restore/virt/"+teardown.ID) && !strings.HasPrefix(request, "GET ") {
+				updates = append(updates, strings.Fields(request)[0])
+			}
+		}
+
+		if diff := cmp.Diff([]string{http.MethodPatch, http.MethodDelete}, updates); diff != "" {
+			t.Errorf("%s expected %s to be stopped before it is deleted (-want +got):\n%s", t.Name(), teardown.ID, diff)
+		}
+	}
+
+	if count := server.Count("POST /v1/network"); count != 0 {
+		t.Errorf("%s expected no network to be created, got %d", t.Name(), count)
+	}
+
+	if !report.Passed() {
+		t.Errorf("%s expected the report to pass", t.Name())
+	}
+
+	if len(server.Networks) != 0 || len(server.VirtualMachines) != 0 {
+		t.Errorf("%s expected no resources to remain, got %d networks and %d virtual machines", t.Name(), len(server.Networks), len(server.VirtualMachines))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := drtest.Report{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved.Results) != 2 || len(saved.Teardown) != 2 {
+		t.Errorf("%s report.json does not match the returned report", t.Name())
+	}
+
+	html, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range report.Results {
+		for _, expected := range []string{result.Hostname, result.SnapshotID, `src="` + result.Screenshot + `"`} {
+			if !strings.Contains(string(html), expected) {
+				t.Errorf("%s expected index.html to contain %q", t.Name(), expected)
+			}
+		}
+	}
+}
+
+func TestTester_Run_TeardownAfterFailure(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addSnapshots(t, server)
+	server.FailNext("POST /v1/restore/virt", 1)
+
+	tester := drtest.NewTester(
+		server.Service(),
+		drtest.WithClientIDs("c_acme"),
+		drtest.WithSampleSize(2),
+		drtest.WithVirtualMachineBoot(time.Second),
+		drtest.WithPollInterval(time.Millisecond),
+	)
+
+	report, err := tester.Run(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 2 {
+		t.Fatalf("%s expected 2 results, got %d", t.Name(), len(report.Results))
+	}
+
+	failed := 0
+	for _, result := range report.Results {
+		if !result.Passed {
+			failed++
+
+			if result.VirtualMachine == nil || !strings.Contains(result.VirtualMachine.Error, "creating virtual machine") {
+				t.Errorf("%s expected a virtual machine error, got %+v", t.Name(), result.VirtualMachine)
+			}
+		}
+	}
+
+	if failed != 1 || report.Passed() {
+		t.Errorf("%s expected exactly one failed agent, got %d", t.Name(), failed)
+	}
+
+	if len(server.Networks) != 0 || len(server.VirtualMachines) != 0 {
+		t.Errorf("%s expected no resources to remain, got %d networks and %d virtual machines", t.Name(), len(server.Networks), len(server.VirtualMachines))
+	}
+}
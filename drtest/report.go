@@ -0,0 +1,147 @@
+package drtest
+
+import (
+	"encoding/json"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	screenshotDir  = "screenshots"
+	reportJSONFile = "report.json"
+	reportHTMLFile = "index.html"
+)
+
+type Report struct {
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Results    []AgentResult    `json:"results"`
+	Teardown   []TeardownResult `json:"teardown"`
+}
+
+type AgentResult struct {
+	ClientID         string                     `json:"client_id"`
+	AgentID          string                     `json:"agent_id"`
+	Hostname         string                     `json:"hostname"`
+	TestedAt         time.Time                  `json:"tested_at"`
+	SnapshotID       string                     `json:"snapshot_id,omitempty"`
+	BackupEndedAt    time.Time                  `json:"backup_ended_at,omitempty"`
+	VerifyBootStatus goslide.SnapshotBootStatus `json:"verify_boot_status,omitempty"`
+	VerifyFSStatus   goslide.SnapshotFSStatus   `json:"verify_fs_status,omitempty"`
+	ScreenshotURL    string                     `json:"screenshot_url,omitempty"`
+
+	// Screenshot is the path of the downloaded screenshot, relative to the bundle
+	Screenshot     string                `json:"screenshot,omitempty"`
+	VirtualMachine *VirtualMachineResult `json:"virtual_machine,omitempty"`
+	Passed         bool                  `json:"passed"`
+	Error          string                `json:"error,omitempty"`
+}
+
+type VirtualMachineResult struct {
+	VirtID    string                      `json:"virt_id,omitempty"`
+	CreatedAt time.Time                   `json:"created_at,omitempty"`
+	RunningAt time.Time                   `json:"running_at,omitempty"`
+	State     goslide.VirtualMachineState `json:"state,omitempty"`
+	Error     string                      `json:"error,omitempty"`
+}
+
+type TeardownResult struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id"`
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Passed reports whether every tested agent passed and everything created was removed.
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+
+	for _, teardown := range r.Teardown {
+		if !teardown.Removed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r Report) write(dir string) error {
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, reportJSONFile), data, 0o644); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, reportHTMLFile))
+	if err != nil {
+		return err
+	}
+
+	if err := reportTemplate.Execute(file, r); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+
+		return t.UTC().Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Disaster recovery test {{timestamp .StartedAt}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.5em; text-align: left; vertical-align: top; }
+.passed { color: #1a7f37; }
+.failed { color: #cf222e; }
+img { max-width: 320px; }
+</style>
+</head>
+<body>
+<h1>Disaster recovery test</h1>
+<p>Started {{timestamp .StartedAt}}, finished {{timestamp .FinishedAt}}.
+{{if .Passed}}<strong class="passed">All agents passed.</strong>{{else}}<strong class="failed">One or more checks failed.</strong>{{end}}</p>
+<table>
+<tr><th>Client</th><th>Agent</th><th>Snapshot</th><th>Boot</th><th>Filesystem</th><th>Virtual machine</th><th>Result</th><th>Screenshot</th></tr>
+{{range .Results}}<tr>
+<td>{{.ClientID}}</td>
+<td>{{.Hostname}}<br><small>{{.AgentID}}</small><br><small>tested {{timestamp .TestedAt}}</small></td>
+<td>{{.SnapshotID}}<br><small>backup ended {{timestamp .BackupEndedAt}}</small></td>
+<td>{{.VerifyBootStatus}}</td>
+<td>{{.VerifyFSStatus}}</td>
+<td>{{with .VirtualMachine}}{{.VirtID}} {{.State}}<br><small>created {{timestamp .CreatedAt}}</small><br><small>running {{timestamp .RunningAt}}</small>{{with .Error}}<br><span class="failed">{{.}}</span>{{end}}{{else}}-{{end}}</td>
+<td>{{if .Passed}}<span class="passed">passed</span>{{else}}<span class="failed">failed</span>{{end}}{{with .Error}}<br>{{.}}{{end}}</td>
+<td>{{with .Screenshot}}<a href="{{.}}"><img src="{{.}}" alt="Boot verification screenshot"></a>{{else}}-{{end}}</td>
+</tr>
+{{end}}</table>
+<h2>Teardown</h2>
+<table>
+<tr><th>Resource</th><th>ID</th><th>Result</th></tr>
+{{range .Teardown}}<tr><td>{{.Kind}}</td><td>{{.ID}}</td><td>{{if .Removed}}<span class="passed">removed</span>{{else}}<span class="failed">{{.Error}}</span>{{end}}</td></tr>
+{{else}}<tr><td colspan="3">Nothing was created.</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -43,3 +43,48 @@ func (e *SlideError) Error() string {
 
 	return sb.String()
 }
+
+// ValidationError is returned before a request is sent when its payload is
+// invalid. It lists every invalid field, using the JSON field names.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("goslide payload validation error")
+
+	for i, field := range e.Fields {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString("; ")
+		}
+
+		sb.WriteString(field.Field + " " + field.Message)
+	}
+
+	return sb.String()
+}
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{
+		Field:   field,
+		Message: message,
+	})
+}
+
+// err returns nil when no fields were invalid.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,287 @@
+// Package exporter exposes the health of a Slide fleet as Prometheus
+// metrics. The API is polled in the background and scrapes are answered from
+// the last poll, so the scrape interval does not drive API usage.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultRefreshInterval = 5 * time.Minute
+
+// Exporter is a prometheus.Collector. Register it and call Run to keep the
+// cached fleet up to date.
+type Exporter struct {
+	service         goslide.Service
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu    sync.RWMutex
+	fleet *fleet
+
+	apiRequests      *prometheus.CounterVec
+	apiErrors        *prometheus.CounterVec
+	apiDuration      *prometheus.HistogramVec
+	refreshSuccess   prometheus.Gauge
+	lastRefresh      prometheus.Gauge
+	refreshDurations prometheus.Histogram
+}
+
+type exporterOption func(e *Exporter)
+
+// WithRefreshInterval sets how often the API is polled.
+func WithRefreshInterval(interval time.Duration) exporterOption {
+	return func(e *Exporter) {
+		e.refreshInterval = interval
+	}
+}
+
+func New(service goslide.Service, options ...exporterOption) *Exporter {
+	exporter := &Exporter{
+		service:         service,
+		refreshInterval: defaultRefreshInterval,
+		now:             time.Now,
+		apiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slide_exporter_api_requests_total",
+			Help: "List operations sent to the Slide API, by resource.",
+		}, []string{"resource"}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "slide_exporter_api_errors_total",
+			Help: "List operations against the Slide API that failed, by resource.",
+		}, []string{"resource"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "slide_exporter_api_request_duration_seconds",
+			Help:    "Time taken to list a resource from the Slide API, including every page.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		}, []string{"resource"}),
+		refreshSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "slide_exporter_last_refresh_success",
+			Help: "Whether the last poll of the Slide API succeeded.",
+		}),
+		lastRefresh: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "slide_exporter_last_refresh_timestamp_seconds",
+			Help: "Time of the last successful poll of the Slide API, which the other metrics reflect.",
+		}),
+		refreshDurations: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "slide_exporter_refresh_duration_seconds",
+			Help:    "Time taken to poll every resource from the Slide API.",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+		}),
+	}
+
+	for _, option := range options {
+		option(exporter)
+	}
+
+	return exporter
+}
+
+// Run refreshes the fleet until ctx is done. A failed refresh keeps serving
+// the previous fleet and is retried at the next interval, it shows up in
+// slide_exporter_last_refresh_success and the API error counters.
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		_ = e.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh polls the API once and replaces the cached fleet.
+func (e *Exporter) Refresh(ctx context.Context) error {
+	startedAt := e.now()
+
+	fleet, err := e.fetch(ctx)
+	e.refreshDurations.Observe(e.now().Sub(startedAt).Seconds())
+	if err != nil {
+		e.refreshSuccess.Set(0)
+
+		return err
+	}
+
+	e.mu.Lock()
+	e.fleet = fleet
+	e.mu.Unlock()
+
+	e.refreshSuccess.Set(1)
+	e.lastRefresh.Set(float64(fleet.refreshedAt.Unix()))
+
+	return nil
+}
+
+// fleet is what a refresh learned from the API.
+type fleet struct {
+	refreshedAt time.Time
+	clients     []goslide.Client
+	devices     []goslide.Device
+	agents      []goslide.Agent
+	openAlerts  []goslide.Alert
+
+	// lastBackups holds the latest successful backup of each agent
+	lastBackups map[string]goslide.Backup
+
+	// snapshotCounts counts the snapshots of each agent by location type
+	snapshotCounts map[string]map[goslide.SnapshotLocationType]int
+
+	// latestSnapshots holds the most recent snapshot of each agent
+	latestSnapshots map[string]goslide.Snapshot
+}
+
+func (e *Exporter) fetch(ctx context.Context) (*fleet, error) {
+	result := &fleet{
+		refreshedAt:     e.now(),
+		lastBackups:     map[string]goslide.Backup{},
+		snapshotCounts:  map[string]map[goslide.SnapshotLocationType]int{},
+		latestSnapshots: map[string]goslide.Snapshot{},
+	}
+
+	var err error
+
+	if result.clients, err = list(e, "client", func(pageHandler func(goslide.ListResponse[goslide.Client]) error) error {
+		return e.service.Clients().List(ctx, pageHandler)
+	}); err != nil {
+		return nil, err
+	}
+
+	if result.devices, err = list(e, "device", func(pageHandler func(goslide.ListResponse[goslide.Device]) error) error {
+		return e.service.Devices().List(ctx, pageHandler)
+	}); err != nil {
+		return nil, err
+	}
+
+	if result.agents, err = list(e, "agent", func(pageHandler func(goslide.ListResponse[goslide.Agent]) error) error {
+		return e.service.Agents().List(ctx, pageHandler)
+	}); err != nil {
+		return nil, err
+	}
+
+	if result.openAlerts, err = list(e, "alert", func(pageHandler func(goslide.ListResponse[goslide.Alert]) error) error {
+		return e.service.Alerts().ListWithQueryParameters(ctx, pageHandler, goslide.WithIncludeResolvedAlerts(false))
+	}); err != nil {
+		return nil, err
+	}
+
+	// Backups are listed newest first, so paging stops once every agent has
+	// its latest successful backup. Only agents that never succeeded page
+	// through their whole history.
+	pending := map[string]bool{}
+	for _, agent := range result.agents {
+		pending[agent.AgentID] = true
+	}
+
+	if err := listUntil(e, "backup", func(pageHandler func(goslide.ListResponse[goslide.Backup]) error) error {
+		return e.service.Backups().ListWithQueryParameters(ctx, pageHandler, goslide.WithSortBy("start_time"), goslide.WithSortDirection(false))
+	}, func(response goslide.ListResponse[goslide.Backup]) bool {
+		for _, backup := range response.Data {
+			if backup.Status != goslide.BackupStatus_SUCCEEDED {
+				continue
+			}
+
+			if latest, ok := result.lastBackups[backup.AgentID]; !ok || backup.EndedAt.After(latest.EndedAt) {
+				result.lastBackups[backup.AgentID] = backup
+			}
+			delete(pending, backup.AgentID)
+		}
+
+		return len(pending) == 0
+	}); err != nil {
+		return nil, err
+	}
+
+	// Each agent is asked for its newest snapshot at each location type, the
+	// total of that single record page is the count
+	for _, agent := range result.agents {
+		counts := map[goslide.SnapshotLocationType]int{}
+		result.snapshotCounts[agent.AgentID] = counts
+
+		for _, location := range []struct {
+			locationType goslide.SnapshotLocationType
+			filter       goslide.SnapshotLocationFilter
+		}{
+			{goslide.SnapshotLocationType_LOCAL, goslide.SnapshotLocationFilter_EXISTS_LOCAL},
+			{goslide.SnapshotLocationType_CLOUD, goslide.SnapshotLocationFilter_EXISTS_CLOUD},
+		} {
+			if err := listUntil(e, "snapshot", func(pageHandler func(goslide.ListResponse[goslide.Snapshot]) error) error {
+				return e.service.Snapshots().ListWithQueryParameters(ctx, pageHandler,
+					goslide.WithAgentID(agent.AgentID),
+					goslide.WithSnapshotLocationFilter(location.filter),
+					goslide.WithSortBy("backup_end_time"),
+					goslide.WithSortDirection(false),
+					goslide.WithLimit(1),
+				)
+			}, func(response goslide.ListResponse[goslide.Snapshot]) bool {
+				counts[location.locationType] = int(response.Pagination.Total)
+
+				for _, snapshot := range response.Data {
+					if latest, ok := result.latestSnapshots[agent.AgentID]; !ok || snapshot.BackupEndedAt.After(latest.BackupEndedAt) {
+						result.latestSnapshots[agent.AgentID] = snapshot
+					}
+				}
+
+				return true
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// errEnoughRecords stops paging once a resource was read far enough.
+var errEnoughRecords = errors.New("enough records")
+
+// list collects every page of a resource and records the request metrics.
+func list[T any](e *Exporter, resource string, listPages func(pageHandler func(response goslide.ListResponse[T]) error) error) ([]T, error) {
+	records := []T{}
+
+	if err := listUntil(e, resource, listPages, func(response goslide.ListResponse[T]) bool {
+		records = append(records, response.Data...)
+
+		return false
+	}); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// listUntil hands pages of a resource to pageHandler until it reports that
+// it has seen enough, and records the request metrics.
+func listUntil[T any](e *Exporter, resource string, listPages func(pageHandler func(response goslide.ListResponse[T]) error) error, pageHandler func(response goslide.ListResponse[T]) (done bool)) error {
+	startedAt := e.now()
+
+	err := listPages(func(response goslide.ListResponse[T]) error {
+		if pageHandler(response) {
+			return errEnoughRecords
+		}
+
+		return nil
+	})
+	if errors.Is(err, errEnoughRecords) {
+		err = nil
+	}
+
+	e.apiRequests.WithLabelValues(resource).Inc()
+	e.apiDuration.WithLabelValues(resource).Observe(e.now().Sub(startedAt).Seconds())
+	if err != nil {
+		e.apiErrors.WithLabelValues(resource).Inc()
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package exporter_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/exporter"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// addHealth gives Acme HQ and its agent a_dc storage, check-ins, backups,
+// snapshots and alerts.
+func addHealth(server *fakeslide.Server) {
+	now := time.Now()
+
+	device := &server.Devices[0]
+	device.HardwareModelName = "Z1"
+	device.StorageUsedBytes = 1000
+	device.StorageTotalBytes = 4000
+	device.LastSeenAt = now.Add(-time.Minute)
+
+	agent := &server.Agents[0]
+	agent.OS = "windows"
+	agent.AgentVersion = "1.2.3"
+	agent.LastSeenAt = now.Add(-2 * time.Minute)
+
+	server.Backups = []goslide.Backup{
+		{BackupID: "b_1", AgentID: "a_dc", Status: goslide.BackupStatus_SUCCEEDED, StartedAt: now.Add(-3 * time.Hour), EndedAt: now.Add(-2 * time.Hour)},
+		{BackupID: "b_2", AgentID: "a_dc", Status: goslide.BackupStatus_SUCCEEDED, StartedAt: now.Add(-70 * time.Minute), EndedAt: now.Add(-time.Hour)},
+		{BackupID: "b_3", AgentID: "a_dc", Status: goslide.BackupStatus_FAILED, StartedAt: now.Add(-10 * time.Minute), EndedAt: now.Add(-5 * time.Minute)},
+	}
+	server.Snapshots = []goslide.Snapshot{
+		{
+			SnapshotID:       "s_1",
+			AgentID:          "a_dc",
+			BackupEndedAt:    now.Add(-2 * time.Hour),
+			Locations:        []goslide.SnapshotLocation{{Type: goslide.SnapshotLocationType_LOCAL}, {Type: goslide.SnapshotLocationType_CLOUD}},
+			VerifyBootStatus: goslide.SnapshotBootStatus_ERROR,
+		},
+		{
+			SnapshotID:       "s_2",
+			AgentID:          "a_dc",
+			BackupEndedAt:    now.Add(-time.Hour),
+			Locations:        []goslide.SnapshotLocation{{Type: goslide.SnapshotLocationType_LOCAL}},
+			VerifyBootStatus: goslide.SnapshotBootStatus_SUCCESS,
+		},
+		{
+			SnapshotID: "s_3",
+			AgentID:    "a_dc",
+			Deleted:    &now,
+			Locations:  []goslide.SnapshotLocation{{Type: goslide.SnapshotLocationType_LOCAL}},
+		},
+	}
+	server.Alerts = []goslide.Alert{
+		{AlertID: "al_1", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, AgentID: "a_dc"},
+		{AlertID: "al_2", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, AgentID: "a_dc"},
+		{AlertID: "al_3", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme"},
+		{AlertID: "al_4", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme", Resolved: true},
+	}
+}
+
+func TestExporter(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addHealth(server)
+	slideExporter := exporter.New(server.Service())
+
+	if err := slideExporter.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP slide_agent_boot_verification_status Boot verification status of the latest snapshot of the agent, 1 for the current status.
+# TYPE slide_agent_boot_verification_status gauge
+slide_agent_boot_verification_status{agent="a_dc",client="c_acme",device="d_acme",status="error"} 0
+slide_agent_boot_verification_status{agent="a_dc",client="c_acme",device="d_acme",status="pending"} 0
+slide_agent_boot_verification_status{agent="a_dc",client="c_acme",device="d_acme",status="pending_due_to_disaster_vm"} 0
+slide_agent_boot_verification_status{agent="a_dc",client="c_acme",device="d_acme",status="skipped"} 0
+slide_agent_boot_verification_status{agent="a_dc",client="c_acme",device="d_acme",status="success"} 1
+slide_agent_boot_verification_status{agent="a_dc",client="c_acme",device="d_acme",status="warning"} 0
+# HELP slide_agent_info Names, operating system and version of an agent, always 1.
+# TYPE slide_agent_info gauge
+slide_agent_info{agent="a_app",client="c_acme",device="d_acme",hostname="acme-app",name="",os="",version=""} 1
+slide_agent_info{agent="a_dc",client="c_acme",device="d_acme",hostname="acme-dc",name="Acme DC01",os="windows",version="1.2.3"} 1
+slide_agent_info{agent="a_mail",client="c_globex",device="d_globex",hostname="globex-mail",name="",os="",version=""} 1
+# HELP slide_agent_last_successful_backup_duration_seconds Duration of the last successful backup of the agent.
+# TYPE slide_agent_last_successful_backup_duration_seconds gauge
+slide_agent_last_successful_backup_duration_seconds{agent="a_dc",client="c_acme",device="d_acme"} 600
+# HELP slide_agent_snapshots Snapshots of the agent kept at each location type.
+# TYPE slide_agent_snapshots gauge
+slide_agent_snapshots{agent="a_app",client="c_acme",device="d_acme",location="cloud"} 0
+slide_agent_snapshots{agent="a_app",client="c_acme",device="d_acme",location="local"} 0
+slide_agent_snapshots{agent="a_dc",client="c_acme",device="d_acme",location="cloud"} 1
+slide_agent_snapshots{agent="a_dc",client="c_acme",device="d_acme",location="local"} 2
+slide_agent_snapshots{agent="a_mail",client="c_globex",device="d_globex",location="cloud"} 0
+slide_agent_snapshots{agent="a_mail",client="c_globex",device="d_globex",location="local"} 0
+# HELP slide_alerts_open Unresolved alerts by type.
+# TYPE slide_alerts_open gauge
+slide_alerts_open{agent="",alert_type="device_not_checking_in",client="c_acme",device="d_acme"} 1
+slide_alerts_open{agent="a_dc",alert_type="agent_backup_failed",client="c_acme",device="d_acme"} 2
+# HELP slide_client_info Name of a client, always 1.
+# TYPE slide_client_info gauge
+slide_client_info{client="c_acme",name="Acme"} 1
+slide_client_info{client="c_globex",name="Globex"} 1
+# HELP slide_device_info Names and model of a device, always 1.
+# TYPE slide_device_info gauge
+slide_device_info{client="c_acme",device="d_acme",hostname="acme-slide",model="Z1",name="Acme HQ"} 1
+slide_device_info{client="c_acme",device="d_acme_branch",hostname="acme-branch",model="",name="Acme Branch"} 1
+slide_device_info{client="c_globex",device="d_globex",hostname="globex-slide",model="",name=""} 1
+# HELP slide_device_storage_total_bytes Storage capacity of the device.
+# TYPE slide_device_storage_total_bytes gauge
+slide_device_storage_total_bytes{client="c_acme",device="d_acme"} 4000
+slide_device_storage_total_bytes{client="c_acme",device="d_acme_branch"} 0
+slide_device_storage_total_bytes{client="c_globex",device="d_globex"} 0
+# HELP slide_device_storage_used_bytes Storage used on the device.
+# TYPE slide_device_storage_used_bytes gauge
+slide_device_storage_used_bytes{client="c_acme",device="d_acme"} 1000
+slide_device_storage_used_bytes{client="c_acme",device="d_acme_branch"} 0
+slide_device_storage_used_bytes{client="c_globex",device="d_globex"} 0
+# HELP slide_exporter_api_errors_total List operations against the Slide API that failed, by resource.
+# TYPE slide_exporter_api_errors_total counter
+# HELP slide_exporter_api_requests_total List operations sent to the Slide API, by resource.
+# TYPE slide_exporter_api_requests_total counter
+slide_exporter_api_requests_total{resource="agent"} 1
+slide_exporter_api_requests_total{resource="alert"} 1
+slide_exporter_api_requests_total{resource="backup"} 1
+slide_exporter_api_requests_total{resource="client"} 1
+slide_exporter_api_requests_total{resource="device"} 1
+slide_exporter_api_requests_total{resource="snapshot"} 6
+# HELP slide_exporter_last_refresh_success Whether the last poll of the Slide API succeeded.
+# TYPE slide_exporter_last_refresh_success gauge
+slide_exporter_last_refresh_success 1
+`
+
+	if err := testutil.CollectAndCompare(slideExporter, strings.NewReader(expected),
+		"slide_agent_boot_verification_status",
+		"slide_agent_info",
+		"slide_agent_last_successful_backup_duration_seconds",
+		"slide_agent_snapshots",
+		"slide_alerts_open",
+		"slide_client_info",
+		"slide_device_info",
+		"slide_device_storage_total_bytes",
+		"slide_device_storage_used_bytes",
+		"slide_exporter_api_errors_total",
+		"slide_exporter_api_requests_total",
+		"slide_exporter_last_refresh_success",
+	); err != nil {
+		t.Fatalf("%s %s", t.Name(), err)
+	}
+
+	// Ages are measured at scrape time
+	for name, minimum := range map[string]float64{
+		"slide_device_last_seen_age_seconds":             60,
+		"slide_agent_last_seen_age_seconds":              120,
+		"slide_agent_last_successful_backup_age_seconds": 3600,
+	} {
+		value := gaugeValue(t, slideExporter, name)
+		if value < minimum || value > minimum+60 {
+			t.Errorf("%s expected %s to be about %v, got %v", t.Name(), name, minimum, value)
+		}
+	}
+}
+
+func TestExporter_BackupPaging(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addHealth(server)
+	now := time.Now()
+
+	server.Backups = append(server.Backups,
+		goslide.Backup{BackupID: "b_4", AgentID: "a_app", Status: goslide.BackupStatus_SUCCEEDED, StartedAt: now.Add(-20 * time.Minute), EndedAt: now.Add(-15 * time.Minute)},
+		goslide.Backup{BackupID: "b_5", AgentID: "a_mail", Status: goslide.BackupStatus_SUCCEEDED, StartedAt: now.Add(-25 * time.Minute), EndedAt: now.Add(-20 * time.Minute)},
+	)
+	for day := 1; day <= 10; day++ {
+		server.Backups = append(server.Backups, goslide.Backup{
+			BackupID:  fmt.Sprintf("b_old_%d", day),
+			AgentID:   "a_dc",
+			Status:    goslide.BackupStatus_SUCCEEDED,
+			StartedAt: now.Add(-time.Duration(day) * 24 * time.Hour),
+			EndedAt:   now.Add(-time.Duration(day)*24*time.Hour + time.Hour),
+		})
+	}
+
+	slideExporter := exporter.New(server.Service())
+	if err := slideExporter.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// b_3, b_4 and b_5, b_2 hold the latest of every agent
+	if count := server.Count("GET /v1/backup"); count != 2 {
+		t.Errorf("%s expected paging to stop after 2 pages, got %d", t.Name(), count)
+	}
+
+	expected := `
+# HELP slide_agent_last_successful_backup_duration_seconds Duration of the last successful backup of the agent.
+# TYPE slide_agent_last_successful_backup_duration_seconds gauge
+slide_agent_last_successful_backup_duration_seconds{agent="a_app",client="c_acme",device="d_acme"} 300
+slide_agent_last_successful_backup_duration_seconds{agent="a_dc",client="c_acme",device="d_acme"} 600
+slide_agent_last_successful_backup_duration_seconds{agent="a_mail",client="c_globex",device="d_globex"} 300
+`
+
+	if err := testutil.CollectAndCompare(slideExporter, strings.NewReader(expected), "slide_agent_last_successful_backup_duration_seconds"); err != nil {
+		t.Fatalf("%s %s", t.Name(), err)
+	}
+}
+
+func TestExporter_RefreshError(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addHealth(server)
+	slideExporter := exporter.New(server.Service())
+
+	if err := slideExporter.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed refresh keeps the previous fleet
+	server.Devices[0].StorageUsedBytes = 2000
+	server.FailNext("GET /v1/snapshot", 1)
+
+	if err := slideExporter.Refresh(context.Background()); err == nil {
+		t.Fatalf("%s expected an error", t.Name())
+	}
+
+	expected := `
+# HELP slide_device_storage_used_bytes Storage used on the device.
+# TYPE slide_device_storage_used_bytes gauge
+slide_device_storage_used_bytes{client="c_acme",device="d_acme"} 1000
+slide_device_storage_used_bytes{client="c_acme",device="d_acme_branch"} 0
+slide_device_storage_used_bytes{client="c_globex",device="d_globex"} 0
+# HELP slide_exporter_api_errors_total List operations against the Slide API that failed, by resource.
+# TYPE slide_exporter_api_errors_total counter
+slide_exporter_api_errors_total{resource="snapshot"} 1
+# HELP slide_exporter_last_refresh_success Whether the last poll of the Slide API succeeded.
+# TYPE slide_exporter_last_refresh_success gauge
+slide_exporter_last_refresh_success 0
+`
+
+	if err := testutil.CollectAndCompare(slideExporter, strings.NewReader(expected),
+		"slide_device_storage_used_bytes",
+		"slide_exporter_api_errors_total",
+		"slide_exporter_last_refresh_success",
+	); err != nil {
+		t.Fatalf("%s %s", t.Name(), err)
+	}
+
+	if count := testutil.CollectAndCount(slideExporter, "slide_exporter_api_request_duration_seconds"); count != 6 {
+		t.Errorf("%s expected a latency histogram per resource, got %d", t.Name(), count)
+	}
+}
+
+func gaugeValue(t *testing.T, collector prometheus.Collector, name string) float64 {
+	t.Helper()
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() == name && len(family.GetMetric()) == 1 {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	t.Fatalf("%s expected a single %s series", t.Name(), name)
+
+	return 0
+}
@@ -0,0 +1,219 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	deviceLabels = []string{"client", "device"}
+	agentLabels  = []string{"client", "device", "agent"}
+
+	clientInfoDesc = prometheus.NewDesc(
+		"slide_client_info", "Name of a client, always 1.",
+		[]string{"client", "name"}, nil,
+	)
+	deviceInfoDesc = prometheus.NewDesc(
+		"slide_device_info", "Names and model of a device, always 1.",
+		append(deviceLabels, "name", "hostname", "model"), nil,
+	)
+	deviceStorageUsedDesc = prometheus.NewDesc(
+		"slide_device_storage_used_bytes", "Storage used on the device.",
+		deviceLabels, nil,
+	)
+	deviceStorageTotalDesc = prometheus.NewDesc(
+		"slide_device_storage_total_bytes", "Storage capacity of the device.",
+		deviceLabels, nil,
+	)
+	deviceLastSeenDesc = prometheus.NewDesc(
+		"slide_device_last_seen_age_seconds", "Seconds since the device last checked in.",
+		deviceLabels, nil,
+	)
+	agentInfoDesc = prometheus.NewDesc(
+		"slide_agent_info", "Names, operating system and version of an agent, always 1.",
+		append(agentLabels, "name", "hostname", "os", "version"), nil,
+	)
+	agentLastSeenDesc = prometheus.NewDesc(
+		"slide_agent_last_seen_age_seconds", "Seconds since the agent last checked in.",
+		agentLabels, nil,
+	)
+	agentLastBackupAgeDesc = prometheus.NewDesc(
+		"slide_agent_last_successful_backup_age_seconds", "Seconds since the last successful backup of the agent ended.",
+		agentLabels, nil,
+	)
+	agentLastBackupDurationDesc = prometheus.NewDesc(
+		"slide_agent_last_successful_backup_duration_seconds", "Duration of the last successful backup of the agent.",
+		agentLabels, nil,
+	)
+	agentSnapshotsDesc = prometheus.NewDesc(
+		"slide_agent_snapshots", "Snapshots of the agent kept at each location type.",
+		append(agentLabels, "location"), nil,
+	)
+	agentBootVerificationDesc = prometheus.NewDesc(
+		"slide_agent_boot_verification_status", "Boot verification status of the latest snapshot of the agent, 1 for the current status.",
+		append(agentLabels, "status"), nil,
+	)
+	openAlertsDesc = prometheus.NewDesc(
+		"slide_alerts_open", "Unresolved alerts by type.",
+		append(agentLabels, "alert_type"), nil,
+	)
+)
+
+// bootStatuses are reported as a state set, so every agent has a series for
+// each status.
+var bootStatuses = []goslide.SnapshotBootStatus{
+	goslide.SnapshotBootStatus_SUCCESS,
+	goslide.SnapshotBootStatus_WARNING,
+	goslide.SnapshotBootStatus_ERROR,
+	goslide.SnapshotBootStatus_SKIPPED,
+	goslide.SnapshotBootStatus_PENDING,
+	goslide.SnapshotBootStatus_PENDING_DUE_TO_DISASTER_VM,
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		clientInfoDesc,
+		deviceInfoDesc,
+		deviceStorageUsedDesc,
+		deviceStorageTotalDesc,
+		deviceLastSeenDesc,
+		agentInfoDesc,
+		agentLastSeenDesc,
+		agentLastBackupAgeDesc,
+		agentLastBackupDurationDesc,
+		agentSnapshotsDesc,
+		agentBootVerificationDesc,
+		openAlertsDesc,
+	} {
+		ch <- desc
+	}
+
+	e.collectors(func(collector prometheus.Collector) {
+		collector.Describe(ch)
+	})
+}
+
+// Collect reports the cached fleet. Ages are measured at scrape time, so they
+// keep growing between refreshes.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.collectors(func(collector prometheus.Collector) {
+		collector.Collect(ch)
+	})
+
+	e.mu.RLock()
+	fleet := e.fleet
+	e.mu.RUnlock()
+
+	// Nothing is reported before the first successful refresh
+	if fleet == nil {
+		return
+	}
+
+	now := e.now()
+
+	for _, client := range fleet.clients {
+		ch <- prometheus.MustNewConstMetric(clientInfoDesc, prometheus.GaugeValue, 1, client.ClientID, client.Name)
+	}
+
+	for _, device := range fleet.devices {
+		labels := []string{device.ClientID, device.DeviceID}
+
+		ch <- prometheus.MustNewConstMetric(deviceInfoDesc, prometheus.GaugeValue, 1, append(labels, device.DisplayName, device.Hostname, device.HardwareModelName)...)
+		ch <- prometheus.MustNewConstMetric(deviceStorageUsedDesc, prometheus.GaugeValue, float64(device.StorageUsedBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(deviceStorageTotalDesc, prometheus.GaugeValue, float64(device.StorageTotalBytes), labels...)
+
+		if !device.LastSeenAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(deviceLastSeenDesc, prometheus.GaugeValue, age(now, device.LastSeenAt), labels...)
+		}
+	}
+
+	agents := map[string]goslide.Agent{}
+	for _, agent := range fleet.agents {
+		agents[agent.AgentID] = agent
+		labels := []string{agent.ClientID, agent.DeviceID, agent.AgentID}
+
+		ch <- prometheus.MustNewConstMetric(agentInfoDesc, prometheus.GaugeValue, 1, append(labels, agent.DisplayName, agent.Hostname, agent.OS, agent.AgentVersion)...)
+
+		if !agent.LastSeenAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(agentLastSeenDesc, prometheus.GaugeValue, age(now, agent.LastSeenAt), labels...)
+		}
+
+		if backup, ok := fleet.lastBackups[agent.AgentID]; ok {
+			ch <- prometheus.MustNewConstMetric(agentLastBackupAgeDesc, prometheus.GaugeValue, age(now, backup.EndedAt), labels...)
+			ch <- prometheus.MustNewConstMetric(agentLastBackupDurationDesc, prometheus.GaugeValue, backup.EndedAt.Sub(backup.StartedAt).Seconds(), labels...)
+		}
+
+		for _, location := range []goslide.SnapshotLocationType{goslide.SnapshotLocationType_LOCAL, goslide.SnapshotLocationType_CLOUD} {
+			count := fleet.snapshotCounts[agent.AgentID][location]
+			ch <- prometheus.MustNewConstMetric(agentSnapshotsDesc, prometheus.GaugeValue, float64(count), append(labels, string(location))...)
+		}
+
+		if snapshot, ok := fleet.latestSnapshots[agent.AgentID]; ok {
+			for _, status := range bootStatuses {
+				value := 0.0
+				if snapshot.VerifyBootStatus == status {
+					value = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(agentBootVerificationDesc, prometheus.GaugeValue, value, append(labels, string(status))...)
+			}
+		}
+	}
+
+	devices := map[string]goslide.Device{}
+	for _, device := range fleet.devices {
+		devices[device.DeviceID] = device
+	}
+
+	type alertKey struct {
+		clientID  string
+		deviceID  string
+		agentID   string
+		alertType goslide.AlertType
+	}
+
+	openAlerts := map[alertKey]int{}
+	for _, alert := range fleet.openAlerts {
+		// The list asks for unresolved alerts only, this keeps a resolved one
+		// returned anyway out of the open count
+		if alert.Resolved {
+			continue
+		}
+
+		// Alerts do not carry a client, it comes from the device or agent
+		key := alertKey{deviceID: alert.DeviceID, agentID: alert.AgentID, alertType: alert.AlertType}
+		if agent, ok := agents[alert.AgentID]; ok {
+			key.clientID = agent.ClientID
+			if key.deviceID == "" {
+				key.deviceID = agent.DeviceID
+			}
+		} else if device, ok := devices[alert.DeviceID]; ok {
+			key.clientID = device.ClientID
+		}
+
+		openAlerts[key]++
+	}
+
+	for key, count := range openAlerts {
+		ch <- prometheus.MustNewConstMetric(openAlertsDesc, prometheus.GaugeValue, float64(count), key.clientID, key.deviceID, key.agentID, string(key.alertType))
+	}
+}
+
+func (e *Exporter) collectors(f func(collector prometheus.Collector)) {
+	for _, collector := range []prometheus.Collector{
+		e.apiRequests,
+		e.apiErrors,
+		e.apiDuration,
+		e.refreshSuccess,
+		e.lastRefresh,
+		e.refreshDurations,
+	} {
+		f(collector)
+	}
+}
+
+func age(now, t time.Time) float64 {
+	return max(now.Sub(t).Seconds(), 0)
+}
@@ -0,0 +1,31 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeAgents() {
+	s.mux.HandleFunc("GET /v1/agent", func(w http.ResponseWriter, r *http.Request) {
+		deviceID := queryValue(r, "device_id")
+
+		writeList(s, w, r, s.Agents, func(agent goslide.Agent) bool {
+			return deviceID == "" || agent.DeviceID == deviceID
+		})
+	})
+
+	s.mux.HandleFunc("GET /v1/agent/{agent_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.Agents, func(agent goslide.Agent) bool {
+			return agent.AgentID == r.PathValue("agent_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Agents[index])
+	})
+}
@@ -0,0 +1,73 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeAlerts() {
+	s.mux.HandleFunc("GET /v1/alert", func(w http.ResponseWriter, r *http.Request) {
+		agentID := queryValue(r, "agent_id")
+		deviceID := queryValue(r, "device_id")
+		includeResolved := queryValue(r, "resolved") == "true"
+
+		alerts := sortList(r, s.Alerts, map[string]func(goslide.Alert) time.Time{
+			"created": func(alert goslide.Alert) time.Time { return alert.CreatedAt },
+		})
+
+		writeList(s, w, r, alerts, func(alert goslide.Alert) bool {
+			return (agentID == "" || alert.AgentID == agentID) &&
+				(deviceID == "" || alert.DeviceID == deviceID) &&
+				(includeResolved || !alert.Resolved)
+		})
+	})
+
+	s.mux.HandleFunc("GET /v1/alert/{alert_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := s.alertIndex(r.PathValue("alert_id"))
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Alerts[index])
+	})
+
+	s.mux.HandleFunc("PATCH /v1/alert/{alert_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := s.alertIndex(r.PathValue("alert_id"))
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		payload := struct {
+			Resolved bool `json:"resolved"`
+		}{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		alert := &s.Alerts[index]
+		alert.Resolved = payload.Resolved
+		alert.ResolvedAt = nil
+		alert.ResolvedBy = ""
+
+		if payload.Resolved {
+			resolvedAt := s.Now().UTC()
+			alert.ResolvedAt = &resolvedAt
+			alert.ResolvedBy = "API"
+		}
+
+		writeJSON(w, http.StatusOK, *alert)
+	})
+}
+
+func (s *Server) alertIndex(alertID string) int {
+	return slices.IndexFunc(s.Alerts, func(alert goslide.Alert) bool {
+		return alert.AlertID == alertID
+	})
+}
@@ -0,0 +1,63 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeBackups() {
+	s.mux.HandleFunc("GET /v1/backup", func(w http.ResponseWriter, r *http.Request) {
+		agentID := queryValue(r, "agent_id")
+
+		backups := sortList(r, s.Backups, map[string]func(goslide.Backup) time.Time{
+			"start_time": func(backup goslide.Backup) time.Time { return backup.StartedAt },
+		})
+
+		writeList(s, w, r, backups, func(backup goslide.Backup) bool {
+			return agentID == "" || backup.AgentID == agentID
+		})
+	})
+
+	s.mux.HandleFunc("POST /v1/backup", func(w http.ResponseWriter, r *http.Request) {
+		payload := struct {
+			AgentID string `json:"agent_id"`
+		}{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		if !slices.ContainsFunc(s.Agents, func(agent goslide.Agent) bool {
+			return agent.AgentID == payload.AgentID
+		}) {
+			writeNotFound(w)
+
+			return
+		}
+
+		backup := goslide.Backup{
+			AgentID:   payload.AgentID,
+			BackupID:  s.id("b"),
+			StartedAt: s.Now().UTC(),
+			Status:    goslide.BackupStatus_PENDING,
+		}
+
+		s.Backups = append(s.Backups, backup)
+		writeJSON(w, http.StatusAccepted, backup)
+	})
+
+	s.mux.HandleFunc("GET /v1/backup/{backup_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.Backups, func(backup goslide.Backup) bool {
+			return backup.BackupID == r.PathValue("backup_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Backups[index])
+	})
+}
@@ -0,0 +1,27 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeClients() {
+	s.mux.HandleFunc("GET /v1/client", func(w http.ResponseWriter, r *http.Request) {
+		writeList(s, w, r, s.Clients, nil)
+	})
+
+	s.mux.HandleFunc("GET /v1/client/{client_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.Clients, func(client goslide.Client) bool {
+			return client.ClientID == r.PathValue("client_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Clients[index])
+	})
+}
@@ -0,0 +1,31 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeDevices() {
+	s.mux.HandleFunc("GET /v1/device", func(w http.ResponseWriter, r *http.Request) {
+		clientID := queryValue(r, "client_id")
+
+		writeList(s, w, r, s.Devices, func(device goslide.Device) bool {
+			return clientID == "" || device.ClientID == clientID
+		})
+	})
+
+	s.mux.HandleFunc("GET /v1/device/{device_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.Devices, func(device goslide.Device) bool {
+			return device.DeviceID == r.PathValue("device_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Devices[index])
+	})
+}
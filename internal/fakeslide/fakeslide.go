@@ -0,0 +1,227 @@
+// Package fakeslide is an in-memory stand-in for the Slide API, used by tests
+// that drive many endpoints through a goslide.Service.
+package fakeslide
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+)
+
+type Server struct {
+	mu  sync.Mutex
+	mux *http.ServeMux
+
+	Agents          []goslide.Agent
+	Alerts          []goslide.Alert
+	Backups         []goslide.Backup
+	Clients         []goslide.Client
+	Devices         []goslide.Device
+	Networks        []goslide.Network
+	Snapshots       []goslide.Snapshot
+	VirtualMachines []goslide.VirtualMachineRestore
+	FileRestores    []goslide.FileRestore
+	ImageExports    []goslide.ImageExportRestore
+	Users           []goslide.User
+
+	// Files are the browse results of every file restore, by directory path
+	// with "" for the root
+	Files map[string][]goslide.FileRestoreData
+
+	// Token, when set, is the only API token accepted
+	Token string
+
+	// Requests logs every request as "METHOD /path"
+	Requests []string
+
+	// PageSize is the number of records returned per list page
+	PageSize int
+
+	// Now is used for timestamps of created resources
+	Now func() time.Time
+
+	failures map[string]int
+	nextID   int
+}
+
+func New() *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		PageSize: 50,
+		Files:    map[string][]goslide.FileRestoreData{},
+		Now:      time.Now,
+		failures: map[string]int{},
+	}
+
+	s.routeAgents()
+	s.routeAlerts()
+	s.routeBackups()
+	s.routeClients()
+	s.routeDevices()
+	s.routeNetworks()
+	s.routeRestores()
+	s.routeSnapshots()
+	s.routeUsers()
+	s.routeVirtualMachines()
+
+	return s
+}
+
+// Service returns a goslide.Service whose requests are all served by s.
+func (s *Server) Service() goslide.Service {
+	return goslide.NewService("fakeToken", goslide.WithCustomRoundtripper(roundtripper.HandlerNetwork(s)))
+}
+
+// FailNext makes the next count requests matching the route pattern, such as
+// "POST /v1/restore/virt", fail with an internal server error.
+func (s *Server) FailNext(pattern string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[pattern] = count
+}
+
+// Count returns the number of logged requests matching "METHOD /path".
+func (s *Server) Count(request string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, logged := range s.Requests {
+		if logged == request {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests = append(s.Requests, r.Method+" "+r.URL.Path)
+
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, goslide.APIErrorCode_ERR_UNAUTHORIZED)
+
+		return
+	}
+
+	_, pattern := s.mux.Handler(r)
+	if s.failures[pattern] > 0 {
+		s.failures[pattern]--
+		writeError(w, http.StatusInternalServerError, goslide.APIErrorCode_ERR_INTERNAL_SERVER_ERROR)
+
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) id(prefix string) string {
+	s.nextID++
+
+	return fmt.Sprintf("%s_%012d", prefix, s.nextID)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code goslide.APIErrorCode) {
+	writeJSON(w, statusCode, map[string]any{
+		"codes":   []goslide.APIErrorCode{code},
+		"details": []string{},
+		"message": string(code),
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, goslide.APIErrorCode_ERR_ENTITY_NOT_FOUND)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, target any) bool {
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		writeError(w, http.StatusBadRequest, goslide.APIErrorCode_ERR_VALIDATION_ERROR)
+
+		return false
+	}
+
+	return true
+}
+
+// writeList filters and paginates records the way the Slide API list endpoints do.
+func writeList[Record any](s *Server, w http.ResponseWriter, r *http.Request, records []Record, keep func(Record) bool) {
+	filtered := []Record{}
+	for _, record := range records {
+		if keep == nil || keep(record) {
+			filtered = append(filtered, record)
+		}
+	}
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = s.PageSize
+	}
+
+	offset = min(offset, len(filtered))
+	end := min(offset+limit, len(filtered))
+
+	response := goslide.ListResponse[Record]{
+		Pagination: goslide.OffsetPagination{
+			Total: uint(len(filtered)),
+		},
+		Data: filtered[offset:end],
+	}
+
+	if end < len(filtered) {
+		next := uint(end)
+		response.Pagination.NextOffset = &next
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// sortList orders records by the time field named in sort_by, newest first
+// unless sort_asc is true. Other fields keep the stored order.
+func sortList[Record any](r *http.Request, records []Record, sortFields map[string]func(Record) time.Time) []Record {
+	field, ok := sortFields[queryValue(r, "sort_by")]
+	if !ok {
+		return records
+	}
+
+	ascending := queryValue(r, "sort_asc") == "true"
+	sorted := slices.Clone(records)
+	slices.SortStableFunc(sorted, func(a, b Record) int {
+		if ascending {
+			return field(a).Compare(field(b))
+		}
+
+		return field(b).Compare(field(a))
+	})
+
+	return sorted
+}
+
+// queryValue returns a query parameter, undoing the extra escaping applied
+// by the goslide query parameter options.
+func queryValue(r *http.Request, key string) string {
+	value := r.URL.Query().Get(key)
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		return unescaped
+	}
+
+	return value
+}
@@ -0,0 +1,35 @@
+package fakeslide
+
+import "github.com/equalsgibson/goslide"
+
+// NewFleet returns a server with the fleet shared by the package tests:
+//
+//   - client c_acme "Acme", with device d_acme "Acme HQ" (acme-slide) running
+//     the agents a_dc "Acme DC01" (acme-dc) and a_app (acme-app), and device
+//     d_acme_branch "Acme Branch" (acme-branch) without agents
+//   - client c_globex "Globex", with device d_globex (globex-slide) and the
+//     agent a_mail (globex-mail)
+//
+// Lists are served two records per page, so every list crosses a page
+// boundary. Tests add the alerts, backups, snapshots, restores and networks
+// they need.
+func NewFleet() *Server {
+	s := New()
+	s.PageSize = 2
+	s.Clients = []goslide.Client{
+		{ClientID: "c_acme", Name: "Acme"},
+		{ClientID: "c_globex", Name: "Globex"},
+	}
+	s.Devices = []goslide.Device{
+		{DeviceID: "d_acme", ClientID: "c_acme", DisplayName: "Acme HQ", Hostname: "acme-slide"},
+		{DeviceID: "d_acme_branch", ClientID: "c_acme", DisplayName: "Acme Branch", Hostname: "acme-branch"},
+		{DeviceID: "d_globex", ClientID: "c_globex", Hostname: "globex-slide"},
+	}
+	s.Agents = []goslide.Agent{
+		{AgentID: "a_dc", DeviceID: "d_acme", ClientID: "c_acme", DisplayName: "Acme DC01", Hostname: "acme-dc"},
+		{AgentID: "a_app", DeviceID: "d_acme", ClientID: "c_acme", Hostname: "acme-app"},
+		{AgentID: "a_mail", DeviceID: "d_globex", ClientID: "c_globex", Hostname: "globex-mail"},
+	}
+
+	return s
+}
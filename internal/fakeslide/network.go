@@ -0,0 +1,263 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeNetworks() {
+	s.mux.HandleFunc("GET /v1/network", func(w http.ResponseWriter, r *http.Request) {
+		clientID := queryValue(r, "client_id")
+
+		writeList(s, w, r, s.Networks, func(network goslide.Network) bool {
+			return clientID == "" || network.ClientID == clientID
+		})
+	})
+
+	s.mux.HandleFunc("POST /v1/network", func(w http.ResponseWriter, r *http.Request) {
+		payload := goslide.NetworkCreatePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		network := goslide.Network{
+			BridgeDeviceID:   payload.BridgeDeviceID,
+			ClientID:         payload.ClientID,
+			Comments:         payload.Comments,
+			ConnectedVirtIDs: []string{},
+			DHCP:             payload.DHCP != nil && *payload.DHCP,
+			DHCPRangeEnd:     payload.DHCPRangeEnd,
+			DHCPRangeStart:   payload.DHCPRangeStart,
+			Internet:         payload.Internet != nil && *payload.Internet,
+			Name:             payload.Name,
+			Nameservers:      payload.Nameservers,
+			NetworkID:        s.id("net"),
+			PortForwards:     []goslide.NetworkPortForward{},
+			RouterPrefix:     payload.RouterPrefix,
+			Type:             payload.Type,
+			WGPeers:          []goslide.NetworkWGPeer{},
+		}
+
+		s.Networks = append(s.Networks, network)
+		writeJSON(w, http.StatusCreated, network)
+	})
+
+	s.mux.HandleFunc("GET /v1/network/{network_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := s.networkIndex(r.PathValue("network_id"))
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Networks[index])
+	})
+
+	s.mux.HandleFunc("PATCH /v1/network/{network_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := s.networkIndex(r.PathValue("network_id"))
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		// Decode on top of the stored network, so only the fields sent are changed
+		network := s.Networks[index]
+		if !decode(w, r, &network) {
+			return
+		}
+
+		s.Networks[index] = network
+		writeJSON(w, http.StatusOK, network)
+	})
+
+	s.mux.HandleFunc("DELETE /v1/network/{network_id}", func(w http.ResponseWriter, r *http.Request) {
+		networkID := r.PathValue("network_id")
+
+		index := s.networkIndex(networkID)
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		s.Networks = slices.Delete(s.Networks, index, index+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.mux.HandleFunc("POST /v1/network/{network_id}/port-forwards", func(w http.ResponseWriter, r *http.Request) {
+		network, ok := s.network(w, r)
+		if !ok {
+			return
+		}
+
+		payload := goslide.NetworkPortForwardPayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		portForward := goslide.NetworkPortForward{
+			Dest:      payload.Dest,
+			NetworkID: network.NetworkID,
+			Port:      uint(40000 + len(network.PortForwards)),
+			Proto:     payload.Proto,
+		}
+
+		network.PortForwards = append(network.PortForwards, portForward)
+		writeJSON(w, http.StatusCreated, portForward)
+	})
+
+	s.mux.HandleFunc("PATCH /v1/network/{network_id}/port-forwards", func(w http.ResponseWriter, r *http.Request) {
+		network, ok := s.network(w, r)
+		if !ok {
+			return
+		}
+
+		payload := goslide.NetworkPortForwardUpdatePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		index := slices.IndexFunc(network.PortForwards, func(portForward goslide.NetworkPortForward) bool {
+			return portForward.Port == payload.Port && portForward.Proto == payload.ProtoOld
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		network.PortForwards[index].Dest = payload.Dest
+		network.PortForwards[index].Proto = payload.ProtoNew
+		writeJSON(w, http.StatusOK, network.PortForwards[index])
+	})
+
+	s.mux.HandleFunc("DELETE /v1/network/{network_id}/port-forwards", func(w http.ResponseWriter, r *http.Request) {
+		network, ok := s.network(w, r)
+		if !ok {
+			return
+		}
+
+		payload := goslide.NetworkPortForwardDeletePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		index := slices.IndexFunc(network.PortForwards, func(portForward goslide.NetworkPortForward) bool {
+			return portForward.Port == payload.Port && portForward.Proto == payload.Proto
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		network.PortForwards = slices.Delete(network.PortForwards, index, index+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.mux.HandleFunc("POST /v1/network/{network_id}/wg-peers", func(w http.ResponseWriter, r *http.Request) {
+		network, ok := s.network(w, r)
+		if !ok {
+			return
+		}
+
+		payload := goslide.NetworkWGPeerCreatePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		peer := goslide.NetworkWGPeer{
+			NetworkID:      network.NetworkID,
+			PeerName:       payload.PeerName,
+			RemoteNetworks: payload.RemoteNetworks,
+			WGAddress:      "10.99.0." + strconv.Itoa(2+len(network.WGPeers)),
+			WGPrivateKey:   "private-" + payload.PeerName,
+			WGPublicKey:    "public-" + payload.PeerName,
+		}
+
+		network.WGPeers = append(network.WGPeers, peer)
+		writeJSON(w, http.StatusCreated, peer)
+	})
+
+	s.mux.HandleFunc("PATCH /v1/network/{network_id}/wg-peers", func(w http.ResponseWriter, r *http.Request) {
+		network, ok := s.network(w, r)
+		if !ok {
+			return
+		}
+
+		payload := goslide.NetworkWGPeerUpdatePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		index := s.wgPeerIndex(network, payload.WGAddress)
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		if payload.PeerName != "" {
+			network.WGPeers[index].PeerName = payload.PeerName
+		}
+
+		if payload.RemoteNetworks != nil {
+			network.WGPeers[index].RemoteNetworks = payload.RemoteNetworks
+		}
+
+		writeJSON(w, http.StatusOK, network.WGPeers[index])
+	})
+
+	s.mux.HandleFunc("DELETE /v1/network/{network_id}/wg-peers", func(w http.ResponseWriter, r *http.Request) {
+		network, ok := s.network(w, r)
+		if !ok {
+			return
+		}
+
+		payload := struct {
+			WGAddress string `json:"wg_address"`
+		}{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		index := s.wgPeerIndex(network, payload.WGAddress)
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		network.WGPeers = slices.Delete(network.WGPeers, index, index+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// network returns the stored network named by the request path, writing a not
+// found response if there is none.
+func (s *Server) network(w http.ResponseWriter, r *http.Request) (*goslide.Network, bool) {
+	index := s.networkIndex(r.PathValue("network_id"))
+	if index < 0 {
+		writeNotFound(w)
+
+		return nil, false
+	}
+
+	return &s.Networks[index], true
+}
+
+func (s *Server) wgPeerIndex(network *goslide.Network, wgAddress string) int {
+	return slices.IndexFunc(network.WGPeers, func(peer goslide.NetworkWGPeer) bool {
+		return peer.WGAddress == wgAddress
+	})
+}
+
+func (s *Server) networkIndex(networkID string) int {
+	return slices.IndexFunc(s.Networks, func(network goslide.Network) bool {
+		return network.NetworkID == networkID
+	})
+}
@@ -0,0 +1,108 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeRestores() {
+	s.mux.HandleFunc("GET /v1/restore/file", func(w http.ResponseWriter, r *http.Request) {
+		writeList(s, w, r, s.FileRestores, nil)
+	})
+
+	s.mux.HandleFunc("POST /v1/restore/file", func(w http.ResponseWriter, r *http.Request) {
+		payload := goslide.FileRestorePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		index := slices.IndexFunc(s.Snapshots, func(snapshot goslide.Snapshot) bool {
+			return snapshot.SnapshotID == payload.SnapshotID
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		now := s.Now().UTC()
+		restore := goslide.FileRestore{
+			AgentID:       s.Snapshots[index].AgentID,
+			CreatedAt:     now,
+			DeviceID:      payload.DeviceID,
+			ExpiresAt:     now.Add(7 * 24 * time.Hour),
+			FileRestoreID: s.id("fr"),
+			SnapshotID:    payload.SnapshotID,
+		}
+
+		s.FileRestores = append(s.FileRestores, restore)
+		writeJSON(w, http.StatusCreated, restore)
+	})
+
+	s.mux.HandleFunc("GET /v1/restore/file/{file_restore_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.FileRestores, func(restore goslide.FileRestore) bool {
+			return restore.FileRestoreID == r.PathValue("file_restore_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.FileRestores[index])
+	})
+
+	s.mux.HandleFunc("GET /v1/restore/file/{file_restore_id}/browse", func(w http.ResponseWriter, r *http.Request) {
+		if !slices.ContainsFunc(s.FileRestores, func(restore goslide.FileRestore) bool {
+			return restore.FileRestoreID == r.PathValue("file_restore_id")
+		}) {
+			writeNotFound(w)
+
+			return
+		}
+
+		files, ok := s.Files[queryValue(r, "path")]
+		if !ok {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeList(s, w, r, files, nil)
+	})
+
+	s.mux.HandleFunc("DELETE /v1/restore/file/{file_restore_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.FileRestores, func(restore goslide.FileRestore) bool {
+			return restore.FileRestoreID == r.PathValue("file_restore_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		s.FileRestores = slices.Delete(s.FileRestores, index, index+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.mux.HandleFunc("GET /v1/restore/image", func(w http.ResponseWriter, r *http.Request) {
+		writeList(s, w, r, s.ImageExports, nil)
+	})
+
+	s.mux.HandleFunc("DELETE /v1/restore/image/{image_export_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.ImageExports, func(restore goslide.ImageExportRestore) bool {
+			return restore.ImageExportID == r.PathValue("image_export_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		s.ImageExports = slices.Delete(s.ImageExports, index, index+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,57 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeSnapshots() {
+	s.mux.HandleFunc("GET /v1/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		agentID := queryValue(r, "agent_id")
+		location := goslide.SnapshotLocationFilter(queryValue(r, "snapshot_location"))
+
+		snapshots := sortList(r, s.Snapshots, map[string]func(goslide.Snapshot) time.Time{
+			"backup_start_time": func(snapshot goslide.Snapshot) time.Time { return snapshot.BackupStartedAt },
+			"backup_end_time":   func(snapshot goslide.Snapshot) time.Time { return snapshot.BackupEndedAt },
+		})
+
+		writeList(s, w, r, snapshots, func(snapshot goslide.Snapshot) bool {
+			if agentID != "" && snapshot.AgentID != agentID {
+				return false
+			}
+
+			switch location {
+			case goslide.SnapshotLocationFilter_EXISTS_LOCAL:
+				return snapshot.Deleted == nil && hasLocation(snapshot, goslide.SnapshotLocationType_LOCAL)
+			case goslide.SnapshotLocationFilter_EXISTS_CLOUD:
+				return snapshot.Deleted == nil && hasLocation(snapshot, goslide.SnapshotLocationType_CLOUD)
+			case goslide.SnapshotLocationFilter_EXISTS_DELETED:
+				return snapshot.Deleted != nil
+			default:
+				return true
+			}
+		})
+	})
+
+	s.mux.HandleFunc("GET /v1/snapshot/{snapshot_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.Snapshots, func(snapshot goslide.Snapshot) bool {
+			return snapshot.SnapshotID == r.PathValue("snapshot_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Snapshots[index])
+	})
+}
+
+func hasLocation(snapshot goslide.Snapshot, locationType goslide.SnapshotLocationType) bool {
+	return slices.ContainsFunc(snapshot.Locations, func(location goslide.SnapshotLocation) bool {
+		return location.Type == locationType
+	})
+}
@@ -0,0 +1,27 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeUsers() {
+	s.mux.HandleFunc("GET /v1/user", func(w http.ResponseWriter, r *http.Request) {
+		writeList(s, w, r, s.Users, nil)
+	})
+
+	s.mux.HandleFunc("GET /v1/user/{user_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := slices.IndexFunc(s.Users, func(user goslide.User) bool {
+			return user.UserID == r.PathValue("user_id")
+		})
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.Users[index])
+	})
+}
@@ -0,0 +1,134 @@
+package fakeslide
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+func (s *Server) routeVirtualMachines() {
+	s.mux.HandleFunc("GET /v1/restore/virt", func(w http.ResponseWriter, r *http.Request) {
+		writeList(s, w, r, s.VirtualMachines, nil)
+	})
+
+	s.mux.HandleFunc("POST /v1/restore/virt", func(w http.ResponseWriter, r *http.Request) {
+		payload := goslide.VirtualMachineRestoreCreatePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		snapshotIndex := slices.IndexFunc(s.Snapshots, func(snapshot goslide.Snapshot) bool {
+			return snapshot.SnapshotID == payload.SnapshotID
+		})
+		if snapshotIndex < 0 {
+			writeError(w, http.StatusBadRequest, goslide.APIErrorCode_ERR_VALIDATION_ERROR)
+
+			return
+		}
+
+		now := s.Now().UTC().Truncate(time.Second)
+		vm := goslide.VirtualMachineRestore{
+			AgentID:      s.Snapshots[snapshotIndex].AgentID,
+			CPUCount:     max(payload.CPUCount, 1),
+			CreatedAt:    now,
+			DeviceID:     payload.DeviceID,
+			DiskBus:      payload.DiskBus,
+			ExpiresAt:    now.Add(24 * time.Hour),
+			MemoryInMB:   max(payload.MemoryInMB, 1024),
+			NetworkModel: payload.NetworkModel,
+			NetworkType:  payload.NetworkType,
+			SnapshotID:   payload.SnapshotID,
+			State:        goslide.VirtualMachineState_RUNNING,
+			VirtID:       s.id("virt"),
+			VNC:          []goslide.VirtualMachineVNC{},
+			VNCPassword:  "vnc-password",
+		}
+
+		if payload.NetworkType == goslide.VirtualMachineNetworkType_NETWORK_ID {
+			networkIndex := s.networkIndex(payload.NetworkSource)
+			if networkIndex < 0 {
+				writeError(w, http.StatusBadRequest, goslide.APIErrorCode_ERR_VALIDATION_ERROR)
+
+				return
+			}
+
+			s.Networks[networkIndex].ConnectedVirtIDs = append(s.Networks[networkIndex].ConnectedVirtIDs, vm.VirtID)
+		}
+
+		s.VirtualMachines = append(s.VirtualMachines, vm)
+		writeJSON(w, http.StatusCreated, vm)
+	})
+
+	s.mux.HandleFunc("GET /v1/restore/virt/{virt_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := s.virtualMachineIndex(r.PathValue("virt_id"))
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, s.VirtualMachines[index])
+	})
+
+	s.mux.HandleFunc("PATCH /v1/restore/virt/{virt_id}", func(w http.ResponseWriter, r *http.Request) {
+		index := s.virtualMachineIndex(r.PathValue("virt_id"))
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		payload := goslide.VirtualMachineRestoreUpdatePayload{}
+		if !decode(w, r, &payload) {
+			return
+		}
+
+		vm := &s.VirtualMachines[index]
+		if payload.State != "" {
+			vm.State = payload.State
+		}
+
+		if payload.CPUCount != 0 {
+			vm.CPUCount = payload.CPUCount
+		}
+
+		if payload.MemoryInMB != 0 {
+			vm.MemoryInMB = payload.MemoryInMB
+		}
+
+		if payload.ExpiresAt != nil {
+			vm.ExpiresAt = *payload.ExpiresAt
+		}
+
+		writeJSON(w, http.StatusOK, vm)
+	})
+
+	s.mux.HandleFunc("DELETE /v1/restore/virt/{virt_id}", func(w http.ResponseWriter, r *http.Request) {
+		virtID := r.PathValue("virt_id")
+
+		index := s.virtualMachineIndex(virtID)
+		if index < 0 {
+			writeNotFound(w)
+
+			return
+		}
+
+		s.VirtualMachines = slices.Delete(s.VirtualMachines, index, index+1)
+
+		for i := range s.Networks {
+			s.Networks[i].ConnectedVirtIDs = slices.DeleteFunc(s.Networks[i].ConnectedVirtIDs, func(connected string) bool {
+				return connected == virtID
+			})
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) virtualMachineIndex(virtID string) int {
+	return slices.IndexFunc(s.VirtualMachines, func(vm goslide.VirtualMachineRestore) bool {
+		return vm.VirtID == virtID
+	})
+}
@@ -0,0 +1,42 @@
+// Package jsonfile saves the state files of long running jobs.
+package jsonfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Write writes value as indented JSON to a temporary file readable only by
+// the owner, and renames it over path, so an interrupted write never leaves
+// a truncated file behind.
+func Write(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(0o600); err != nil {
+		temp.Close()
+
+		return err
+	}
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
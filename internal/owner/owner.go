@@ -0,0 +1,121 @@
+// Package owner looks up the devices and agents alerts are raised for, and
+// the clients they belong to, since alerts do not carry a client themselves.
+package owner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/equalsgibson/goslide"
+)
+
+// Cache looks up each device and agent once. It is safe for concurrent use.
+type Cache struct {
+	service goslide.Service
+
+	mu      sync.Mutex
+	devices map[string]goslide.Device
+	agents  map[string]goslide.Agent
+}
+
+func NewCache(service goslide.Service) *Cache {
+	return &Cache{
+		service: service,
+		devices: map[string]goslide.Device{},
+		agents:  map[string]goslide.Agent{},
+	}
+}
+
+// Device looks up a device. Errors are not cached, so the lookup is tried
+// again the next time.
+func (c *Cache) Device(ctx context.Context, deviceID string) (goslide.Device, error) {
+	c.mu.Lock()
+	device, ok := c.devices[deviceID]
+	c.mu.Unlock()
+
+	if ok {
+		return device, nil
+	}
+
+	device, err := c.service.Devices().Get(ctx, deviceID)
+	if err != nil {
+		return goslide.Device{}, err
+	}
+
+	c.mu.Lock()
+	c.devices[deviceID] = device
+	c.mu.Unlock()
+
+	return device, nil
+}
+
+// Agent looks up an agent. Errors are not cached, so the lookup is tried
+// again the next time.
+func (c *Cache) Agent(ctx context.Context, agentID string) (goslide.Agent, error) {
+	c.mu.Lock()
+	agent, ok := c.agents[agentID]
+	c.mu.Unlock()
+
+	if ok {
+		return agent, nil
+	}
+
+	agent, err := c.service.Agents().Get(ctx, agentID)
+	if err != nil {
+		return goslide.Agent{}, err
+	}
+
+	c.mu.Lock()
+	c.agents[agentID] = agent
+	c.mu.Unlock()
+
+	return agent, nil
+}
+
+// ClientID returns the client of an alert through its device, or its agent
+// for alerts without a device. It is empty for alerts of neither.
+func (c *Cache) ClientID(ctx context.Context, alert goslide.Alert) (string, error) {
+	switch {
+	case alert.DeviceID != "":
+		device, err := c.Device(ctx, alert.DeviceID)
+
+		return device.ClientID, err
+	case alert.AgentID != "":
+		agent, err := c.Agent(ctx, alert.AgentID)
+
+		return agent.ClientID, err
+	default:
+		return "", nil
+	}
+}
+
+// DeviceName returns the display name of a device, its hostname or ID when
+// it has none.
+func DeviceName(device goslide.Device) string {
+	return firstName(device.DisplayName, device.Hostname, device.DeviceID)
+}
+
+// AgentName returns the display name of an agent, its hostname or ID when it
+// has none.
+func AgentName(agent goslide.Agent) string {
+	return firstName(agent.DisplayName, agent.Hostname, agent.AgentID)
+}
+
+func firstName(names ...string) string {
+	for _, name := range names {
+		if name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// IsNotFound reports whether err is the API saying a record does not exist.
+func IsNotFound(err error) bool {
+	slideError := &goslide.SlideError{}
+
+	return errors.As(err, &slideError) && slideError.HTTPStatusCode == http.StatusNotFound
+}
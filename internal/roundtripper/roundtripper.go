@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
@@ -130,3 +131,16 @@ func ServeAndValidate(t *testing.T, r TestResponse, expected ExpectedTestRequest
 		return r.CreateResponse()
 	}
 }
+
+// HandlerNetwork serves every request with the handler, for tests that make
+// many requests in an order that is not known up front.
+func HandlerNetwork(handler http.Handler) http.RoundTripper {
+	return TestNetwork{
+		RoundTripFunc: func(r *http.Request) (*http.Response, error) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+
+			return recorder.Result(), nil
+		},
+	}
+}
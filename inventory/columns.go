@@ -0,0 +1,175 @@
+package inventory
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+// Key columns start every device and agent row whatever the configured
+// columns, so that exports can be compared.
+const (
+	columnClientID   = "client_id"
+	columnClientName = "client_name"
+	columnDeviceID   = "device_id"
+	columnDeviceName = "device_name"
+	columnAgentID    = "agent_id"
+	columnAgentName  = "agent_name"
+)
+
+// column is a field of a row. Values are strings, numbers, booleans or
+// times, so that JSON and XLSX keep their types.
+type column[T any] struct {
+	name  string
+	value func(record T) any
+}
+
+var deviceKeyColumns = []column[Device]{
+	{columnClientID, func(d Device) any { return d.ClientID }},
+	{columnClientName, func(d Device) any { return d.ClientName }},
+	{columnDeviceID, func(d Device) any { return d.DeviceID }},
+	{columnDeviceName, func(d Device) any { return d.name() }},
+}
+
+var deviceColumns = []column[Device]{
+	{"hostname", func(d Device) any { return d.Hostname }},
+	{"serial_number", func(d Device) any { return d.SerialNumber }},
+	{"hardware_model", func(d Device) any { return d.HardwareModelName }},
+	{"service_model", func(d Device) any { return d.ServiceModelName }},
+	{"service_model_short", func(d Device) any { return d.ServiceModelNameShort }},
+	{"nfr", func(d Device) any { return d.NFR }},
+	{"storage_used_bytes", func(d Device) any { return d.StorageUsedBytes }},
+	{"storage_total_bytes", func(d Device) any { return d.StorageTotalBytes }},
+	{"storage_used_percent", func(d Device) any { return percent(d.StorageUsedBytes, d.StorageTotalBytes) }},
+	{"agents", func(d Device) any { return d.Agents }},
+	{"image_version", func(d Device) any { return d.ImageVersion }},
+	{"package_version", func(d Device) any { return d.PackageVersion }},
+	{"public_ip", func(d Device) any { return d.PublicIPAddress }},
+	{"last_seen_at", func(d Device) any { return d.LastSeenAt }},
+}
+
+var defaultDeviceColumns = []string{
+	"hardware_model",
+	"service_model",
+	"nfr",
+	"storage_used_bytes",
+	"storage_total_bytes",
+	"agents",
+}
+
+var agentKeyColumns = []column[Agent]{
+	{columnClientID, func(a Agent) any { return a.ClientID }},
+	{columnClientName, func(a Agent) any { return a.ClientName }},
+	{columnAgentID, func(a Agent) any { return a.AgentID }},
+	{columnAgentName, func(a Agent) any { return a.name() }},
+}
+
+var agentColumns = []column[Agent]{
+	{"device_id", func(a Agent) any { return a.DeviceID }},
+	{"device_name", func(a Agent) any { return a.DeviceName }},
+	{"hostname", func(a Agent) any { return a.Hostname }},
+	{"os", func(a Agent) any { return a.OS }},
+	{"os_version", func(a Agent) any { return a.OSVersion }},
+	{"agent_version", func(a Agent) any { return a.AgentVersion }},
+	{"platform", func(a Agent) any { return a.Platform }},
+	{"manufacturer", func(a Agent) any { return a.Manufacturer }},
+	{"snapshots", func(a Agent) any { return a.Snapshots }},
+	{"local_snapshots", func(a Agent) any { return a.LocalSnapshots }},
+	{"cloud_snapshots", func(a Agent) any { return a.CloudSnapshots }},
+	{"latest_backup_at", func(a Agent) any { return backupStartedAt(a.LatestBackup) }},
+	{"latest_backup_status", func(a Agent) any { return backupStatus(a.LatestBackup) }},
+	{"latest_successful_backup_at", func(a Agent) any { return backupStartedAt(a.LatestSuccessfulBackup) }},
+	{"last_seen_at", func(a Agent) any { return a.LastSeenAt }},
+}
+
+var defaultAgentColumns = []string{
+	"device_name",
+	"os",
+	"agent_version",
+	"platform",
+	"snapshots",
+	"local_snapshots",
+	"cloud_snapshots",
+	"latest_backup_at",
+	"latest_backup_status",
+}
+
+// DeviceColumns returns the names of the columns devices can have, besides
+// client_id, client_name, device_id and device_name which they always have.
+func DeviceColumns() []string {
+	return columnNames(deviceColumns)
+}
+
+// AgentColumns returns the names of the columns agents can have, besides
+// client_id, client_name, agent_id and agent_name which they always have.
+func AgentColumns() []string {
+	return columnNames(agentColumns)
+}
+
+// DefaultDeviceColumns returns the columns of devices when none are set.
+func DefaultDeviceColumns() []string {
+	return append([]string{}, defaultDeviceColumns...)
+}
+
+// DefaultAgentColumns returns the columns of agents when none are set.
+func DefaultAgentColumns() []string {
+	return append([]string{}, defaultAgentColumns...)
+}
+
+func columnNames[T any](columns []column[T]) []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+// selectColumns returns the key columns followed by the named ones.
+func selectColumns[T any](keys, available []column[T], names []string) ([]column[T], error) {
+	selected := append([]column[T]{}, keys...)
+
+	for _, name := range names {
+		found := false
+		for _, c := range available {
+			if c.name == name {
+				selected = append(selected, c)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown column %q, expected one of %v", name, columnNames(available))
+		}
+	}
+
+	return selected, nil
+}
+
+func percent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(used)*1000/float64(total)) / 10
+}
+
+func backupStartedAt(backup *goslide.Backup) time.Time {
+	if backup == nil {
+		return time.Time{}
+	}
+
+	return backup.StartedAt
+}
+
+func backupStatus(backup *goslide.Backup) string {
+	if backup == nil {
+		return ""
+	}
+
+	return string(backup.Status)
+}
@@ -0,0 +1,163 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Item identifies a device or agent in a Baseline.
+type Item struct {
+	ClientID   string
+	ClientName string
+	ID         string
+	Name       string
+}
+
+// Baseline is what a previous export holds for a comparison.
+type Baseline struct {
+	GeneratedAt time.Time
+	Devices     []Item
+	Agents      []Item
+}
+
+// ChangeType tells whether a device or agent was added or removed.
+type ChangeType string
+
+const (
+	ChangeType_ADDED   ChangeType = "added"
+	ChangeType_REMOVED ChangeType = "removed"
+)
+
+// Change is a device or agent added or removed since a baseline.
+type Change struct {
+	Type ChangeType
+	Item
+}
+
+// Changes are the devices and agents added and removed since a baseline.
+type Changes struct {
+	Since   time.Time
+	Devices []Change
+	Agents  []Change
+}
+
+// Baseline returns the inventory as a baseline for a later comparison.
+func (i *Inventory) Baseline() Baseline {
+	baseline := Baseline{GeneratedAt: i.GeneratedAt, Devices: []Item{}, Agents: []Item{}}
+
+	for _, device := range i.Devices {
+		baseline.Devices = append(baseline.Devices, Item{ClientID: device.ClientID, ClientName: device.ClientName, ID: device.DeviceID, Name: device.name()})
+	}
+
+	for _, agent := range i.Agents {
+		baseline.Agents = append(baseline.Agents, Item{ClientID: agent.ClientID, ClientName: agent.ClientName, ID: agent.AgentID, Name: agent.name()})
+	}
+
+	return baseline
+}
+
+// ReadBaseline reads the devices and agents of a previous JSON export. Only
+// the key columns are used, so the export may have any other columns.
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	export := struct {
+		GeneratedAt time.Time        `json:"generated_at"`
+		Devices     []map[string]any `json:"devices"`
+		Agents      []map[string]any `json:"agents"`
+	}{}
+
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Baseline{}, fmt.Errorf("unable to read previous export - %w", err)
+	}
+
+	baseline := Baseline{GeneratedAt: export.GeneratedAt, Devices: []Item{}, Agents: []Item{}}
+
+	for _, row := range export.Devices {
+		item, err := readItem(row, columnDeviceID, columnDeviceName)
+		if err != nil {
+			return Baseline{}, err
+		}
+
+		baseline.Devices = append(baseline.Devices, item)
+	}
+
+	for _, row := range export.Agents {
+		item, err := readItem(row, columnAgentID, columnAgentName)
+		if err != nil {
+			return Baseline{}, err
+		}
+
+		baseline.Agents = append(baseline.Agents, item)
+	}
+
+	return baseline, nil
+}
+
+func readItem(row map[string]any, idColumn, nameColumn string) (Item, error) {
+	text := func(column string) string {
+		value, _ := row[column].(string)
+
+		return value
+	}
+
+	item := Item{
+		ClientID:   text(columnClientID),
+		ClientName: text(columnClientName),
+		ID:         text(idColumn),
+		Name:       text(nameColumn),
+	}
+
+	if item.ID == "" {
+		return Item{}, fmt.Errorf("previous export has a row without %s", idColumn)
+	}
+
+	return item, nil
+}
+
+// Compare records the devices and agents added and removed since baseline in
+// Changes. Removed ones are reported with their client and name at the time
+// of the baseline.
+func (i *Inventory) Compare(baseline Baseline) {
+	current := i.Baseline()
+
+	i.Changes = &Changes{
+		Since:   baseline.GeneratedAt,
+		Devices: compareItems(baseline.Devices, current.Devices),
+		Agents:  compareItems(baseline.Agents, current.Agents),
+	}
+}
+
+func compareItems(previous, current []Item) []Change {
+	previousIDs := map[string]bool{}
+	for _, item := range previous {
+		previousIDs[item.ID] = true
+	}
+
+	currentIDs := map[string]bool{}
+	for _, item := range current {
+		currentIDs[item.ID] = true
+	}
+
+	changes := []Change{}
+	for _, item := range current {
+		if !previousIDs[item.ID] {
+			changes = append(changes, Change{Type: ChangeType_ADDED, Item: item})
+		}
+	}
+
+	for _, item := range previous {
+		if !currentIDs[item.ID] {
+			changes = append(changes, Change{Type: ChangeType_REMOVED, Item: item})
+		}
+	}
+
+	sort.SliceStable(changes, func(a, b int) bool {
+		x, y := changes[a], changes[b]
+
+		return less(x.ClientName, y.ClientName, string(x.Type), string(y.Type), x.Name, y.Name, x.ID, y.ID)
+	})
+
+	return changes
+}
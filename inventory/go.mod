@@ -0,0 +1,23 @@
+module github.com/equalsgibson/goslide/inventory
+
+go 1.23.8
+
+require (
+	github.com/equalsgibson/goslide v0.0.0-00010101000000-000000000000
+	github.com/google/go-cmp v0.7.0
+	github.com/xuri/excelize/v2 v2.9.1
+)
+
+require (
+	github.com/richardlehane/mscfb v1.0.4 // indirect
+	github.com/richardlehane/msoleps v1.0.4 // indirect
+	github.com/tiendc/go-deepcopy v1.6.0 // indirect
+	github.com/xuri/efp v0.0.1 // indirect
+	github.com/xuri/nfp v0.0.1 // indirect
+	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/oauth2 v0.29.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+)
+
+replace github.com/equalsgibson/goslide => ../
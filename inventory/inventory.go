@@ -0,0 +1,234 @@
+// Package inventory builds the per-client inventory of a Slide account, for
+// reconciling usage against invoices: devices with their models, NFR status
+// and storage, agents with their operating system and version, and their
+// snapshot counts and latest backups. Reports are written as CSV, JSON or
+// XLSX, and can list the devices and agents added and removed since a
+// previous export.
+package inventory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+// Inventory is the state of an account at GeneratedAt.
+type Inventory struct {
+	GeneratedAt time.Time
+	Clients     []goslide.Client
+	Devices     []Device
+	Agents      []Agent
+
+	// Changes is set by Compare
+	Changes *Changes
+}
+
+// Device is a device with the name of its client.
+type Device struct {
+	goslide.Device
+	ClientName string
+
+	// Agents is the number of agents backing up to the device
+	Agents int
+}
+
+// Agent is an agent with the names of its client and device, and a summary
+// of its snapshots and backups.
+type Agent struct {
+	goslide.Agent
+	ClientName string
+	DeviceName string
+
+	// Snapshots counts the snapshots that are not deleted, and the others
+	// count those kept at each location type
+	Snapshots      int
+	LocalSnapshots int
+	CloudSnapshots int
+
+	// LatestBackup is the most recently started backup whatever its status,
+	// nil if the agent never backed up
+	LatestBackup           *goslide.Backup
+	LatestSuccessfulBackup *goslide.Backup
+}
+
+// Build lists the account and assembles its inventory, ordered by client
+// and name.
+func Build(ctx context.Context, service goslide.Service) (*Inventory, error) {
+	generatedAt := time.Now()
+
+	clients, err := list(func(pageHandler func(goslide.ListResponse[goslide.Client]) error) error {
+		return service.Clients().List(ctx, pageHandler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := list(func(pageHandler func(goslide.ListResponse[goslide.Device]) error) error {
+		return service.Devices().List(ctx, pageHandler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := list(func(pageHandler func(goslide.ListResponse[goslide.Agent]) error) error {
+		return service.Agents().List(ctx, pageHandler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := list(func(pageHandler func(goslide.ListResponse[goslide.Snapshot]) error) error {
+		return service.Snapshots().List(ctx, pageHandler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := list(func(pageHandler func(goslide.ListResponse[goslide.Backup]) error) error {
+		return service.Backups().List(ctx, pageHandler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientNames := map[string]string{}
+	for _, client := range clients {
+		clientNames[client.ClientID] = client.Name
+	}
+
+	deviceAgents := map[string]int{}
+	for _, agent := range agents {
+		deviceAgents[agent.DeviceID]++
+	}
+
+	inventory := &Inventory{
+		GeneratedAt: generatedAt,
+		Clients:     clients,
+		Devices:     make([]Device, 0, len(devices)),
+		Agents:      make([]Agent, 0, len(agents)),
+	}
+
+	deviceIndex := map[string]Device{}
+	for _, device := range devices {
+		record := Device{
+			Device:     device,
+			ClientName: clientNames[device.ClientID],
+			Agents:     deviceAgents[device.DeviceID],
+		}
+
+		deviceIndex[device.DeviceID] = record
+		inventory.Devices = append(inventory.Devices, record)
+	}
+
+	for _, agent := range agents {
+		device := deviceIndex[agent.DeviceID]
+
+		// Agents not assigned to a client are billed to the client of
+		// their device
+		if agent.ClientID == "" {
+			agent.ClientID = device.ClientID
+		}
+
+		inventory.Agents = append(inventory.Agents, Agent{
+			Agent:      agent,
+			ClientName: clientNames[agent.ClientID],
+			DeviceName: device.name(),
+		})
+	}
+
+	agentIndex := map[string]*Agent{}
+	for i := range inventory.Agents {
+		agentIndex[inventory.Agents[i].AgentID] = &inventory.Agents[i]
+	}
+
+	for _, snapshot := range snapshots {
+		agent, ok := agentIndex[snapshot.AgentID]
+		if !ok || snapshot.Deleted != nil {
+			continue
+		}
+
+		agent.Snapshots++
+		for _, location := range snapshot.Locations {
+			switch location.Type {
+			case goslide.SnapshotLocationType_LOCAL:
+				agent.LocalSnapshots++
+			case goslide.SnapshotLocationType_CLOUD:
+				agent.CloudSnapshots++
+			}
+		}
+	}
+
+	for _, backup := range backups {
+		agent, ok := agentIndex[backup.AgentID]
+		if !ok {
+			continue
+		}
+
+		backup := backup
+		if agent.LatestBackup == nil || backup.StartedAt.After(agent.LatestBackup.StartedAt) {
+			agent.LatestBackup = &backup
+		}
+
+		if backup.Status == goslide.BackupStatus_SUCCEEDED && (agent.LatestSuccessfulBackup == nil || backup.StartedAt.After(agent.LatestSuccessfulBackup.StartedAt)) {
+			agent.LatestSuccessfulBackup = &backup
+		}
+	}
+
+	sort.SliceStable(inventory.Clients, func(i, j int) bool {
+		return inventory.Clients[i].Name < inventory.Clients[j].Name
+	})
+
+	sort.SliceStable(inventory.Devices, func(i, j int) bool {
+		a, b := inventory.Devices[i], inventory.Devices[j]
+
+		return less(a.ClientName, b.ClientName, a.name(), b.name(), a.DeviceID, b.DeviceID)
+	})
+
+	sort.SliceStable(inventory.Agents, func(i, j int) bool {
+		a, b := inventory.Agents[i], inventory.Agents[j]
+
+		return less(a.ClientName, b.ClientName, a.name(), b.name(), a.AgentID, b.AgentID)
+	})
+
+	return inventory, nil
+}
+
+func (d Device) name() string {
+	if d.DisplayName != "" {
+		return d.DisplayName
+	}
+
+	return d.Hostname
+}
+
+func (a Agent) name() string {
+	if a.DisplayName != "" {
+		return a.DisplayName
+	}
+
+	return a.Hostname
+}
+
+// less orders by pairs of keys, the first pair that differs deciding.
+func less(pairs ...string) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] != pairs[i+1] {
+			return pairs[i] < pairs[i+1]
+		}
+	}
+
+	return false
+}
+
+func list[T any](listPages func(pageHandler func(response goslide.ListResponse[T]) error) error) ([]T, error) {
+	records := []T{}
+	err := listPages(func(response goslide.ListResponse[T]) error {
+		records = append(records, response.Data...)
+
+		return nil
+	})
+
+	return records, err
+}
@@ -0,0 +1,231 @@
+package inventory_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/inventory"
+	"github.com/google/go-cmp/cmp"
+	"github.com/xuri/excelize/v2"
+)
+
+var backupTime = time.Date(2025, 3, 31, 22, 0, 0, 0, time.UTC)
+
+// addModels gives the fleet hardware, storage, operating systems, snapshots
+// and backups. a_app reports no client, which is taken from its device.
+func addModels(server *fakeslide.Server) {
+	acme, globex := device(server, "d_acme"), device(server, "d_globex")
+	acme.HardwareModelName, acme.ServiceModelName = "Z1", "Slide 2TB"
+	acme.StorageUsedBytes, acme.StorageTotalBytes = 500, 2000
+	globex.HardwareModelName, globex.ServiceModelName = "Z1", "Slide 1TB"
+	globex.NFR, globex.StorageTotalBytes = true, 1000
+
+	dc, app, mail := agent(server, "a_dc"), agent(server, "a_app"), agent(server, "a_mail")
+	dc.OS, dc.AgentVersion, dc.Platform = "Windows Server 2022", "1.4.2", "x86_64"
+	app.ClientID, app.OS, app.AgentVersion, app.Platform = "", "Windows Server 2019", "1.4.1", "x86_64"
+	mail.OS, mail.AgentVersion, mail.Platform = "Windows 11", "1.4.2", "x86_64"
+
+	deleted := backupTime
+	server.Snapshots = []goslide.Snapshot{
+		{SnapshotID: "s_1", AgentID: "a_dc", Locations: []goslide.SnapshotLocation{{Type: goslide.SnapshotLocationType_LOCAL}, {Type: goslide.SnapshotLocationType_CLOUD}}},
+		{SnapshotID: "s_2", AgentID: "a_dc", Locations: []goslide.SnapshotLocation{{Type: goslide.SnapshotLocationType_LOCAL}}},
+		{SnapshotID: "s_3", AgentID: "a_dc", Deleted: &deleted},
+	}
+	server.Backups = []goslide.Backup{
+		{BackupID: "b_1", AgentID: "a_dc", Status: goslide.BackupStatus_SUCCEEDED, StartedAt: backupTime.Add(-24 * time.Hour)},
+		{BackupID: "b_2", AgentID: "a_dc", Status: goslide.BackupStatus_FAILED, StartedAt: backupTime},
+	}
+}
+
+// device returns a device of the fleet for changes, by ID.
+func device(server *fakeslide.Server, deviceID string) *goslide.Device {
+	index := slices.IndexFunc(server.Devices, func(device goslide.Device) bool {
+		return device.DeviceID == deviceID
+	})
+
+	return &server.Devices[index]
+}
+
+// agent returns an agent of the fleet for changes, by ID.
+func agent(server *fakeslide.Server, agentID string) *goslide.Agent {
+	index := slices.IndexFunc(server.Agents, func(agent goslide.Agent) bool {
+		return agent.AgentID == agentID
+	})
+
+	return &server.Agents[index]
+}
+
+func csvTable(t *testing.T, report *inventory.Report, name string) string {
+	t.Helper()
+
+	table, ok := report.Table(name)
+	if !ok {
+		t.Fatalf("%s missing table %s", t.Name(), name)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := table.WriteCSV(buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.String()
+}
+
+func TestReport(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addModels(server)
+
+	inv, err := inventory.Build(context.Background(), server.Service())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := inv.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		inventory.Table_CLIENTS: `client_id,client_name,devices,nfr_devices,agents,storage_used_bytes,storage_total_bytes,snapshots
+c_acme,Acme,2,0,2,500,2000,2
+c_globex,Globex,1,1,1,0,1000,0
+`,
+		inventory.Table_DEVICES: `client_id,client_name,device_id,device_name,hardware_model,service_model,nfr,storage_used_bytes,storage_total_bytes,agents
+c_acme,Acme,d_acme_branch,Acme Branch,,,false,0,0,0
+c_acme,Acme,d_acme,Acme HQ,Z1,Slide 2TB,false,500,2000,2
+c_globex,Globex,d_globex,globex-slide,Z1,Slide 1TB,true,0,1000,1
+`,
+		inventory.Table_AGENTS: `client_id,client_name,agent_id,agent_name,device_name,os,agent_version,platform,snapshots,local_snapshots,cloud_snapshots,latest_backup_at,latest_backup_status
+c_acme,Acme,a_dc,Acme DC01,Acme HQ,Windows Server 2022,1.4.2,x86_64,2,2,1,2025-03-31T22:00:00Z,failed
+c_acme,Acme,a_app,acme-app,Acme HQ,Windows Server 2019,1.4.1,x86_64,0,0,0,,
+c_globex,Globex,a_mail,globex-mail,globex-slide,Windows 11,1.4.2,x86_64,0,0,0,,
+`,
+	}
+
+	for name, want := range expected {
+		if diff := cmp.Diff(want, csvTable(t, report, name)); diff != "" {
+			t.Fatalf("%s %s table (-want +got):\n%s", t.Name(), name, diff)
+		}
+	}
+
+	if _, ok := report.Table(inventory.Table_CHANGES); ok {
+		t.Fatalf("%s expected no changes table without a comparison", t.Name())
+	}
+
+	report, err = inv.Report(
+		inventory.WithDeviceColumns("serial_number", "storage_used_percent"),
+		inventory.WithAgentColumns("latest_successful_backup_at"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(`client_id,client_name,device_id,device_name,serial_number,storage_used_percent
+c_acme,Acme,d_acme_branch,Acme Branch,,0.0
+c_acme,Acme,d_acme,Acme HQ,,25.0
+c_globex,Globex,d_globex,globex-slide,,0.0
+`, csvTable(t, report, inventory.Table_DEVICES)); diff != "" {
+		t.Fatalf("%s configured device columns (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if !strings.Contains(csvTable(t, report, inventory.Table_AGENTS), "c_acme,Acme,a_dc,Acme DC01,2025-03-30T22:00:00Z\n") {
+		t.Fatalf("%s expected the latest successful backup of a_dc:\n%s", t.Name(), csvTable(t, report, inventory.Table_AGENTS))
+	}
+
+	if _, err := inv.Report(inventory.WithAgentColumns("colour")); err == nil || !strings.Contains(err.Error(), `unknown column "colour"`) {
+		t.Fatalf("%s expected an unknown column error, got %v", t.Name(), err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ctx := context.Background()
+	server := fakeslide.NewFleet()
+	addModels(server)
+
+	previous, err := inventory.Build(ctx, server.Service())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previousReport, err := previous.Report(inventory.WithDeviceColumns(), inventory.WithAgentColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exported := &bytes.Buffer{}
+	if err := previousReport.WriteJSON(exported); err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := inventory.ReadBaseline(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(previous.Baseline(), baseline); diff != "" {
+		t.Fatalf("%s baseline read back from JSON (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// A month later, Globex left and Acme got a new device and agent
+	server.Devices = []goslide.Device{
+		*device(server, "d_acme"),
+		*device(server, "d_acme_branch"),
+		{DeviceID: "d_acme_east", ClientID: "c_acme", DisplayName: "Acme East"},
+	}
+	server.Agents = []goslide.Agent{
+		*agent(server, "a_dc"),
+		*agent(server, "a_app"),
+		{AgentID: "a_east", DeviceID: "d_acme_east", ClientID: "c_acme", Hostname: "acme-east"},
+	}
+
+	current, err := inventory.Build(ctx, server.Service())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current.Compare(baseline)
+
+	report, err := current.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(`change,type,client_id,client_name,id,name
+added,device,c_acme,Acme,d_acme_east,Acme East
+removed,device,c_globex,Globex,d_globex,globex-slide
+added,agent,c_acme,Acme,a_east,acme-east
+removed,agent,c_globex,Globex,a_mail,globex-mail
+`, csvTable(t, report, inventory.Table_CHANGES)); diff != "" {
+		t.Fatalf("%s changes (-want +got):\n%s", t.Name(), diff)
+	}
+
+	xlsx := &bytes.Buffer{}
+	if err := report.WriteXLSX(xlsx); err != nil {
+		t.Fatal(err)
+	}
+
+	workbook, err := excelize.OpenReader(xlsx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer workbook.Close()
+
+	if diff := cmp.Diff([]string{"clients", "devices", "agents", "changes"}, workbook.GetSheetList()); diff != "" {
+		t.Fatalf("%s sheets (-want +got):\n%s", t.Name(), diff)
+	}
+
+	rows, err := workbook.GetRows("changes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"added", "device", "c_acme", "Acme", "d_acme_east", "Acme East"}, rows[1]); diff != "" {
+		t.Fatalf("%s first change row (-want +got):\n%s", t.Name(), diff)
+	}
+}
@@ -0,0 +1,394 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Table names of a report.
+const (
+	Table_CLIENTS = "clients"
+	Table_DEVICES = "devices"
+	Table_AGENTS  = "agents"
+	Table_CHANGES = "changes"
+)
+
+// Table is a sheet of a report. Values are strings, numbers, booleans or
+// times, the zero time meaning no value.
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+// Report is an inventory laid out in tables: a summary per client, the
+// devices, the agents and, after Compare, the changes.
+type Report struct {
+	GeneratedAt time.Time
+	Tables      []Table
+}
+
+type reportConfig struct {
+	deviceColumns []string
+	agentColumns  []string
+}
+
+type reportOption func(c *reportConfig)
+
+// WithDeviceColumns sets the columns of the devices table, from those of
+// DeviceColumns.
+func WithDeviceColumns(columns ...string) reportOption {
+	return func(c *reportConfig) {
+		c.deviceColumns = columns
+	}
+}
+
+// WithAgentColumns sets the columns of the agents table, from those of
+// AgentColumns.
+func WithAgentColumns(columns ...string) reportOption {
+	return func(c *reportConfig) {
+		c.agentColumns = columns
+	}
+}
+
+// Report lays the inventory out in tables, with the default device and agent
+// columns unless set.
+func (i *Inventory) Report(options ...reportOption) (*Report, error) {
+	config := &reportConfig{
+		deviceColumns: defaultDeviceColumns,
+		agentColumns:  defaultAgentColumns,
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	devices, err := selectColumns(deviceKeyColumns, deviceColumns, config.deviceColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device columns - %w", err)
+	}
+
+	agents, err := selectColumns(agentKeyColumns, agentColumns, config.agentColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid agent columns - %w", err)
+	}
+
+	report := &Report{
+		GeneratedAt: i.GeneratedAt,
+		Tables: []Table{
+			i.clientsTable(),
+			newTable(Table_DEVICES, devices, i.Devices),
+			newTable(Table_AGENTS, agents, i.Agents),
+		},
+	}
+
+	if i.Changes != nil {
+		report.Tables = append(report.Tables, i.changesTable())
+	}
+
+	return report, nil
+}
+
+func newTable[T any](name string, columns []column[T], records []T) Table {
+	table := Table{Name: name, Columns: columnNames(columns), Rows: [][]any{}}
+
+	for _, record := range records {
+		row := make([]any, 0, len(columns))
+		for _, c := range columns {
+			row = append(row, c.value(record))
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+// clientsTable sums up each client. Devices and agents without a client are
+// counted on a row with an empty client_id.
+func (i *Inventory) clientsTable() Table {
+	type summary struct {
+		clientID          string
+		name              string
+		devices           int
+		nfrDevices        int
+		agents            int
+		storageUsedBytes  uint64
+		storageTotalBytes uint64
+		snapshots         int
+	}
+
+	summaries := []*summary{}
+	byClient := map[string]*summary{}
+	for _, client := range i.Clients {
+		s := &summary{clientID: client.ClientID, name: client.Name}
+		summaries = append(summaries, s)
+		byClient[client.ClientID] = s
+	}
+
+	get := func(clientID, name string) *summary {
+		s, ok := byClient[clientID]
+		if !ok {
+			s = &summary{clientID: clientID, name: name}
+			summaries = append(summaries, s)
+			byClient[clientID] = s
+		}
+
+		return s
+	}
+
+	for _, device := range i.Devices {
+		s := get(device.ClientID, device.ClientName)
+		s.devices++
+		s.storageUsedBytes += device.StorageUsedBytes
+		s.storageTotalBytes += device.StorageTotalBytes
+		if device.NFR {
+			s.nfrDevices++
+		}
+	}
+
+	for _, agent := range i.Agents {
+		s := get(agent.ClientID, agent.ClientName)
+		s.agents++
+		s.snapshots += agent.Snapshots
+	}
+
+	table := Table{
+		Name:    Table_CLIENTS,
+		Columns: []string{columnClientID, columnClientName, "devices", "nfr_devices", "agents", "storage_used_bytes", "storage_total_bytes", "snapshots"},
+		Rows:    [][]any{},
+	}
+
+	for _, s := range summaries {
+		table.Rows = append(table.Rows, []any{s.clientID, s.name, s.devices, s.nfrDevices, s.agents, s.storageUsedBytes, s.storageTotalBytes, s.snapshots})
+	}
+
+	return table
+}
+
+func (i *Inventory) changesTable() Table {
+	table := Table{
+		Name:    Table_CHANGES,
+		Columns: []string{"change", "type", columnClientID, columnClientName, "id", "name"},
+		Rows:    [][]any{},
+	}
+
+	for _, change := range i.Changes.Devices {
+		table.Rows = append(table.Rows, []any{string(change.Type), "device", change.ClientID, change.ClientName, change.ID, change.Name})
+	}
+
+	for _, change := range i.Changes.Agents {
+		table.Rows = append(table.Rows, []any{string(change.Type), "agent", change.ClientID, change.ClientName, change.ID, change.Name})
+	}
+
+	return table
+}
+
+// Table returns a table of the report by name.
+func (r *Report) Table(name string) (Table, bool) {
+	for _, table := range r.Tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+
+	return Table{}, false
+}
+
+// WriteCSV writes the table with a header row. Times are in RFC 3339.
+func (t Table) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		record := make([]string, 0, len(row))
+		for _, value := range row {
+			record = append(record, formatValue(value))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WriteJSON writes an object with generated_at and an array of rows per
+// table, each row an object keyed by column in column order. It can be read
+// back by ReadBaseline.
+func (r *Report) WriteJSON(w io.Writer) error {
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("{\n    \"generated_at\": ")
+	if err := writeJSONValue(buffer, r.GeneratedAt); err != nil {
+		return err
+	}
+
+	for _, table := range r.Tables {
+		fmt.Fprintf(buffer, ",\n    %q: [", table.Name)
+
+		for i, row := range table.Rows {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+
+			buffer.WriteString("\n        {")
+			for j, value := range row {
+				if j > 0 {
+					buffer.WriteString(", ")
+				}
+
+				fmt.Fprintf(buffer, "%q: ", table.Columns[j])
+				if err := writeJSONValue(buffer, value); err != nil {
+					return err
+				}
+			}
+
+			buffer.WriteString("}")
+		}
+
+		if len(table.Rows) > 0 {
+			buffer.WriteString("\n    ")
+		}
+
+		buffer.WriteString("]")
+	}
+
+	buffer.WriteString("\n}\n")
+
+	_, err := w.Write(buffer.Bytes())
+
+	return err
+}
+
+func writeJSONValue(buffer *bytes.Buffer, value any) error {
+	// The zero time is no value
+	if t, ok := value.(time.Time); ok && t.IsZero() {
+		value = nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	buffer.Write(data)
+
+	return nil
+}
+
+// WriteXLSX writes a workbook with a sheet per table, with a frozen header
+// row and filters. Times are UTC.
+func (r *Report) WriteXLSX(w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	for i, table := range r.Tables {
+		if i == 0 {
+			if err := file.SetSheetName(file.GetSheetName(0), table.Name); err != nil {
+				return err
+			}
+		} else if _, err := file.NewSheet(table.Name); err != nil {
+			return err
+		}
+
+		if err := writeSheet(file, table, headerStyle); err != nil {
+			return fmt.Errorf("unable to write sheet %s - %w", table.Name, err)
+		}
+	}
+
+	return file.Write(w)
+}
+
+func writeSheet(file *excelize.File, table Table, headerStyle int) error {
+	header := make([]any, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		header = append(header, column)
+	}
+
+	if err := file.SetSheetRow(table.Name, "A1", &header); err != nil {
+		return err
+	}
+
+	if err := file.SetRowStyle(table.Name, 1, 1, headerStyle); err != nil {
+		return err
+	}
+
+	for i, row := range table.Rows {
+		values := make([]any, 0, len(row))
+		for _, value := range row {
+			if t, ok := value.(time.Time); ok {
+				if t.IsZero() {
+					value = nil
+				} else {
+					value = t.UTC()
+				}
+			}
+
+			values = append(values, value)
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+
+		if err := file.SetSheetRow(table.Name, cell, &values); err != nil {
+			return err
+		}
+	}
+
+	lastColumn, err := excelize.ColumnNumberToName(len(table.Columns))
+	if err != nil {
+		return err
+	}
+
+	if err := file.SetColWidth(table.Name, "A", lastColumn, 18); err != nil {
+		return err
+	}
+
+	if err := file.AutoFilter(table.Name, "A1:"+lastColumn+strconv.Itoa(len(table.Rows)+1), nil); err != nil {
+		return err
+	}
+
+	return file.SetPanes(table.Name, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/equalsgibson/goslide/mirror
+
+go 1.23.8
+
+require (
+	github.com/equalsgibson/goslide v0.0.0-00010101000000-000000000000
+	github.com/google/go-cmp v0.7.0
+	go.etcd.io/bbolt v1.4.3
+)
+
+require (
+	golang.org/x/oauth2 v0.29.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+)
+
+replace github.com/equalsgibson/goslide => ../
@@ -0,0 +1,294 @@
+package mirror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/mirror"
+	"github.com/google/go-cmp/cmp"
+)
+
+// addHistory gives the fleet backups, a snapshot and an open and a resolved
+// alert.
+func addHistory(server *fakeslide.Server) {
+	server.Backups = []goslide.Backup{
+		{BackupID: "b_1", AgentID: "a_dc", Status: goslide.BackupStatus_SUCCEEDED},
+		{BackupID: "b_2", AgentID: "a_mail", Status: goslide.BackupStatus_FAILED},
+	}
+	server.Snapshots = []goslide.Snapshot{
+		{SnapshotID: "s_1", AgentID: "a_dc", Locations: []goslide.SnapshotLocation{
+			{DeviceID: "d_acme", Type: goslide.SnapshotLocationType_LOCAL},
+		}},
+	}
+	server.Alerts = []goslide.Alert{
+		{AlertID: "al_open", AgentID: "a_app", DeviceID: "d_acme"},
+		{AlertID: "al_resolved", AgentID: "a_mail", DeviceID: "d_globex", Resolved: true},
+	}
+}
+
+func openStore(t *testing.T, path string) *mirror.Store {
+	t.Helper()
+
+	store, err := mirror.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func agentIDs(agents []goslide.Agent) []string {
+	ids := []string{}
+	for _, agent := range agents {
+		ids = append(ids, agent.AgentID)
+	}
+
+	return ids
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	server := fakeslide.NewFleet()
+	addHistory(server)
+	path := filepath.Join(t.TempDir(), "mirror.db")
+	store := openStore(t, path)
+
+	syncer := mirror.NewSyncer(server.Service(), store)
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	devices, err := store.DevicesByClient("c_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(server.Devices[:2], devices); diff != "" {
+		t.Fatalf("%s devices by client (-want +got):\n%s", t.Name(), diff)
+	}
+
+	agents, err := store.AgentsByDevice("d_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"a_app", "a_dc"}, agentIDs(agents)); diff != "" {
+		t.Fatalf("%s agents by device (-want +got):\n%s", t.Name(), diff)
+	}
+
+	alerts, err := store.Alerts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(alerts) != 2 {
+		t.Fatalf("%s expected resolved alerts to be mirrored, got %d alerts", t.Name(), len(alerts))
+	}
+
+	snapshots, err := store.SnapshotsByDevice("d_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(server.Snapshots, snapshots); diff != "" {
+		t.Fatalf("%s snapshots by device (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Move an agent, remove another and add a new one
+	server.Agents[1].DeviceID = "d_globex"
+	server.Agents[1].ClientID = "c_globex"
+	server.Agents = append(server.Agents[1:], goslide.Agent{AgentID: "a_new", DeviceID: "d_acme", ClientID: "c_acme"})
+
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := store.SyncState(mirror.Resource_AGENTS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]int{3, 1, 1, 1}, []int{state.Records, state.Added, state.Updated, state.Removed}); diff != "" {
+		t.Fatalf("%s agent sync counts (-want +got):\n%s", t.Name(), diff)
+	}
+
+	for deviceID, want := range map[string][]string{
+		"d_acme":   {"a_new"},
+		"d_globex": {"a_app", "a_mail"},
+	} {
+		agents, err := store.AgentsByDevice(deviceID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(want, agentIDs(agents)); diff != "" {
+			t.Fatalf("%s agents by device %s after update (-want +got):\n%s", t.Name(), deviceID, diff)
+		}
+	}
+
+	if _, err := store.Agent("a_dc"); !errors.Is(err, mirror.ErrNotFound) {
+		t.Fatalf("%s expected removed agent to be not found, got %v", t.Name(), err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mirror is read without the API
+	offline := openStore(t, path)
+	defer offline.Close()
+
+	agent, err := offline.Agent("a_app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(server.Agents[0], agent); diff != "" {
+		t.Fatalf("%s offline agent (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestSyncIncremental(t *testing.T) {
+	ctx := context.Background()
+	server := fakeslide.NewFleet()
+	store := openStore(t, filepath.Join(t.TempDir(), "mirror.db"))
+	defer store.Close()
+
+	now := time.Now()
+	for hour := 10; hour > 0; hour-- {
+		server.Backups = append(server.Backups, goslide.Backup{
+			BackupID:  fmt.Sprintf("b_%02d", 11-hour),
+			AgentID:   "a_dc",
+			Status:    goslide.BackupStatus_SUCCEEDED,
+			StartedAt: now.Add(-time.Duration(hour) * time.Hour),
+		})
+	}
+	server.Alerts = []goslide.Alert{
+		{AlertID: "al_old", AgentID: "a_dc", CreatedAt: now.Add(-48 * time.Hour)},
+		{AlertID: "al_new", AgentID: "a_dc", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	syncer := mirror.NewSyncer(server.Service(), store, mirror.WithResources(mirror.Resource_BACKUPS, mirror.Resource_ALERTS))
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new backup, an alert resolved at the top and a backup gone from the bottom
+	server.Backups = append(server.Backups[1:], goslide.Backup{BackupID: "b_11", AgentID: "a_dc", Status: goslide.BackupStatus_STARTED, StartedAt: now})
+	server.Alerts[1].Resolved = true
+	listed := server.Count("GET /v1/backup")
+
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// b_11 and b_10 fit on the first page, and b_10 is mirrored already
+	if count := server.Count("GET /v1/backup") - listed; count != 1 {
+		t.Errorf("%s expected the incremental sync to stop after 1 page, got %d", t.Name(), count)
+	}
+
+	state, err := store.SyncState(mirror.Resource_BACKUPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]int{11, 1, 0, 0}, []int{state.Records, state.Added, state.Updated, state.Removed}); diff != "" {
+		t.Fatalf("%s incremental backup sync counts (-want +got):\n%s", t.Name(), diff)
+	}
+
+	alert, err := store.Alert("al_new")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !alert.Resolved {
+		t.Errorf("%s expected the resolved alert to be updated", t.Name())
+	}
+
+	// A full sync removes the backup the API no longer returns
+	full := mirror.NewSyncer(server.Service(), store, mirror.WithResources(mirror.Resource_BACKUPS), mirror.WithFullSyncInterval(0))
+	if err := full.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Backup("b_01"); !errors.Is(err, mirror.ErrNotFound) {
+		t.Fatalf("%s expected removed backup to be not found, got %v", t.Name(), err)
+	}
+
+	state, err = store.SyncState(mirror.Resource_BACKUPS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]int{10, 0, 0, 1}, []int{state.Records, state.Added, state.Updated, state.Removed}); diff != "" {
+		t.Fatalf("%s full backup sync counts (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestSyncFailureKeepsRecords(t *testing.T) {
+	ctx := context.Background()
+	server := fakeslide.NewFleet()
+	addHistory(server)
+	store := openStore(t, filepath.Join(t.TempDir(), "mirror.db"))
+	defer store.Close()
+
+	syncer := mirror.NewSyncer(server.Service(), store, mirror.WithResources(mirror.Resource_DEVICES, mirror.Resource_AGENTS))
+	if err := syncer.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	synced, err := store.SyncState(mirror.Resource_DEVICES)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Agents = server.Agents[:1]
+	server.FailNext("GET /v1/device", 1)
+
+	if err := syncer.Sync(ctx); err == nil {
+		t.Fatalf("%s expected the device sync to fail", t.Name())
+	}
+
+	devices, err := store.Devices()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(server.Devices, devices); diff != "" {
+		t.Fatalf("%s devices after failed sync (-want +got):\n%s", t.Name(), diff)
+	}
+
+	failed, err := store.SyncState(mirror.Resource_DEVICES)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if failed.Error == "" || !failed.SyncedAt.Equal(synced.SyncedAt) || failed.Records != 3 {
+		t.Fatalf("%s unexpected state after failed sync: %+v", t.Name(), failed)
+	}
+
+	// Other resources are still synced
+	agents, err := store.Agents()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"a_dc"}, agentIDs(agents)); diff != "" {
+		t.Fatalf("%s agents after failed device sync (-want +got):\n%s", t.Name(), diff)
+	}
+
+	clients, err := store.SyncState(mirror.Resource_CLIENTS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !clients.SyncedAt.IsZero() {
+		t.Fatalf("%s expected clients not to be synced, got %v", t.Name(), clients.SyncedAt.Format(time.RFC3339))
+	}
+}
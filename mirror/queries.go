@@ -0,0 +1,153 @@
+package mirror
+
+import "github.com/equalsgibson/goslide"
+
+func (s *Store) Clients() ([]goslide.Client, error) {
+	return all(s, clients)
+}
+
+func (s *Store) Client(clientID string) (goslide.Client, error) {
+	return get(s, clients, clientID)
+}
+
+func (s *Store) Devices() ([]goslide.Device, error) {
+	return all(s, devices)
+}
+
+func (s *Store) Device(deviceID string) (goslide.Device, error) {
+	return get(s, devices, deviceID)
+}
+
+func (s *Store) DevicesByClient(clientID string) ([]goslide.Device, error) {
+	return lookup(s, devices, indexClient, clientID)
+}
+
+func (s *Store) Agents() ([]goslide.Agent, error) {
+	return all(s, agents)
+}
+
+func (s *Store) Agent(agentID string) (goslide.Agent, error) {
+	return get(s, agents, agentID)
+}
+
+func (s *Store) AgentsByClient(clientID string) ([]goslide.Agent, error) {
+	return lookup(s, agents, indexClient, clientID)
+}
+
+func (s *Store) AgentsByDevice(deviceID string) ([]goslide.Agent, error) {
+	return lookup(s, agents, indexDevice, deviceID)
+}
+
+func (s *Store) Backups() ([]goslide.Backup, error) {
+	return all(s, backups)
+}
+
+func (s *Store) Backup(backupID string) (goslide.Backup, error) {
+	return get(s, backups, backupID)
+}
+
+func (s *Store) BackupsByAgent(agentID string) ([]goslide.Backup, error) {
+	return lookup(s, backups, indexAgent, agentID)
+}
+
+func (s *Store) Snapshots() ([]goslide.Snapshot, error) {
+	return all(s, snapshots)
+}
+
+func (s *Store) Snapshot(snapshotID string) (goslide.Snapshot, error) {
+	return get(s, snapshots, snapshotID)
+}
+
+func (s *Store) SnapshotsByAgent(agentID string) ([]goslide.Snapshot, error) {
+	return lookup(s, snapshots, indexAgent, agentID)
+}
+
+// SnapshotsByDevice returns the snapshots with a location on the device.
+func (s *Store) SnapshotsByDevice(deviceID string) ([]goslide.Snapshot, error) {
+	return lookup(s, snapshots, indexDevice, deviceID)
+}
+
+// Alerts returns every alert, including the resolved ones.
+func (s *Store) Alerts() ([]goslide.Alert, error) {
+	return all(s, alerts)
+}
+
+func (s *Store) Alert(alertID string) (goslide.Alert, error) {
+	return get(s, alerts, alertID)
+}
+
+func (s *Store) AlertsByDevice(deviceID string) ([]goslide.Alert, error) {
+	return lookup(s, alerts, indexDevice, deviceID)
+}
+
+func (s *Store) AlertsByAgent(agentID string) ([]goslide.Alert, error) {
+	return lookup(s, alerts, indexAgent, agentID)
+}
+
+func (s *Store) Networks() ([]goslide.Network, error) {
+	return all(s, networks)
+}
+
+func (s *Store) Network(networkID string) (goslide.Network, error) {
+	return get(s, networks, networkID)
+}
+
+func (s *Store) NetworksByClient(clientID string) ([]goslide.Network, error) {
+	return lookup(s, networks, indexClient, clientID)
+}
+
+func (s *Store) Users() ([]goslide.User, error) {
+	return all(s, users)
+}
+
+func (s *Store) User(userID string) (goslide.User, error) {
+	return get(s, users, userID)
+}
+
+func (s *Store) FileRestores() ([]goslide.FileRestore, error) {
+	return all(s, fileRestores)
+}
+
+func (s *Store) FileRestore(fileRestoreID string) (goslide.FileRestore, error) {
+	return get(s, fileRestores, fileRestoreID)
+}
+
+func (s *Store) FileRestoresByDevice(deviceID string) ([]goslide.FileRestore, error) {
+	return lookup(s, fileRestores, indexDevice, deviceID)
+}
+
+func (s *Store) FileRestoresByAgent(agentID string) ([]goslide.FileRestore, error) {
+	return lookup(s, fileRestores, indexAgent, agentID)
+}
+
+func (s *Store) ImageExportRestores() ([]goslide.ImageExportRestore, error) {
+	return all(s, imageExports)
+}
+
+func (s *Store) ImageExportRestore(imageExportID string) (goslide.ImageExportRestore, error) {
+	return get(s, imageExports, imageExportID)
+}
+
+func (s *Store) ImageExportRestoresByDevice(deviceID string) ([]goslide.ImageExportRestore, error) {
+	return lookup(s, imageExports, indexDevice, deviceID)
+}
+
+func (s *Store) ImageExportRestoresByAgent(agentID string) ([]goslide.ImageExportRestore, error) {
+	return lookup(s, imageExports, indexAgent, agentID)
+}
+
+func (s *Store) VirtualMachineRestores() ([]goslide.VirtualMachineRestore, error) {
+	return all(s, virtualMachines)
+}
+
+func (s *Store) VirtualMachineRestore(virtID string) (goslide.VirtualMachineRestore, error) {
+	return get(s, virtualMachines, virtID)
+}
+
+func (s *Store) VirtualMachineRestoresByDevice(deviceID string) ([]goslide.VirtualMachineRestore, error) {
+	return lookup(s, virtualMachines, indexDevice, deviceID)
+}
+
+func (s *Store) VirtualMachineRestoresByAgent(agentID string) ([]goslide.VirtualMachineRestore, error) {
+	return lookup(s, virtualMachines, indexAgent, agentID)
+}
@@ -0,0 +1,410 @@
+// Package mirror keeps a local copy of the metadata of a Slide account in a
+// bbolt file. A Syncer refreshes it, and the Store answers queries with the
+// goslide types, so tools can read it without reaching the API.
+//
+// Backups, snapshots and alerts are only ever added to, so they are listed
+// newest first and a sync stops at the first record the mirror already has.
+// The other resources are small and change in place, so each of their syncs
+// lists the whole resource and compares it with the mirror. Either way only
+// the differences are written.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ErrNotFound = errors.New("not found in mirror")
+
+// syncBucket holds the SyncState of each resource.
+var syncBucket = []byte("sync")
+
+// openTimeout bounds the wait for the file lock, which is held by the
+// process that has the mirror open for writing.
+const openTimeout = time.Second
+
+// SyncState is the outcome of the last sync of a resource.
+type SyncState struct {
+	// SyncedAt is when the resource was last synced successfully, the zero
+	// time if it never was
+	SyncedAt time.Time `json:"synced_at"`
+
+	// AttemptedAt and Error describe the last attempt, which may have failed
+	// after SyncedAt
+	AttemptedAt time.Time `json:"attempted_at"`
+	Error       string    `json:"error,omitempty"`
+
+	// FullSyncedAt is when the whole resource was last listed, which for
+	// resources synced incrementally is less often than SyncedAt
+	FullSyncedAt time.Time `json:"full_synced_at"`
+
+	// Records is the number of records mirrored, and the other counts are
+	// the changes written by the last successful sync
+	Records int `json:"records"`
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+}
+
+// Store is a mirror file. Records are kept as JSON in a bucket per resource,
+// with index buckets for the lookups by client, device and agent.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the mirror at path, creating it if needed. Only one process can
+// have a mirror open at a time.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open mirror %s - %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SyncState returns the state of a resource, the zero value if it was never
+// synced.
+func (s *Store) SyncState(resource Resource) (SyncState, error) {
+	state := SyncState{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return loadSyncState(tx, resource, &state)
+	})
+
+	return state, err
+}
+
+func loadSyncState(tx *bolt.Tx, resource Resource, state *SyncState) error {
+	bucket := tx.Bucket(syncBucket)
+	if bucket == nil {
+		return nil
+	}
+
+	data := bucket.Get([]byte(resource))
+	if data == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, state)
+}
+
+func saveSyncState(tx *bolt.Tx, resource Resource, state SyncState) error {
+	bucket, err := tx.CreateBucketIfNotExists(syncBucket)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(resource), data)
+}
+
+// recordSyncFailure keeps the records and counts of the last successful sync.
+func (s *Store) recordSyncFailure(resource Resource, attemptedAt time.Time, syncErr error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		state := SyncState{}
+		if err := loadSyncState(tx, resource, &state); err != nil {
+			return err
+		}
+
+		state.AttemptedAt = attemptedAt
+		state.Error = syncErr.Error()
+
+		return saveSyncState(tx, resource, state)
+	})
+}
+
+// errCaughtUp stops an incremental listing at the records already mirrored.
+var errCaughtUp = errors.New("caught up with the mirror")
+
+// sync writes the differences between the API and the mirror in a single
+// transaction: changed records are replaced and new ones added. A full sync
+// lists the whole resource and also removes the records the API no longer
+// returns. An incremental sync lists the newest records until a page holds
+// one that is mirrored unchanged, and is done for resources with listNewest
+// until fullSyncInterval has passed since the last full sync. A failed list
+// leaves the mirrored records untouched.
+func (t *table[T]) sync(ctx context.Context, service goslide.Service, store *Store, now func() time.Time, fullSyncInterval time.Duration) error {
+	attemptedAt := now()
+
+	last, err := store.SyncState(t.resource)
+	if err != nil {
+		return err
+	}
+
+	incremental := t.listNewest != nil && !last.FullSyncedAt.IsZero() && attemptedAt.Sub(last.FullSyncedAt) < fullSyncInterval
+
+	type entry struct {
+		record T
+		data   []byte
+	}
+
+	listed := map[string]entry{}
+	pageHandler := func(response goslide.ListResponse[T]) error {
+		ids := []string{}
+		for _, record := range response.Data {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+
+			id := t.id(record)
+			listed[id] = entry{record: record, data: data}
+			ids = append(ids, id)
+		}
+
+		if !incremental {
+			return nil
+		}
+
+		caughtUp := false
+		if err := store.db.View(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket([]byte(t.resource))
+			for _, id := range ids {
+				if bucket != nil && bytes.Equal(bucket.Get([]byte(id)), listed[id].data) {
+					caughtUp = true
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if caughtUp {
+			return errCaughtUp
+		}
+
+		return nil
+	}
+
+	if incremental {
+		err = t.listNewest(ctx, service, pageHandler)
+		if errors.Is(err, errCaughtUp) {
+			err = nil
+		}
+	} else {
+		err = t.list(ctx, service, pageHandler)
+	}
+	if err != nil {
+		err = fmt.Errorf("unable to sync %s - %w", t.resource, err)
+
+		return errors.Join(err, store.recordSyncFailure(t.resource, attemptedAt, err))
+	}
+
+	return store.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(t.resource))
+		if err != nil {
+			return err
+		}
+
+		state := SyncState{SyncedAt: attemptedAt, AttemptedAt: attemptedAt, FullSyncedAt: attemptedAt, Records: len(listed)}
+
+		// An incremental sync adds to the records counted by the last full sync
+		if incremental {
+			state.FullSyncedAt = last.FullSyncedAt
+			state.Records = last.Records
+		} else {
+			removed := [][]byte{}
+			if err := bucket.ForEach(func(id, data []byte) error {
+				if _, ok := listed[string(id)]; !ok {
+					removed = append(removed, bytes.Clone(id))
+				}
+
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			for _, id := range removed {
+				if err := t.unindex(tx, string(id), bucket.Get(id)); err != nil {
+					return err
+				}
+
+				if err := bucket.Delete(id); err != nil {
+					return err
+				}
+
+				state.Removed++
+			}
+		}
+
+		for id, entry := range listed {
+			previous := bucket.Get([]byte(id))
+			if bytes.Equal(previous, entry.data) {
+				continue
+			}
+
+			if previous != nil {
+				if err := t.unindex(tx, id, previous); err != nil {
+					return err
+				}
+
+				state.Updated++
+			} else {
+				state.Added++
+
+				if incremental {
+					state.Records++
+				}
+			}
+
+			if err := bucket.Put([]byte(id), entry.data); err != nil {
+				return err
+			}
+
+			if err := t.index(tx, id, entry.record); err != nil {
+				return err
+			}
+		}
+
+		return saveSyncState(tx, t.resource, state)
+	})
+}
+
+// indexKey orders index entries by related ID, so a lookup is a prefix scan.
+func indexKey(relatedID, id string) []byte {
+	return []byte(relatedID + "\x00" + id)
+}
+
+func (t *table[T]) indexBucket(name string) []byte {
+	return []byte(string(t.resource) + "/" + name)
+}
+
+func (t *table[T]) index(tx *bolt.Tx, id string, record T) error {
+	for name, relatedIDs := range t.indexes {
+		bucket, err := tx.CreateBucketIfNotExists(t.indexBucket(name))
+		if err != nil {
+			return err
+		}
+
+		for _, relatedID := range relatedIDs(record) {
+			if relatedID == "" {
+				continue
+			}
+
+			if err := bucket.Put(indexKey(relatedID, id), nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *table[T]) unindex(tx *bolt.Tx, id string, data []byte) error {
+	var record T
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+
+	for name, relatedIDs := range t.indexes {
+		bucket := tx.Bucket(t.indexBucket(name))
+		if bucket == nil {
+			continue
+		}
+
+		for _, relatedID := range relatedIDs(record) {
+			if err := bucket.Delete(indexKey(relatedID, id)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// get returns a record by ID.
+func get[T any](s *Store, t *table[T], id string) (T, error) {
+	var record T
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(t.resource))
+		if bucket == nil {
+			return ErrNotFound
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		return json.Unmarshal(data, &record)
+	})
+
+	return record, err
+}
+
+// all returns every record, ordered by ID.
+func all[T any](s *Store, t *table[T]) ([]T, error) {
+	records := []T{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(t.resource))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(id, data []byte) error {
+			var record T
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+
+			return nil
+		})
+	})
+
+	return records, err
+}
+
+// lookup returns the records related to an ID through an index, ordered by
+// ID.
+func lookup[T any](s *Store, t *table[T], index, relatedID string) ([]T, error) {
+	records := []T{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(t.resource))
+		indexBucket := tx.Bucket(t.indexBucket(index))
+		if bucket == nil || indexBucket == nil {
+			return nil
+		}
+
+		prefix := indexKey(relatedID, "")
+		cursor := indexBucket.Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+			data := bucket.Get(key[len(prefix):])
+			if data == nil {
+				continue
+			}
+
+			var record T
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		return nil
+	})
+
+	return records, err
+}
@@ -0,0 +1,150 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	defaultSyncInterval     = 15 * time.Minute
+	defaultFullSyncInterval = 24 * time.Hour
+)
+
+// Syncer refreshes a Store from the API. Backups, snapshots and alerts are
+// synced incrementally, with a full sync once per full sync interval to pick
+// up changes to older records, such as a resolved alert or a deleted
+// snapshot, and records that are gone.
+type Syncer struct {
+	service          goslide.Service
+	store            *Store
+	resources        []Resource
+	interval         time.Duration
+	intervals        map[Resource]time.Duration
+	fullSyncInterval time.Duration
+	now              func() time.Time
+}
+
+type syncerOption func(s *Syncer)
+
+// WithSyncInterval sets how often every resource is synced by Run.
+func WithSyncInterval(interval time.Duration) syncerOption {
+	return func(s *Syncer) {
+		s.interval = interval
+	}
+}
+
+// WithResourceInterval syncs a resource on its own schedule, such as
+// snapshots less often than agents.
+func WithResourceInterval(resource Resource, interval time.Duration) syncerOption {
+	return func(s *Syncer) {
+		s.intervals[resource] = interval
+	}
+}
+
+// WithFullSyncInterval sets how often the incrementally synced resources are
+// listed in full, once a day by default.
+func WithFullSyncInterval(interval time.Duration) syncerOption {
+	return func(s *Syncer) {
+		s.fullSyncInterval = interval
+	}
+}
+
+// WithResources limits the sync to some resources. Every resource in
+// Resources is synced by default.
+func WithResources(resources ...Resource) syncerOption {
+	return func(s *Syncer) {
+		s.resources = resources
+	}
+}
+
+func NewSyncer(service goslide.Service, store *Store, options ...syncerOption) *Syncer {
+	syncer := &Syncer{
+		service:          service,
+		store:            store,
+		resources:        Resources,
+		interval:         defaultSyncInterval,
+		intervals:        map[Resource]time.Duration{},
+		fullSyncInterval: defaultFullSyncInterval,
+		now:              time.Now,
+	}
+
+	for _, option := range options {
+		option(syncer)
+	}
+
+	return syncer
+}
+
+// Run syncs the resources that are due until ctx is done. Schedules are
+// based on the SyncState kept in the store, so a restarted syncer only syncs
+// the resources whose interval passed. Failed resources keep their records
+// and are retried at the next tick, their error is kept in the SyncState.
+func (s *Syncer) Run(ctx context.Context) error {
+	tick := s.interval
+	for _, interval := range s.intervals {
+		tick = min(tick, interval)
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		_ = s.syncDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync syncs every resource now. A resource that fails does not stop the
+// others, the errors are joined.
+func (s *Syncer) Sync(ctx context.Context) error {
+	return s.sync(ctx, s.resources)
+}
+
+func (s *Syncer) syncDue(ctx context.Context) error {
+	due := []Resource{}
+	for _, resource := range s.resources {
+		state, err := s.store.SyncState(resource)
+		if err != nil {
+			return err
+		}
+
+		interval, ok := s.intervals[resource]
+		if !ok {
+			interval = s.interval
+		}
+
+		if s.now().Sub(state.SyncedAt) >= interval {
+			due = append(due, resource)
+		}
+	}
+
+	return s.sync(ctx, due)
+}
+
+func (s *Syncer) sync(ctx context.Context, resources []Resource) error {
+	errs := []error{}
+	for _, resource := range Resources {
+		if !slices.Contains(resources, resource) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := tables[resource].sync(ctx, s.service, s.store, s.now, s.fullSyncInterval); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
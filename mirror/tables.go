@@ -0,0 +1,223 @@
+package mirror
+
+import (
+	"context"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+// Resource names a collection kept in the mirror.
+type Resource string
+
+const (
+	Resource_CLIENTS          Resource = "clients"
+	Resource_DEVICES          Resource = "devices"
+	Resource_AGENTS           Resource = "agents"
+	Resource_BACKUPS          Resource = "backups"
+	Resource_SNAPSHOTS        Resource = "snapshots"
+	Resource_ALERTS           Resource = "alerts"
+	Resource_NETWORKS         Resource = "networks"
+	Resource_USERS            Resource = "users"
+	Resource_FILE_RESTORES    Resource = "file_restores"
+	Resource_IMAGE_EXPORTS    Resource = "image_exports"
+	Resource_VIRTUAL_MACHINES Resource = "virtual_machines"
+)
+
+// Resources lists every resource the mirror keeps, in the order they are
+// synced.
+var Resources = []Resource{
+	Resource_CLIENTS,
+	Resource_DEVICES,
+	Resource_AGENTS,
+	Resource_BACKUPS,
+	Resource_SNAPSHOTS,
+	Resource_ALERTS,
+	Resource_NETWORKS,
+	Resource_USERS,
+	Resource_FILE_RESTORES,
+	Resource_IMAGE_EXPORTS,
+	Resource_VIRTUAL_MACHINES,
+}
+
+// Indexes used for lookups by a related ID.
+const (
+	indexClient = "client"
+	indexDevice = "device"
+	indexAgent  = "agent"
+)
+
+// table describes how a resource is listed from the API, keyed and indexed.
+type table[T any] struct {
+	resource Resource
+	id       func(record T) string
+
+	// indexes return the related IDs of a record, by index name
+	indexes map[string]func(record T) []string
+
+	list func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[T]) error) error
+
+	// listNewest lists the resource newest first, for resources that are
+	// only added to and can be synced incrementally
+	listNewest func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[T]) error) error
+}
+
+// syncable is a table of any record type.
+type syncable interface {
+	sync(ctx context.Context, service goslide.Service, store *Store, now func() time.Time, fullSyncInterval time.Duration) error
+}
+
+var clients = &table[goslide.Client]{
+	resource: Resource_CLIENTS,
+	id:       func(client goslide.Client) string { return client.ClientID },
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Client]) error) error {
+		return service.Clients().List(ctx, pageHandler)
+	},
+}
+
+var devices = &table[goslide.Device]{
+	resource: Resource_DEVICES,
+	id:       func(device goslide.Device) string { return device.DeviceID },
+	indexes: map[string]func(device goslide.Device) []string{
+		indexClient: func(device goslide.Device) []string { return []string{device.ClientID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Device]) error) error {
+		return service.Devices().List(ctx, pageHandler)
+	},
+}
+
+var agents = &table[goslide.Agent]{
+	resource: Resource_AGENTS,
+	id:       func(agent goslide.Agent) string { return agent.AgentID },
+	indexes: map[string]func(agent goslide.Agent) []string{
+		indexClient: func(agent goslide.Agent) []string { return []string{agent.ClientID} },
+		indexDevice: func(agent goslide.Agent) []string { return []string{agent.DeviceID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Agent]) error) error {
+		return service.Agents().List(ctx, pageHandler)
+	},
+}
+
+var backups = &table[goslide.Backup]{
+	resource: Resource_BACKUPS,
+	id:       func(backup goslide.Backup) string { return backup.BackupID },
+	indexes: map[string]func(backup goslide.Backup) []string{
+		indexAgent: func(backup goslide.Backup) []string { return []string{backup.AgentID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Backup]) error) error {
+		return service.Backups().List(ctx, pageHandler)
+	},
+	listNewest: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Backup]) error) error {
+		return service.Backups().ListWithQueryParameters(ctx, pageHandler, goslide.WithSortBy("start_time"), goslide.WithSortDirection(false))
+	},
+}
+
+var snapshots = &table[goslide.Snapshot]{
+	resource: Resource_SNAPSHOTS,
+	id:       func(snapshot goslide.Snapshot) string { return snapshot.SnapshotID },
+	indexes: map[string]func(snapshot goslide.Snapshot) []string{
+		indexAgent: func(snapshot goslide.Snapshot) []string { return []string{snapshot.AgentID} },
+
+		// A snapshot is kept on the devices of its locations
+		indexDevice: func(snapshot goslide.Snapshot) []string {
+			deviceIDs := []string{}
+			for _, location := range snapshot.Locations {
+				deviceIDs = append(deviceIDs, location.DeviceID)
+			}
+
+			return deviceIDs
+		},
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Snapshot]) error) error {
+		return service.Snapshots().List(ctx, pageHandler)
+	},
+	listNewest: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Snapshot]) error) error {
+		return service.Snapshots().ListWithQueryParameters(ctx, pageHandler, goslide.WithSortBy("backup_end_time"), goslide.WithSortDirection(false))
+	},
+}
+
+var alerts = &table[goslide.Alert]{
+	resource: Resource_ALERTS,
+	id:       func(alert goslide.Alert) string { return alert.AlertID },
+	indexes: map[string]func(alert goslide.Alert) []string{
+		indexDevice: func(alert goslide.Alert) []string { return []string{alert.DeviceID} },
+		indexAgent:  func(alert goslide.Alert) []string { return []string{alert.AgentID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Alert]) error) error {
+		// Resolved alerts are mirrored too, so resolving one updates it
+		// rather than removing it
+		return service.Alerts().ListWithQueryParameters(ctx, pageHandler, goslide.WithIncludeResolvedAlerts(true))
+	},
+	listNewest: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Alert]) error) error {
+		return service.Alerts().ListWithQueryParameters(ctx, pageHandler, goslide.WithIncludeResolvedAlerts(true), goslide.WithSortBy("created"), goslide.WithSortDirection(false))
+	},
+}
+
+var networks = &table[goslide.Network]{
+	resource: Resource_NETWORKS,
+	id:       func(network goslide.Network) string { return network.NetworkID },
+	indexes: map[string]func(network goslide.Network) []string{
+		indexClient: func(network goslide.Network) []string { return []string{network.ClientID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.Network]) error) error {
+		return service.Networks().List(ctx, pageHandler)
+	},
+}
+
+var users = &table[goslide.User]{
+	resource: Resource_USERS,
+	id:       func(user goslide.User) string { return user.UserID },
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.User]) error) error {
+		return service.Users().List(ctx, pageHandler)
+	},
+}
+
+var fileRestores = &table[goslide.FileRestore]{
+	resource: Resource_FILE_RESTORES,
+	id:       func(restore goslide.FileRestore) string { return restore.FileRestoreID },
+	indexes: map[string]func(restore goslide.FileRestore) []string{
+		indexDevice: func(restore goslide.FileRestore) []string { return []string{restore.DeviceID} },
+		indexAgent:  func(restore goslide.FileRestore) []string { return []string{restore.AgentID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.FileRestore]) error) error {
+		return service.FileRestores().List(ctx, pageHandler)
+	},
+}
+
+var imageExports = &table[goslide.ImageExportRestore]{
+	resource: Resource_IMAGE_EXPORTS,
+	id:       func(export goslide.ImageExportRestore) string { return export.ImageExportID },
+	indexes: map[string]func(export goslide.ImageExportRestore) []string{
+		indexDevice: func(export goslide.ImageExportRestore) []string { return []string{export.DeviceID} },
+		indexAgent:  func(export goslide.ImageExportRestore) []string { return []string{export.AgentID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.ImageExportRestore]) error) error {
+		return service.ImageExportRestores().List(ctx, pageHandler)
+	},
+}
+
+var virtualMachines = &table[goslide.VirtualMachineRestore]{
+	resource: Resource_VIRTUAL_MACHINES,
+	id:       func(vm goslide.VirtualMachineRestore) string { return vm.VirtID },
+	indexes: map[string]func(vm goslide.VirtualMachineRestore) []string{
+		indexDevice: func(vm goslide.VirtualMachineRestore) []string { return []string{vm.DeviceID} },
+		indexAgent:  func(vm goslide.VirtualMachineRestore) []string { return []string{vm.AgentID} },
+	},
+	list: func(ctx context.Context, service goslide.Service, pageHandler func(response goslide.ListResponse[goslide.VirtualMachineRestore]) error) error {
+		return service.VirtualMachineRestores().List(ctx, pageHandler)
+	},
+}
+
+var tables = map[Resource]syncable{
+	Resource_CLIENTS:          clients,
+	Resource_DEVICES:          devices,
+	Resource_AGENTS:           agents,
+	Resource_BACKUPS:          backups,
+	Resource_SNAPSHOTS:        snapshots,
+	Resource_ALERTS:           alerts,
+	Resource_NETWORKS:         networks,
+	Resource_USERS:            users,
+	Resource_FILE_RESTORES:    fileRestores,
+	Resource_IMAGE_EXPORTS:    imageExports,
+	Resource_VIRTUAL_MACHINES: virtualMachines,
+}
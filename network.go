@@ -16,19 +16,21 @@ type NetworkService struct {
 }
 
 type Network struct {
-	BridgeDeviceID   string              `json:"bridge_device_id"`
-	ClientID         string              `json:"client_id"`
-	Comments         string              `json:"comments"`
-	ConnectedVirtIDs []string            `json:"connected_virt_ids"`
-	DHCP             bool                `json:"dhcp"`
-	DHCPRangeEnd     string              `json:"dhcp_range_end"`
-	DHCPRangeStart   string              `json:"dhcp_range_start"`
-	Internet         bool                `json:"internet"`
-	Name             string              `json:"name"`
-	Nameservers      string              `json:"nameservers"`
-	NetworkID        string              `json:"network_id"`
-	RouterPrefix     string              `json:"router_prefix"`
-	Type             NetworkTypeDisaster `json:"type"`
+	BridgeDeviceID   string               `json:"bridge_device_id"`
+	ClientID         string               `json:"client_id"`
+	Comments         string               `json:"comments"`
+	ConnectedVirtIDs []string             `json:"connected_virt_ids"`
+	DHCP             bool                 `json:"dhcp"`
+	DHCPRangeEnd     string               `json:"dhcp_range_end"`
+	DHCPRangeStart   string               `json:"dhcp_range_start"`
+	Internet         bool                 `json:"internet"`
+	Name             string               `json:"name"`
+	Nameservers      string               `json:"nameservers"`
+	NetworkID        string               `json:"network_id"`
+	PortForwards     []NetworkPortForward `json:"port_forwards"`
+	RouterPrefix     string               `json:"router_prefix"`
+	Type             NetworkTypeDisaster  `json:"type"`
+	WGPeers          []NetworkWGPeer      `json:"wg_peers"`
 }
 
 type NetworkTypeDisaster string
@@ -51,7 +53,12 @@ func (n NetworkService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Network]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := n.requestClient.startPagination(ctx, n.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -100,6 +107,12 @@ func (n NetworkService) ListWithQueryParameters(
 	return nil
 }
 
+// Bool returns a pointer to b, for optional payload fields where false must
+// be sent rather than omitted.
+func Bool(b bool) *bool {
+	return &b
+}
+
 type NetworkCreatePayload struct {
 	Name string              `json:"name"`
 	Type NetworkTypeDisaster `json:"type"`
@@ -107,10 +120,10 @@ type NetworkCreatePayload struct {
 	BridgeDeviceID string `json:"bridge_device_id,omitempty"`
 	ClientID       string `json:"client_id,omitempty"`
 	Comments       string `json:"comments,omitempty"`
-	DHCP           bool   `json:"dhcp,omitempty"`
+	DHCP           *bool  `json:"dhcp,omitempty"`
 	DHCPRangeEnd   string `json:"dhcp_range_end,omitempty"`
 	DHCPRangeStart string `json:"dhcp_range_start,omitempty"`
-	Internet       bool   `json:"internet,omitempty"`
+	Internet       *bool  `json:"internet,omitempty"`
 	Nameservers    string `json:"nameservers,omitempty"`
 	RouterPrefix   string `json:"router_prefix,omitempty"`
 }
@@ -119,16 +132,20 @@ type NetworkUpdatePayload struct {
 	Name           string              `json:"name,omitempty"`
 	Type           NetworkTypeDisaster `json:"type,omitempty"`
 	Comments       string              `json:"comments,omitempty"`
-	DHCP           bool                `json:"dhcp,omitempty"`
+	DHCP           *bool               `json:"dhcp,omitempty"`
 	DHCPRangeEnd   string              `json:"dhcp_range_end,omitempty"`
 	DHCPRangeStart string              `json:"dhcp_range_start,omitempty"`
-	Internet       bool                `json:"internet,omitempty"`
+	Internet       *bool               `json:"internet,omitempty"`
 	Nameservers    string              `json:"nameservers,omitempty"`
 	RouterPrefix   string              `json:"router_prefix,omitempty"`
 }
 
 // https://docs.slide.tech/api/#tag/networks/POST/v1/network
 func (n NetworkService) Create(ctx context.Context, payload NetworkCreatePayload) (Network, error) {
+	if err := payload.Validate(); err != nil {
+		return Network{}, err
+	}
+
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return Network{}, err
@@ -195,6 +212,10 @@ func (n NetworkService) Delete(ctx context.Context, networkID string) error {
 
 // https://docs.slide.tech/api/#tag/networks/PATCH/v1/network/{network_id}
 func (n NetworkService) Update(ctx context.Context, networkID string, payload NetworkUpdatePayload) (Network, error) {
+	if err := payload.Validate(); err != nil {
+		return Network{}, err
+	}
+
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return Network{}, err
@@ -234,6 +255,34 @@ const (
 	NetworkProto_TCP NetworkProto = "tcp"
 )
 
+// ListPortForwards returns the port forwards of a network, which the API
+// includes in the network itself.
+func (n NetworkService) ListPortForwards(ctx context.Context, networkID string) ([]NetworkPortForward, error) {
+	network, err := n.Get(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return network.PortForwards, nil
+}
+
+// GetPortForward returns the port forward of a network matching the protocol
+// and public port. A SlideError with a 404 status is returned if there is none.
+func (n NetworkService) GetPortForward(ctx context.Context, networkID string, proto NetworkProto, port uint) (NetworkPortForward, error) {
+	portForwards, err := n.ListPortForwards(ctx, networkID)
+	if err != nil {
+		return NetworkPortForward{}, err
+	}
+
+	for _, portForward := range portForwards {
+		if portForward.Proto == proto && portForward.Port == port {
+			return portForward, nil
+		}
+	}
+
+	return NetworkPortForward{}, n.notFound(networkID, fmt.Sprintf("port forward %s/%d not found", proto, port))
+}
+
 // https://docs.slide.tech/api/#tag/networks/POST/v1/network/{network_id}/port-forwards
 func (n NetworkService) CreatePortForward(ctx context.Context, networkID string, payload NetworkPortForwardPayload) (NetworkPortForward, error) {
 	payloadBytes, err := json.Marshal(payload)
@@ -262,8 +311,14 @@ func (n NetworkService) CreatePortForward(ctx context.Context, networkID string,
 	return target, nil
 }
 
+type NetworkPortForwardDeletePayload struct {
+	NetworkID string       `json:"network_id"`
+	Proto     NetworkProto `json:"proto"`
+	Port      uint         `json:"port"`
+}
+
 // https://docs.slide.tech/api/#tag/networks/DELETE/v1/network/{network_id}/port-forwards
-func (n NetworkService) DeletePortForward(ctx context.Context, networkID string, payload NetworkPortForwardPayload) error {
+func (n NetworkService) DeletePortForward(ctx context.Context, networkID string, payload NetworkPortForwardDeletePayload) error {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return err
@@ -273,7 +328,7 @@ func (n NetworkService) DeletePortForward(ctx context.Context, networkID string,
 
 	request, err := http.NewRequestWithContext(
 		ctx,
-		http.MethodPost,
+		http.MethodDelete,
 		n.baseEndpoint+"/"+networkID+"/port-forwards",
 		requestBody,
 	)
@@ -290,30 +345,31 @@ func (n NetworkService) DeletePortForward(ctx context.Context, networkID string,
 }
 
 // https://docs.slide.tech/api/#tag/networks/PATCH/v1/network/{network_id}/port-forwards
-func (n NetworkService) UpdatePortForward(ctx context.Context, networkID string, payload NetworkPortForwardUpdatePayload) error {
+func (n NetworkService) UpdatePortForward(ctx context.Context, networkID string, payload NetworkPortForwardUpdatePayload) (NetworkPortForward, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return NetworkPortForward{}, err
 	}
 
 	requestBody := bytes.NewReader(payloadBytes)
 
 	request, err := http.NewRequestWithContext(
 		ctx,
-		http.MethodPost,
+		http.MethodPatch,
 		n.baseEndpoint+"/"+networkID+"/port-forwards",
 		requestBody,
 	)
 
 	if err != nil {
-		return err
+		return NetworkPortForward{}, err
 	}
 
-	if err := n.requestClient.SlideRequest(request, nil); err != nil {
-		return err
+	target := NetworkPortForward{}
+	if err := n.requestClient.SlideRequest(request, &target); err != nil {
+		return NetworkPortForward{}, err
 	}
 
-	return nil
+	return target, nil
 }
 
 type NetworkPortForwardUpdatePayload struct {
@@ -346,6 +402,41 @@ type NetworkWGPeerCreatePayload struct {
 	RemoteNetworks []string `json:"remote_networks"`
 }
 
+type NetworkWGPeerUpdatePayload struct {
+	NetworkID      string   `json:"network_id"`
+	WGAddress      string   `json:"wg_address"`
+	PeerName       string   `json:"peer_name,omitempty"`
+	RemoteNetworks []string `json:"remote_networks,omitempty"`
+}
+
+// ListWGPeers returns the WireGuard peers of a network, which the API
+// includes in the network itself.
+func (n NetworkService) ListWGPeers(ctx context.Context, networkID string) ([]NetworkWGPeer, error) {
+	network, err := n.Get(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return network.WGPeers, nil
+}
+
+// GetWGPeer returns the WireGuard peer of a network with the given address.
+// A SlideError with a 404 status is returned if there is none.
+func (n NetworkService) GetWGPeer(ctx context.Context, networkID, wgAddress string) (NetworkWGPeer, error) {
+	peers, err := n.ListWGPeers(ctx, networkID)
+	if err != nil {
+		return NetworkWGPeer{}, err
+	}
+
+	for _, peer := range peers {
+		if peer.WGAddress == wgAddress {
+			return peer, nil
+		}
+	}
+
+	return NetworkWGPeer{}, n.notFound(networkID, fmt.Sprintf("WireGuard peer %s not found", wgAddress))
+}
+
 // https://docs.slide.tech/api/#tag/networks/POST/v1/network/{network_id}/wg-peers
 func (n NetworkService) CreateWGPeer(ctx context.Context, networkID string, payload NetworkWGPeerCreatePayload) (NetworkWGPeer, error) {
 	payloadBytes, err := json.Marshal(payload)
@@ -395,7 +486,7 @@ func (n NetworkService) DeleteWGPeer(ctx context.Context, networkID, wgAddress s
 
 	request, err := http.NewRequestWithContext(
 		ctx,
-		http.MethodPost,
+		http.MethodDelete,
 		n.baseEndpoint+"/"+networkID+"/wg-peers",
 		requestBody,
 	)
@@ -412,7 +503,7 @@ func (n NetworkService) DeleteWGPeer(ctx context.Context, networkID, wgAddress s
 }
 
 // https://docs.slide.tech/api/#tag/networks/PATCH/v1/network/{network_id}/wg-peers
-func (n NetworkService) UpdateWGPeer(ctx context.Context, networkID string, payload NetworkPortForwardUpdatePayload) (NetworkWGPeer, error) {
+func (n NetworkService) UpdateWGPeer(ctx context.Context, networkID string, payload NetworkWGPeerUpdatePayload) (NetworkWGPeer, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return NetworkWGPeer{}, err
@@ -422,7 +513,7 @@ func (n NetworkService) UpdateWGPeer(ctx context.Context, networkID string, payl
 
 	request, err := http.NewRequestWithContext(
 		ctx,
-		http.MethodPost,
+		http.MethodPatch,
 		n.baseEndpoint+"/"+networkID+"/wg-peers",
 		requestBody,
 	)
@@ -438,3 +529,16 @@ func (n NetworkService) UpdateWGPeer(ctx context.Context, networkID string, payl
 
 	return target, nil
 }
+
+// notFound mirrors the error the API returns for a missing entity, for
+// lookups that are answered from the network rather than their own endpoint.
+func (n NetworkService) notFound(networkID, message string) error {
+	return &SlideError{
+		HTTPStatusCode:    http.StatusNotFound,
+		HTTPRequestPath:   n.baseEndpoint + "/" + networkID,
+		HTTPRequestMethod: http.MethodGet,
+		Codes:             []APIErrorCode{APIErrorCode_ERR_ENTITY_NOT_FOUND},
+		Details:           []string{},
+		Message:           message,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -223,8 +224,58 @@ func TestNetwork_Create(t *testing.T) {
 	ctx := context.Background()
 
 	actual, err := testService.Networks().Create(ctx, goslide.NetworkCreatePayload{
-		Name: "Bridge to LAN Network",
-		Type: goslide.NetworkTypeDisaster_BRIDGE_LAN,
+		Name:           "Bridge to LAN Network",
+		Type:           goslide.NetworkTypeDisaster_BRIDGE_LAN,
+		BridgeDeviceID: "d_0123456789ab",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNetwork_CreatePortForward(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusCreated,
+							FilePath:   "testdata/responses/network/create_network_port_forward_201.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodPost,
+							Path:      "/v1/network/" + networkID + "/port-forwards",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/create_network_port_forward_201.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	expected := goslide.NetworkPortForward{
+		Dest:      "10.0.0.100:80",
+		NetworkID: networkID,
+		Port:      25881,
+		Proto:     goslide.NetworkProto_TCP,
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().CreatePortForward(ctx, networkID, goslide.NetworkPortForwardPayload{
+		Dest:      "10.0.0.100:80",
+		NetworkID: networkID,
+		Proto:     goslide.NetworkProto_TCP,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -234,3 +285,376 @@ func TestNetwork_Create(t *testing.T) {
 		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
 	}
 }
+
+func TestNetwork_UpdatePortForward(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusOK,
+							FilePath:   "testdata/responses/network/update_network_port_forward_201.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodPatch,
+							Path:      "/v1/network/" + networkID + "/port-forwards",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/update_network_port_forward_201.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	expected := goslide.NetworkPortForward{
+		Dest:      "10.0.0.101:8080",
+		NetworkID: networkID,
+		Port:      25881,
+		Proto:     goslide.NetworkProto_UDP,
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().UpdatePortForward(ctx, networkID, goslide.NetworkPortForwardUpdatePayload{
+		Dest:      "10.0.0.101:8080",
+		NetworkID: networkID,
+		Port:      25881,
+		ProtoNew:  goslide.NetworkProto_UDP,
+		ProtoOld:  goslide.NetworkProto_TCP,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNetwork_DeletePortForward(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseNoContent{
+							StatusCode: http.StatusNoContent,
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodDelete,
+							Path:      "/v1/network/" + networkID + "/port-forwards",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/delete_network_port_forward_204.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	if err := testService.Networks().DeletePortForward(ctx, networkID, goslide.NetworkPortForwardDeletePayload{
+		NetworkID: networkID,
+		Proto:     goslide.NetworkProto_TCP,
+		Port:      25881,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNetwork_ListPortForwards(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusOK,
+							FilePath:   "testdata/responses/network/get_with_port_forwards_and_wg_peers_200.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method: http.MethodGet,
+							Path:   "/v1/network/" + networkID,
+							Query:  url.Values{},
+						},
+					),
+				},
+			),
+		),
+	)
+
+	expected := []goslide.NetworkPortForward{
+		{
+			Dest:      "10.0.0.100:80",
+			NetworkID: networkID,
+			Port:      25881,
+			Proto:     goslide.NetworkProto_TCP,
+		},
+		{
+			Dest:      "10.0.0.100:53",
+			NetworkID: networkID,
+			Port:      25882,
+			Proto:     goslide.NetworkProto_UDP,
+		},
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().ListPortForwards(ctx, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNetwork_GetPortForward(t *testing.T) {
+	networkID := "net_012345"
+
+	serveNetwork := roundtripper.ServeAndValidate(
+		t,
+		&roundtripper.TestResponseFile{
+			StatusCode: http.StatusOK,
+			FilePath:   "testdata/responses/network/get_with_port_forwards_and_wg_peers_200.json",
+		},
+		roundtripper.ExpectedTestRequest{
+			Method: http.MethodGet,
+			Path:   "/v1/network/" + networkID,
+			Query:  url.Values{},
+		},
+	)
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveNetwork,
+					serveNetwork,
+				},
+			),
+		),
+	)
+
+	expected := goslide.NetworkPortForward{
+		Dest:      "10.0.0.100:53",
+		NetworkID: networkID,
+		Port:      25882,
+		Proto:     goslide.NetworkProto_UDP,
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().GetPortForward(ctx, networkID, goslide.NetworkProto_UDP, 25882)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// The port exists, but not for this protocol
+	_, err = testService.Networks().GetPortForward(ctx, networkID, goslide.NetworkProto_TCP, 25882)
+
+	slideError := &goslide.SlideError{}
+	if !errors.As(err, &slideError) || slideError.HTTPStatusCode != http.StatusNotFound {
+		t.Fatalf("%s expected a not found SlideError, got %v", t.Name(), err)
+	}
+}
+
+func TestNetwork_CreateWGPeer(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusCreated,
+							FilePath:   "testdata/responses/network/create_network_wg_peer_201.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodPost,
+							Path:      "/v1/network/" + networkID + "/wg-peers",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/create_network_wg_peer_201.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	expected := goslide.NetworkWGPeer{
+		NetworkID:      networkID,
+		PeerName:       "My PC",
+		RemoteNetworks: []string{"10.80.0.0/24"},
+		WGAddress:      "10.0.0.100",
+		WGPrivateKey:   "INRPOJHOzuMiRzim2tzzU2OU7Z3jNOIS4HRVA32NzUY",
+		WGPublicKey:    "buRAPDIX5SMlPlzLsLO8WVaWe+1+FXf+TKk26e4Vkm8=",
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().CreateWGPeer(ctx, networkID, goslide.NetworkWGPeerCreatePayload{
+		NetworkID:      networkID,
+		PeerName:       "My Bridge to LAN Network",
+		RemoteNetworks: []string{"10.80.0.0/24", "10.80.1.0/24"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNetwork_UpdateWGPeer(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusOK,
+							FilePath:   "testdata/responses/network/update_network_wg_peer_201.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodPatch,
+							Path:      "/v1/network/" + networkID + "/wg-peers",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/update_network_wg_peer_201.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	expected := goslide.NetworkWGPeer{
+		NetworkID:      networkID,
+		PeerName:       "My PC",
+		RemoteNetworks: []string{"10.80.0.0/24"},
+		WGAddress:      "10.0.0.100",
+		WGPrivateKey:   "INRPOJHOzuMiRzim2tzzU2OU7Z3jNOIS4HRVA32NzUY",
+		WGPublicKey:    "buRAPDIX5SMlPlzLsLO8WVaWe+1+FXf+TKk26e4Vkm8=",
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().UpdateWGPeer(ctx, networkID, goslide.NetworkWGPeerUpdatePayload{
+		NetworkID:      networkID,
+		WGAddress:      "10.0.0.100",
+		PeerName:       "My Bridge to LAN Network",
+		RemoteNetworks: []string{"10.80.0.0/24", "10.80.1.0/24"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNetwork_DeleteWGPeer(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseNoContent{
+							StatusCode: http.StatusNoContent,
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodDelete,
+							Path:      "/v1/network/" + networkID + "/wg-peers",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/delete_network_wg_peer_204.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	if err := testService.Networks().DeleteWGPeer(ctx, networkID, "10.0.0.100"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNetwork_GetWGPeer(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusOK,
+							FilePath:   "testdata/responses/network/get_with_port_forwards_and_wg_peers_200.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method: http.MethodGet,
+							Path:   "/v1/network/" + networkID,
+							Query:  url.Values{},
+						},
+					),
+				},
+			),
+		),
+	)
+
+	expected := goslide.NetworkWGPeer{
+		NetworkID:      networkID,
+		PeerName:       "My PC",
+		RemoteNetworks: []string{"10.80.0.0/24"},
+		WGAddress:      "10.0.0.100",
+		WGPrivateKey:   "INRPOJHOzuMiRzim2tzzU2OU7Z3jNOIS4HRVA32NzUY",
+		WGPublicKey:    "buRAPDIX5SMlPlzLsLO8WVaWe+1+FXf+TKk26e4Vkm8=",
+	}
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().GetWGPeer(ctx, networkID, "10.0.0.100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned struct mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
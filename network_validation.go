@@ -0,0 +1,101 @@
+package goslide
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Validate checks the payload before it is sent, returning a *ValidationError
+// listing every invalid field.
+func (p NetworkCreatePayload) Validate() error {
+	validationError := &ValidationError{}
+
+	if strings.TrimSpace(p.Name) == "" {
+		validationError.add("name", "is required")
+	}
+
+	switch p.Type {
+	case NetworkTypeDisaster_STANDARD:
+	case NetworkTypeDisaster_BRIDGE_LAN:
+		if p.BridgeDeviceID == "" {
+			validationError.add("bridge_device_id", "is required for bridge-lan networks")
+		}
+	case "":
+		validationError.add("type", "is required")
+	default:
+		validationError.add("type", "must be standard or bridge-lan, got "+string(p.Type))
+	}
+
+	validateAddressing(validationError, p.RouterPrefix, p.DHCPRangeStart, p.DHCPRangeEnd, p.Nameservers)
+
+	return validationError.err()
+}
+
+// Validate checks the fields set on the payload before it is sent, returning
+// a *ValidationError listing every invalid field. A DHCP range can only be
+// checked against the router prefix when both are being updated.
+func (p NetworkUpdatePayload) Validate() error {
+	validationError := &ValidationError{}
+
+	if p.Name != "" && strings.TrimSpace(p.Name) == "" {
+		validationError.add("name", "must not be blank")
+	}
+
+	switch p.Type {
+	case "", NetworkTypeDisaster_STANDARD, NetworkTypeDisaster_BRIDGE_LAN:
+	default:
+		validationError.add("type", "must be standard or bridge-lan, got "+string(p.Type))
+	}
+
+	validateAddressing(validationError, p.RouterPrefix, p.DHCPRangeStart, p.DHCPRangeEnd, p.Nameservers)
+
+	return validationError.err()
+}
+
+func validateAddressing(validationError *ValidationError, routerPrefix, rangeStart, rangeEnd, nameservers string) {
+	var prefix netip.Prefix
+	if routerPrefix != "" {
+		parsed, err := netip.ParsePrefix(routerPrefix)
+		if err != nil {
+			validationError.add("router_prefix", "must be a CIDR such as 10.0.0.1/24, got "+routerPrefix)
+		} else {
+			prefix = parsed
+		}
+	}
+
+	start := validateRangeAddress(validationError, "dhcp_range_start", rangeStart, prefix)
+	end := validateRangeAddress(validationError, "dhcp_range_end", rangeEnd, prefix)
+
+	if start.IsValid() && end.IsValid() && end.Less(start) {
+		validationError.add("dhcp_range_end", "must not be before dhcp_range_start")
+	}
+
+	if nameservers != "" {
+		for _, nameserver := range strings.Split(nameservers, ",") {
+			if _, err := netip.ParseAddr(strings.TrimSpace(nameserver)); err != nil {
+				validationError.add("nameservers", "must be a comma separated list of IP addresses, got "+nameservers)
+
+				break
+			}
+		}
+	}
+}
+
+func validateRangeAddress(validationError *ValidationError, field, value string, prefix netip.Prefix) netip.Addr {
+	if value == "" {
+		return netip.Addr{}
+	}
+
+	address, err := netip.ParseAddr(value)
+	if err != nil {
+		validationError.add(field, "must be an IP address, got "+value)
+
+		return netip.Addr{}
+	}
+
+	if prefix.IsValid() && !prefix.Contains(address) {
+		validationError.add(field, "must be inside router_prefix "+prefix.String())
+	}
+
+	return address
+}
@@ -0,0 +1,175 @@
+package goslide_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNetworkCreatePayload_Validate(t *testing.T) {
+	testCases := map[string]struct {
+		payload  goslide.NetworkCreatePayload
+		expected []goslide.FieldError
+	}{
+		"valid standard network": {
+			payload: goslide.NetworkCreatePayload{
+				Name:           "Standard Network",
+				Type:           goslide.NetworkTypeDisaster_STANDARD,
+				RouterPrefix:   "10.0.0.1/24",
+				DHCP:           goslide.Bool(true),
+				DHCPRangeStart: "10.0.0.100",
+				DHCPRangeEnd:   "10.0.0.200",
+				Nameservers:    "1.1.1.1, 2606:4700:4700::1111",
+			},
+		},
+		"bridge without device": {
+			payload: goslide.NetworkCreatePayload{
+				Name: "Bridge to LAN Network",
+				Type: goslide.NetworkTypeDisaster_BRIDGE_LAN,
+			},
+			expected: []goslide.FieldError{
+				{Field: "bridge_device_id", Message: "is required for bridge-lan networks"},
+			},
+		},
+		"every field invalid": {
+			payload: goslide.NetworkCreatePayload{
+				Name:           " ",
+				RouterPrefix:   "10.0.0.1",
+				DHCPRangeStart: "10.0.0.300",
+				DHCPRangeEnd:   "10.0.0.200",
+				Nameservers:    "1.1.1.1,dns.example",
+			},
+			expected: []goslide.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "type", Message: "is required"},
+				{Field: "router_prefix", Message: "must be a CIDR such as 10.0.0.1/24, got 10.0.0.1"},
+				{Field: "dhcp_range_start", Message: "must be an IP address, got 10.0.0.300"},
+				{Field: "nameservers", Message: "must be a comma separated list of IP addresses, got 1.1.1.1,dns.example"},
+			},
+		},
+		"range outside prefix and reversed": {
+			payload: goslide.NetworkCreatePayload{
+				Name:           "Standard Network",
+				Type:           goslide.NetworkTypeDisaster_STANDARD,
+				RouterPrefix:   "10.0.0.1/24",
+				DHCPRangeStart: "10.0.1.100",
+				DHCPRangeEnd:   "10.0.0.200",
+			},
+			expected: []goslide.FieldError{
+				{Field: "dhcp_range_start", Message: "must be inside router_prefix 10.0.0.1/24"},
+				{Field: "dhcp_range_end", Message: "must not be before dhcp_range_start"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := testCase.payload.Validate()
+			if testCase.expected == nil {
+				if err != nil {
+					t.Fatalf("%s expected no error, got %v", t.Name(), err)
+				}
+
+				return
+			}
+
+			validationError := &goslide.ValidationError{}
+			if !errors.As(err, &validationError) {
+				t.Fatalf("%s expected a ValidationError, got %v", t.Name(), err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, validationError.Fields); diff != "" {
+				t.Fatalf("%s Field errors mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+}
+
+func TestNetworkUpdatePayload_Validate(t *testing.T) {
+	if err := (goslide.NetworkUpdatePayload{DHCP: goslide.Bool(false)}).Validate(); err != nil {
+		t.Fatalf("%s expected a partial update to be valid, got %v", t.Name(), err)
+	}
+
+	err := goslide.NetworkUpdatePayload{
+		Type:           "bridge",
+		DHCPRangeStart: "10.0.0.200",
+		DHCPRangeEnd:   "10.0.0.100",
+	}.Validate()
+
+	validationError := &goslide.ValidationError{}
+	if !errors.As(err, &validationError) {
+		t.Fatalf("%s expected a ValidationError, got %v", t.Name(), err)
+	}
+
+	expected := []goslide.FieldError{
+		{Field: "type", Message: "must be standard or bridge-lan, got bridge"},
+		{Field: "dhcp_range_end", Message: "must not be before dhcp_range_start"},
+	}
+
+	if diff := cmp.Diff(expected, validationError.Fields); diff != "" {
+		t.Fatalf("%s Field errors mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNetwork_Create_Invalid(t *testing.T) {
+	// No responses are queued, so any request sent fails the test
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(t, []roundtripper.TestRoundTripFunc{}),
+		),
+	)
+
+	_, err := testService.Networks().Create(context.Background(), goslide.NetworkCreatePayload{})
+
+	validationError := &goslide.ValidationError{}
+	if !errors.As(err, &validationError) {
+		t.Fatalf("%s expected a ValidationError, got %v", t.Name(), err)
+	}
+}
+
+func TestNetwork_Update_DisableDHCPAndInternet(t *testing.T) {
+	networkID := "net_012345"
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusOK,
+							FilePath:   "testdata/responses/network/update_disable_dhcp_and_internet_200.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodPatch,
+							Path:      "/v1/network/" + networkID,
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/network/update_disable_dhcp_and_internet_200.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+
+	actual, err := testService.Networks().Update(ctx, networkID, goslide.NetworkUpdatePayload{
+		DHCP:     goslide.Bool(false),
+		Internet: goslide.Bool(false),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.DHCP || actual.Internet {
+		t.Fatalf("%s expected DHCP and internet to be disabled, got %+v", t.Name(), actual)
+	}
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// DeadLetter is a line of the dead-letter file.
+type DeadLetter struct {
+	FailedAt time.Time `json:"failed_at"`
+	Sink     string    `json:"sink"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Message  Message   `json:"message"`
+}
+
+func (d *Dispatcher) deadLetter(sink string, attempts int, sendErr error, message Message) error {
+	line, err := json.Marshal(DeadLetter{
+		FailedAt: d.now().UTC(),
+		Sink:     sink,
+		Attempts: attempts,
+		Error:    sendErr.Error(),
+		Message:  message,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.OpenFile(d.deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+)
+
+type Severity int
+
+const (
+	Severity_INFO Severity = iota
+	Severity_WARNING
+	Severity_CRITICAL
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Severity_CRITICAL:
+		return "critical"
+	case Severity_WARNING:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// MarshalText lets severities be written by name in JSON.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*s = Severity_INFO
+	case "warning":
+		*s = Severity_WARNING
+	case "critical":
+		*s = Severity_CRITICAL
+	default:
+		return fmt.Errorf("unknown severity %q", text)
+	}
+
+	return nil
+}
+
+// SeverityOf rates an alert type. Unknown alert types are warnings.
+func SeverityOf(alertType goslide.AlertType) Severity {
+	switch alertType {
+	case goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL,
+		goslide.AlertType_DEVICE_STORAGE_NOT_HEALTHY,
+		goslide.AlertType_AGENT_BACKUP_FAILED:
+		return Severity_CRITICAL
+	case goslide.AlertType_DEVICE_OUT_OF_DATE:
+		return Severity_INFO
+	default:
+		return Severity_WARNING
+	}
+}
+
+// Message is a rendered notification, as handed to every Sink.
+type Message struct {
+	EventType  alertwatch.EventType `json:"event_type"`
+	Alert      goslide.Alert        `json:"alert"`
+	Severity   Severity             `json:"severity"`
+	ClientID   string               `json:"client_id"`
+	DeviceName string               `json:"device_name"`
+	AgentName  string               `json:"agent_name"`
+	ObservedAt time.Time            `json:"observed_at"`
+
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+var titles = map[goslide.AlertType]string{
+	goslide.AlertType_DEVICE_NOT_CHECKING_IN:        "Device not checking in",
+	goslide.AlertType_DEVICE_OUT_OF_DATE:            "Device out of date",
+	goslide.AlertType_DEVICE_STORAGE_NOT_HEALTHY:    "Device storage not healthy",
+	goslide.AlertType_DEVICE_STORAGE_SPACE_LOW:      "Device storage space low",
+	goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL: "Device storage space critical",
+	goslide.AlertType_AGENT_NOT_CHECKING_IN:         "Agent not checking in",
+	goslide.AlertType_AGENT_NOT_BACKING_UP:          "Agent not backing up",
+	goslide.AlertType_AGENT_BACKUP_FAILED:           "Agent backup failed",
+}
+
+// Title is a readable name for an alert type.
+func Title(alertType goslide.AlertType) string {
+	if title, ok := titles[alertType]; ok {
+		return title
+	}
+
+	return strings.ReplaceAll(string(alertType), "_", " ")
+}
+
+var templateFuncs = template.FuncMap{
+	"title": Title,
+}
+
+// DefaultSubject and DefaultText are used unless WithTemplates overrides them.
+// Both are executed with a Message.
+const (
+	DefaultSubject = `[{{ .Severity }}] {{ if eq .EventType "alert_resolved" }}Resolved: {{ else if eq .EventType "alert_reopened" }}Reopened: {{ end }}{{ title .Alert.AlertType }}{{ with .DeviceName }} on {{ . }}{{ end }}`
+	DefaultText    = `{{ title .Alert.AlertType }}
+{{- with .AgentName }}
+Agent: {{ . }}{{ end }}
+{{- with .DeviceName }}
+Device: {{ . }}{{ end }}
+Alert: {{ .Alert.AlertID }}
+Raised: {{ .Alert.CreatedAt.Format "2006-01-02 15:04 MST" }}
+{{- if .Alert.Resolved }}
+Resolved by: {{ .Alert.ResolvedBy }}{{ end }}`
+)
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func render(tmpl *template.Template, message Message) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, message); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,289 @@
+// Package notify routes alert events to Slack, Microsoft Teams, email and
+// signed webhooks, with quiet hours, deduplication and retries.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+	"github.com/equalsgibson/goslide/internal/owner"
+)
+
+const (
+	defaultDedupWindow   = time.Hour
+	defaultRetryAttempts = 3
+	defaultRetryBackoff  = time.Second
+)
+
+type Dispatcher struct {
+	service goslide.Service
+	sinks   map[string]Sink
+	rules   []Rule
+
+	subjectTemplate string
+	textTemplate    string
+	subject         *template.Template
+	text            *template.Template
+
+	quietHours     *QuietHours
+	dedupWindow    time.Duration
+	retryAttempts  int
+	retryBackoff   time.Duration
+	deadLetterPath string
+	now            func() time.Time
+	owners         *owner.Cache
+
+	mu sync.Mutex
+	// sent holds when a message was last delivered, by alert, event and sink
+	sent map[string]time.Time
+}
+
+type dispatcherOption func(d *Dispatcher)
+
+// WithTemplates replaces the text/template sources of the subject and text,
+// which are executed with a Message.
+func WithTemplates(subject, text string) dispatcherOption {
+	return func(d *Dispatcher) {
+		d.subjectTemplate = subject
+		d.textTemplate = text
+	}
+}
+
+func WithQuietHours(quietHours QuietHours) dispatcherOption {
+	return func(d *Dispatcher) {
+		d.quietHours = &quietHours
+	}
+}
+
+// WithDedupWindow sets how long a delivered message is not sent again to the
+// same sink. Alert watchers deliver events at least once, so the same event
+// can be handled more than once.
+func WithDedupWindow(window time.Duration) dispatcherOption {
+	return func(d *Dispatcher) {
+		d.dedupWindow = window
+	}
+}
+
+// WithRetry sets how often delivery to a sink is attempted, waiting backoff
+// after the first failure and doubling it after every further failure.
+func WithRetry(attempts int, backoff time.Duration) dispatcherOption {
+	return func(d *Dispatcher) {
+		d.retryAttempts = attempts
+		d.retryBackoff = backoff
+	}
+}
+
+// WithDeadLetterFile appends messages that could not be delivered to a JSON
+// lines file, instead of returning an error from Handle.
+func WithDeadLetterFile(path string) dispatcherOption {
+	return func(d *Dispatcher) {
+		d.deadLetterPath = path
+	}
+}
+
+func NewDispatcher(service goslide.Service, sinks map[string]Sink, rules []Rule, options ...dispatcherOption) (*Dispatcher, error) {
+	dispatcher := &Dispatcher{
+		service:         service,
+		sinks:           sinks,
+		rules:           rules,
+		subjectTemplate: DefaultSubject,
+		textTemplate:    DefaultText,
+		dedupWindow:     defaultDedupWindow,
+		retryAttempts:   defaultRetryAttempts,
+		retryBackoff:    defaultRetryBackoff,
+		now:             time.Now,
+		sent:            map[string]time.Time{},
+		owners:          owner.NewCache(service),
+	}
+
+	for _, option := range options {
+		option(dispatcher)
+	}
+
+	errs := []error{}
+	for i, rule := range rules {
+		for _, sink := range rule.Sinks {
+			if _, ok := sinks[sink]; !ok {
+				errs = append(errs, fmt.Errorf("rules[%d] uses unknown sink %q", i, sink))
+			}
+		}
+	}
+
+	var err error
+	if dispatcher.subject, err = parseTemplate("subject", dispatcher.subjectTemplate); err != nil {
+		errs = append(errs, err)
+	}
+
+	if dispatcher.text, err = parseTemplate("text", dispatcher.textTemplate); err != nil {
+		errs = append(errs, err)
+	}
+
+	if dispatcher.quietHours != nil {
+		errs = append(errs, dispatcher.quietHours.validate())
+	}
+
+	if dispatcher.retryAttempts < 1 {
+		errs = append(errs, errors.New("retry attempts must be at least 1"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+
+	return dispatcher, nil
+}
+
+// Handler returns Handle bound to ctx, to pass to alertwatch.Watcher.Run.
+func (d *Dispatcher) Handler(ctx context.Context) func(event alertwatch.Event) error {
+	return func(event alertwatch.Event) error {
+		return d.Handle(ctx, event)
+	}
+}
+
+// Handle notifies the sinks routed to by the event.
+func (d *Dispatcher) Handle(ctx context.Context, event alertwatch.Event) error {
+	message, err := d.message(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	if d.quietHours != nil && d.quietHours.suppresses(now, message.Severity) {
+		return nil
+	}
+
+	errs := []error{}
+	for _, name := range route(d.rules, message) {
+		key := message.Alert.AlertID + "|" + string(message.EventType) + "|" + name
+		if d.delivered(key, now) {
+			continue
+		}
+
+		attempts, err := d.send(ctx, d.sinks[name], message)
+		if err != nil && d.deadLetterPath != "" {
+			err = d.deadLetter(name, attempts, err, message)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %q: %w", name, err))
+
+			continue
+		}
+
+		d.mu.Lock()
+		d.sent[key] = now
+		d.mu.Unlock()
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("notify: alert %s: %w", message.Alert.AlertID, err)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) delivered(key string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for sentKey, sentAt := range d.sent {
+		if now.Sub(sentAt) >= d.dedupWindow {
+			delete(d.sent, sentKey)
+		}
+	}
+
+	_, ok := d.sent[key]
+
+	return ok
+}
+
+// send returns the number of attempts made along with the last error.
+func (d *Dispatcher) send(ctx context.Context, sink Sink, message Message) (int, error) {
+	backoff := d.retryBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = sink.Send(ctx, message); err == nil {
+			return attempt, nil
+		}
+
+		if attempt == d.retryAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) message(ctx context.Context, event alertwatch.Event) (Message, error) {
+	message := Message{
+		EventType:  event.Type,
+		Alert:      event.Alert,
+		Severity:   SeverityOf(event.Alert.AlertType),
+		ObservedAt: event.ObservedAt,
+	}
+
+	if event.Alert.DeviceID != "" {
+		device, err := d.device(ctx, event.Alert.DeviceID)
+		if err != nil {
+			return Message{}, err
+		}
+
+		message.DeviceName = owner.DeviceName(device)
+		message.ClientID = device.ClientID
+	}
+
+	if event.Alert.AgentID != "" {
+		agent, err := d.agent(ctx, event.Alert.AgentID)
+		if err != nil {
+			return Message{}, err
+		}
+
+		message.AgentName = owner.AgentName(agent)
+		message.ClientID = agent.ClientID
+	}
+
+	var err error
+	if message.Subject, err = render(d.subject, message); err != nil {
+		return Message{}, fmt.Errorf("notify: rendering subject - %w", err)
+	}
+
+	if message.Text, err = render(d.text, message); err != nil {
+		return Message{}, fmt.Errorf("notify: rendering text - %w", err)
+	}
+
+	return message, nil
+}
+
+// device looks up a device. A device that no longer exists is named by its
+// ID, so its alerts are still delivered.
+func (d *Dispatcher) device(ctx context.Context, deviceID string) (goslide.Device, error) {
+	device, err := d.owners.Device(ctx, deviceID)
+	if owner.IsNotFound(err) {
+		return goslide.Device{DeviceID: deviceID}, nil
+	}
+
+	return device, err
+}
+
+// agent looks up an agent, like device.
+func (d *Dispatcher) agent(ctx context.Context, agentID string) (goslide.Agent, error) {
+	agent, err := d.owners.Agent(ctx, agentID)
+	if owner.IsNotFound(err) {
+		return goslide.Agent{AgentID: agentID}, nil
+	}
+
+	return agent, err
+}
@@ -0,0 +1,411 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/notify"
+	"github.com/google/go-cmp/cmp"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	messages []notify.Message
+	failures int
+}
+
+func (r *recorder) Send(ctx context.Context, message notify.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+
+		return errors.New("sink unavailable")
+	}
+
+	r.messages = append(r.messages, message)
+
+	return nil
+}
+
+func (r *recorder) subjects() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subjects := []string{}
+	for _, message := range r.messages {
+		subjects = append(subjects, message.Subject)
+	}
+
+	return subjects
+}
+
+var createdAt = time.Date(2024, time.August, 23, 1, 25, 8, 0, time.UTC)
+
+func event(eventType alertwatch.EventType, alertID string, alertType goslide.AlertType, deviceID, agentID string) alertwatch.Event {
+	return alertwatch.Event{
+		Type: eventType,
+		Alert: goslide.Alert{
+			AlertID:   alertID,
+			AlertType: alertType,
+			DeviceID:  deviceID,
+			AgentID:   agentID,
+			CreatedAt: createdAt,
+			Resolved:  eventType == alertwatch.EventType_ALERT_RESOLVED,
+		},
+	}
+}
+
+func TestDispatcher_Handle(t *testing.T) {
+	server := fakeslide.NewFleet()
+	oncall := &recorder{}
+	acme := &recorder{}
+
+	dispatcher, err := notify.NewDispatcher(server.Service(),
+		map[string]notify.Sink{
+			"oncall": oncall,
+			"acme":   acme,
+		},
+		[]notify.Rule{
+			{MinSeverity: notify.Severity_CRITICAL, Sinks: []string{"oncall"}},
+			{ClientIDs: []string{"c_acme"}, Sinks: []string{"acme", "oncall"}},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, event := range []alertwatch.Event{
+		event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_AGENT_BACKUP_FAILED, "d_acme", "a_dc"),
+		event(alertwatch.EventType_ALERT_RAISED, "al_2", goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL, "d_globex", ""),
+		event(alertwatch.EventType_ALERT_RAISED, "al_3", goslide.AlertType_DEVICE_OUT_OF_DATE, "d_globex", ""),
+		event(alertwatch.EventType_ALERT_RESOLVED, "al_1", goslide.AlertType_AGENT_BACKUP_FAILED, "d_acme", "a_dc"),
+	} {
+		if err := dispatcher.Handle(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectedOncall := []string{
+		"[critical] Agent backup failed on Acme HQ",
+		"[critical] Device storage space critical on globex-slide",
+		"[critical] Resolved: Agent backup failed on Acme HQ",
+	}
+	if diff := cmp.Diff(expectedOncall, oncall.subjects()); diff != "" {
+		t.Errorf("%s On call subjects mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	expectedACME := []string{
+		"[critical] Agent backup failed on Acme HQ",
+		"[critical] Resolved: Agent backup failed on Acme HQ",
+	}
+	if diff := cmp.Diff(expectedACME, acme.subjects()); diff != "" {
+		t.Errorf("%s ACME subjects mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	expectedText := `Agent backup failed
+Agent: Acme DC01
+Device: Acme HQ
+Alert: al_1
+Raised: 2024-08-23 01:25 UTC`
+	if diff := cmp.Diff(expectedText, acme.messages[0].Text); diff != "" {
+		t.Errorf("%s Text mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if acme.messages[0].ClientID != "c_acme" {
+		t.Errorf("%s expected the client to be resolved, got %q", t.Name(), acme.messages[0].ClientID)
+	}
+
+	// Names are looked up once
+	if count := server.Count("GET /v1/device/d_acme"); count != 1 {
+		t.Errorf("%s expected one device lookup, got %d", t.Name(), count)
+	}
+}
+
+func TestDispatcher_Handler(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = []goslide.Alert{
+		{AlertID: "al_1", AlertType: goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL, DeviceID: "d_acme", CreatedAt: createdAt},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The watcher stops once the alert was delivered
+	sink := &recorder{}
+	dispatcher, err := notify.NewDispatcher(server.Service(),
+		map[string]notify.Sink{"sink": notify.SinkFunc(func(ctx context.Context, message notify.Message) error {
+			defer cancel()
+
+			return sink.Send(ctx, message)
+		})},
+		[]notify.Rule{{Sinks: []string{"sink"}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := alertwatch.NewWatcher(server.Service(), alertwatch.WithInitialEvents())
+	if err := watcher.Run(ctx, dispatcher.Handler(ctx)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("%s expected the watcher to be cancelled, got %v", t.Name(), err)
+	}
+
+	if diff := cmp.Diff([]string{"[critical] Device storage space critical on Acme HQ"}, sink.subjects()); diff != "" {
+		t.Errorf("%s Subjects mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestDispatcher_Templates(t *testing.T) {
+	sink := &recorder{}
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"sink": sink},
+		[]notify.Rule{{Sinks: []string{"sink"}}},
+		notify.WithTemplates(`{{ .Severity }} {{ .Alert.AlertType }} {{ .AgentName }}`, `{{ .ClientID }}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dispatcher.Handle(context.Background(), event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_AGENT_NOT_CHECKING_IN, "", "a_dc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"warning agent_not_checking_in Acme DC01"}, sink.subjects()); diff != "" {
+		t.Errorf("%s Subject mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if sink.messages[0].Text != "c_acme" {
+		t.Errorf("%s expected the client ID as text, got %q", t.Name(), sink.messages[0].Text)
+	}
+}
+
+func TestDispatcher_QuietHours(t *testing.T) {
+	// Quiet hours around the current time, so the test does not depend on the clock
+	now := time.Now().UTC()
+	sink := &recorder{}
+	minSeverity := notify.Severity_CRITICAL
+
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"sink": sink},
+		[]notify.Rule{{Sinks: []string{"sink"}}},
+		notify.WithQuietHours(notify.QuietHours{
+			Start:       now.Add(-time.Hour).Format("15:04"),
+			End:         now.Add(time.Hour).Format("15:04"),
+			Location:    time.UTC,
+			MinSeverity: &minSeverity,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, event := range []alertwatch.Event{
+		event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, "d_acme", ""),
+		event(alertwatch.EventType_ALERT_RAISED, "al_2", goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL, "d_acme", ""),
+	} {
+		if err := dispatcher.Handle(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"[critical] Device storage space critical on Acme HQ"}, sink.subjects()); diff != "" {
+		t.Errorf("%s Subjects mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestDispatcher_QuietHoursDefaultSeverity(t *testing.T) {
+	// Without a location quiet hours are in local time
+	now := time.Now()
+	sink := &recorder{}
+
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"sink": sink},
+		[]notify.Rule{{Sinks: []string{"sink"}}},
+		notify.WithQuietHours(notify.QuietHours{
+			Start: now.Add(-time.Hour).Format("15:04"),
+			End:   now.Add(time.Hour).Format("15:04"),
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, event := range []alertwatch.Event{
+		event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_DEVICE_OUT_OF_DATE, "d_acme", ""),
+		event(alertwatch.EventType_ALERT_RAISED, "al_2", goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, "d_acme", ""),
+		event(alertwatch.EventType_ALERT_RAISED, "al_3", goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL, "d_acme", ""),
+	} {
+		if err := dispatcher.Handle(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"[critical] Device storage space critical on Acme HQ"}, sink.subjects()); diff != "" {
+		t.Errorf("%s Subjects mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestDispatcher_QuietHoursInfoSeverity(t *testing.T) {
+	// A minimum of info sends everything, even in quiet hours
+	now := time.Now().UTC()
+	sink := &recorder{}
+	minSeverity := notify.Severity_INFO
+
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"sink": sink},
+		[]notify.Rule{{Sinks: []string{"sink"}}},
+		notify.WithQuietHours(notify.QuietHours{
+			Start:       now.Add(-time.Hour).Format("15:04"),
+			End:         now.Add(time.Hour).Format("15:04"),
+			Location:    time.UTC,
+			MinSeverity: &minSeverity,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, event := range []alertwatch.Event{
+		event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_DEVICE_OUT_OF_DATE, "d_acme", ""),
+		event(alertwatch.EventType_ALERT_RAISED, "al_2", goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL, "d_acme", ""),
+	} {
+		if err := dispatcher.Handle(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(sink.subjects()) != 2 {
+		t.Errorf("%s expected both messages to be sent, got %v", t.Name(), sink.subjects())
+	}
+}
+
+func TestDispatcher_Dedup(t *testing.T) {
+	sink := &recorder{}
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"sink": sink},
+		[]notify.Rule{{Sinks: []string{"sink"}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raised := event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_DEVICE_NOT_CHECKING_IN, "d_acme", "")
+	resolved := event(alertwatch.EventType_ALERT_RESOLVED, "al_1", goslide.AlertType_DEVICE_NOT_CHECKING_IN, "d_acme", "")
+
+	for _, event := range []alertwatch.Event{raised, raised, resolved, resolved} {
+		if err := dispatcher.Handle(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(sink.messages) != 2 {
+		t.Fatalf("%s expected redelivered events to be sent once, got %v", t.Name(), sink.subjects())
+	}
+}
+
+func TestDispatcher_RetryAndDeadLetter(t *testing.T) {
+	flaky := &recorder{failures: 1}
+	down := &recorder{failures: 100}
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"flaky": flaky, "down": down},
+		[]notify.Rule{{Sinks: []string{"flaky", "down"}}},
+		notify.WithRetry(3, time.Millisecond),
+		notify.WithDeadLetterFile(deadLetterPath),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dispatcher.Handle(context.Background(), event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_AGENT_BACKUP_FAILED, "d_acme", "")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flaky.messages) != 1 {
+		t.Errorf("%s expected the flaky sink to succeed on retry, got %d messages", t.Name(), len(flaky.messages))
+	}
+
+	if down.failures != 97 {
+		t.Errorf("%s expected 3 attempts, got %d", t.Name(), 100-down.failures)
+	}
+
+	file, err := os.Open(deadLetterPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	deadLetters := []notify.DeadLetter{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		deadLetter := notify.DeadLetter{}
+		if err := json.Unmarshal(scanner.Bytes(), &deadLetter); err != nil {
+			t.Fatal(err)
+		}
+
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	if len(deadLetters) != 1 {
+		t.Fatalf("%s expected one dead letter, got %d", t.Name(), len(deadLetters))
+	}
+
+	if deadLetters[0].Sink != "down" || deadLetters[0].Attempts != 3 || deadLetters[0].Message.Alert.AlertID != "al_1" {
+		t.Errorf("%s unexpected dead letter %+v", t.Name(), deadLetters[0])
+	}
+}
+
+func TestDispatcher_RetryWithoutDeadLetter(t *testing.T) {
+	dispatcher, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{"down": &recorder{failures: 100}},
+		[]notify.Rule{{Sinks: []string{"down"}}},
+		notify.WithRetry(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = dispatcher.Handle(context.Background(), event(alertwatch.EventType_ALERT_RAISED, "al_1", goslide.AlertType_AGENT_BACKUP_FAILED, "d_acme", ""))
+	if err == nil || !strings.Contains(err.Error(), `sink "down": sink unavailable`) {
+		t.Fatalf("%s expected the delivery error, got %v", t.Name(), err)
+	}
+}
+
+func TestNewDispatcher_Invalid(t *testing.T) {
+	_, err := notify.NewDispatcher(fakeslide.NewFleet().Service(),
+		map[string]notify.Sink{},
+		[]notify.Rule{{Sinks: []string{"missing"}}},
+		notify.WithTemplates("{{ .Subject", "{{ .Text }}"),
+		notify.WithQuietHours(notify.QuietHours{Start: "22:00", End: "7am"}),
+	)
+	if err == nil {
+		t.Fatalf("%s expected an error", t.Name())
+	}
+
+	for _, expected := range []string{
+		`rules[0] uses unknown sink "missing"`,
+		"template: subject",
+		`invalid quiet hours end "7am"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s expected error to contain %q, got: %s", t.Name(), expected, err)
+		}
+	}
+}
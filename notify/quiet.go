@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours suppresses messages between Start and End, which are times of
+// day such as "22:00" and "07:00" in Location. Messages at or above
+// MinSeverity are still sent, critical ones when it is nil.
+type QuietHours struct {
+	Start       string
+	End         string
+	Location    *time.Location
+	MinSeverity *Severity
+}
+
+func (q QuietHours) validate() error {
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		return fmt.Errorf("invalid quiet hours start %q - %w", q.Start, err)
+	}
+
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		return fmt.Errorf("invalid quiet hours end %q - %w", q.End, err)
+	}
+
+	return nil
+}
+
+// suppresses reports whether a message of the given severity is not sent at now.
+func (q QuietHours) suppresses(now time.Time, severity Severity) bool {
+	minSeverity := Severity_CRITICAL
+	if q.MinSeverity != nil {
+		minSeverity = *q.MinSeverity
+	}
+
+	if severity >= minSeverity {
+		return false
+	}
+
+	location := q.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	now = now.In(location)
+	start, _ := time.Parse("15:04", q.Start)
+	end, _ := time.Parse("15:04", q.End)
+
+	minute := now.Hour()*60 + now.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	// Quiet hours such as 22:00 to 07:00 span midnight
+	if startMinute > endMinute {
+		return minute >= startMinute || minute < endMinute
+	}
+
+	return minute >= startMinute && minute < endMinute
+}
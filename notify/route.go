@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"slices"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+)
+
+// Rule sends matching messages to the named sinks. Empty fields match
+// everything.
+type Rule struct {
+	AlertTypes  []goslide.AlertType    `json:"alert_types"`
+	ClientIDs   []string               `json:"client_ids"`
+	EventTypes  []alertwatch.EventType `json:"event_types"`
+	MinSeverity Severity               `json:"min_severity"`
+	Sinks       []string               `json:"sinks"`
+}
+
+func (r Rule) matches(message Message) bool {
+	if len(r.AlertTypes) > 0 && !slices.Contains(r.AlertTypes, message.Alert.AlertType) {
+		return false
+	}
+
+	if len(r.ClientIDs) > 0 && !slices.Contains(r.ClientIDs, message.ClientID) {
+		return false
+	}
+
+	if len(r.EventTypes) > 0 && !slices.Contains(r.EventTypes, message.EventType) {
+		return false
+	}
+
+	return message.Severity >= r.MinSeverity
+}
+
+// route returns the sinks of every matching rule, in rule order and without
+// repeats.
+func route(rules []Rule, message Message) []string {
+	sinks := []string{}
+	for _, rule := range rules {
+		if !rule.matches(message) {
+			continue
+		}
+
+		for _, sink := range rule.Sinks {
+			if !slices.Contains(sinks, sink) {
+				sinks = append(sinks, sink)
+			}
+		}
+	}
+
+	return sinks
+}
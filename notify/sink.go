@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sink delivers a message to one destination.
+type Sink interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(ctx context.Context, message Message) error
+
+func (f SinkFunc) Send(ctx context.Context, message Message) error {
+	return f(ctx, message)
+}
+
+// StatusError is returned by the HTTP sinks for a non 2xx response.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d: %s", e.StatusCode, e.Body)
+}
+
+func postJSON(ctx context.Context, httpClient *http.Client, url string, body any, headers http.Header) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, httpClient, url, payload, headers)
+}
+
+func post(ctx context.Context, httpClient *http.Client, url string, payload []byte, headers http.Header) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, values := range headers {
+		request.Header[key] = values
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+		return StatusError{
+			StatusCode: response.StatusCode,
+			Body:       string(body),
+		}
+	}
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	return nil
+}
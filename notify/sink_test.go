@@ -0,0 +1,192 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+	"github.com/equalsgibson/goslide/notify"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testMessage = notify.Message{
+	EventType: alertwatch.EventType_ALERT_RAISED,
+	Alert: goslide.Alert{
+		AlertID:   "al_0123456789ab",
+		AlertType: goslide.AlertType_DEVICE_STORAGE_SPACE_CRITICAL,
+		DeviceID:  "d_0123456789ab",
+		CreatedAt: createdAt,
+	},
+	Severity:   notify.Severity_CRITICAL,
+	ClientID:   "c_0123456789ab",
+	DeviceName: "ACME Box",
+	Subject:    "[critical] Device storage space critical on ACME Box",
+	Text:       "Device storage space critical\nDevice: ACME Box",
+}
+
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newCaptureServer(t *testing.T, statusCode int) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+
+	requests := make(chan capturedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{header: r.Header, body: body}
+
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestSlackSink(t *testing.T) {
+	server, requests := newCaptureServer(t, http.StatusOK)
+
+	if err := (notify.SlackSink{WebhookURL: server.URL}).Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"text":"[critical] Device storage space critical on ACME Box","blocks":[{"type":"header","text":{"type":"plain_text","text":"[critical] Device storage space critical on ACME Box"}},{"type":"section","text":{"type":"mrkdwn","text":"Device storage space critical\nDevice: ACME Box"}}]}`
+	if diff := cmp.Diff(expected, string((<-requests).body)); diff != "" {
+		t.Fatalf("%s Payload mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestTeamsSink(t *testing.T) {
+	server, requests := newCaptureServer(t, http.StatusOK)
+
+	if err := (notify.TeamsSink{WebhookURL: server.URL}).Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	card := map[string]any{}
+	if err := json.Unmarshal((<-requests).body, &card); err != nil {
+		t.Fatal(err)
+	}
+
+	if card["@type"] != "MessageCard" || card["themeColor"] != "D40E0D" || card["title"] != testMessage.Subject {
+		t.Fatalf("%s unexpected card %v", t.Name(), card)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	server, requests := newCaptureServer(t, http.StatusAccepted)
+
+	if err := (notify.WebhookSink{URL: server.URL, Secret: "s3cret"}).Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	request := <-requests
+	expected := notify.Sign("s3cret", request.header.Get(notify.TimestampHeader), request.body)
+	if !hmac.Equal([]byte(expected), []byte(request.header.Get(notify.SignatureHeader))) {
+		t.Fatalf("%s signature mismatch, got %q", t.Name(), request.header.Get(notify.SignatureHeader))
+	}
+
+	actual := notify.Message{}
+	if err := json.Unmarshal(request.body, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(testMessage, actual); diff != "" {
+		t.Fatalf("%s Payload mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestWebhookSink_ErrorStatus(t *testing.T) {
+	server, _ := newCaptureServer(t, http.StatusBadGateway)
+
+	err := (notify.WebhookSink{URL: server.URL}).Send(context.Background(), testMessage)
+
+	statusErr := notify.StatusError{}
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("%s expected a status error, got %v", t.Name(), err)
+	}
+}
+
+func TestSMTPSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	sink := notify.SMTPSink{
+		Addr: listener.Addr().String(),
+		From: "alerts@example.com",
+		To:   []string{"oncall@example.com", "acme@example.com"},
+	}
+	if err := sink.Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	data := <-received
+	for _, expected := range []string{
+		"MAIL FROM:<alerts@example.com>",
+		"RCPT TO:<oncall@example.com>",
+		"RCPT TO:<acme@example.com>",
+		"Subject: [critical] Device storage space critical on ACME Box\r\n",
+		"\r\n\r\nDevice storage space critical\r\nDevice: ACME Box\r\n",
+	} {
+		if !strings.Contains(data, expected) {
+			t.Errorf("%s expected the session to contain %q, got:\n%s", t.Name(), expected, data)
+		}
+	}
+}
+
+// serveSMTP accepts a single mail and sends the whole session to received.
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	session := strings.Builder{}
+
+	_ = text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		session.WriteString(line + "\n")
+
+		switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+		case "EHLO", "HELO":
+			_ = text.PrintfLine("250 localhost")
+		case "DATA":
+			_ = text.PrintfLine("354 go ahead")
+
+			data, _ := io.ReadAll(bufio.NewReader(text.DotReader()))
+			session.WriteString(strings.ReplaceAll(string(data), "\n", "\r\n"))
+			_ = text.PrintfLine("250 queued")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			received <- session.String()
+
+			return
+		default:
+			_ = text.PrintfLine("250 ok")
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// SlackSink posts to a Slack incoming webhook.
+type SlackSink struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+func (s SlackSink) Send(ctx context.Context, message Message) error {
+	type text struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	type block struct {
+		Type string `json:"type"`
+		Text text   `json:"text"`
+	}
+
+	type payload struct {
+		Text   string  `json:"text"`
+		Blocks []block `json:"blocks"`
+	}
+
+	return postJSON(ctx, s.HTTPClient, s.WebhookURL, payload{
+		// Text is the fallback for notifications and clients without blocks
+		Text: message.Subject,
+		Blocks: []block{
+			{Type: "header", Text: text{Type: "plain_text", Text: message.Subject}},
+			{Type: "section", Text: text{Type: "mrkdwn", Text: message.Text}},
+		},
+	}, nil)
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// TeamsSink posts a message card to a Microsoft Teams incoming webhook.
+type TeamsSink struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+func (t TeamsSink) Send(ctx context.Context, message Message) error {
+	type fact struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	type section struct {
+		ActivityTitle string `json:"activityTitle"`
+		Text          string `json:"text"`
+		Facts         []fact `json:"facts"`
+	}
+
+	type messageCard struct {
+		Type       string    `json:"@type"`
+		Context    string    `json:"@context"`
+		Summary    string    `json:"summary"`
+		ThemeColor string    `json:"themeColor"`
+		Title      string    `json:"title"`
+		Sections   []section `json:"sections"`
+	}
+
+	facts := []fact{
+		{Name: "Severity", Value: message.Severity.String()},
+		{Name: "Alert", Value: message.Alert.AlertID},
+	}
+	if message.DeviceName != "" {
+		facts = append(facts, fact{Name: "Device", Value: message.DeviceName})
+	}
+	if message.AgentName != "" {
+		facts = append(facts, fact{Name: "Agent", Value: message.AgentName})
+	}
+
+	return postJSON(ctx, t.HTTPClient, t.WebhookURL, messageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    message.Subject,
+		ThemeColor: themeColor(message),
+		Title:      message.Subject,
+		Sections: []section{
+			{
+				ActivityTitle: Title(message.Alert.AlertType),
+				Text:          message.Text,
+				Facts:         facts,
+			},
+		},
+	}, nil)
+}
+
+func themeColor(message Message) string {
+	if message.Alert.Resolved {
+		return "2EB886"
+	}
+
+	switch message.Severity {
+	case Severity_CRITICAL:
+		return "D40E0D"
+	case Severity_WARNING:
+		return "F2C744"
+	default:
+		return "439FE0"
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Goslide-Signature"
+	TimestampHeader = "X-Goslide-Timestamp"
+)
+
+// WebhookSink posts the message as JSON. When Secret is set, the request is
+// signed with an HMAC-SHA256 of "<timestamp>.<body>", sent as
+// "sha256=<hex>" in the X-Goslide-Signature header.
+type WebhookSink struct {
+	URL        string
+	Secret     string
+	HTTPClient *http.Client
+}
+
+func (w WebhookSink) Send(ctx context.Context, message Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	headers := http.Header{}
+	if w.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers.Set(TimestampHeader, timestamp)
+		headers.Set(SignatureHeader, Sign(w.Secret, timestamp, payload))
+	}
+
+	return post(ctx, w.HTTPClient, w.URL, payload, headers)
+}
+
+// Sign computes the signature of a webhook request, for receivers to compare
+// with hmac.Equal.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
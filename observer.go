@@ -0,0 +1,107 @@
+package goslide
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Observer is told about every API call of a service, to trace it or record
+// metrics. The otelslide package provides one for OpenTelemetry.
+type Observer interface {
+	// StartRequest is called before a request is sent, with its route
+	// template, such as /v1/agent/{agent_id}, or an empty string for paths the
+	// library does not know. The returned request is sent in its place, so a
+	// trace context can be added to its context and headers.
+	StartRequest(request *http.Request, route string) (*http.Request, RequestObservation)
+
+	// StartPagination is called before the first page of a list is requested.
+	// The requests for the pages use the returned context, and the returned
+	// function is called once the list is done.
+	StartPagination(ctx context.Context, route string) (context.Context, func(err error))
+}
+
+// RequestObservation follows a single API call started by an Observer.
+type RequestObservation interface {
+	// Retry is called before a request is sent again.
+	Retry(attempt int, statusCode int, delay time.Duration)
+
+	// End is called with the status code of the last response, zero when no
+	// response was received, and the error returned to the caller.
+	End(statusCode int, err error)
+}
+
+// WithObserver sets the Observer told about every API call. Nothing is
+// observed by default.
+func WithObserver(observer Observer) configOption {
+	return func(s *serviceConfig) {
+		s.observer = observer
+	}
+}
+
+type noopObserver struct{}
+
+func (noopObserver) StartRequest(request *http.Request, route string) (*http.Request, RequestObservation) {
+	return request, noopObservation{}
+}
+
+func (noopObserver) StartPagination(ctx context.Context, route string) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
+type noopObservation struct{}
+
+func (noopObservation) Retry(int, int, time.Duration) {}
+
+func (noopObservation) End(int, error) {}
+
+// routes resolves request paths to the route templates of the API, so
+// observers do not label calls with the IDs of resources.
+var routes = func() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	for _, route := range []string{
+		"/v1/account",
+		"/v1/account/{account_id}",
+		"/v1/agent",
+		"/v1/agent/pair",
+		"/v1/agent/{agent_id}",
+		"/v1/alert",
+		"/v1/alert/{alert_id}",
+		"/v1/backup",
+		"/v1/backup/{backup_id}",
+		"/v1/client",
+		"/v1/client/{client_id}",
+		"/v1/device",
+		"/v1/device/{device_id}",
+		"/v1/network",
+		"/v1/network/{network_id}",
+		"/v1/network/{network_id}/port-forwards",
+		"/v1/network/{network_id}/wg-peers",
+		"/v1/restore/file",
+		"/v1/restore/file/{file_restore_id}",
+		"/v1/restore/file/{file_restore_id}/browse",
+		"/v1/restore/image",
+		"/v1/restore/image/{image_export_id}",
+		"/v1/restore/image/{image_export_id}/browse",
+		"/v1/restore/virt",
+		"/v1/restore/virt/{virt_id}",
+		"/v1/snapshot",
+		"/v1/snapshot/{snapshot_id}",
+		"/v1/user",
+		"/v1/user/{user_id}",
+	} {
+		mux.HandleFunc(route, func(http.ResponseWriter, *http.Request) {})
+	}
+
+	return mux
+}()
+
+// routeTemplate returns the route of a path, such as /v1/agent/{agent_id},
+// or an empty string for paths the library does not know.
+func routeTemplate(method, path string) string {
+	_, pattern := routes.Handler(&http.Request{Method: method, URL: &url.URL{Path: path}})
+
+	return pattern
+}
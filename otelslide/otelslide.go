@@ -0,0 +1,227 @@
+// Package otelslide records the API calls of a goslide.Service as
+// OpenTelemetry spans and metrics.
+//
+//	service := goslide.NewService(token,
+//		goslide.WithObserver(otelslide.NewObserver()),
+//	)
+package otelslide
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/equalsgibson/goslide"
+
+// Attributes specific to the Slide API.
+const (
+	attributeErrorCodes = attribute.Key("slide.error.codes")
+	attributeAttempt    = attribute.Key("slide.retry.attempt")
+	attributeDelay      = attribute.Key("slide.retry.delay")
+)
+
+type observerConfig struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+type observerOption func(*observerConfig)
+
+// WithTracerProvider sets where the spans of API calls are sent. The global
+// provider of the otel package is used by default.
+func WithTracerProvider(provider trace.TracerProvider) observerOption {
+	return func(c *observerConfig) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets where the latency and error metrics of API calls are
+// sent. The global provider of the otel package is used by default.
+func WithMeterProvider(provider metric.MeterProvider) observerOption {
+	return func(c *observerConfig) {
+		c.meterProvider = provider
+	}
+}
+
+// WithPropagator sets how the trace context of the caller is passed on in
+// request headers. The global propagator of the otel package is used by
+// default.
+func WithPropagator(propagator propagation.TextMapPropagator) observerOption {
+	return func(c *observerConfig) {
+		c.propagator = propagator
+	}
+}
+
+// Observer creates a client span for every API call, named after its route
+// template, and records its latency and failures.
+type Observer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	duration   metric.Float64Histogram
+	errors     metric.Int64Counter
+}
+
+var _ goslide.Observer = (*Observer)(nil)
+
+// NewObserver returns an Observer for goslide.WithObserver.
+func NewObserver(options ...observerOption) *Observer {
+	config := &observerConfig{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	meter := config.meterProvider.Meter(instrumentationName)
+
+	// Instruments that cannot be created are reported to the otel error
+	// handler, and fall back to no-op instruments
+	duration, err := meter.Float64Histogram(
+		"http.client.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of Slide API requests, including retries."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		duration = noop.Float64Histogram{}
+	}
+
+	errorCount, err := meter.Int64Counter(
+		"slide.client.request.errors",
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Slide API requests that failed."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		errorCount = noop.Int64Counter{}
+	}
+
+	return &Observer{
+		tracer:     config.tracerProvider.Tracer(instrumentationName),
+		propagator: config.propagator,
+		duration:   duration,
+		errors:     errorCount,
+	}
+}
+
+// StartRequest starts the client span of a request and passes its context
+// on in the request headers.
+func (o *Observer) StartRequest(request *http.Request, route string) (*http.Request, goslide.RequestObservation) {
+	attributes := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(request.Method),
+		semconv.ServerAddress(request.URL.Host),
+	}
+	if route != "" {
+		attributes = append(attributes, semconv.HTTPRoute(route))
+	}
+
+	name := request.Method
+	if route != "" {
+		name += " " + route
+	}
+
+	ctx, span := o.tracer.Start(request.Context(), name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+
+	request = request.WithContext(ctx)
+	o.propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	return request, &requestObservation{
+		observer:   o,
+		ctx:        ctx,
+		span:       span,
+		attributes: attributes,
+		startedAt:  time.Now(),
+	}
+}
+
+// StartPagination starts the span that groups the requests for the pages of
+// a list.
+func (o *Observer) StartPagination(ctx context.Context, route string) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, "paginate GET "+route,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(semconv.HTTPRoute(route)),
+	)
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}
+}
+
+type requestObservation struct {
+	observer   *Observer
+	ctx        context.Context
+	span       trace.Span
+	attributes []attribute.KeyValue
+	startedAt  time.Time
+}
+
+// Retry adds an event for a request that is sent again.
+func (r *requestObservation) Retry(attempt int, statusCode int, delay time.Duration) {
+	r.span.AddEvent("retry", trace.WithAttributes(
+		attributeAttempt.Int(attempt),
+		semconv.HTTPResponseStatusCode(statusCode),
+		attributeDelay.String(delay.String()),
+	))
+}
+
+// End records the outcome of a request on its span and in the metrics.
+func (r *requestObservation) End(statusCode int, err error) {
+	defer r.span.End()
+
+	attributes := r.attributes
+	if statusCode != 0 {
+		attributes = append(attributes, semconv.HTTPResponseStatusCode(statusCode))
+		r.span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+	}
+
+	if err != nil {
+		errorType := "error"
+		if statusCode != 0 {
+			errorType = strconv.Itoa(statusCode)
+		}
+
+		slideError := &goslide.SlideError{}
+		if errors.As(err, &slideError) && len(slideError.Codes) > 0 {
+			errorCodes := make([]string, 0, len(slideError.Codes))
+			for _, code := range slideError.Codes {
+				errorCodes = append(errorCodes, string(code))
+			}
+
+			r.span.SetAttributes(attributeErrorCodes.StringSlice(errorCodes))
+			errorType = errorCodes[0]
+		}
+
+		attributes = append(attributes, semconv.ErrorTypeKey.String(errorType))
+
+		r.span.RecordError(err)
+		r.span.SetStatus(codes.Error, err.Error())
+		r.observer.errors.Add(r.ctx, 1, metric.WithAttributes(attributes...))
+	}
+
+	r.observer.duration.Record(r.ctx, time.Since(r.startedAt).Seconds(), metric.WithAttributes(attributes...))
+}
@@ -0,0 +1,214 @@
+package otelslide_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/equalsgibson/goslide/otelslide"
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type observerTest struct {
+	service goslide.Service
+	server  *fakeslide.Server
+	spans   *tracetest.InMemoryExporter
+	reader  *sdkmetric.ManualReader
+	tracer  *sdktrace.TracerProvider
+
+	mu          sync.Mutex
+	traceparent []string
+}
+
+func newObserverTest(t *testing.T) *observerTest {
+	t.Helper()
+
+	test := &observerTest{
+		server: fakeslide.NewFleet(),
+		spans:  tracetest.NewInMemoryExporter(),
+		reader: sdkmetric.NewManualReader(),
+	}
+	test.tracer = sdktrace.NewTracerProvider(sdktrace.WithSyncer(test.spans))
+
+	// The handler records the propagated trace context of every request
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		test.mu.Lock()
+		test.traceparent = append(test.traceparent, r.Header.Get("traceparent"))
+		test.mu.Unlock()
+
+		test.server.ServeHTTP(w, r)
+	})
+
+	test.service = goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(roundtripper.HandlerNetwork(handler)),
+		goslide.WithObserver(otelslide.NewObserver(
+			otelslide.WithTracerProvider(test.tracer),
+			otelslide.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(test.reader))),
+			otelslide.WithPropagator(propagation.TraceContext{}),
+		)),
+		goslide.WithRetries(2, time.Millisecond),
+	)
+
+	return test
+}
+
+func TestObserver_Pagination(t *testing.T) {
+	test := newObserverTest(t)
+
+	ctx, caller := test.tracer.Tracer("test").Start(context.Background(), "caller")
+	if err := test.service.Agents().List(ctx, func(response goslide.ListResponse[goslide.Agent]) error {
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	caller.End()
+
+	spans := test.spans.GetSpans()
+
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+
+	if diff := cmp.Diff([]string{"GET /v1/agent", "GET /v1/agent", "paginate GET /v1/agent", "caller"}, names); diff != "" {
+		t.Fatalf("%s Span mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Pages are children of the pagination span, which is a child of the caller
+	pagination, root := spans[2], spans[3]
+	if pagination.Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Errorf("%s expected the pagination span to be a child of the caller", t.Name())
+	}
+
+	for _, page := range spans[:2] {
+		if page.Parent.SpanID() != pagination.SpanContext.SpanID() {
+			t.Errorf("%s expected the page span to be a child of the pagination span", t.Name())
+		}
+
+		expected := map[attribute.Key]attribute.Value{
+			"http.request.method":       attribute.StringValue(http.MethodGet),
+			"http.route":                attribute.StringValue("/v1/agent"),
+			"http.response.status_code": attribute.IntValue(http.StatusOK),
+			"server.address":            attribute.StringValue("api.slide.tech"),
+		}
+		if diff := cmp.Diff(expected, attributeMap(page.Attributes), cmp.AllowUnexported(attribute.Value{})); diff != "" {
+			t.Errorf("%s Page attributes mismatch (-want +got):\n%s", t.Name(), diff)
+		}
+	}
+
+	// The trace context of each page is sent to the API
+	for i, traceparent := range test.traceparent {
+		expected := "00-" + root.SpanContext.TraceID().String() + "-" + spans[i].SpanContext.SpanID().String() + "-01"
+		if traceparent != expected {
+			t.Errorf("%s expected traceparent %s, got %s", t.Name(), expected, traceparent)
+		}
+	}
+}
+
+func TestObserver_ErrorsAndRetries(t *testing.T) {
+	test := newObserverTest(t)
+	ctx := context.Background()
+
+	test.server.FailNext("GET /v1/agent/{agent_id}", 1)
+	if _, err := test.service.Agents().Get(ctx, "a_dc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := test.service.Agents().Get(ctx, "a_missing"); err == nil {
+		t.Fatalf("%s expected an error for a missing agent", t.Name())
+	}
+
+	// Requests that change something are not retried
+	test.server.FailNext("POST /v1/backup", 1)
+	if err := test.service.Backups().StartBackup(ctx, "a_dc"); err == nil {
+		t.Fatalf("%s expected the failed backup start to be returned", t.Name())
+	}
+
+	spans := test.spans.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("%s expected 3 spans, got %d", t.Name(), len(spans))
+	}
+
+	retried, missing, start := spans[0], spans[1], spans[2]
+
+	if retried.Name != "GET /v1/agent/{agent_id}" || retried.Status.Code != codes.Unset {
+		t.Errorf("%s expected a successful span for the retried request, got %s %v", t.Name(), retried.Name, retried.Status)
+	}
+
+	if len(retried.Events) != 1 || retried.Events[0].Name != "retry" {
+		t.Fatalf("%s expected a retry event, got %+v", t.Name(), retried.Events)
+	}
+
+	if diff := cmp.Diff(map[attribute.Key]attribute.Value{
+		"slide.retry.attempt":       attribute.IntValue(1),
+		"http.response.status_code": attribute.IntValue(http.StatusInternalServerError),
+		"slide.retry.delay":         attribute.StringValue("1ms"),
+	}, attributeMap(retried.Events[0].Attributes), cmp.AllowUnexported(attribute.Value{})); diff != "" {
+		t.Errorf("%s Retry event mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if missing.Status.Code != codes.Error {
+		t.Errorf("%s expected an error status, got %v", t.Name(), missing.Status)
+	}
+
+	if diff := cmp.Diff([]string{"err_entity_not_found"}, attributeMap(missing.Attributes)["slide.error.codes"].AsStringSlice()); diff != "" {
+		t.Errorf("%s Error codes mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if len(start.Events) != 1 || start.Events[0].Name != "exception" {
+		t.Errorf("%s expected only the error event on the backup start, got %+v", t.Name(), start.Events)
+	}
+
+	metrics := metricdata.ResourceMetrics{}
+	if err := test.reader.Collect(ctx, &metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := map[string]uint64{}
+	failures := map[string]int64{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					method, _ := point.Attributes.Value("http.request.method")
+					requests[method.AsString()] += point.Count
+				}
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					errorType, _ := point.Attributes.Value("error.type")
+					failures[errorType.AsString()] += point.Value
+				}
+			}
+		}
+	}
+
+	if diff := cmp.Diff(map[string]uint64{http.MethodGet: 2, http.MethodPost: 1}, requests); diff != "" {
+		t.Errorf("%s Request duration count mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if diff := cmp.Diff(map[string]int64{"err_entity_not_found": 1, "err_internal_server_error": 1}, failures); diff != "" {
+		t.Errorf("%s Error count mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func attributeMap(attributes []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	result := map[attribute.Key]attribute.Value{}
+	for _, kv := range attributes {
+		result[kv.Key] = kv.Value
+	}
+
+	return result
+}
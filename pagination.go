@@ -47,6 +47,12 @@ func WithAgentID(agentID string) paginationQueryParam {
 	}
 }
 
+func WithClientID(clientID string) paginationQueryParam {
+	return func(u url.Values) {
+		u.Set("client_id", url.QueryEscape(clientID))
+	}
+}
+
 func WithDeviceID(deviceID string) paginationQueryParam {
 	return func(u url.Values) {
 		u.Set("device_id", url.QueryEscape(deviceID))
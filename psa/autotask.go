@@ -0,0 +1,158 @@
+package psa
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultAutotaskNewStatus      = 1
+	defaultAutotaskCompleteStatus = 5
+	defaultAutotaskPriority       = 2
+)
+
+// Autotask opens tickets through the Autotask REST API.
+type Autotask struct {
+	// BaseURL is the REST API root of the zone, such as
+	// https://webservices2.autotask.net/atservicesrest/v1.0
+	BaseURL         string
+	Username        string
+	Secret          string
+	IntegrationCode string
+
+	QueueID int
+	// Priority of new tickets, 2 (medium) by default
+	Priority int
+	// CompleteStatus is the status set on closed tickets, 5 (complete) by default
+	CompleteStatus int
+
+	HTTPClient *http.Client
+}
+
+func (a Autotask) FindCompany(ctx context.Context, name string) (string, error) {
+	type filter struct {
+		Op    string `json:"op"`
+		Field string `json:"field"`
+		Value string `json:"value"`
+	}
+
+	result := struct {
+		Items []struct {
+			ID int `json:"id"`
+		} `json:"items"`
+	}{}
+	if err := a.do(ctx, http.MethodPost, "/Companies/query", map[string][]filter{
+		"filter": {{Op: "eq", Field: "companyName", Value: name}},
+	}, &result); err != nil {
+		return "", err
+	}
+
+	if len(result.Items) == 0 {
+		return "", ErrCompanyNotFound
+	}
+
+	return strconv.Itoa(result.Items[0].ID), nil
+}
+
+func (a Autotask) CreateTicket(ctx context.Context, ticket Ticket) (string, error) {
+	type ticketPayload struct {
+		CompanyID   int    `json:"companyID"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Status      int    `json:"status"`
+		Priority    int    `json:"priority"`
+		QueueID     int    `json:"queueID,omitempty"`
+	}
+
+	companyID, err := strconv.Atoi(ticket.CompanyID)
+	if err != nil {
+		return "", err
+	}
+
+	payload := ticketPayload{
+		CompanyID: companyID,
+		// Titles are limited to 255 characters
+		Title:       truncate(ticket.Summary, 255),
+		Description: ticket.Description,
+		Status:      defaultAutotaskNewStatus,
+		Priority:    a.Priority,
+		QueueID:     a.QueueID,
+	}
+
+	if payload.Priority == 0 {
+		payload.Priority = defaultAutotaskPriority
+	}
+
+	created := struct {
+		ItemID int `json:"itemId"`
+	}{}
+	if err := a.do(ctx, http.MethodPost, "/Tickets", payload, &created); err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(created.ItemID), nil
+}
+
+func (a Autotask) AddNote(ctx context.Context, ticketID, note string) error {
+	type notePayload struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		NoteType    int    `json:"noteType"`
+		Publish     int    `json:"publish"`
+	}
+
+	return a.do(ctx, http.MethodPost, "/Tickets/"+ticketID+"/Notes", notePayload{
+		Title:       "Slide",
+		Description: note,
+		NoteType:    1,
+		Publish:     1,
+	}, nil)
+}
+
+func (a Autotask) CloseTicket(ctx context.Context, ticketID, note string) error {
+	if err := a.AddNote(ctx, ticketID, note); err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(ticketID)
+	if err != nil {
+		return err
+	}
+
+	return a.do(ctx, http.MethodPatch, "/Tickets", map[string]int{
+		"id":     id,
+		"status": a.completeStatus(),
+	}, nil)
+}
+
+func (a Autotask) IsClosed(ctx context.Context, ticketID string) (bool, error) {
+	result := struct {
+		Item struct {
+			Status int `json:"status"`
+		} `json:"item"`
+	}{}
+	if err := a.do(ctx, http.MethodGet, "/Tickets/"+ticketID, nil, &result); err != nil {
+		return false, err
+	}
+
+	return result.Item.Status == a.completeStatus(), nil
+}
+
+func (a Autotask) completeStatus() int {
+	if a.CompleteStatus == 0 {
+		return defaultAutotaskCompleteStatus
+	}
+
+	return a.CompleteStatus
+}
+
+func (a Autotask) do(ctx context.Context, method, path string, body, target any) error {
+	headers := http.Header{}
+	headers.Set("UserName", a.Username)
+	headers.Set("Secret", a.Secret)
+	headers.Set("ApiIntegrationCode", a.IntegrationCode)
+
+	return doJSON(ctx, a.HTTPClient, method, strings.TrimSuffix(a.BaseURL, "/")+path, headers, body, target)
+}
@@ -0,0 +1,96 @@
+package psa_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/equalsgibson/goslide/psa"
+)
+
+func TestAutotask(t *testing.T) {
+	validateAuth := func(r *http.Request) error {
+		if r.Header.Get("UserName") != "api@acme.com" || r.Header.Get("Secret") != "secret" || r.Header.Get("ApiIntegrationCode") != "integration-code" {
+			return errors.New("missing Autotask credentials")
+		}
+
+		return nil
+	}
+
+	adapter := psa.Autotask{
+		BaseURL:         "https://webservices2.autotask.net/atservicesrest/v1.0",
+		Username:        "api@acme.com",
+		Secret:          "secret",
+		IntegrationCode: "integration-code",
+		QueueID:         8,
+		HTTPClient: &http.Client{
+			Transport: roundtripper.NetworkQueue(t, []roundtripper.TestRoundTripFunc{
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/autotask/company_query_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method: http.MethodPost,
+						Path:   "/atservicesrest/v1.0/Companies/query",
+						Query:  url.Values{},
+						Validator: func(r *http.Request) error {
+							return errors.Join(validateAuth(r), validateRequestBody(t, "testdata/requests/autotask/company_query.json")(r))
+						},
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/autotask/ticket_create_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPost,
+						Path:      "/atservicesrest/v1.0/Tickets",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/autotask/create_ticket.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/autotask/note_create_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPost,
+						Path:      "/atservicesrest/v1.0/Tickets/7012/Notes",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/autotask/add_note.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/autotask/ticket_update_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPatch,
+						Path:      "/atservicesrest/v1.0/Tickets",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/autotask/close_ticket.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/autotask/ticket_get_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method: http.MethodGet,
+						Path:   "/atservicesrest/v1.0/Tickets/7012",
+						Query:  url.Values{},
+					},
+				),
+			}),
+		},
+	}
+
+	testAdapter(t, adapter, "29683", "7012")
+}
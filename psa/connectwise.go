@@ -0,0 +1,144 @@
+package psa
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const defaultConnectWiseClosedStatus = "Closed"
+
+// ConnectWise opens service tickets in ConnectWise Manage.
+type ConnectWise struct {
+	// BaseURL is the REST API root, such as
+	// https://na.myconnectwise.net/v4_6_release/apis/3.0
+	BaseURL    string
+	CompanyID  string
+	PublicKey  string
+	PrivateKey string
+	ClientID   string
+
+	// BoardID is the service board of new tickets, or the default board when 0
+	BoardID int
+	// ClosedStatus is the status name set on closed tickets, "Closed" by default
+	ClosedStatus string
+
+	HTTPClient *http.Client
+}
+
+func (c ConnectWise) FindCompany(ctx context.Context, name string) (string, error) {
+	query := url.Values{}
+	query.Set("conditions", `name="`+strings.ReplaceAll(name, `"`, `\"`)+`"`)
+
+	companies := []struct {
+		ID int `json:"id"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/company/companies?"+query.Encode(), nil, &companies); err != nil {
+		return "", err
+	}
+
+	if len(companies) == 0 {
+		return "", ErrCompanyNotFound
+	}
+
+	return strconv.Itoa(companies[0].ID), nil
+}
+
+func (c ConnectWise) CreateTicket(ctx context.Context, ticket Ticket) (string, error) {
+	type reference struct {
+		ID int `json:"id"`
+	}
+
+	type ticketPayload struct {
+		Summary            string     `json:"summary"`
+		InitialDescription string     `json:"initialDescription"`
+		Company            reference  `json:"company"`
+		Board              *reference `json:"board,omitempty"`
+	}
+
+	companyID, err := strconv.Atoi(ticket.CompanyID)
+	if err != nil {
+		return "", err
+	}
+
+	payload := ticketPayload{
+		// Summaries are limited to 100 characters
+		Summary:            truncate(ticket.Summary, 100),
+		InitialDescription: ticket.Description,
+		Company:            reference{ID: companyID},
+	}
+
+	if c.BoardID != 0 {
+		payload.Board = &reference{ID: c.BoardID}
+	}
+
+	created := struct {
+		ID int `json:"id"`
+	}{}
+	if err := c.do(ctx, http.MethodPost, "/service/tickets", payload, &created); err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(created.ID), nil
+}
+
+func (c ConnectWise) AddNote(ctx context.Context, ticketID, note string) error {
+	return c.do(ctx, http.MethodPost, "/service/tickets/"+ticketID+"/notes", map[string]any{
+		"text":                 note,
+		"internalAnalysisFlag": true,
+	}, nil)
+}
+
+func (c ConnectWise) CloseTicket(ctx context.Context, ticketID, note string) error {
+	if err := c.AddNote(ctx, ticketID, note); err != nil {
+		return err
+	}
+
+	status := c.ClosedStatus
+	if status == "" {
+		status = defaultConnectWiseClosedStatus
+	}
+
+	type patchOperation struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}
+
+	return c.do(ctx, http.MethodPatch, "/service/tickets/"+ticketID, []patchOperation{
+		{Op: "replace", Path: "status", Value: map[string]string{"name": status}},
+	}, nil)
+}
+
+func (c ConnectWise) IsClosed(ctx context.Context, ticketID string) (bool, error) {
+	ticket := struct {
+		ClosedFlag bool `json:"closedFlag"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/service/tickets/"+ticketID, nil, &ticket); err != nil {
+		return false, err
+	}
+
+	return ticket.ClosedFlag, nil
+}
+
+func (c ConnectWise) do(ctx context.Context, method, path string, body, target any) error {
+	credentials := c.CompanyID + "+" + c.PublicKey + ":" + c.PrivateKey
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	headers.Set("clientId", c.ClientID)
+
+	return doJSON(ctx, c.HTTPClient, method, strings.TrimSuffix(c.BaseURL, "/")+path, headers, body, target)
+}
+
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+
+	return string(runes[:length])
+}
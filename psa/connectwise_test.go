@@ -0,0 +1,97 @@
+package psa_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/equalsgibson/goslide/psa"
+)
+
+func TestConnectWise(t *testing.T) {
+	validateAuth := func(r *http.Request) error {
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("acme+public:private"))
+		if r.Header.Get("Authorization") != expected || r.Header.Get("clientId") != "client-id" {
+			return errors.New("missing ConnectWise credentials")
+		}
+
+		return nil
+	}
+
+	adapter := psa.ConnectWise{
+		BaseURL:    "https://na.myconnectwise.net/v4_6_release/apis/3.0",
+		CompanyID:  "acme",
+		PublicKey:  "public",
+		PrivateKey: "private",
+		ClientID:   "client-id",
+		BoardID:    1,
+		HTTPClient: &http.Client{
+			Transport: roundtripper.NetworkQueue(t, []roundtripper.TestRoundTripFunc{
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/connectwise/company_find_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodGet,
+						Path:      "/v4_6_release/apis/3.0/company/companies",
+						Query:     url.Values{"conditions": []string{`name="ACME Corp"`}},
+						Validator: validateAuth,
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusCreated,
+						FilePath:   "testdata/responses/connectwise/ticket_create_201.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPost,
+						Path:      "/v4_6_release/apis/3.0/service/tickets",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/connectwise/create_ticket.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusCreated,
+						FilePath:   "testdata/responses/connectwise/note_create_201.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPost,
+						Path:      "/v4_6_release/apis/3.0/service/tickets/1001/notes",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/connectwise/add_note.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/connectwise/ticket_closed_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPatch,
+						Path:      "/v4_6_release/apis/3.0/service/tickets/1001",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/connectwise/close_ticket.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/connectwise/ticket_closed_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method: http.MethodGet,
+						Path:   "/v4_6_release/apis/3.0/service/tickets/1001",
+						Query:  url.Values{},
+					},
+				),
+			}),
+		},
+	}
+
+	testAdapter(t, adapter, "250", "1001")
+}
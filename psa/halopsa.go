@@ -0,0 +1,164 @@
+package psa
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const defaultHaloPSAClosedStatusID = 9
+
+// HaloPSA opens tickets through the HaloPSA API, authenticating with the
+// client credentials of an API application.
+type HaloPSA struct {
+	// BaseURL is the API root, such as https://acme.halopsa.com/api
+	BaseURL string
+	// TokenURL is the token endpoint, such as https://acme.halopsa.com/auth/token
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+
+	TicketTypeID int
+	// ClosedStatusID is the status set on closed tickets, 9 (closed) by default
+	ClosedStatusID int
+
+	HTTPClient *http.Client
+
+	once   sync.Once
+	client *http.Client
+}
+
+func (h *HaloPSA) FindCompany(ctx context.Context, name string) (string, error) {
+	query := url.Values{}
+	query.Set("search", name)
+
+	result := struct {
+		Clients []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"clients"`
+	}{}
+	if err := h.do(ctx, http.MethodGet, "/Client?"+query.Encode(), nil, &result); err != nil {
+		return "", err
+	}
+
+	// The search also matches partial names
+	for _, client := range result.Clients {
+		if strings.EqualFold(client.Name, name) {
+			return strconv.Itoa(client.ID), nil
+		}
+	}
+
+	return "", ErrCompanyNotFound
+}
+
+func (h *HaloPSA) CreateTicket(ctx context.Context, ticket Ticket) (string, error) {
+	type ticketPayload struct {
+		Summary      string `json:"summary"`
+		Details      string `json:"details"`
+		ClientID     int    `json:"client_id"`
+		TicketTypeID int    `json:"tickettype_id,omitempty"`
+	}
+
+	clientID, err := strconv.Atoi(ticket.CompanyID)
+	if err != nil {
+		return "", err
+	}
+
+	created := struct {
+		ID int `json:"id"`
+	}{}
+
+	// Tickets are created by posting an array, and the created ticket is returned
+	if err := h.do(ctx, http.MethodPost, "/Tickets", []ticketPayload{
+		{
+			Summary:      ticket.Summary,
+			Details:      ticket.Description,
+			ClientID:     clientID,
+			TicketTypeID: h.TicketTypeID,
+		},
+	}, &created); err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(created.ID), nil
+}
+
+type haloAction struct {
+	TicketID       int    `json:"ticket_id"`
+	Note           string `json:"note"`
+	Outcome        string `json:"outcome"`
+	HiddenFromUser bool   `json:"hiddenfromuser"`
+	NewStatus      int    `json:"new_status,omitempty"`
+}
+
+func (h *HaloPSA) AddNote(ctx context.Context, ticketID, note string) error {
+	id, err := strconv.Atoi(ticketID)
+	if err != nil {
+		return err
+	}
+
+	return h.do(ctx, http.MethodPost, "/Actions", []haloAction{
+		{TicketID: id, Note: note, Outcome: "Note", HiddenFromUser: true},
+	}, nil)
+}
+
+// CloseTicket adds the note as an action that also sets the closed status.
+func (h *HaloPSA) CloseTicket(ctx context.Context, ticketID, note string) error {
+	id, err := strconv.Atoi(ticketID)
+	if err != nil {
+		return err
+	}
+
+	return h.do(ctx, http.MethodPost, "/Actions", []haloAction{
+		{TicketID: id, Note: note, Outcome: "Closed", HiddenFromUser: true, NewStatus: h.closedStatusID()},
+	}, nil)
+}
+
+func (h *HaloPSA) IsClosed(ctx context.Context, ticketID string) (bool, error) {
+	ticket := struct {
+		StatusID      int  `json:"status_id"`
+		HasBeenClosed bool `json:"hasbeenclosed"`
+	}{}
+	if err := h.do(ctx, http.MethodGet, "/Tickets/"+ticketID, nil, &ticket); err != nil {
+		return false, err
+	}
+
+	return ticket.HasBeenClosed || ticket.StatusID == h.closedStatusID(), nil
+}
+
+func (h *HaloPSA) closedStatusID() int {
+	if h.ClosedStatusID == 0 {
+		return defaultHaloPSAClosedStatusID
+	}
+
+	return h.ClosedStatusID
+}
+
+// do authenticates through a token source shared by all requests, so a token
+// is only requested again once it expires.
+func (h *HaloPSA) do(ctx context.Context, method, path string, body, target any) error {
+	h.once.Do(func() {
+		config := clientcredentials.Config{
+			ClientID:     h.ClientID,
+			ClientSecret: h.ClientSecret,
+			TokenURL:     h.TokenURL,
+			Scopes:       []string{"all"},
+		}
+
+		tokenCtx := context.Background()
+		if h.HTTPClient != nil {
+			tokenCtx = context.WithValue(tokenCtx, oauth2.HTTPClient, h.HTTPClient)
+		}
+
+		h.client = config.Client(tokenCtx)
+	})
+
+	return doJSON(ctx, h.client, method, strings.TrimSuffix(h.BaseURL, "/")+path, nil, body, target)
+}
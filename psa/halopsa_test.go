@@ -0,0 +1,108 @@
+package psa_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/equalsgibson/goslide/psa"
+)
+
+func TestHaloPSA(t *testing.T) {
+	validateAuth := func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer halo-token" {
+			return errors.New("missing HaloPSA access token")
+		}
+
+		return nil
+	}
+
+	adapter := &psa.HaloPSA{
+		BaseURL:      "https://acme.halopsa.com/api",
+		TokenURL:     "https://acme.halopsa.com/auth/token",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		TicketTypeID: 3,
+		HTTPClient: &http.Client{
+			Transport: roundtripper.NetworkQueue(t, []roundtripper.TestRoundTripFunc{
+				// The token is requested once and reused by every following request
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/halopsa/token_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method: http.MethodPost,
+						Path:   "/auth/token",
+						Query:  url.Values{},
+						Validator: func(r *http.Request) error {
+							if err := r.ParseForm(); err != nil {
+								return err
+							}
+
+							if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != "all" {
+								return errors.New("expected a client credentials grant for the all scope")
+							}
+
+							return nil
+						},
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/halopsa/client_search_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodGet,
+						Path:      "/api/Client",
+						Query:     url.Values{"search": []string{"ACME Corp"}},
+						Validator: validateAuth,
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusCreated,
+						FilePath:   "testdata/responses/halopsa/ticket_create_201.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method: http.MethodPost,
+						Path:   "/api/Tickets",
+						Query:  url.Values{},
+						Validator: func(r *http.Request) error {
+							return errors.Join(validateAuth(r), validateRequestBody(t, "testdata/requests/halopsa/create_ticket.json")(r))
+						},
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusCreated,
+						FilePath:   "testdata/responses/halopsa/action_create_201.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodPost,
+						Path:      "/api/Actions",
+						Query:     url.Values{},
+						Validator: validateRequestBody(t, "testdata/requests/halopsa/close_ticket.json"),
+					},
+				),
+				roundtripper.ServeAndValidate(t,
+					&roundtripper.TestResponseFile{
+						StatusCode: http.StatusOK,
+						FilePath:   "testdata/responses/halopsa/ticket_get_200.json",
+					},
+					roundtripper.ExpectedTestRequest{
+						Method:    http.MethodGet,
+						Path:      "/api/Tickets/2045",
+						Query:     url.Values{},
+						Validator: validateAuth,
+					},
+				),
+			}),
+		},
+	}
+
+	testAdapter(t, adapter, "12", "2045")
+}
@@ -0,0 +1,75 @@
+package psa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StatusError is returned by the adapters for a non 2xx response.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d: %s", e.StatusCode, e.Body)
+}
+
+// doJSON sends body as JSON and decodes the response into target. A nil body
+// sends no content and a nil target discards the response.
+func doJSON(ctx context.Context, httpClient *http.Client, method, url string, headers http.Header, body, target any) error {
+	var requestBody io.Reader = http.NoBody
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		requestBody = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, url, requestBody)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	for key, values := range headers {
+		request.Header[key] = values
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+		return StatusError{
+			StatusCode: response.StatusCode,
+			Body:       string(responseBody),
+		}
+	}
+
+	if target == nil {
+		_, _ = io.Copy(io.Discard, response.Body)
+
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
@@ -0,0 +1,279 @@
+// Package psa keeps tickets in a PSA in sync with Slide alerts: a ticket is
+// opened when an alert is raised, noted when it is reopened and closed when
+// it is resolved. Tickets closed in the PSA resolve their alert in Slide.
+package psa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+	"github.com/equalsgibson/goslide/internal/owner"
+	"github.com/equalsgibson/goslide/notify"
+)
+
+// ErrCompanyNotFound is returned by Adapter.FindCompany when no company has
+// the name of the Slide client.
+var ErrCompanyNotFound = errors.New("company not found")
+
+// Ticket is a ticket to open in the PSA.
+type Ticket struct {
+	CompanyID   string
+	Summary     string
+	Description string
+}
+
+// Adapter is a PSA that tickets can be opened in.
+type Adapter interface {
+	FindCompany(ctx context.Context, name string) (string, error)
+	CreateTicket(ctx context.Context, ticket Ticket) (string, error)
+	AddNote(ctx context.Context, ticketID, note string) error
+	CloseTicket(ctx context.Context, ticketID, note string) error
+	IsClosed(ctx context.Context, ticketID string) (bool, error)
+}
+
+type Syncer struct {
+	service goslide.Service
+	adapter Adapter
+	store   *Store
+	now     func() time.Time
+
+	// companies holds the PSA company of each Slide client, by client ID
+	companies map[string]string
+	owners    *owner.Cache
+}
+
+type syncerOption func(s *Syncer)
+
+// WithCompany maps a Slide client to a PSA company, for clients whose name
+// differs from the company name in the PSA.
+func WithCompany(clientID, companyID string) syncerOption {
+	return func(s *Syncer) {
+		s.companies[clientID] = companyID
+	}
+}
+
+func NewSyncer(service goslide.Service, adapter Adapter, store *Store, options ...syncerOption) *Syncer {
+	syncer := &Syncer{
+		service:   service,
+		adapter:   adapter,
+		store:     store,
+		now:       time.Now,
+		companies: map[string]string{},
+		owners:    owner.NewCache(service),
+	}
+
+	for _, option := range options {
+		option(syncer)
+	}
+
+	return syncer
+}
+
+// Handler returns Handle bound to ctx, to pass to alertwatch.Watcher.Run.
+func (s *Syncer) Handler(ctx context.Context) func(event alertwatch.Event) error {
+	return func(event alertwatch.Event) error {
+		return s.Handle(ctx, event)
+	}
+}
+
+// Handle updates the ticket of the alert in the event. Handling the same event
+// twice has no further effect.
+func (s *Syncer) Handle(ctx context.Context, event alertwatch.Event) error {
+	mapping, ok := s.store.Get(event.Alert.AlertID)
+
+	switch event.Type {
+	case alertwatch.EventType_ALERT_RAISED, alertwatch.EventType_ALERT_REOPENED:
+		if ok && !mapping.Closed {
+			if event.Type == alertwatch.EventType_ALERT_RAISED {
+				return nil
+			}
+
+			if err := s.adapter.AddNote(ctx, mapping.TicketID, "The alert was reopened in Slide."); err != nil {
+				return fmt.Errorf("psa: adding note to ticket %s - %w", mapping.TicketID, err)
+			}
+
+			mapping.UpdatedAt = s.now().UTC()
+
+			return s.store.Put(mapping)
+		}
+
+		return s.open(ctx, event.Alert, ok)
+	case alertwatch.EventType_ALERT_RESOLVED:
+		if !ok || mapping.Closed {
+			return nil
+		}
+
+		note := "The alert was resolved in Slide"
+		if event.Alert.ResolvedBy != "" {
+			note += " by " + event.Alert.ResolvedBy
+		}
+
+		if err := s.adapter.CloseTicket(ctx, mapping.TicketID, note+"."); err != nil {
+			return fmt.Errorf("psa: closing ticket %s - %w", mapping.TicketID, err)
+		}
+
+		mapping.Closed = true
+		mapping.UpdatedAt = s.now().UTC()
+
+		return s.store.Put(mapping)
+	}
+
+	return nil
+}
+
+// SyncClosedTickets resolves the alerts whose ticket was closed in the PSA.
+func (s *Syncer) SyncClosedTickets(ctx context.Context) error {
+	errs := []error{}
+	for _, mapping := range s.store.Open() {
+		closed, err := s.adapter.IsClosed(ctx, mapping.TicketID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ticket %s - %w", mapping.TicketID, err))
+
+			continue
+		}
+
+		if !closed {
+			continue
+		}
+
+		if _, err := s.service.Alerts().Update(ctx, mapping.AlertID, true); err != nil && !owner.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("resolving alert %s - %w", mapping.AlertID, err))
+
+			continue
+		}
+
+		mapping.Closed = true
+		mapping.UpdatedAt = s.now().UTC()
+		if err := s.store.Put(mapping); err != nil {
+			return err
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("psa: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Syncer) open(ctx context.Context, alert goslide.Alert, reopened bool) error {
+	clientID, err := s.owners.ClientID(ctx, alert)
+	if err != nil {
+		return fmt.Errorf("psa: alert %s - %w", alert.AlertID, err)
+	}
+
+	if clientID == "" {
+		return fmt.Errorf("psa: alert %s does not belong to a client", alert.AlertID)
+	}
+
+	agentName, deviceName, err := s.names(ctx, alert)
+	if err != nil {
+		return fmt.Errorf("psa: alert %s - %w", alert.AlertID, err)
+	}
+
+	companyID, err := s.company(ctx, clientID)
+	if err != nil {
+		return fmt.Errorf("psa: alert %s - %w", alert.AlertID, err)
+	}
+
+	ticket := Ticket{
+		CompanyID:   companyID,
+		Summary:     "Slide: " + notify.Title(alert.AlertType),
+		Description: description(alert, agentName, deviceName, reopened),
+	}
+
+	// The agent is what the alert is about when both are set
+	if agentName != "" {
+		ticket.Summary += " on " + agentName
+	} else if deviceName != "" {
+		ticket.Summary += " on " + deviceName
+	}
+
+	ticketID, err := s.adapter.CreateTicket(ctx, ticket)
+	if err != nil {
+		return fmt.Errorf("psa: opening ticket for alert %s - %w", alert.AlertID, err)
+	}
+
+	now := s.now().UTC()
+
+	return s.store.Put(Mapping{
+		AlertID:   alert.AlertID,
+		TicketID:  ticketID,
+		CompanyID: companyID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func description(alert goslide.Alert, agentName, deviceName string, reopened bool) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s\n", notify.Title(alert.AlertType))
+	if alert.AgentID != "" {
+		fmt.Fprintf(&sb, "Agent: %s\n", agentName)
+	}
+	if alert.DeviceID != "" {
+		fmt.Fprintf(&sb, "Device: %s\n", deviceName)
+	}
+	fmt.Fprintf(&sb, "Slide alert: %s\n", alert.AlertID)
+	fmt.Fprintf(&sb, "Raised: %s\n", alert.CreatedAt.Format(time.RFC3339))
+
+	if reopened {
+		sb.WriteString("\nThe alert was reopened after its previous ticket was closed.\n")
+	}
+
+	return sb.String()
+}
+
+// names returns the names of the agent and device of an alert, empty for
+// those it has none of.
+func (s *Syncer) names(ctx context.Context, alert goslide.Alert) (string, string, error) {
+	agentName, deviceName := "", ""
+
+	if alert.AgentID != "" {
+		agent, err := s.owners.Agent(ctx, alert.AgentID)
+		if err != nil {
+			return "", "", err
+		}
+
+		agentName = owner.AgentName(agent)
+	}
+
+	if alert.DeviceID != "" {
+		device, err := s.owners.Device(ctx, alert.DeviceID)
+		if err != nil {
+			return "", "", err
+		}
+
+		deviceName = owner.DeviceName(device)
+	}
+
+	return agentName, deviceName, nil
+}
+
+// company finds the PSA company with the name of the Slide client, unless
+// the client was mapped with WithCompany.
+func (s *Syncer) company(ctx context.Context, clientID string) (string, error) {
+	if companyID, ok := s.companies[clientID]; ok {
+		return companyID, nil
+	}
+
+	client, err := s.service.Clients().Get(ctx, clientID)
+	if err != nil {
+		return "", err
+	}
+
+	companyID, err := s.adapter.FindCompany(ctx, client.Name)
+	if err != nil {
+		return "", fmt.Errorf("client %q (%s) - %w", client.Name, clientID, err)
+	}
+
+	s.companies[clientID] = companyID
+
+	return companyID, nil
+}
@@ -0,0 +1,339 @@
+package psa_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/alertwatch"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/psa"
+	"github.com/google/go-cmp/cmp"
+)
+
+func validateRequestBody(t *testing.T, filePath string) func(r *http.Request) error {
+	t.Helper()
+
+	return func(r *http.Request) error {
+		expectedBody, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("error during test setup - could not read file: %w", err)
+		}
+
+		actualBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("error during test setup - could not read request body: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(actualBody))
+
+		var actualBodyFormatted bytes.Buffer
+		if err := json.Indent(&actualBodyFormatted, actualBody, "", "    "); err != nil {
+			return fmt.Errorf("error during test setup - could not format request body: %w", err)
+		}
+
+		if diff := cmp.Diff(string(expectedBody), actualBodyFormatted.String()); diff != "" {
+			t.Fatalf("%s Expected Request Body mismatch (-want +got):\n%s", t.Name(), diff)
+		}
+
+		return nil
+	}
+}
+
+// testAdapter runs the recorded lifecycle of a ticket against an adapter.
+func testAdapter(t *testing.T, adapter psa.Adapter, expectedCompanyID, expectedTicketID string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	companyID, err := adapter.FindCompany(ctx, "ACME Corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if companyID != expectedCompanyID {
+		t.Fatalf("%s expected company %s, got %s", t.Name(), expectedCompanyID, companyID)
+	}
+
+	ticketID, err := adapter.CreateTicket(ctx, psa.Ticket{
+		CompanyID:   companyID,
+		Summary:     "Slide: Agent backup failed on ACME DC01",
+		Description: "Agent backup failed\nSlide alert: al_0123456789ab\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ticketID != expectedTicketID {
+		t.Fatalf("%s expected ticket %s, got %s", t.Name(), expectedTicketID, ticketID)
+	}
+
+	if err := adapter.CloseTicket(ctx, ticketID, "The alert was resolved in Slide by John Smith."); err != nil {
+		t.Fatal(err)
+	}
+
+	closed, err := adapter.IsClosed(ctx, ticketID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !closed {
+		t.Fatalf("%s expected ticket %s to be closed", t.Name(), ticketID)
+	}
+}
+
+type fakeTicket struct {
+	psa.Ticket
+	Notes  []string
+	Closed bool
+}
+
+// fakeAdapter is a PSA in memory.
+type fakeAdapter struct {
+	companies map[string]string
+	tickets   map[string]*fakeTicket
+}
+
+func newFakeAdapter() *fakeAdapter {
+	return &fakeAdapter{
+		companies: map[string]string{"Acme": "250"},
+		tickets:   map[string]*fakeTicket{},
+	}
+}
+
+func (f *fakeAdapter) FindCompany(ctx context.Context, name string) (string, error) {
+	if companyID, ok := f.companies[name]; ok {
+		return companyID, nil
+	}
+
+	return "", psa.ErrCompanyNotFound
+}
+
+func (f *fakeAdapter) CreateTicket(ctx context.Context, ticket psa.Ticket) (string, error) {
+	ticketID := strconv.Itoa(1001 + len(f.tickets))
+	f.tickets[ticketID] = &fakeTicket{Ticket: ticket}
+
+	return ticketID, nil
+}
+
+func (f *fakeAdapter) AddNote(ctx context.Context, ticketID, note string) error {
+	f.tickets[ticketID].Notes = append(f.tickets[ticketID].Notes, note)
+
+	return nil
+}
+
+func (f *fakeAdapter) CloseTicket(ctx context.Context, ticketID, note string) error {
+	f.tickets[ticketID].Notes = append(f.tickets[ticketID].Notes, note)
+	f.tickets[ticketID].Closed = true
+
+	return nil
+}
+
+func (f *fakeAdapter) IsClosed(ctx context.Context, ticketID string) (bool, error) {
+	return f.tickets[ticketID].Closed, nil
+}
+
+// testAlerts returns an agent and a device alert of the fleet.
+func testAlerts() []goslide.Alert {
+	return []goslide.Alert{
+		{AlertID: "al_1", AlertType: goslide.AlertType_AGENT_BACKUP_FAILED, AgentID: "a_dc", DeviceID: "d_acme"},
+		{AlertID: "al_2", AlertType: goslide.AlertType_DEVICE_STORAGE_SPACE_LOW, DeviceID: "d_acme"},
+	}
+}
+
+func raised(alert goslide.Alert) alertwatch.Event {
+	return alertwatch.Event{Type: alertwatch.EventType_ALERT_RAISED, Alert: alert}
+}
+
+func TestSyncer(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	adapter := newFakeAdapter()
+	storePath := filepath.Join(t.TempDir(), "tickets.json")
+
+	store, err := psa.OpenStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	syncer := psa.NewSyncer(server.Service(), adapter, store)
+
+	alert := server.Alerts[0]
+	alert.CreatedAt = time.Date(2024, time.August, 23, 1, 25, 8, 0, time.UTC)
+	if err := syncer.Handle(ctx, raised(alert)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := psa.Ticket{
+		CompanyID: "250",
+		Summary:   "Slide: Agent backup failed on Acme DC01",
+		Description: `Agent backup failed
+Agent: Acme DC01
+Device: Acme HQ
+Slide alert: al_1
+Raised: 2024-08-23T01:25:08Z
+`,
+	}
+	if diff := cmp.Diff(expected, adapter.tickets["1001"].Ticket); diff != "" {
+		t.Fatalf("%s Ticket mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// A restarted sync with the same mapping file does not open a second ticket
+	store, err = psa.OpenStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncer = psa.NewSyncer(server.Service(), adapter, store)
+	if err := syncer.Handle(ctx, raised(alert)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(adapter.tickets) != 1 {
+		t.Fatalf("%s expected one ticket, got %d", t.Name(), len(adapter.tickets))
+	}
+
+	if err := syncer.Handle(ctx, alertwatch.Event{Type: alertwatch.EventType_ALERT_REOPENED, Alert: alert}); err != nil {
+		t.Fatal(err)
+	}
+
+	alert.Resolved = true
+	alert.ResolvedBy = "John Smith"
+	if err := syncer.Handle(ctx, alertwatch.Event{Type: alertwatch.EventType_ALERT_RESOLVED, Alert: alert}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNotes := []string{
+		"The alert was reopened in Slide.",
+		"The alert was resolved in Slide by John Smith.",
+	}
+	if diff := cmp.Diff(expectedNotes, adapter.tickets["1001"].Notes); diff != "" {
+		t.Fatalf("%s Notes mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if !adapter.tickets["1001"].Closed {
+		t.Fatalf("%s expected the ticket to be closed", t.Name())
+	}
+
+	// Reopening after the ticket was closed opens a new ticket
+	alert.Resolved = false
+	if err := syncer.Handle(ctx, alertwatch.Event{Type: alertwatch.EventType_ALERT_REOPENED, Alert: alert}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(adapter.tickets) != 2 || !strings.Contains(adapter.tickets["1002"].Description, "reopened") {
+		t.Fatalf("%s expected a new ticket for the reopened alert, got %d tickets", t.Name(), len(adapter.tickets))
+	}
+}
+
+func TestSyncer_Handler(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	adapter := newFakeAdapter()
+
+	store, err := psa.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	syncer := psa.NewSyncer(server.Service(), adapter, store)
+
+	watcher := alertwatch.NewWatcher(server.Service(), alertwatch.WithInitialEvents())
+	if err := watcher.Poll(ctx, syncer.Handler(ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(adapter.tickets) != len(server.Alerts) {
+		t.Fatalf("%s expected a ticket per open alert, got %d", t.Name(), len(adapter.tickets))
+	}
+}
+
+func TestSyncer_SyncClosedTickets(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	adapter := newFakeAdapter()
+
+	store, err := psa.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	syncer := psa.NewSyncer(server.Service(), adapter, store)
+
+	for _, alert := range server.Alerts {
+		if err := syncer.Handle(ctx, raised(alert)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first ticket is closed by a technician in the PSA
+	adapter.tickets["1001"].Closed = true
+
+	if err := syncer.SyncClosedTickets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !server.Alerts[0].Resolved || server.Alerts[1].Resolved {
+		t.Fatalf("%s expected only the first alert to be resolved, got %+v", t.Name(), server.Alerts)
+	}
+
+	// Closed tickets are not checked again
+	if err := syncer.SyncClosedTickets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := server.Count("PATCH /v1/alert/al_1"); count != 1 {
+		t.Fatalf("%s expected the alert to be resolved once, got %d", t.Name(), count)
+	}
+
+	// The resolved event of the watcher does not close the ticket again
+	resolved := server.Alerts[0]
+	if err := syncer.Handle(ctx, alertwatch.Event{Type: alertwatch.EventType_ALERT_RESOLVED, Alert: resolved}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(adapter.tickets["1001"].Notes) != 0 {
+		t.Fatalf("%s expected no notes, got %v", t.Name(), adapter.tickets["1001"].Notes)
+	}
+}
+
+func TestSyncer_Company(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Alerts = testAlerts()
+	server.Alerts = append(server.Alerts, goslide.Alert{AlertID: "al_globex", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_globex"})
+	adapter := newFakeAdapter()
+
+	store, err := psa.OpenStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	err = psa.NewSyncer(server.Service(), adapter, store).Handle(ctx, raised(server.Alerts[2]))
+	if !errors.Is(err, psa.ErrCompanyNotFound) || !strings.Contains(err.Error(), `client "Globex" (c_globex)`) {
+		t.Fatalf("%s expected a company not found error, got %v", t.Name(), err)
+	}
+
+	syncer := psa.NewSyncer(server.Service(), adapter, store, psa.WithCompany("c_globex", "777"))
+	if err := syncer.Handle(ctx, raised(server.Alerts[2])); err != nil {
+		t.Fatal(err)
+	}
+
+	if ticket := adapter.tickets["1001"]; ticket.CompanyID != "777" || ticket.Summary != "Slide: Device not checking in on globex-slide" {
+		t.Fatalf("%s unexpected ticket %+v", t.Name(), ticket.Ticket)
+	}
+}
@@ -0,0 +1,105 @@
+package psa
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/equalsgibson/goslide/internal/jsonfile"
+)
+
+// Mapping links a Slide alert to the ticket opened for it.
+type Mapping struct {
+	AlertID   string    `json:"alert_id"`
+	TicketID  string    `json:"ticket_id"`
+	CompanyID string    `json:"company_id"`
+	Closed    bool      `json:"closed"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store keeps the mappings in a local JSON file, so a sync that is run again
+// never opens a second ticket for the same alert.
+type Store struct {
+	path string
+
+	mu       sync.Mutex
+	mappings map[string]Mapping
+}
+
+// OpenStore loads the mappings from path. An empty path keeps the mappings
+// in memory only.
+func OpenStore(path string) (*Store, error) {
+	store := &Store{
+		path:     path,
+		mappings: map[string]Mapping{},
+	}
+
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.mappings); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *Store) Get(alertID string) (Mapping, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mapping, ok := s.mappings[alertID]
+
+	return mapping, ok
+}
+
+// Open returns the mappings whose ticket is still open, ordered by alert ID.
+func (s *Store) Open() []Mapping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open := []Mapping{}
+	for _, mapping := range s.mappings {
+		if !mapping.Closed {
+			open = append(open, mapping)
+		}
+	}
+
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].AlertID < open[j].AlertID
+	})
+
+	return open
+}
+
+func (s *Store) Put(mapping Mapping) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mappings[mapping.AlertID] = mapping
+
+	if s.path == "" {
+		return nil
+	}
+
+	return s.save()
+}
+
+func (s *Store) save() error {
+	return jsonfile.Write(s.path, s.mappings)
+}
@@ -0,0 +1,403 @@
+// Package reaper removes file, image and virtual machine restores that have
+// expired or been abandoned.
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	defaultPollInterval    = 5 * time.Second
+	defaultShutdownTimeout = 5 * time.Minute
+)
+
+// Policy decides which restores are removed. A restore matching any enabled
+// rule is removed, unless Keep names it.
+type Policy struct {
+	// MaxAge removes restores created longer ago than this
+	MaxAge time.Duration
+
+	// Expired removes restores whose ExpiresAt has passed. Image exports have
+	// no expiry and are only covered by MaxAge.
+	Expired bool
+
+	// MaxStopped removes virtual machines that have been stopped for longer than
+	// this. The API does not report when a machine stopped, so the reaper
+	// records when it first saw each machine stopped; use WithStatePath to
+	// remember this between runs.
+	MaxStopped time.Duration
+
+	// Keep lists restores that are never removed, by their ID, or the ID or
+	// name of their agent, device or client. Restores have no labels of their
+	// own, so the display names and hostnames of agents and devices, and the
+	// names of clients, serve as labels.
+	Keep []string
+}
+
+type RestoreKind string
+
+const (
+	RestoreKind_FILE            RestoreKind = "file"
+	RestoreKind_IMAGE           RestoreKind = "image"
+	RestoreKind_VIRTUAL_MACHINE RestoreKind = "virtual_machine"
+)
+
+type Reaper struct {
+	service         goslide.Service
+	policy          Policy
+	enforce         bool
+	statePath       string
+	pollInterval    time.Duration
+	shutdownTimeout time.Duration
+	now             func() time.Time
+
+	// stoppedSince records when each virtual machine was first seen stopped
+	stoppedSince map[string]time.Time
+	// kept holds the IDs of the restores, agents and devices named by Keep
+	kept map[string]bool
+}
+
+type reaperOption func(r *Reaper)
+
+// WithEnforce makes the reaper delete the restores it selects. Without it the
+// reaper runs in dry-run mode and only reports what it would delete.
+func WithEnforce() reaperOption {
+	return func(r *Reaper) {
+		r.enforce = true
+	}
+}
+
+// WithStatePath sets the file used to remember when virtual machines were first seen stopped.
+func WithStatePath(path string) reaperOption {
+	return func(r *Reaper) {
+		r.statePath = path
+	}
+}
+
+// WithPollInterval sets how often the API is polled while a virtual machine shuts down.
+func WithPollInterval(interval time.Duration) reaperOption {
+	return func(r *Reaper) {
+		r.pollInterval = interval
+	}
+}
+
+// WithShutdownTimeout sets how long a running virtual machine is given to stop before it is skipped.
+func WithShutdownTimeout(timeout time.Duration) reaperOption {
+	return func(r *Reaper) {
+		r.shutdownTimeout = timeout
+	}
+}
+
+func NewReaper(service goslide.Service, policy Policy, options ...reaperOption) (*Reaper, error) {
+	reaper := &Reaper{
+		service:         service,
+		policy:          policy,
+		pollInterval:    defaultPollInterval,
+		shutdownTimeout: defaultShutdownTimeout,
+		now:             time.Now,
+		stoppedSince:    map[string]time.Time{},
+	}
+
+	for _, option := range options {
+		option(reaper)
+	}
+
+	if reaper.statePath != "" {
+		stoppedSince, err := loadState(reaper.statePath)
+		if err != nil {
+			return nil, err
+		}
+
+		reaper.stoppedSince = stoppedSince
+	}
+
+	return reaper, nil
+}
+
+// Run checks every restore against the policy. In enforce mode the selected
+// restores are deleted, shutting down running virtual machines first. Errors
+// deleting individual restores are recorded in the report rather than
+// stopping the run. If the state can not be saved afterwards, the report of
+// the deletions is returned with the error.
+func (r *Reaper) Run(ctx context.Context) (Report, error) {
+	report := Report{
+		Enforce:   r.enforce,
+		StartedAt: r.now().UTC(),
+		Actions:   []Action{},
+	}
+
+	kept, err := r.keep(ctx)
+	if err != nil {
+		return report, err
+	}
+	r.kept = kept
+
+	fileActions, err := r.fileRestores(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	imageActions, err := r.imageExports(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	virtualMachineActions, err := r.virtualMachines(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	if r.statePath != "" {
+		if err := saveState(r.statePath, r.stoppedSince); err != nil {
+			return report, err
+		}
+	}
+
+	deletedMachines := false
+	for _, action := range slices.Concat(fileActions, imageActions, virtualMachineActions) {
+		if r.enforce {
+			action = r.delete(ctx, action)
+		}
+
+		if action.Deleted && action.Kind == RestoreKind_VIRTUAL_MACHINE {
+			delete(r.stoppedSince, action.ID)
+			deletedMachines = true
+		}
+
+		report.Actions = append(report.Actions, action)
+	}
+
+	// The deletions are in the report even when the state can not be saved
+	if deletedMachines && r.statePath != "" {
+		if err := saveState(r.statePath, r.stoppedSince); err != nil {
+			return report, fmt.Errorf("reaper: unable to save state after deleting - %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *Reaper) fileRestores(ctx context.Context) ([]Action, error) {
+	actions := []Action{}
+
+	err := r.service.FileRestores().List(ctx, func(response goslide.ListResponse[goslide.FileRestore]) error {
+		for _, restore := range response.Data {
+			action := Action{
+				Kind:      RestoreKind_FILE,
+				ID:        restore.FileRestoreID,
+				AgentID:   restore.AgentID,
+				DeviceID:  restore.DeviceID,
+				CreatedAt: restore.CreatedAt,
+				ExpiresAt: restore.ExpiresAt,
+			}
+
+			if r.selected(&action) {
+				actions = append(actions, action)
+			}
+		}
+
+		return nil
+	})
+
+	return actions, err
+}
+
+func (r *Reaper) imageExports(ctx context.Context) ([]Action, error) {
+	actions := []Action{}
+
+	err := r.service.ImageExportRestores().List(ctx, func(response goslide.ListResponse[goslide.ImageExportRestore]) error {
+		for _, restore := range response.Data {
+			action := Action{
+				Kind:      RestoreKind_IMAGE,
+				ID:        restore.ImageExportID,
+				AgentID:   restore.AgentID,
+				DeviceID:  restore.DeviceID,
+				CreatedAt: restore.CreatedAt,
+			}
+
+			if r.selected(&action) {
+				actions = append(actions, action)
+			}
+		}
+
+		return nil
+	})
+
+	return actions, err
+}
+
+func (r *Reaper) virtualMachines(ctx context.Context) ([]Action, error) {
+	actions := []Action{}
+	seen := map[string]bool{}
+
+	err := r.service.VirtualMachineRestores().List(ctx, func(response goslide.ListResponse[goslide.VirtualMachineRestore]) error {
+		for _, vm := range response.Data {
+			seen[vm.VirtID] = true
+
+			action := Action{
+				Kind:      RestoreKind_VIRTUAL_MACHINE,
+				ID:        vm.VirtID,
+				AgentID:   vm.AgentID,
+				DeviceID:  vm.DeviceID,
+				CreatedAt: vm.CreatedAt,
+				ExpiresAt: vm.ExpiresAt,
+				State:     vm.State,
+			}
+
+			if vm.State == goslide.VirtualMachineState_STOPPED {
+				if _, ok := r.stoppedSince[vm.VirtID]; !ok {
+					r.stoppedSince[vm.VirtID] = r.now().UTC()
+				}
+
+				stoppedSince := r.stoppedSince[vm.VirtID]
+				action.StoppedSince = &stoppedSince
+			} else {
+				delete(r.stoppedSince, vm.VirtID)
+			}
+
+			if r.selected(&action) {
+				actions = append(actions, action)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Forget machines that no longer exist
+	for virtID := range r.stoppedSince {
+		if !seen[virtID] {
+			delete(r.stoppedSince, virtID)
+		}
+	}
+
+	return actions, nil
+}
+
+// keep resolves the names in Keep to the IDs of the agents and devices they
+// name. Entries that name nothing are kept as IDs.
+func (r *Reaper) keep(ctx context.Context) (map[string]bool, error) {
+	kept := map[string]bool{}
+	if len(r.policy.Keep) == 0 {
+		return kept, nil
+	}
+
+	for _, entry := range r.policy.Keep {
+		kept[entry] = true
+	}
+
+	clientIDs := map[string]bool{}
+	if err := r.service.Clients().List(ctx, func(response goslide.ListResponse[goslide.Client]) error {
+		for _, client := range response.Data {
+			if slices.Contains(r.policy.Keep, client.Name) {
+				clientIDs[client.ClientID] = true
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	named := func(id, clientID string, names ...string) {
+		if kept[clientID] || clientIDs[clientID] {
+			kept[id] = true
+		}
+
+		for _, name := range names {
+			if name != "" && slices.Contains(r.policy.Keep, name) {
+				kept[id] = true
+			}
+		}
+	}
+
+	if err := r.service.Devices().List(ctx, func(response goslide.ListResponse[goslide.Device]) error {
+		for _, device := range response.Data {
+			named(device.DeviceID, device.ClientID, device.DisplayName, device.Hostname)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := r.service.Agents().List(ctx, func(response goslide.ListResponse[goslide.Agent]) error {
+		for _, agent := range response.Data {
+			named(agent.AgentID, agent.ClientID, agent.DisplayName, agent.Hostname)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return kept, nil
+}
+
+// selected applies the policy, recording the reasons a restore was selected.
+func (r *Reaper) selected(action *Action) bool {
+	if r.kept[action.ID] || r.kept[action.AgentID] || r.kept[action.DeviceID] {
+		return false
+	}
+
+	now := r.now()
+
+	if r.policy.MaxAge > 0 && now.Sub(action.CreatedAt) > r.policy.MaxAge {
+		action.Reasons = append(action.Reasons, fmt.Sprintf("created more than %s ago", r.policy.MaxAge))
+	}
+
+	if r.policy.Expired && !action.ExpiresAt.IsZero() && now.After(action.ExpiresAt) {
+		action.Reasons = append(action.Reasons, "expired")
+	}
+
+	if r.policy.MaxStopped > 0 && action.StoppedSince != nil && now.Sub(*action.StoppedSince) > r.policy.MaxStopped {
+		action.Reasons = append(action.Reasons, fmt.Sprintf("stopped for more than %s", r.policy.MaxStopped))
+	}
+
+	return len(action.Reasons) > 0
+}
+
+func (r *Reaper) delete(ctx context.Context, action Action) Action {
+	var err error
+
+	switch action.Kind {
+	case RestoreKind_FILE:
+		err = r.service.FileRestores().Delete(ctx, action.ID)
+	case RestoreKind_IMAGE:
+		err = r.service.ImageExportRestores().Delete(ctx, action.ID)
+	case RestoreKind_VIRTUAL_MACHINE:
+		err = r.deleteVirtualMachine(ctx, action)
+	}
+
+	if err != nil {
+		action.Error = err.Error()
+
+		return action
+	}
+
+	action.Deleted = true
+
+	return action
+}
+
+// deleteVirtualMachine shuts a running or paused machine down before deleting
+// it. A machine that does not stop in time is left in place.
+func (r *Reaper) deleteVirtualMachine(ctx context.Context, action Action) error {
+	if action.State != goslide.VirtualMachineState_STOPPED {
+		shutdownCtx, cancel := context.WithTimeout(ctx, r.shutdownTimeout)
+		defer cancel()
+
+		if _, err := r.service.VirtualMachineRestores().Stop(shutdownCtx, action.ID, goslide.WithVirtualMachinePollInterval(r.pollInterval)); err != nil {
+			return fmt.Errorf("shutting down - %w", err)
+		}
+	}
+
+	return r.service.VirtualMachineRestores().Delete(ctx, action.ID)
+}
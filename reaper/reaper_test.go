@@ -0,0 +1,311 @@
+package reaper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/equalsgibson/goslide/reaper"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testPolicy = reaper.Policy{
+	MaxAge:     7 * 24 * time.Hour,
+	Expired:    true,
+	MaxStopped: 24 * time.Hour,
+	Keep:       []string{"a_mail"},
+}
+
+// addRestores adds restores covering each policy rule to the fleet, and
+// returns a state file recording that v_stopped_long was first seen stopped
+// three days ago.
+func addRestores(t *testing.T, server *fakeslide.Server) string {
+	t.Helper()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	server.FileRestores = []goslide.FileRestore{
+		{FileRestoreID: "fr_old", AgentID: "a_app", DeviceID: "d_acme", CreatedAt: now.Add(-10 * 24 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{FileRestoreID: "fr_fresh", AgentID: "a_app", DeviceID: "d_acme", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+	}
+	server.ImageExports = []goslide.ImageExportRestore{
+		{ImageExportID: "ie_kept", AgentID: "a_mail", DeviceID: "d_globex", CreatedAt: now.Add(-30 * 24 * time.Hour)},
+	}
+	server.VirtualMachines = []goslide.VirtualMachineRestore{
+		{VirtID: "v_expired", AgentID: "a_app", DeviceID: "d_acme", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour), State: goslide.VirtualMachineState_RUNNING},
+		{VirtID: "v_stopped_long", AgentID: "a_app", DeviceID: "d_acme", CreatedAt: now.Add(-4 * 24 * time.Hour), ExpiresAt: now.Add(time.Hour), State: goslide.VirtualMachineState_STOPPED},
+		{VirtID: "v_stopped_recent", AgentID: "a_app", DeviceID: "d_acme", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour), State: goslide.VirtualMachineState_STOPPED},
+	}
+
+	statePath := filepath.Join(t.TempDir(), "reaper.json")
+	state, err := json.Marshal(map[string]any{
+		"stopped_since": map[string]time.Time{
+			"v_stopped_long": now.Add(-3 * 24 * time.Hour),
+			"v_deleted":      now.Add(-3 * 24 * time.Hour),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(statePath, state, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return statePath
+}
+
+func actionIDs(report reaper.Report) []string {
+	ids := []string{}
+	for _, action := range report.Actions {
+		ids = append(ids, action.ID)
+	}
+
+	return ids
+}
+
+func TestReaper_DryRun(t *testing.T) {
+	server := fakeslide.NewFleet()
+	statePath := addRestores(t, server)
+
+	r, err := reaper.NewReaper(server.Service(), testPolicy, reaper.WithStatePath(statePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"fr_old", "v_expired", "v_stopped_long"}, actionIDs(report)); diff != "" {
+		t.Fatalf("%s Selected restores mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	for _, action := range report.Actions {
+		if action.Deleted {
+			t.Errorf("%s dry run deleted %s", t.Name(), action.ID)
+		}
+	}
+
+	if len(server.FileRestores) != 2 || len(server.ImageExports) != 1 || len(server.VirtualMachines) != 3 {
+		t.Errorf("%s dry run must not delete anything", t.Name())
+	}
+
+	if server.Count("PATCH /v1/restore/virt/v_expired") != 0 {
+		t.Errorf("%s dry run must not shut down virtual machines", t.Name())
+	}
+
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The recently stopped machine is recorded, and the missing one forgotten
+	for expected, present := range map[string]bool{"v_stopped_long": true, "v_stopped_recent": true, "v_deleted": false} {
+		if strings.Contains(string(data), expected) != present {
+			t.Errorf("%s expected %s in the state file: %t, got:\n%s", t.Name(), expected, present, data)
+		}
+	}
+
+	text := &bytes.Buffer{}
+	if err := report.WriteText(text); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"dry run", "fr_old", "created more than 168h0m0s ago", "expired", "stopped for more than 24h0m0s", "would delete"} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("%s expected report to contain %q, got:\n%s", t.Name(), expected, text)
+		}
+	}
+}
+
+func TestReaper_Enforce(t *testing.T) {
+	server := fakeslide.NewFleet()
+	statePath := addRestores(t, server)
+
+	r, err := reaper.NewReaper(
+		server.Service(),
+		testPolicy,
+		reaper.WithEnforce(),
+		reaper.WithStatePath(statePath),
+		reaper.WithPollInterval(time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range report.Actions {
+		if !action.Deleted || action.Error != "" {
+			t.Errorf("%s expected %s to be deleted, got error %q", t.Name(), action.ID, action.Error)
+		}
+	}
+
+	if len(server.FileRestores) != 1 || server.FileRestores[0].FileRestoreID != "fr_fresh" {
+		t.Errorf("%s expected only fr_fresh to remain, got %+v", t.Name(), server.FileRestores)
+	}
+
+	if len(server.ImageExports) != 1 {
+		t.Errorf("%s expected the kept image export to remain", t.Name())
+	}
+
+	if len(server.VirtualMachines) != 1 || server.VirtualMachines[0].VirtID != "v_stopped_recent" {
+		t.Errorf("%s expected only v_stopped_recent to remain, got %+v", t.Name(), server.VirtualMachines)
+	}
+
+	// The running machine is shut down before it is deleted, the stopped one is not
+	if server.Count("PATCH /v1/restore/virt/v_expired") != 1 {
+		t.Errorf("%s expected v_expired to be shut down before deletion", t.Name())
+	}
+
+	if server.Count("PATCH /v1/restore/virt/v_stopped_long") != 0 {
+		t.Errorf("%s expected v_stopped_long to be deleted without a shutdown", t.Name())
+	}
+}
+
+func TestReaper_KeepByName(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addRestores(t, server)
+	server.FileRestores = append(server.FileRestores, goslide.FileRestore{
+		FileRestoreID: "fr_legal",
+		AgentID:       "a_dc",
+		DeviceID:      "d_acme",
+		CreatedAt:     time.Now().Add(-30 * 24 * time.Hour),
+	})
+
+	testCases := []struct {
+		name     string
+		keep     []string
+		expected []string
+	}{
+		{
+			name:     "none",
+			expected: []string{"fr_old", "fr_legal", "ie_kept"},
+		},
+		{
+			name:     "agent hostname",
+			keep:     []string{"acme-app"},
+			expected: []string{"fr_legal", "ie_kept"},
+		},
+		{
+			name:     "device hostname",
+			keep:     []string{"globex-slide"},
+			expected: []string{"fr_old", "fr_legal"},
+		},
+		{
+			name:     "agent display name and client name",
+			keep:     []string{"Acme DC01", "Globex"},
+			expected: []string{"fr_old"},
+		},
+		{
+			name:     "restore ID",
+			keep:     []string{"fr_legal", "ie_kept"},
+			expected: []string{"fr_old"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r, err := reaper.NewReaper(server.Service(), reaper.Policy{MaxAge: 7 * 24 * time.Hour, Keep: testCase.keep})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := r.Run(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, actionIDs(report)); diff != "" {
+				t.Fatalf("%s Selected restores mismatch (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+}
+
+func TestReaper_Enforce_DeleteFailure(t *testing.T) {
+	server := fakeslide.NewFleet()
+	addRestores(t, server)
+	server.FailNext("DELETE /v1/restore/file/{file_restore_id}", 1)
+
+	r, err := reaper.NewReaper(server.Service(), reaper.Policy{MaxAge: 7 * 24 * time.Hour}, reaper.WithEnforce())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"fr_old", "ie_kept"}, actionIDs(report)); diff != "" {
+		t.Fatalf("%s Selected restores mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if report.Actions[0].Deleted || report.Actions[0].Error == "" {
+		t.Errorf("%s expected the failed deletion to be reported", t.Name())
+	}
+
+	if !report.Actions[1].Deleted {
+		t.Errorf("%s expected the run to continue after a failed deletion", t.Name())
+	}
+}
+
+func TestReaper_Enforce_StateSaveFailure(t *testing.T) {
+	server := fakeslide.NewFleet()
+	statePath := addRestores(t, server)
+
+	// The state directory is replaced by a file once a machine is deleted, so
+	// the state can no longer be saved
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/restore/virt/") {
+			dir := filepath.Dir(statePath)
+			if err := errors.Join(os.RemoveAll(dir), os.WriteFile(dir, nil, 0o600)); err != nil {
+				t.Error(err)
+			}
+		}
+
+		server.ServeHTTP(w, r)
+	})
+
+	r, err := reaper.NewReaper(
+		goslide.NewService("fakeToken", goslide.WithCustomRoundtripper(roundtripper.HandlerNetwork(handler))),
+		testPolicy,
+		reaper.WithEnforce(),
+		reaper.WithStatePath(statePath),
+		reaper.WithPollInterval(time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := r.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unable to save state") {
+		t.Fatalf("%s expected a state save error, got %v", t.Name(), err)
+	}
+
+	for _, action := range report.Actions {
+		if !action.Deleted || action.Error != "" {
+			t.Errorf("%s expected %s to be reported as deleted, got error %q", t.Name(), action.ID, action.Error)
+		}
+	}
+
+	if len(server.VirtualMachines) != 1 {
+		t.Errorf("%s expected the machines to be deleted, got %+v", t.Name(), server.VirtualMachines)
+	}
+}
@@ -0,0 +1,68 @@
+package reaper
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+type Report struct {
+	Enforce   bool      `json:"enforce"`
+	StartedAt time.Time `json:"started_at"`
+	Actions   []Action  `json:"actions"`
+}
+
+// Action is a restore selected by the policy. In dry-run mode Deleted is always false.
+type Action struct {
+	Kind         RestoreKind                 `json:"kind"`
+	ID           string                      `json:"id"`
+	AgentID      string                      `json:"agent_id"`
+	DeviceID     string                      `json:"device_id"`
+	CreatedAt    time.Time                   `json:"created_at"`
+	ExpiresAt    time.Time                   `json:"expires_at,omitempty"`
+	State        goslide.VirtualMachineState `json:"state,omitempty"`
+	StoppedSince *time.Time                  `json:"stopped_since,omitempty"`
+	Reasons      []string                    `json:"reasons"`
+	Deleted      bool                        `json:"deleted"`
+	Error        string                      `json:"error,omitempty"`
+}
+
+// WriteText writes the report as a table.
+func (r Report) WriteText(w io.Writer) error {
+	mode := "dry run, nothing was deleted"
+	if r.Enforce {
+		mode = "enforce"
+	}
+
+	if _, err := fmt.Fprintf(w, "Restore reaper run at %s (%s)\n\n", r.StartedAt.Format(time.RFC3339), mode); err != nil {
+		return err
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "KIND\tID\tAGENT\tCREATED\tREASONS\tRESULT")
+
+	for _, action := range r.Actions {
+		result := "would delete"
+		switch {
+		case action.Error != "":
+			result = "failed: " + action.Error
+		case action.Deleted:
+			result = "deleted"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			action.Kind,
+			action.ID,
+			action.AgentID,
+			action.CreatedAt.Format(time.RFC3339),
+			strings.Join(action.Reasons, ", "),
+			result,
+		)
+	}
+
+	return table.Flush()
+}
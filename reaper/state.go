@@ -0,0 +1,41 @@
+package reaper
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/equalsgibson/goslide/internal/jsonfile"
+)
+
+type state struct {
+	StoppedSince map[string]time.Time `json:"stopped_since"`
+}
+
+func loadState(path string) (map[string]time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]time.Time{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	saved := state{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+
+	if saved.StoppedSince == nil {
+		return map[string]time.Time{}, nil
+	}
+
+	return saved.StoppedSince, nil
+}
+
+func saveState(path string, stoppedSince map[string]time.Time) error {
+	return jsonfile.Write(path, state{StoppedSince: stoppedSince})
+}
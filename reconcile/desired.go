@@ -0,0 +1,171 @@
+// Package reconcile brings the disaster recovery networks of a client in line
+// with a desired state kept in version control.
+package reconcile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+	"gopkg.in/yaml.v3"
+)
+
+// Desired is every network a client should have. Networks of the client that
+// are not listed are deleted.
+type Desired struct {
+	ClientID string    `json:"client_id"`
+	Networks []Network `json:"networks"`
+}
+
+// Network is identified by its name within the client. Empty string fields
+// and omitted booleans are left as they are on existing networks.
+type Network struct {
+	Name           string                      `json:"name"`
+	Type           goslide.NetworkTypeDisaster `json:"type"`
+	BridgeDeviceID string                      `json:"bridge_device_id"`
+	Comments       string                      `json:"comments"`
+	DHCP           *bool                       `json:"dhcp"`
+	DHCPRangeStart string                      `json:"dhcp_range_start"`
+	DHCPRangeEnd   string                      `json:"dhcp_range_end"`
+	Internet       *bool                       `json:"internet"`
+	Nameservers    string                      `json:"nameservers"`
+	RouterPrefix   string                      `json:"router_prefix"`
+	PortForwards   []PortForward               `json:"port_forwards"`
+	WGPeers        []WGPeer                    `json:"wg_peers"`
+}
+
+// PortForward is identified by its protocol and destination.
+type PortForward struct {
+	Proto goslide.NetworkProto `json:"proto"`
+	Dest  string               `json:"dest"`
+}
+
+// WGPeer is identified by its name within the network.
+type WGPeer struct {
+	PeerName       string   `json:"peer_name"`
+	RemoteNetworks []string `json:"remote_networks"`
+}
+
+// Load reads the desired state from a YAML (.yaml, .yml) or JSON file.
+func Load(path string) (Desired, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Desired{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	default:
+		return ParseJSON(data)
+	}
+}
+
+func ParseJSON(data []byte) (Desired, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	desired := Desired{}
+	if err := decoder.Decode(&desired); err != nil {
+		return Desired{}, fmt.Errorf("reconcile: %w", err)
+	}
+
+	if err := desired.Validate(); err != nil {
+		return Desired{}, err
+	}
+
+	return desired, nil
+}
+
+// ParseYAML converts the YAML document to JSON, so both formats share the
+// field names and validation of the JSON struct tags.
+func ParseYAML(data []byte) (Desired, error) {
+	document := map[string]any{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return Desired{}, fmt.Errorf("reconcile: %w", err)
+	}
+
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return Desired{}, fmt.Errorf("reconcile: %w", err)
+	}
+
+	return ParseJSON(jsonData)
+}
+
+func (d Desired) Validate() error {
+	errs := []error{}
+
+	// Without a client every network in the account would be reconciled
+	if d.ClientID == "" {
+		errs = append(errs, errors.New("client_id is required"))
+	}
+
+	names := map[string]bool{}
+	for i, network := range d.Networks {
+		if names[network.Name] {
+			errs = append(errs, fmt.Errorf("networks[%d].name %q is not unique", i, network.Name))
+		}
+		names[network.Name] = true
+
+		if err := network.createPayload(d.ClientID).Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("networks[%d] - %w", i, err))
+		}
+
+		portForwards := map[PortForward]bool{}
+		for j, portForward := range network.PortForwards {
+			if portForward.Proto != goslide.NetworkProto_TCP && portForward.Proto != goslide.NetworkProto_UDP {
+				errs = append(errs, fmt.Errorf("networks[%d].port_forwards[%d].proto must be tcp or udp", i, j))
+			}
+
+			if _, _, err := net.SplitHostPort(portForward.Dest); err != nil {
+				errs = append(errs, fmt.Errorf("networks[%d].port_forwards[%d].dest must be host:port", i, j))
+			}
+
+			if portForwards[portForward] {
+				errs = append(errs, fmt.Errorf("networks[%d].port_forwards[%d] is a duplicate", i, j))
+			}
+			portForwards[portForward] = true
+		}
+
+		peers := map[string]bool{}
+		for j, peer := range network.WGPeers {
+			if peer.PeerName == "" {
+				errs = append(errs, fmt.Errorf("networks[%d].wg_peers[%d].peer_name is required", i, j))
+			}
+
+			if peers[peer.PeerName] {
+				errs = append(errs, fmt.Errorf("networks[%d].wg_peers[%d].peer_name %q is not unique", i, j, peer.PeerName))
+			}
+			peers[peer.PeerName] = true
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("reconcile: invalid desired state:\n%w", err)
+	}
+
+	return nil
+}
+
+func (n Network) createPayload(clientID string) goslide.NetworkCreatePayload {
+	return goslide.NetworkCreatePayload{
+		Name:           n.Name,
+		Type:           n.Type,
+		BridgeDeviceID: n.BridgeDeviceID,
+		ClientID:       clientID,
+		Comments:       n.Comments,
+		DHCP:           n.DHCP,
+		DHCPRangeEnd:   n.DHCPRangeEnd,
+		DHCPRangeStart: n.DHCPRangeStart,
+		Internet:       n.Internet,
+		Nameservers:    n.Nameservers,
+		RouterPrefix:   n.RouterPrefix,
+	}
+}
@@ -0,0 +1,414 @@
+package reconcile
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+)
+
+type Action string
+
+const (
+	Action_CREATE Action = "create"
+	Action_UPDATE Action = "update"
+	Action_DELETE Action = "delete"
+	Action_NOOP   Action = "no-op"
+)
+
+// Plan is the set of changes that brings the client's networks in line with
+// the desired state.
+type Plan struct {
+	ClientID string
+	Changes  []Change
+}
+
+type Change struct {
+	Action    Action
+	Name      string
+	NetworkID string
+
+	Fields       []FieldChange
+	PortForwards []PortForwardChange
+	WGPeers      []WGPeerChange
+
+	// Blocked explains why the change cannot be applied, such as a network
+	// that still has virtual machines connected.
+	Blocked string
+
+	desired Network
+}
+
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+type PortForwardChange struct {
+	Action Action
+	Proto  goslide.NetworkProto
+	Dest   string
+
+	// Port is the public port, known for existing port forwards and after creation
+	Port uint
+}
+
+type WGPeerChange struct {
+	Action         Action
+	PeerName       string
+	WGAddress      string
+	RemoteNetworks []string
+
+	// FromRemoteNetworks is set on updates
+	FromRemoteNetworks []string
+}
+
+// HasChanges reports whether applying the plan would change anything.
+func (p Plan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Action != Action_NOOP {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String renders the plan as a diff, with + for additions, - for removals and
+// ~ for changes.
+func (p Plan) String() string {
+	var sb strings.Builder
+
+	for _, change := range p.Changes {
+		switch change.Action {
+		case Action_CREATE:
+			fmt.Fprintf(&sb, "+ network %q\n", change.Name)
+		case Action_UPDATE:
+			fmt.Fprintf(&sb, "~ network %q (%s)\n", change.Name, change.NetworkID)
+		case Action_DELETE:
+			fmt.Fprintf(&sb, "- network %q (%s)\n", change.Name, change.NetworkID)
+		default:
+			fmt.Fprintf(&sb, "  network %q (%s) is up to date\n", change.Name, change.NetworkID)
+		}
+
+		for _, field := range change.Fields {
+			if change.Action == Action_CREATE {
+				fmt.Fprintf(&sb, "    %s: %s\n", field.Field, field.To)
+			} else {
+				fmt.Fprintf(&sb, "    %s: %s -> %s\n", field.Field, field.From, field.To)
+			}
+		}
+
+		for _, portForward := range change.PortForwards {
+			fmt.Fprintf(&sb, "    %s port forward %s -> %s", symbol(portForward.Action), portForward.Proto, portForward.Dest)
+			if portForward.Port != 0 {
+				fmt.Fprintf(&sb, " (port %d)", portForward.Port)
+			}
+			sb.WriteString("\n")
+		}
+
+		for _, peer := range change.WGPeers {
+			fmt.Fprintf(&sb, "    %s WireGuard peer %q", symbol(peer.Action), peer.PeerName)
+			if peer.WGAddress != "" {
+				fmt.Fprintf(&sb, " (%s)", peer.WGAddress)
+			}
+
+			if peer.Action == Action_UPDATE {
+				fmt.Fprintf(&sb, " remote networks: [%s] -> [%s]", strings.Join(peer.FromRemoteNetworks, ", "), strings.Join(peer.RemoteNetworks, ", "))
+			}
+			sb.WriteString("\n")
+		}
+
+		if change.Blocked != "" {
+			fmt.Fprintf(&sb, "    ! blocked: %s\n", change.Blocked)
+		}
+	}
+
+	return sb.String()
+}
+
+func symbol(action Action) string {
+	switch action {
+	case Action_CREATE:
+		return "+"
+	case Action_DELETE:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// plan compares the desired state with the client's existing networks.
+func plan(desired Desired, existing []goslide.Network) Plan {
+	result := Plan{
+		ClientID: desired.ClientID,
+		Changes:  []Change{},
+	}
+
+	byName := map[string]goslide.Network{}
+	for _, network := range existing {
+		byName[network.Name] = network
+	}
+
+	for _, network := range desired.Networks {
+		current, ok := byName[network.Name]
+		if !ok {
+			result.Changes = append(result.Changes, planCreate(network))
+
+			continue
+		}
+
+		delete(byName, network.Name)
+		result.Changes = append(result.Changes, planUpdate(network, current))
+	}
+
+	// Whatever is left exists but is not desired
+	for _, current := range existing {
+		if _, ok := byName[current.Name]; !ok {
+			continue
+		}
+
+		change := Change{
+			Action:    Action_DELETE,
+			Name:      current.Name,
+			NetworkID: current.NetworkID,
+		}
+
+		if len(current.ConnectedVirtIDs) > 0 {
+			change.Blocked = fmt.Sprintf("virtual machines are still connected: %s", strings.Join(current.ConnectedVirtIDs, ", "))
+		}
+
+		result.Changes = append(result.Changes, change)
+	}
+
+	return result
+}
+
+func planCreate(network Network) Change {
+	change := Change{
+		Action:  Action_CREATE,
+		Name:    network.Name,
+		Fields:  diffFields(Network{}, network, true),
+		desired: network,
+	}
+
+	for _, portForward := range network.PortForwards {
+		change.PortForwards = append(change.PortForwards, PortForwardChange{
+			Action: Action_CREATE,
+			Proto:  portForward.Proto,
+			Dest:   portForward.Dest,
+		})
+	}
+
+	for _, peer := range network.WGPeers {
+		change.WGPeers = append(change.WGPeers, WGPeerChange{
+			Action:         Action_CREATE,
+			PeerName:       peer.PeerName,
+			RemoteNetworks: peer.RemoteNetworks,
+		})
+	}
+
+	return change
+}
+
+func planUpdate(network Network, current goslide.Network) Change {
+	change := Change{
+		Action:    Action_NOOP,
+		Name:      network.Name,
+		NetworkID: current.NetworkID,
+		Fields:    diffFields(fromAPI(current), network, false),
+		desired:   network,
+	}
+
+	// The API cannot move a bridge to another device
+	if network.BridgeDeviceID != "" && network.BridgeDeviceID != current.BridgeDeviceID {
+		change.Blocked = fmt.Sprintf("bridge_device_id cannot be changed from %q to %q, delete and recreate the network", current.BridgeDeviceID, network.BridgeDeviceID)
+	}
+
+	desiredForwards := map[PortForward]bool{}
+	for _, portForward := range network.PortForwards {
+		desiredForwards[portForward] = true
+	}
+
+	existingForwards := map[PortForward]bool{}
+	for _, portForward := range current.PortForwards {
+		key := PortForward{Proto: portForward.Proto, Dest: portForward.Dest}
+		existingForwards[key] = true
+
+		if !desiredForwards[key] {
+			change.PortForwards = append(change.PortForwards, PortForwardChange{
+				Action: Action_DELETE,
+				Proto:  portForward.Proto,
+				Dest:   portForward.Dest,
+				Port:   portForward.Port,
+			})
+		}
+	}
+
+	for _, portForward := range network.PortForwards {
+		if !existingForwards[portForward] {
+			change.PortForwards = append(change.PortForwards, PortForwardChange{
+				Action: Action_CREATE,
+				Proto:  portForward.Proto,
+				Dest:   portForward.Dest,
+			})
+		}
+	}
+
+	desiredPeers := map[string]WGPeer{}
+	for _, peer := range network.WGPeers {
+		desiredPeers[peer.PeerName] = peer
+	}
+
+	existingPeers := map[string]bool{}
+	for _, peer := range current.WGPeers {
+		existingPeers[peer.PeerName] = true
+
+		wanted, ok := desiredPeers[peer.PeerName]
+		if !ok {
+			change.WGPeers = append(change.WGPeers, WGPeerChange{
+				Action:         Action_DELETE,
+				PeerName:       peer.PeerName,
+				WGAddress:      peer.WGAddress,
+				RemoteNetworks: peer.RemoteNetworks,
+			})
+
+			continue
+		}
+
+		if !sameSet(wanted.RemoteNetworks, peer.RemoteNetworks) {
+			change.WGPeers = append(change.WGPeers, WGPeerChange{
+				Action:             Action_UPDATE,
+				PeerName:           peer.PeerName,
+				WGAddress:          peer.WGAddress,
+				RemoteNetworks:     wanted.RemoteNetworks,
+				FromRemoteNetworks: peer.RemoteNetworks,
+			})
+		}
+	}
+
+	for _, peer := range network.WGPeers {
+		if !existingPeers[peer.PeerName] {
+			change.WGPeers = append(change.WGPeers, WGPeerChange{
+				Action:         Action_CREATE,
+				PeerName:       peer.PeerName,
+				RemoteNetworks: peer.RemoteNetworks,
+			})
+		}
+	}
+
+	if len(change.Fields) > 0 || len(change.PortForwards) > 0 || len(change.WGPeers) > 0 {
+		change.Action = Action_UPDATE
+	}
+
+	return change
+}
+
+func fromAPI(network goslide.Network) Network {
+	return Network{
+		Name:           network.Name,
+		Type:           network.Type,
+		BridgeDeviceID: network.BridgeDeviceID,
+		Comments:       network.Comments,
+		DHCP:           goslide.Bool(network.DHCP),
+		DHCPRangeStart: network.DHCPRangeStart,
+		DHCPRangeEnd:   network.DHCPRangeEnd,
+		Internet:       goslide.Bool(network.Internet),
+		Nameservers:    network.Nameservers,
+		RouterPrefix:   network.RouterPrefix,
+	}
+}
+
+// diffFields lists the updatable fields that differ. For creations every set
+// field is listed.
+func diffFields(from, to Network, all bool) []FieldChange {
+	fields := []FieldChange{}
+
+	compare := func(field, fromValue, toValue string) {
+		if all {
+			if toValue != "" {
+				fields = append(fields, FieldChange{Field: field, From: fromValue, To: toValue})
+			}
+
+			return
+		}
+
+		// The update payload omits empty strings, so they leave the field unmanaged
+		if toValue != "" && fromValue != toValue {
+			fields = append(fields, FieldChange{Field: field, From: fromValue, To: toValue})
+		}
+	}
+
+	compare("type", string(from.Type), string(to.Type))
+	if all {
+		compare("bridge_device_id", from.BridgeDeviceID, to.BridgeDeviceID)
+	}
+	compare("comments", from.Comments, to.Comments)
+	compare("dhcp", formatBool(from.DHCP), formatBool(to.DHCP))
+	compare("dhcp_range_start", from.DHCPRangeStart, to.DHCPRangeStart)
+	compare("dhcp_range_end", from.DHCPRangeEnd, to.DHCPRangeEnd)
+	compare("internet", formatBool(from.Internet), formatBool(to.Internet))
+	compare("nameservers", normalizeNameservers(from.Nameservers), normalizeNameservers(to.Nameservers))
+	compare("router_prefix", from.RouterPrefix, to.RouterPrefix)
+
+	return fields
+}
+
+// updatePayload sends only the fields that changed.
+func (c Change) updatePayload() goslide.NetworkUpdatePayload {
+	payload := goslide.NetworkUpdatePayload{}
+
+	for _, field := range c.Fields {
+		switch field.Field {
+		case "type":
+			payload.Type = c.desired.Type
+		case "comments":
+			payload.Comments = c.desired.Comments
+		case "dhcp":
+			payload.DHCP = c.desired.DHCP
+		case "dhcp_range_start":
+			payload.DHCPRangeStart = c.desired.DHCPRangeStart
+		case "dhcp_range_end":
+			payload.DHCPRangeEnd = c.desired.DHCPRangeEnd
+		case "internet":
+			payload.Internet = c.desired.Internet
+		case "nameservers":
+			payload.Nameservers = c.desired.Nameservers
+		case "router_prefix":
+			payload.RouterPrefix = c.desired.RouterPrefix
+		}
+	}
+
+	return payload
+}
+
+// formatBool renders an omitted boolean as an empty string, which diffFields
+// treats as unmanaged.
+func formatBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*value)
+}
+
+func normalizeNameservers(nameservers string) string {
+	parts := []string{}
+	for _, nameserver := range strings.Split(nameservers, ",") {
+		if nameserver = strings.TrimSpace(nameserver); nameserver != "" {
+			parts = append(parts, nameserver)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func sameSet(a, b []string) bool {
+	sortedA := slices.Sorted(slices.Values(a))
+	sortedB := slices.Sorted(slices.Values(b))
+
+	return slices.Equal(sortedA, sortedB)
+}
@@ -0,0 +1,163 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/equalsgibson/goslide"
+)
+
+type Reconciler struct {
+	service goslide.Service
+}
+
+func NewReconciler(service goslide.Service) *Reconciler {
+	return &Reconciler{
+		service: service,
+	}
+}
+
+// Plan compares the desired state with the client's networks without changing anything.
+func (r *Reconciler) Plan(ctx context.Context, desired Desired) (Plan, error) {
+	if err := desired.Validate(); err != nil {
+		return Plan{}, err
+	}
+
+	existing := []goslide.Network{}
+	if err := r.service.Networks().ListWithQueryParameters(ctx, func(response goslide.ListResponse[goslide.Network]) error {
+		for _, network := range response.Data {
+			// Filter again, so a missing server side filter can never reach other clients
+			if network.ClientID == desired.ClientID {
+				existing = append(existing, network)
+			}
+		}
+
+		return nil
+	}, goslide.WithClientID(desired.ClientID)); err != nil {
+		return Plan{}, err
+	}
+
+	return plan(desired, existing), nil
+}
+
+// Apply plans against the current state of the API and applies the result,
+// so running it again with the same desired state changes nothing. Blocked
+// changes are skipped and reported in the returned error; the rest of the
+// plan is still applied. The returned plan records what was applied.
+func (r *Reconciler) Apply(ctx context.Context, desired Desired) (Plan, error) {
+	current, err := r.Plan(ctx, desired)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	errs := []error{}
+	for i := range current.Changes {
+		change := &current.Changes[i]
+		if change.Blocked != "" {
+			errs = append(errs, fmt.Errorf("network %q: %s", change.Name, change.Blocked))
+
+			continue
+		}
+
+		if err := r.apply(ctx, desired.ClientID, change); err != nil {
+			errs = append(errs, fmt.Errorf("network %q: %w", change.Name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return current, fmt.Errorf("reconcile: %w", err)
+	}
+
+	return current, nil
+}
+
+func (r *Reconciler) apply(ctx context.Context, clientID string, change *Change) error {
+	networks := r.service.Networks()
+
+	switch change.Action {
+	case Action_NOOP:
+		return nil
+	case Action_DELETE:
+		return networks.Delete(ctx, change.NetworkID)
+	case Action_CREATE:
+		network, err := networks.Create(ctx, change.desired.createPayload(clientID))
+		if err != nil {
+			return err
+		}
+
+		change.NetworkID = network.NetworkID
+	case Action_UPDATE:
+		if len(change.Fields) > 0 {
+			if _, err := networks.Update(ctx, change.NetworkID, change.updatePayload()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Removals go first, so a replaced port forward never clashes with its successor
+	for i := range change.PortForwards {
+		portForward := &change.PortForwards[i]
+		if portForward.Action != Action_DELETE {
+			continue
+		}
+
+		if err := networks.DeletePortForward(ctx, change.NetworkID, goslide.NetworkPortForwardDeletePayload{
+			NetworkID: change.NetworkID,
+			Proto:     portForward.Proto,
+			Port:      portForward.Port,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for i := range change.PortForwards {
+		portForward := &change.PortForwards[i]
+		if portForward.Action != Action_CREATE {
+			continue
+		}
+
+		created, err := networks.CreatePortForward(ctx, change.NetworkID, goslide.NetworkPortForwardPayload{
+			Dest:      portForward.Dest,
+			NetworkID: change.NetworkID,
+			Proto:     portForward.Proto,
+		})
+		if err != nil {
+			return err
+		}
+
+		portForward.Port = created.Port
+	}
+
+	for i := range change.WGPeers {
+		peer := &change.WGPeers[i]
+
+		switch peer.Action {
+		case Action_DELETE:
+			if err := networks.DeleteWGPeer(ctx, change.NetworkID, peer.WGAddress); err != nil {
+				return err
+			}
+		case Action_UPDATE:
+			if _, err := networks.UpdateWGPeer(ctx, change.NetworkID, goslide.NetworkWGPeerUpdatePayload{
+				NetworkID:      change.NetworkID,
+				WGAddress:      peer.WGAddress,
+				RemoteNetworks: peer.RemoteNetworks,
+			}); err != nil {
+				return err
+			}
+		case Action_CREATE:
+			created, err := networks.CreateWGPeer(ctx, change.NetworkID, goslide.NetworkWGPeerCreatePayload{
+				NetworkID:      change.NetworkID,
+				PeerName:       peer.PeerName,
+				RemoteNetworks: peer.RemoteNetworks,
+			})
+			if err != nil {
+				return err
+			}
+
+			peer.WGAddress = created.WGAddress
+		}
+	}
+
+	return nil
+}
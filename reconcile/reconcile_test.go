@@ -0,0 +1,251 @@
+package reconcile_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/reconcile"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDesired = `client_id: c_acme
+networks:
+  - name: dr-lan
+    type: standard
+    comments: Managed by reconcile
+    dhcp: false
+    internet: false
+    router_prefix: 10.0.0.1/24
+    port_forwards:
+      - proto: tcp
+        dest: 10.0.0.10:3389
+    wg_peers:
+      - peer_name: Tech laptop
+        remote_networks: [192.168.1.0/24]
+  - name: dr-dmz
+    type: standard
+    dhcp: true
+    internet: true
+    router_prefix: 10.1.0.1/24
+    dhcp_range_start: 10.1.0.100
+    dhcp_range_end: 10.1.0.200
+`
+
+// testNetworks returns the networks of the fleet before a reconcile: dr-lan
+// drifted from the desired state, old is not desired and still has a virtual
+// machine connected, and other belongs to another client.
+func testNetworks() []goslide.Network {
+	return []goslide.Network{
+		{
+			NetworkID:        "net_dr_lan",
+			ClientID:         "c_acme",
+			Name:             "dr-lan",
+			Type:             goslide.NetworkTypeDisaster_STANDARD,
+			Comments:         "Created by hand",
+			ConnectedVirtIDs: []string{},
+			DHCP:             true,
+			Internet:         true,
+			RouterPrefix:     "10.0.0.1/24",
+			PortForwards: []goslide.NetworkPortForward{
+				{NetworkID: "net_dr_lan", Proto: goslide.NetworkProto_TCP, Dest: "10.0.0.10:22", Port: 40022},
+			},
+			WGPeers: []goslide.NetworkWGPeer{
+				{NetworkID: "net_dr_lan", PeerName: "Tech laptop", WGAddress: "10.99.0.2", RemoteNetworks: []string{}},
+				{NetworkID: "net_dr_lan", PeerName: "Old laptop", WGAddress: "10.99.0.3", RemoteNetworks: []string{}},
+			},
+		},
+		{
+			NetworkID:        "net_old",
+			ClientID:         "c_acme",
+			Name:             "old",
+			Type:             goslide.NetworkTypeDisaster_STANDARD,
+			ConnectedVirtIDs: []string{"virt_0123456789ab"},
+		},
+		{
+			NetworkID: "net_other_client",
+			ClientID:  "c_globex",
+			Name:      "other",
+			Type:      goslide.NetworkTypeDisaster_STANDARD,
+		},
+	}
+}
+
+func loadDesired(t *testing.T) reconcile.Desired {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "networks.yaml")
+	if err := os.WriteFile(path, []byte(testDesired), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	desired, err := reconcile.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return desired
+}
+
+func TestReconciler_Plan(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Networks = testNetworks()
+	reconciler := reconcile.NewReconciler(server.Service())
+
+	plan, err := reconciler.Plan(context.Background(), loadDesired(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `~ network "dr-lan" (net_dr_lan)
+    comments: Created by hand -> Managed by reconcile
+    dhcp: true -> false
+    internet: true -> false
+    - port forward tcp -> 10.0.0.10:22 (port 40022)
+    + port forward tcp -> 10.0.0.10:3389
+    ~ WireGuard peer "Tech laptop" (10.99.0.2) remote networks: [] -> [192.168.1.0/24]
+    - WireGuard peer "Old laptop" (10.99.0.3)
++ network "dr-dmz"
+    type: standard
+    dhcp: true
+    dhcp_range_start: 10.1.0.100
+    dhcp_range_end: 10.1.0.200
+    internet: true
+    router_prefix: 10.1.0.1/24
+- network "old" (net_old)
+    ! blocked: virtual machines are still connected: virt_0123456789ab
+`
+
+	if diff := cmp.Diff(expected, plan.String()); diff != "" {
+		t.Fatalf("%s Plan mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Planning never changes anything
+	for _, request := range server.Requests {
+		if !strings.HasPrefix(request, "GET ") {
+			t.Errorf("%s expected only reads while planning, got %s", t.Name(), request)
+		}
+	}
+}
+
+func TestReconciler_Plan_OmittedBooleans(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Networks = testNetworks()[:1]
+	server.Networks[0].Comments = "Managed by reconcile"
+	reconciler := reconcile.NewReconciler(server.Service())
+
+	// dhcp and internet are enabled on dr-lan, omitting them leaves them be
+	desired, err := reconcile.ParseYAML([]byte(`client_id: c_acme
+networks:
+  - name: dr-lan
+    type: standard
+    comments: Managed by reconcile
+    router_prefix: 10.0.0.1/24
+    port_forwards:
+      - proto: tcp
+        dest: 10.0.0.10:22
+    wg_peers:
+      - peer_name: Tech laptop
+      - peer_name: Old laptop
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := reconciler.Plan(context.Background(), desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.HasChanges() {
+		t.Fatalf("%s expected no changes, got:\n%s", t.Name(), plan)
+	}
+}
+
+func TestReconciler_Apply(t *testing.T) {
+	server := fakeslide.NewFleet()
+	server.Networks = testNetworks()
+	reconciler := reconcile.NewReconciler(server.Service())
+	desired := loadDesired(t)
+
+	applied, err := reconciler.Apply(context.Background(), desired)
+	if err == nil || !strings.Contains(err.Error(), `network "old": virtual machines are still connected`) {
+		t.Fatalf("%s expected the blocked delete to be reported, got %v", t.Name(), err)
+	}
+
+	if !applied.HasChanges() {
+		t.Fatalf("%s expected the applied plan to record changes", t.Name())
+	}
+
+	if server.Count("DELETE /v1/network/net_old") != 0 {
+		t.Errorf("%s expected the blocked network to be kept", t.Name())
+	}
+
+	if server.Count("PATCH /v1/network/net_other_client") != 0 || server.Count("DELETE /v1/network/net_other_client") != 0 {
+		t.Errorf("%s expected networks of other clients to be left alone", t.Name())
+	}
+
+	network := server.Networks[0]
+	if network.DHCP || network.Internet {
+		t.Errorf("%s expected DHCP and internet to be disabled, got %+v", t.Name(), network)
+	}
+
+	if len(network.PortForwards) != 1 || network.PortForwards[0].Dest != "10.0.0.10:3389" {
+		t.Errorf("%s expected the port forward to be replaced, got %+v", t.Name(), network.PortForwards)
+	}
+
+	expectedPeers := []goslide.NetworkWGPeer{
+		{NetworkID: "net_dr_lan", PeerName: "Tech laptop", WGAddress: "10.99.0.2", RemoteNetworks: []string{"192.168.1.0/24"}},
+	}
+	if diff := cmp.Diff(expectedPeers, network.WGPeers); diff != "" {
+		t.Errorf("%s WireGuard peers mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// A second run only has the blocked delete left
+	plan, err := reconciler.Plan(context.Background(), desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, change := range plan.Changes {
+		if change.Action != reconcile.Action_NOOP && change.Blocked == "" {
+			t.Errorf("%s expected no changes after applying, got:\n%s", t.Name(), plan)
+		}
+	}
+}
+
+func TestParseYAML_Invalid(t *testing.T) {
+	_, err := reconcile.ParseYAML([]byte(`networks:
+  - name: dr-lan
+    type: standard
+    port_forwards:
+      - proto: icmp
+        dest: 10.0.0.10
+  - name: dr-lan
+    type: bridge-lan
+`))
+	if err == nil {
+		t.Fatalf("%s expected an error", t.Name())
+	}
+
+	for _, expected := range []string{
+		"client_id is required",
+		`networks[1].name "dr-lan" is not unique`,
+		"networks[0].port_forwards[0].proto must be tcp or udp",
+		"networks[0].port_forwards[0].dest must be host:port",
+		"bridge_device_id",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s expected error to contain %q, got: %s", t.Name(), expected, err)
+		}
+	}
+
+	_, err = reconcile.ParseYAML([]byte("client_id: c_acme\nnetworkz: []\n"))
+	if err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Fatalf("%s expected an unknown field error, got %v", t.Name(), err)
+	}
+}
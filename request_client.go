@@ -1,18 +1,29 @@
 package goslide
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"golang.org/x/oauth2"
 )
 
+const maxRetryDelay = 30 * time.Second
+
 type requestClient struct {
 	token      *oauth2.Token
 	apiURL     string
 	httpClient *http.Client
+	observer   Observer
+
+	// maxRetries is how often a GET request that was rate limited or hit a
+	// server error is sent again
+	maxRetries   uint
+	retryBackoff time.Duration
 }
 
 func (rc *requestClient) do(request *http.Request, target any) error {
@@ -28,10 +39,41 @@ func (rc *requestClient) do(request *http.Request, target any) error {
 		request.Header.Set("Content-Type", "application/json")
 	}
 
-	response, err := rc.httpClient.Do(request)
-	if err != nil {
-		return err
+	request, observation := rc.observer.StartRequest(request, routeTemplate(request.Method, request.URL.Path))
+
+	statusCode, err := rc.send(request, target, observation.Retry)
+
+	observation.End(statusCode, err)
+
+	return err
+}
+
+// send sends the request, and sends it again while it can be retried. It
+// returns the status code of the last response.
+func (rc *requestClient) send(request *http.Request, target any, onRetry func(attempt int, statusCode int, delay time.Duration)) (int, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := rc.httpClient.Do(request)
+		if err != nil {
+			return 0, err
+		}
+
+		if uint(attempt) <= rc.maxRetries && retryable(request, response) {
+			delay := rc.retryDelay(response, attempt)
+			response.Body.Close()
+
+			onRetry(attempt, response.StatusCode, delay)
+			if err := sleep(request.Context(), delay); err != nil {
+				return response.StatusCode, err
+			}
+
+			continue
+		}
+
+		return response.StatusCode, decodeResponse(request, response, target)
 	}
+}
+
+func decodeResponse(request *http.Request, response *http.Response, target any) error {
 	defer response.Body.Close()
 
 	if response.StatusCode >= http.StatusBadRequest {
@@ -66,6 +108,39 @@ func (rc *requestClient) do(request *http.Request, target any) error {
 	return nil
 }
 
+// retryable reports whether a response is worth another attempt. Only GET
+// requests are retried, since they have no body to send again and no side
+// effects to repeat.
+func retryable(request *http.Request, response *http.Response) bool {
+	if request.Method != http.MethodGet {
+		return false
+	}
+
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+}
+
+// retryDelay honours the Retry-After header of rate limited responses, and
+// otherwise doubles the backoff with every attempt.
+func (rc *requestClient) retryDelay(response *http.Response, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, maxRetryDelay)
+	}
+
+	return min(rc.retryBackoff<<(attempt-1), maxRetryDelay)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (rc *requestClient) SlideRequest(request *http.Request, target any) error {
 	if request.Header.Get("Authorization") == "" {
 		if rc.token == nil {
@@ -80,3 +155,14 @@ func (rc *requestClient) SlideRequest(request *http.Request, target any) error {
 func (rc *requestClient) Request(request *http.Request, target any) error {
 	return rc.do(request, target)
 }
+
+// startPagination tells the observer about a list, whose pages are requested
+// with the returned context.
+func (rc *requestClient) startPagination(ctx context.Context, path string) (context.Context, func(err error)) {
+	route := routeTemplate(http.MethodGet, path)
+	if route == "" {
+		route = path
+	}
+
+	return rc.observer.StartPagination(ctx, route)
+}
@@ -0,0 +1,278 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type NodeType string
+
+const (
+	NodeType_CLIENT          NodeType = "client"
+	NodeType_DEVICE          NodeType = "device"
+	NodeType_AGENT           NodeType = "agent"
+	NodeType_BACKUP          NodeType = "backup"
+	NodeType_SNAPSHOT        NodeType = "snapshot"
+	NodeType_ALERT           NodeType = "alert"
+	NodeType_FILE_RESTORE    NodeType = "file_restore"
+	NodeType_IMAGE_EXPORT    NodeType = "image_export"
+	NodeType_VIRTUAL_MACHINE NodeType = "virtual_machine"
+)
+
+// Node is a resource, identified by its Slide ID.
+type Node struct {
+	ID    string   `json:"id"`
+	Type  NodeType `json:"type"`
+	Label string   `json:"label"`
+}
+
+// Edge points from a resource to one it owns.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is the ownership tree of a fleet: clients own devices, devices own
+// agents and agents own their backups, snapshots, open alerts and restores.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Graph builds the graph of the whole fleet.
+func (r *Resolver) Graph(ctx context.Context) (Graph, error) {
+	graph := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	nodes := map[string]bool{}
+
+	addNode := func(id string, nodeType NodeType, label string) {
+		nodes[id] = true
+		graph.Nodes = append(graph.Nodes, Node{ID: id, Type: nodeType, Label: label})
+	}
+
+	// Edges are added once every node is known, so that references to
+	// resources that were not listed are left out
+	edges := []Edge{}
+	addEdge := func(from, to string) {
+		edges = append(edges, Edge{From: from, To: to})
+	}
+
+	clients, _, err := r.clients.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, client := range clients {
+		addNode(client.ClientID, NodeType_CLIENT, client.Name)
+	}
+
+	devices, _, err := r.devices.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, device := range devices {
+		addNode(device.DeviceID, NodeType_DEVICE, firstNonEmpty(device.DisplayName, device.Hostname, device.DeviceID))
+		addEdge(device.ClientID, device.DeviceID)
+	}
+
+	agents, _, err := r.agents.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, agent := range agents {
+		addNode(agent.AgentID, NodeType_AGENT, firstNonEmpty(agent.DisplayName, agent.Hostname, agent.AgentID))
+
+		// Agents hang off their device, or their client when the device is
+		// unknown
+		if agent.DeviceID != "" {
+			addEdge(agent.DeviceID, agent.AgentID)
+		} else {
+			addEdge(agent.ClientID, agent.AgentID)
+		}
+	}
+
+	backups, _, err := r.backups.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, backup := range backups {
+		addNode(backup.BackupID, NodeType_BACKUP, strings.TrimSpace(string(backup.Status)+" "+formatTime(backup.StartedAt)))
+		addEdge(backup.AgentID, backup.BackupID)
+	}
+
+	snapshots, _, err := r.snapshots.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, snapshot := range snapshots {
+		addNode(snapshot.SnapshotID, NodeType_SNAPSHOT, formatTime(snapshot.BackupEndedAt))
+		addEdge(snapshot.AgentID, snapshot.SnapshotID)
+	}
+
+	alerts, _, err := r.alerts.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, alert := range alerts {
+		addNode(alert.AlertID, NodeType_ALERT, string(alert.AlertType))
+
+		// Device alerts have no agent
+		if alert.AgentID != "" {
+			addEdge(alert.AgentID, alert.AlertID)
+		} else {
+			addEdge(alert.DeviceID, alert.AlertID)
+		}
+	}
+
+	fileRestores, _, err := r.fileRestores.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, restore := range fileRestores {
+		addNode(restore.FileRestoreID, NodeType_FILE_RESTORE, strings.TrimSpace("file restore "+formatTime(restore.CreatedAt)))
+		addEdge(restore.AgentID, restore.FileRestoreID)
+	}
+
+	imageExports, _, err := r.imageExports.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, export := range imageExports {
+		addNode(export.ImageExportID, NodeType_IMAGE_EXPORT, strings.TrimSpace(string(export.ImageType)+" export "+formatTime(export.CreatedAt)))
+		addEdge(export.AgentID, export.ImageExportID)
+	}
+
+	virtualMachines, _, err := r.virtualMachines.load(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	for _, vm := range virtualMachines {
+		addNode(vm.VirtID, NodeType_VIRTUAL_MACHINE, "virtual machine "+string(vm.State))
+		addEdge(vm.AgentID, vm.VirtID)
+	}
+
+	for _, edge := range edges {
+		if nodes[edge.From] && nodes[edge.To] {
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	return graph, nil
+}
+
+// ClientGraph builds the graph of one client and everything it owns.
+func (r *Resolver) ClientGraph(ctx context.Context, clientID string) (Graph, error) {
+	graph, err := r.Graph(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	return graph.Subgraph(clientID), nil
+}
+
+// Subgraph returns the nodes reachable from root, with the edges between
+// them.
+func (g Graph) Subgraph(root string) Graph {
+	children := map[string][]string{}
+	for _, edge := range g.Edges {
+		children[edge.From] = append(children[edge.From], edge.To)
+	}
+
+	reachable := map[string]bool{}
+	queue := []string{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if reachable[id] {
+			continue
+		}
+
+		reachable[id] = true
+		queue = append(queue, children[id]...)
+	}
+
+	subgraph := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for _, node := range g.Nodes {
+		if reachable[node.ID] {
+			subgraph.Nodes = append(subgraph.Nodes, node)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		if reachable[edge.From] && reachable[edge.To] {
+			subgraph.Edges = append(subgraph.Edges, edge)
+		}
+	}
+
+	return subgraph
+}
+
+func (g Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+
+	return encoder.Encode(g)
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language, for example to
+// render it with: dot -Tsvg
+func (g Graph) WriteDOT(w io.Writer) error {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph slide {\n")
+	builder.WriteString("    rankdir=LR;\n")
+	builder.WriteString("    node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(builder, "    %s [label=%s, class=%s];\n", dotQuote(node.ID), dotQuote(node.Label+"\n"+string(node.Type)), dotQuote(string(node.Type)))
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(builder, "    %s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+// dotQuote returns a DOT string literal, in which only quotes and
+// backslashes are escaped and \n is a line break.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format("2006-01-02 15:04")
+}
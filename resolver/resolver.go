@@ -0,0 +1,393 @@
+// Package resolver follows the relationships between Slide resources, such as
+// the client that owns a snapshot, without a call per hop. Each resource is
+// listed once and cached, so resolving many records costs a few list calls.
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+// Resolver answers relationship lookups from cached lists of the resources.
+// It is safe for concurrent use.
+type Resolver struct {
+	service  goslide.Service
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	clients         *cache[goslide.Client]
+	devices         *cache[goslide.Device]
+	agents          *cache[goslide.Agent]
+	backups         *cache[goslide.Backup]
+	snapshots       *cache[goslide.Snapshot]
+	alerts          *cache[goslide.Alert]
+	fileRestores    *cache[goslide.FileRestore]
+	imageExports    *cache[goslide.ImageExportRestore]
+	virtualMachines *cache[goslide.VirtualMachineRestore]
+}
+
+type resolverOption func(r *Resolver)
+
+// WithCacheTTL sets how long a listed resource is reused before it is listed
+// again.
+func WithCacheTTL(ttl time.Duration) resolverOption {
+	return func(r *Resolver) {
+		r.cacheTTL = ttl
+	}
+}
+
+func New(service goslide.Service, options ...resolverOption) *Resolver {
+	resolver := &Resolver{
+		service:  service,
+		cacheTTL: defaultCacheTTL,
+		now:      time.Now,
+	}
+
+	for _, option := range options {
+		option(resolver)
+	}
+
+	resolver.clients = &cache[goslide.Client]{
+		resolver: resolver,
+		id:       func(client goslide.Client) string { return client.ClientID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.Client]) error) error {
+			return service.Clients().List(ctx, pageHandler)
+		},
+		get: service.Clients().Get,
+	}
+	resolver.devices = &cache[goslide.Device]{
+		resolver: resolver,
+		id:       func(device goslide.Device) string { return device.DeviceID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.Device]) error) error {
+			return service.Devices().List(ctx, pageHandler)
+		},
+		get: service.Devices().Get,
+	}
+	resolver.agents = &cache[goslide.Agent]{
+		resolver: resolver,
+		id:       func(agent goslide.Agent) string { return agent.AgentID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.Agent]) error) error {
+			return service.Agents().List(ctx, pageHandler)
+		},
+		get: service.Agents().Get,
+	}
+	resolver.backups = &cache[goslide.Backup]{
+		resolver: resolver,
+		id:       func(backup goslide.Backup) string { return backup.BackupID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.Backup]) error) error {
+			return service.Backups().List(ctx, pageHandler)
+		},
+		get: service.Backups().Get,
+	}
+	resolver.snapshots = &cache[goslide.Snapshot]{
+		resolver: resolver,
+		id:       func(snapshot goslide.Snapshot) string { return snapshot.SnapshotID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.Snapshot]) error) error {
+			return service.Snapshots().List(ctx, pageHandler)
+		},
+		get: service.Snapshots().Get,
+	}
+	resolver.alerts = &cache[goslide.Alert]{
+		resolver: resolver,
+		id:       func(alert goslide.Alert) string { return alert.AlertID },
+		// Only open alerts are listed, as resolved ones pile up
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.Alert]) error) error {
+			return service.Alerts().ListWithQueryParameters(ctx, pageHandler, goslide.WithIncludeResolvedAlerts(false))
+		},
+		get: service.Alerts().Get,
+	}
+	resolver.fileRestores = &cache[goslide.FileRestore]{
+		resolver: resolver,
+		id:       func(restore goslide.FileRestore) string { return restore.FileRestoreID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.FileRestore]) error) error {
+			return service.FileRestores().List(ctx, pageHandler)
+		},
+		get: service.FileRestores().Get,
+	}
+	resolver.imageExports = &cache[goslide.ImageExportRestore]{
+		resolver: resolver,
+		id:       func(export goslide.ImageExportRestore) string { return export.ImageExportID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.ImageExportRestore]) error) error {
+			return service.ImageExportRestores().List(ctx, pageHandler)
+		},
+		get: service.ImageExportRestores().Get,
+	}
+	resolver.virtualMachines = &cache[goslide.VirtualMachineRestore]{
+		resolver: resolver,
+		id:       func(vm goslide.VirtualMachineRestore) string { return vm.VirtID },
+		list: func(ctx context.Context, pageHandler func(response goslide.ListResponse[goslide.VirtualMachineRestore]) error) error {
+			return service.VirtualMachineRestores().List(ctx, pageHandler)
+		},
+		get: service.VirtualMachineRestores().Get,
+	}
+
+	return resolver
+}
+
+// Reset drops every cached list, so the next lookups list the resources
+// again.
+func (r *Resolver) Reset() {
+	r.clients.reset()
+	r.devices.reset()
+	r.agents.reset()
+	r.backups.reset()
+	r.snapshots.reset()
+	r.alerts.reset()
+	r.fileRestores.reset()
+	r.imageExports.reset()
+	r.virtualMachines.reset()
+}
+
+// cache holds every record of a resource, listed in one go.
+type cache[T any] struct {
+	resolver *Resolver
+	id       func(record T) string
+	list     func(ctx context.Context, pageHandler func(response goslide.ListResponse[T]) error) error
+	get      func(ctx context.Context, id string) (T, error)
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	records  []T
+	byID     map[string]T
+}
+
+// load lists the resource unless the cached list is recent enough. Callers
+// waiting on the same load share its result.
+func (c *cache[T]) load(ctx context.Context) ([]T, map[string]T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.byID != nil && c.resolver.now().Sub(c.loadedAt) < c.resolver.cacheTTL {
+		return c.records, c.byID, nil
+	}
+
+	records := []T{}
+	if err := c.list(ctx, func(response goslide.ListResponse[T]) error {
+		records = append(records, response.Data...)
+
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[string]T, len(records))
+	for _, record := range records {
+		byID[c.id(record)] = record
+	}
+
+	c.loadedAt = c.resolver.now()
+	c.records = records
+	c.byID = byID
+
+	return records, byID, nil
+}
+
+// lookup returns a record by ID. A record missing from the cached list, such
+// as one created since, is fetched on its own and added to the cache.
+func (c *cache[T]) lookup(ctx context.Context, id string) (T, error) {
+	_, byID, err := c.load(ctx)
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	c.mu.Lock()
+	record, ok := byID[id]
+	c.mu.Unlock()
+	if ok {
+		return record, nil
+	}
+
+	record, err = c.get(ctx, id)
+	if err != nil {
+		return record, err
+	}
+
+	c.mu.Lock()
+	if c.byID != nil {
+		c.byID[id] = record
+		c.records = append(c.records, record)
+	}
+	c.mu.Unlock()
+
+	return record, nil
+}
+
+func (c *cache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.records = nil
+	c.byID = nil
+}
+
+// filter returns the cached records that keep returns true for.
+func filter[T any](ctx context.Context, c *cache[T], keep func(record T) bool) ([]T, error) {
+	records, _, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []T{}
+	for _, record := range records {
+		if keep(record) {
+			matched = append(matched, record)
+		}
+	}
+
+	return matched, nil
+}
+
+func (r *Resolver) Client(ctx context.Context, clientID string) (goslide.Client, error) {
+	return r.clients.lookup(ctx, clientID)
+}
+
+func (r *Resolver) Device(ctx context.Context, deviceID string) (goslide.Device, error) {
+	return r.devices.lookup(ctx, deviceID)
+}
+
+func (r *Resolver) Agent(ctx context.Context, agentID string) (goslide.Agent, error) {
+	return r.agents.lookup(ctx, agentID)
+}
+
+// ResolveAgent returns the agent a snapshot was taken of.
+func (r *Resolver) ResolveAgent(ctx context.Context, snapshot goslide.Snapshot) (goslide.Agent, error) {
+	agent, err := r.Agent(ctx, snapshot.AgentID)
+	if err != nil {
+		return goslide.Agent{}, fmt.Errorf("unable to resolve agent of snapshot %s - %w", snapshot.SnapshotID, err)
+	}
+
+	return agent, nil
+}
+
+// ResolveDevice returns the device an agent backs up to.
+func (r *Resolver) ResolveDevice(ctx context.Context, agent goslide.Agent) (goslide.Device, error) {
+	device, err := r.Device(ctx, agent.DeviceID)
+	if err != nil {
+		return goslide.Device{}, fmt.Errorf("unable to resolve device of agent %s - %w", agent.AgentID, err)
+	}
+
+	return device, nil
+}
+
+// ResolveClient returns the client of an agent.
+func (r *Resolver) ResolveClient(ctx context.Context, agent goslide.Agent) (goslide.Client, error) {
+	client, err := r.Client(ctx, agent.ClientID)
+	if err != nil {
+		return goslide.Client{}, fmt.Errorf("unable to resolve client of agent %s - %w", agent.AgentID, err)
+	}
+
+	return client, nil
+}
+
+// Owner is the chain of resources a snapshot belongs to.
+type Owner struct {
+	Client goslide.Client
+	Device goslide.Device
+	Agent  goslide.Agent
+}
+
+// ResolveOwner returns the agent, device and client of a snapshot.
+func (r *Resolver) ResolveOwner(ctx context.Context, snapshot goslide.Snapshot) (Owner, error) {
+	agent, err := r.ResolveAgent(ctx, snapshot)
+	if err != nil {
+		return Owner{}, err
+	}
+
+	device, err := r.ResolveDevice(ctx, agent)
+	if err != nil {
+		return Owner{}, err
+	}
+
+	owner := Owner{Device: device, Agent: agent}
+
+	// Agents not assigned to a client belong to the client of their device
+	clientID := agent.ClientID
+	if clientID == "" {
+		clientID = device.ClientID
+	}
+
+	if clientID != "" {
+		if owner.Client, err = r.Client(ctx, clientID); err != nil {
+			return Owner{}, fmt.Errorf("unable to resolve client of snapshot %s - %w", snapshot.SnapshotID, err)
+		}
+	}
+
+	return owner, nil
+}
+
+func (r *Resolver) DevicesForClient(ctx context.Context, clientID string) ([]goslide.Device, error) {
+	return filter(ctx, r.devices, func(device goslide.Device) bool {
+		return device.ClientID == clientID
+	})
+}
+
+func (r *Resolver) AgentsForClient(ctx context.Context, clientID string) ([]goslide.Agent, error) {
+	return filter(ctx, r.agents, func(agent goslide.Agent) bool {
+		return agent.ClientID == clientID
+	})
+}
+
+func (r *Resolver) AgentsForDevice(ctx context.Context, deviceID string) ([]goslide.Agent, error) {
+	return filter(ctx, r.agents, func(agent goslide.Agent) bool {
+		return agent.DeviceID == deviceID
+	})
+}
+
+func (r *Resolver) BackupsForAgent(ctx context.Context, agentID string) ([]goslide.Backup, error) {
+	return filter(ctx, r.backups, func(backup goslide.Backup) bool {
+		return backup.AgentID == agentID
+	})
+}
+
+func (r *Resolver) SnapshotsForAgent(ctx context.Context, agentID string) ([]goslide.Snapshot, error) {
+	return filter(ctx, r.snapshots, func(snapshot goslide.Snapshot) bool {
+		return snapshot.AgentID == agentID
+	})
+}
+
+// AlertsForAgent returns the open alerts of an agent.
+func (r *Resolver) AlertsForAgent(ctx context.Context, agentID string) ([]goslide.Alert, error) {
+	return filter(ctx, r.alerts, func(alert goslide.Alert) bool {
+		return alert.AgentID == agentID
+	})
+}
+
+// Restores are the restores of an agent, of every kind.
+type Restores struct {
+	FileRestores    []goslide.FileRestore
+	ImageExports    []goslide.ImageExportRestore
+	VirtualMachines []goslide.VirtualMachineRestore
+}
+
+func (r *Resolver) RestoresForAgent(ctx context.Context, agentID string) (Restores, error) {
+	restores := Restores{}
+
+	var err error
+	if restores.FileRestores, err = filter(ctx, r.fileRestores, func(restore goslide.FileRestore) bool {
+		return restore.AgentID == agentID
+	}); err != nil {
+		return Restores{}, err
+	}
+
+	if restores.ImageExports, err = filter(ctx, r.imageExports, func(export goslide.ImageExportRestore) bool {
+		return export.AgentID == agentID
+	}); err != nil {
+		return Restores{}, err
+	}
+
+	if restores.VirtualMachines, err = filter(ctx, r.virtualMachines, func(vm goslide.VirtualMachineRestore) bool {
+		return vm.AgentID == agentID
+	}); err != nil {
+		return Restores{}, err
+	}
+
+	return restores, nil
+}
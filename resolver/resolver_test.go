@@ -0,0 +1,159 @@
+package resolver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/resolver"
+	"github.com/google/go-cmp/cmp"
+)
+
+// addHistory gives a_dc a backup, a snapshot and a file restore, a_mail a
+// snapshot and d_acme an alert.
+func addHistory(server *fakeslide.Server) {
+	server.Backups = []goslide.Backup{
+		{BackupID: "b_1", AgentID: "a_dc", Status: goslide.BackupStatus_SUCCEEDED, StartedAt: time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)},
+	}
+	server.Snapshots = []goslide.Snapshot{
+		{SnapshotID: "s_dc", AgentID: "a_dc", BackupEndedAt: time.Date(2024, 5, 1, 2, 30, 0, 0, time.UTC)},
+		{SnapshotID: "s_mail", AgentID: "a_mail"},
+	}
+	server.Alerts = []goslide.Alert{
+		{AlertID: "al_device", AlertType: goslide.AlertType_DEVICE_NOT_CHECKING_IN, DeviceID: "d_acme"},
+	}
+	server.FileRestores = []goslide.FileRestore{
+		{FileRestoreID: "fr_1", AgentID: "a_dc", DeviceID: "d_acme", SnapshotID: "s_dc"},
+	}
+}
+
+func TestResolveOwner(t *testing.T) {
+	ctx := context.Background()
+	server := fakeslide.NewFleet()
+	addHistory(server)
+	r := resolver.New(server.Service())
+
+	owners := map[string]string{}
+	for _, snapshot := range server.Snapshots {
+		owner, err := r.ResolveOwner(ctx, snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		owners[snapshot.SnapshotID] = owner.Client.Name + "/" + owner.Device.DeviceID + "/" + owner.Agent.AgentID
+	}
+
+	if diff := cmp.Diff(map[string]string{
+		"s_dc":   "Acme/d_acme/a_dc",
+		"s_mail": "Globex/d_globex/a_mail",
+	}, owners); diff != "" {
+		t.Fatalf("%s owners (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Each resource is listed once, whatever the number of snapshots
+	if diff := cmp.Diff([]string{
+		"GET /v1/agent", "GET /v1/agent", "GET /v1/device", "GET /v1/device", "GET /v1/client",
+	}, server.Requests); diff != "" {
+		t.Fatalf("%s requests (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// An agent created since the list is fetched on its own
+	server.Agents = append(server.Agents, goslide.Agent{AgentID: "a_new", DeviceID: "d_acme", ClientID: "c_acme"})
+
+	agent, err := r.ResolveAgent(ctx, goslide.Snapshot{SnapshotID: "s_new", AgentID: "a_new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if agent.AgentID != "a_new" || server.Count("GET /v1/agent/a_new") != 1 || server.Count("GET /v1/agent") != 2 {
+		t.Fatalf("%s expected a_new to be fetched by ID, got %+v after %v", t.Name(), agent, server.Requests)
+	}
+
+	devices, err := r.DevicesForClient(ctx, "c_globex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(server.Devices[2:], devices); diff != "" {
+		t.Fatalf("%s devices for client (-want +got):\n%s", t.Name(), diff)
+	}
+
+	agents, err := r.AgentsForDevice(ctx, "d_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(agents) != 3 {
+		t.Fatalf("%s expected the cached a_new among the agents of d_acme, got %+v", t.Name(), agents)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	ctx := context.Background()
+	server := fakeslide.NewFleet()
+	addHistory(server)
+	r := resolver.New(server.Service())
+
+	graph, err := r.ClientGraph(ctx, "c_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dot := &bytes.Buffer{}
+	if err := graph.WriteDOT(dot); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph slide {
+    rankdir=LR;
+    node [shape=box];
+    "c_acme" [label="Acme\nclient", class="client"];
+    "d_acme" [label="Acme HQ\ndevice", class="device"];
+    "d_acme_branch" [label="Acme Branch\ndevice", class="device"];
+    "a_dc" [label="Acme DC01\nagent", class="agent"];
+    "a_app" [label="acme-app\nagent", class="agent"];
+    "b_1" [label="succeeded 2024-05-01 02:00\nbackup", class="backup"];
+    "s_dc" [label="2024-05-01 02:30\nsnapshot", class="snapshot"];
+    "al_device" [label="device_not_checking_in\nalert", class="alert"];
+    "fr_1" [label="file restore\nfile_restore", class="file_restore"];
+    "c_acme" -> "d_acme";
+    "c_acme" -> "d_acme_branch";
+    "d_acme" -> "a_dc";
+    "d_acme" -> "a_app";
+    "a_dc" -> "b_1";
+    "a_dc" -> "s_dc";
+    "d_acme" -> "al_device";
+    "a_dc" -> "fr_1";
+}
+`
+	if diff := cmp.Diff(want, dot.String()); diff != "" {
+		t.Fatalf("%s DOT (-want +got):\n%s", t.Name(), diff)
+	}
+
+	full, err := r.Graph(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &bytes.Buffer{}
+	if err := full.WriteJSON(data); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := resolver.Graph{}
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(full, decoded); diff != "" {
+		t.Fatalf("%s JSON round trip (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if len(decoded.Nodes) != 13 || len(decoded.Edges) != 11 {
+		t.Fatalf("%s expected 13 nodes and 11 edges, got %d and %d", t.Name(), len(decoded.Nodes), len(decoded.Edges))
+	}
+}
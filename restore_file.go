@@ -43,7 +43,12 @@ func (f FileRestoreService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[FileRestore]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := f.requestClient.startPagination(ctx, f.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -157,7 +162,12 @@ func (f FileRestoreService) BrowseWithQueryParameters(
 	fileRestoreID string,
 	pageHandler func(response ListResponse[FileRestoreData]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := f.requestClient.startPagination(ctx, f.baseEndpoint+"/"+fileRestoreID+"/browse")
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -74,7 +74,12 @@ func (i ImageExportRestoreService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[ImageExportRestore]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := i.requestClient.startPagination(ctx, i.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -188,7 +193,12 @@ func (i ImageExportRestoreService) BrowseWithQueryParameters(
 	imageExportRestoreID string,
 	pageHandler func(response ListResponse[ImageExportRestoreData]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := i.requestClient.startPagination(ctx, i.baseEndpoint+"/"+imageExportRestoreID+"/browse")
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
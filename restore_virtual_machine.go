@@ -53,7 +53,12 @@ func (v VirtualMachineRestoreService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[VirtualMachineRestore]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := v.requestClient.startPagination(ctx, v.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -200,7 +205,7 @@ type VirtualMachineRestoreUpdatePayload struct {
 	State      VirtualMachineState `json:"state,omitempty"`
 	CPUCount   uint                `json:"cpu_count,omitempty"`
 	MemoryInMB uint                `json:"memory_in_mb,omitempty"`
-	ExpiresAt  time.Time           `json:"expires_at,omitempty"`
+	ExpiresAt  *time.Time          `json:"expires_at,omitempty"`
 }
 
 type VirtualMachineRestoreCreatePayload struct {
@@ -213,6 +218,10 @@ type VirtualMachineRestoreCreatePayload struct {
 	MemoryInMB   uint                       `json:"memory_in_mb,omitempty"`
 	NetworkModel VirtualMachineNetworkModel `json:"network_model,omitempty"`
 	NetworkType  VirtualMachineNetworkType  `json:"network_type,omitempty"`
+
+	// NetworkSource is the ID of the disaster network to attach the virtual
+	// machine to, used with VirtualMachineNetworkType_NETWORK_ID
+	NetworkSource string `json:"network_source,omitempty"`
 }
 
 type BootMod string
@@ -242,6 +251,7 @@ const (
 	VirtualMachineNetworkType_NETWORK          VirtualMachineNetworkType = "network"
 	VirtualMachineNetworkType_NETWORK_ISOLATED VirtualMachineNetworkType = "network-isolated"
 	VirtualMachineNetworkType_BRIDGE           VirtualMachineNetworkType = "bridge"
+	VirtualMachineNetworkType_NETWORK_ID       VirtualMachineNetworkType = "network-id"
 )
 
 type VirtualMachineState string
@@ -0,0 +1,253 @@
+package goslide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const defaultLifecyclePollInterval = 5 * time.Second
+
+type VirtualMachineStateTransitionError struct {
+	VirtID string
+	From   VirtualMachineState
+	To     VirtualMachineState
+}
+
+func (e *VirtualMachineStateTransitionError) Error() string {
+	return fmt.Sprintf("virtual machine %s can not transition from %q to %q", e.VirtID, e.From, e.To)
+}
+
+// VirtualMachineStateError is returned by operations that the virtual machine
+// does not allow in its current state.
+type VirtualMachineStateError struct {
+	VirtID    string
+	Operation string
+	State     VirtualMachineState
+}
+
+func (e *VirtualMachineStateError) Error() string {
+	return fmt.Sprintf("virtual machine %s can not be %s while %q", e.VirtID, e.Operation, e.State)
+}
+
+type lifecycleConfig struct {
+	pollInterval time.Duration
+}
+
+type lifecycleOption func(c *lifecycleConfig)
+
+// WithVirtualMachinePollInterval sets how often the virtual machine is
+// refreshed while waiting for a lifecycle operation to complete.
+func WithVirtualMachinePollInterval(interval time.Duration) lifecycleOption {
+	return func(c *lifecycleConfig) {
+		c.pollInterval = interval
+	}
+}
+
+// Start boots a stopped virtual machine and waits until it is running.
+func (v VirtualMachineRestoreService) Start(ctx context.Context, virtID string, options ...lifecycleOption) (VirtualMachineRestore, error) {
+	return v.transition(ctx, virtID, VirtualMachineState_RUNNING, []VirtualMachineState{
+		VirtualMachineState_STOPPED,
+	}, options...)
+}
+
+// Stop powers off a running or paused virtual machine and waits until it is stopped.
+func (v VirtualMachineRestoreService) Stop(ctx context.Context, virtID string, options ...lifecycleOption) (VirtualMachineRestore, error) {
+	return v.transition(ctx, virtID, VirtualMachineState_STOPPED, []VirtualMachineState{
+		VirtualMachineState_RUNNING,
+		VirtualMachineState_PAUSED,
+	}, options...)
+}
+
+// Pause suspends a running virtual machine and waits until it is paused.
+func (v VirtualMachineRestoreService) Pause(ctx context.Context, virtID string, options ...lifecycleOption) (VirtualMachineRestore, error) {
+	return v.transition(ctx, virtID, VirtualMachineState_PAUSED, []VirtualMachineState{
+		VirtualMachineState_RUNNING,
+	}, options...)
+}
+
+// Resume continues a paused virtual machine and waits until it is running.
+func (v VirtualMachineRestoreService) Resume(ctx context.Context, virtID string, options ...lifecycleOption) (VirtualMachineRestore, error) {
+	return v.transition(ctx, virtID, VirtualMachineState_RUNNING, []VirtualMachineState{
+		VirtualMachineState_PAUSED,
+	}, options...)
+}
+
+// Resize changes the CPU count and memory of a stopped virtual machine, and
+// waits until the new values are reported.
+func (v VirtualMachineRestoreService) Resize(
+	ctx context.Context,
+	virtID string,
+	cpuCount uint,
+	memoryInMB uint,
+	options ...lifecycleOption,
+) (VirtualMachineRestore, error) {
+	if cpuCount == 0 || memoryInMB == 0 {
+		return VirtualMachineRestore{}, errors.New("cpu count and memory must both be greater than zero")
+	}
+
+	config := newLifecycleConfig(options)
+
+	if _, err := v.checkState(ctx, virtID, "resized", []VirtualMachineState{
+		VirtualMachineState_STOPPED,
+	}); err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	latest, err := v.Update(ctx, virtID, VirtualMachineRestoreUpdatePayload{
+		CPUCount:   cpuCount,
+		MemoryInMB: memoryInMB,
+	})
+	if err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	return v.waitFor(ctx, virtID, latest, config, func(vm VirtualMachineRestore) bool {
+		return vm.CPUCount == cpuCount && vm.MemoryInMB == memoryInMB
+	})
+}
+
+// ExtendExpiry pushes the expiry of the virtual machine back by the given
+// duration. A virtual machine that has already expired is extended from now.
+func (v VirtualMachineRestoreService) ExtendExpiry(
+	ctx context.Context,
+	virtID string,
+	duration time.Duration,
+	options ...lifecycleOption,
+) (VirtualMachineRestore, error) {
+	if duration <= 0 {
+		return VirtualMachineRestore{}, errors.New("expiry extension must be greater than zero")
+	}
+
+	config := newLifecycleConfig(options)
+
+	current, err := v.checkState(ctx, virtID, "extended", []VirtualMachineState{
+		VirtualMachineState_RUNNING,
+		VirtualMachineState_STOPPED,
+		VirtualMachineState_PAUSED,
+	})
+	if err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	expiresAt := current.ExpiresAt
+	if now := time.Now(); expiresAt.Before(now) {
+		expiresAt = now
+	}
+
+	// The API reports expiry with second precision
+	expiresAt = expiresAt.Add(duration).UTC().Truncate(time.Second)
+
+	latest, err := v.Update(ctx, virtID, VirtualMachineRestoreUpdatePayload{
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	return v.waitFor(ctx, virtID, latest, config, func(vm VirtualMachineRestore) bool {
+		return !vm.ExpiresAt.Before(expiresAt)
+	})
+}
+
+func (v VirtualMachineRestoreService) transition(
+	ctx context.Context,
+	virtID string,
+	target VirtualMachineState,
+	allowedFrom []VirtualMachineState,
+	options ...lifecycleOption,
+) (VirtualMachineRestore, error) {
+	config := newLifecycleConfig(options)
+
+	current, err := v.Get(ctx, virtID)
+	if err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	if current.State == target {
+		return current, nil
+	}
+
+	if !slices.Contains(allowedFrom, current.State) {
+		return VirtualMachineRestore{}, &VirtualMachineStateTransitionError{
+			VirtID: virtID,
+			From:   current.State,
+			To:     target,
+		}
+	}
+
+	latest, err := v.Update(ctx, virtID, VirtualMachineRestoreUpdatePayload{
+		State: target,
+	})
+	if err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	return v.waitFor(ctx, virtID, latest, config, func(vm VirtualMachineRestore) bool {
+		return vm.State == target
+	})
+}
+
+// checkState returns the virtual machine if it is in one of the allowed states.
+func (v VirtualMachineRestoreService) checkState(
+	ctx context.Context,
+	virtID string,
+	operation string,
+	allowed []VirtualMachineState,
+) (VirtualMachineRestore, error) {
+	current, err := v.Get(ctx, virtID)
+	if err != nil {
+		return VirtualMachineRestore{}, err
+	}
+
+	if !slices.Contains(allowed, current.State) {
+		return VirtualMachineRestore{}, &VirtualMachineStateError{
+			VirtID:    virtID,
+			Operation: operation,
+			State:     current.State,
+		}
+	}
+
+	return current, nil
+}
+
+func (v VirtualMachineRestoreService) waitFor(
+	ctx context.Context,
+	virtID string,
+	latest VirtualMachineRestore,
+	config lifecycleConfig,
+	done func(vm VirtualMachineRestore) bool,
+) (VirtualMachineRestore, error) {
+	ticker := time.NewTicker(config.pollInterval)
+	defer ticker.Stop()
+
+	for !done(latest) {
+		select {
+		case <-ctx.Done():
+			return VirtualMachineRestore{}, ctx.Err()
+		case <-ticker.C:
+		}
+
+		var err error
+		latest, err = v.Get(ctx, virtID)
+		if err != nil {
+			return VirtualMachineRestore{}, err
+		}
+	}
+
+	return latest, nil
+}
+
+func newLifecycleConfig(options []lifecycleOption) lifecycleConfig {
+	config := lifecycleConfig{
+		pollInterval: defaultLifecyclePollInterval,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
@@ -0,0 +1,286 @@
+package goslide_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/google/go-cmp/cmp"
+)
+
+func serveVirtualMachine(t *testing.T, method, virtID, responseFile, requestFile string) roundtripper.TestRoundTripFunc {
+	t.Helper()
+
+	expected := roundtripper.ExpectedTestRequest{
+		Method: method,
+		Path:   "/v1/restore/virt/" + virtID,
+		Query:  url.Values{},
+	}
+
+	if requestFile != "" {
+		expected.Validator = validateRequestBody(t, requestFile)
+	}
+
+	return roundtripper.ServeAndValidate(
+		t,
+		&roundtripper.TestResponseFile{
+			StatusCode: http.StatusOK,
+			FilePath:   responseFile,
+		},
+		expected,
+	)
+}
+
+func TestRestore_Virtual_Machine_Start(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_stopped_200.json", ""),
+					serveVirtualMachine(t, http.MethodPatch, virtID, "testdata/responses/restore_virtual_machine/get_stopped_200.json", "testdata/requests/restore_virtual_machine/start_202.json"),
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_stopped_200.json", ""),
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	actual, err := testService.VirtualMachineRestores().Start(ctx, virtID, goslide.WithVirtualMachinePollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(goslide.VirtualMachineState_RUNNING, actual.State); diff != "" {
+		t.Fatalf("%s Returned state mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestRestore_Virtual_Machine_Start_AlreadyRunning(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	actual, err := testService.VirtualMachineRestores().Start(ctx, virtID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(goslide.VirtualMachineState_RUNNING, actual.State); diff != "" {
+		t.Fatalf("%s Returned state mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestRestore_Virtual_Machine_Pause(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""),
+					serveVirtualMachine(t, http.MethodPatch, virtID, "testdata/responses/restore_virtual_machine/get_paused_200.json", "testdata/requests/restore_virtual_machine/pause_202.json"),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	actual, err := testService.VirtualMachineRestores().Pause(ctx, virtID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(goslide.VirtualMachineState_PAUSED, actual.State); diff != "" {
+		t.Fatalf("%s Returned state mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestRestore_Virtual_Machine_Resume_IllegalTransition(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_stopped_200.json", ""),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	_, err := testService.VirtualMachineRestores().Resume(ctx, virtID)
+
+	transitionErr := &goslide.VirtualMachineStateTransitionError{}
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("%s expected a VirtualMachineStateTransitionError, got %v", t.Name(), err)
+	}
+
+	expected := &goslide.VirtualMachineStateTransitionError{
+		VirtID: virtID,
+		From:   goslide.VirtualMachineState_STOPPED,
+		To:     goslide.VirtualMachineState_RUNNING,
+	}
+
+	if diff := cmp.Diff(expected, transitionErr); diff != "" {
+		t.Fatalf("%s Returned error mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestRestore_Virtual_Machine_Stop_Timeout(t *testing.T) {
+	virtID := "virt_0123456789ab"
+
+	queue := []roundtripper.TestRoundTripFunc{
+		serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""),
+		serveVirtualMachine(t, http.MethodPatch, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""),
+	}
+	for range 100 {
+		queue = append(queue, serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""))
+	}
+
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(t, queue),
+		),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := testService.VirtualMachineRestores().Stop(ctx, virtID, goslide.WithVirtualMachinePollInterval(time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%s expected context.DeadlineExceeded, got %v", t.Name(), err)
+	}
+}
+
+func TestRestore_Virtual_Machine_Resize(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_stopped_200.json", ""),
+					serveVirtualMachine(t, http.MethodPatch, virtID, "testdata/responses/restore_virtual_machine/get_stopped_200.json", "testdata/requests/restore_virtual_machine/resize_202.json"),
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_resized_200.json", ""),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	actual, err := testService.VirtualMachineRestores().Resize(ctx, virtID, 4, 8192, goslide.WithVirtualMachinePollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.CPUCount != 4 || actual.MemoryInMB != 8192 {
+		t.Fatalf("%s expected 4 CPUs and 8192MB of memory, got %d CPUs and %dMB", t.Name(), actual.CPUCount, actual.MemoryInMB)
+	}
+}
+
+func TestRestore_Virtual_Machine_Resize_Running(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_200.json", ""),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	_, err := testService.VirtualMachineRestores().Resize(ctx, virtID, 4, 8192)
+
+	stateErr := &goslide.VirtualMachineStateError{}
+	if !errors.As(err, &stateErr) {
+		t.Fatalf("%s expected a VirtualMachineStateError, got %v", t.Name(), err)
+	}
+
+	expected := &goslide.VirtualMachineStateError{
+		VirtID:    virtID,
+		Operation: "resized",
+		State:     goslide.VirtualMachineState_RUNNING,
+	}
+
+	if diff := cmp.Diff(expected, stateErr); diff != "" {
+		t.Fatalf("%s Returned error mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestRestore_Virtual_Machine_ExtendExpiry(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_future_expiry_200.json", ""),
+					serveVirtualMachine(t, http.MethodPatch, virtID, "testdata/responses/restore_virtual_machine/get_extended_200.json", "testdata/requests/restore_virtual_machine/extend_expiry_202.json"),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	actual, err := testService.VirtualMachineRestores().ExtendExpiry(ctx, virtID, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(generateRFC3389FromString(t, "2124-08-25T01:25:08Z"), actual.ExpiresAt); diff != "" {
+		t.Fatalf("%s Returned expiry mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestRestore_Virtual_Machine_ExtendExpiry_Deleting(t *testing.T) {
+	virtID := "virt_0123456789ab"
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					serveVirtualMachine(t, http.MethodGet, virtID, "testdata/responses/restore_virtual_machine/get_deleting_200.json", ""),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	_, err := testService.VirtualMachineRestores().ExtendExpiry(ctx, virtID, 24*time.Hour)
+
+	stateErr := &goslide.VirtualMachineStateError{}
+	if !errors.As(err, &stateErr) {
+		t.Fatalf("%s expected a VirtualMachineStateError, got %v", t.Name(), err)
+	}
+
+	expected := &goslide.VirtualMachineStateError{
+		VirtID:    virtID,
+		Operation: "extended",
+		State:     goslide.VirtualMachineState("deleting"),
+	}
+
+	if diff := cmp.Diff(expected, stateErr); diff != "" {
+		t.Fatalf("%s Returned error mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
@@ -336,6 +336,41 @@ func TestRestore_Virtual_Machine_Create_With_Options(t *testing.T) {
 	}
 }
 
+func TestRestore_Virtual_Machine_Create_With_Network_ID(t *testing.T) {
+	testService := goslide.NewService("fakeToken",
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusCreated,
+							FilePath:   "testdata/responses/restore_virtual_machine/create_201.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method:    http.MethodPost,
+							Path:      "/v1/restore/virt",
+							Query:     url.Values{},
+							Validator: validateRequestBody(t, "testdata/requests/restore_virtual_machine/create_with_network_id_201.json"),
+						},
+					),
+				},
+			),
+		),
+	)
+
+	ctx := context.Background()
+	if _, err := testService.VirtualMachineRestores().Create(ctx, goslide.VirtualMachineRestoreCreatePayload{
+		DeviceID:      "d_0123456789ab",
+		SnapshotID:    "s_0123456789ab",
+		NetworkType:   goslide.VirtualMachineNetworkType_NETWORK_ID,
+		NetworkSource: "net_0123456789ab",
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestRestore_Virtual_Machine_Update(t *testing.T) {
 	virtID := "virt_0123456789ab"
 	testService := goslide.NewService("fakeToken",
@@ -383,12 +418,14 @@ func TestRestore_Virtual_Machine_Update(t *testing.T) {
 		),
 	)
 
+	expiresAt := generateRFC3389FromString(t, "2024-08-23T01:25:08Z")
+
 	ctx := context.Background()
 	actual, err := testService.VirtualMachineRestores().Update(ctx, virtID, goslide.VirtualMachineRestoreUpdatePayload{
 		State:      goslide.VirtualMachineState_RUNNING,
 		CPUCount:   2,
 		MemoryInMB: 4096,
-		ExpiresAt:  generateRFC3389FromString(t, "2024-08-23T01:25:08Z"),
+		ExpiresAt:  &expiresAt,
 	})
 	if err != nil {
 		t.Fatal(err)
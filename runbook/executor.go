@@ -0,0 +1,715 @@
+package runbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	defaultPollInterval        = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Minute
+	defaultHealthCheckInterval = 10 * time.Second
+)
+
+// Executor runs a runbook against the Slide API in three phases: Plan shows
+// what will happen, Apply creates the resources, and Teardown removes them.
+// Progress is saved to the state file after every step.
+type Executor struct {
+	service      goslide.Service
+	runbook      Runbook
+	state        *State
+	pollInterval time.Duration
+	httpClient   *http.Client
+	dialer       *net.Dialer
+	now          func() time.Time
+}
+
+type executorOption func(e *Executor)
+
+// WithPollInterval sets how often the API is polled while waiting for virtual
+// machines to change state.
+func WithPollInterval(interval time.Duration) executorOption {
+	return func(e *Executor) {
+		e.pollInterval = interval
+	}
+}
+
+// WithHTTPClient sets the client used by HTTP health checks.
+func WithHTTPClient(httpClient *http.Client) executorOption {
+	return func(e *Executor) {
+		e.httpClient = httpClient
+	}
+}
+
+func NewExecutor(service goslide.Service, runbook Runbook, statePath string, options ...executorOption) (*Executor, error) {
+	if err := runbook.Validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := LoadState(statePath, runbook.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	executor := &Executor{
+		service:      service,
+		runbook:      runbook,
+		state:        state,
+		pollInterval: defaultPollInterval,
+		httpClient:   http.DefaultClient,
+		dialer:       &net.Dialer{},
+		now:          time.Now,
+	}
+
+	for _, option := range options {
+		option(executor)
+	}
+
+	return executor, nil
+}
+
+// State returns the current run state, including the IDs of created resources.
+func (e *Executor) State() *State {
+	return e.state
+}
+
+type PlannedStep struct {
+	ID          string
+	Description string
+	Status      StepStatus
+}
+
+type step struct {
+	id       string
+	describe func(ctx context.Context) (string, error)
+	run      func(ctx context.Context, state *StepState) error
+
+	// undo removes whatever run created. Steps without undo are skipped on teardown.
+	undo func(ctx context.Context, state *StepState) error
+}
+
+// Plan describes each step and whether it has already been applied. It makes
+// read-only API calls to resolve snapshots and existing networks.
+func (e *Executor) Plan(ctx context.Context) ([]PlannedStep, error) {
+	planned := []PlannedStep{}
+	for _, s := range e.steps() {
+		status := StepStatus_PENDING
+		if existing, ok := e.state.Steps[s.id]; ok {
+			status = existing.Status
+		}
+
+		description, err := s.describe(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("runbook: planning step %s - %w", s.id, err)
+		}
+
+		planned = append(planned, PlannedStep{
+			ID:          s.id,
+			Description: description,
+			Status:      status,
+		})
+	}
+
+	return planned, nil
+}
+
+// Apply runs every step that has not yet completed. If a step fails the error
+// is recorded in the state file, and calling Apply again resumes from that step.
+func (e *Executor) Apply(ctx context.Context) error {
+	for _, s := range e.steps() {
+		if e.state.done(s.id) {
+			continue
+		}
+
+		stepState := e.state.step(s.id)
+		if err := s.run(ctx, stepState); err != nil {
+			stepState.Status = StepStatus_FAILED
+			stepState.Error = err.Error()
+			stepState.UpdatedAt = e.now()
+
+			return errors.Join(fmt.Errorf("runbook: step %s failed - %w", s.id, err), e.state.save())
+		}
+
+		stepState.Status = StepStatus_DONE
+		stepState.Error = ""
+		stepState.UpdatedAt = e.now()
+
+		if err := e.state.save(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Teardown removes the resources created by Apply in reverse order. Once
+// everything has been removed the state file is deleted.
+func (e *Executor) Teardown(ctx context.Context) error {
+	steps := e.steps()
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		if s.undo == nil || !e.state.done(s.id) {
+			continue
+		}
+
+		teardownID := "teardown/" + s.id
+		if e.state.done(teardownID) {
+			continue
+		}
+
+		teardownState := e.state.step(teardownID)
+		if err := s.undo(ctx, e.state.Steps[s.id]); err != nil {
+			teardownState.Status = StepStatus_FAILED
+			teardownState.Error = err.Error()
+			teardownState.UpdatedAt = e.now()
+
+			return errors.Join(fmt.Errorf("runbook: teardown of %s failed - %w", s.id, err), e.state.save())
+		}
+
+		teardownState.Status = StepStatus_DONE
+		teardownState.Error = ""
+		teardownState.UpdatedAt = e.now()
+
+		if err := e.state.save(); err != nil {
+			return err
+		}
+	}
+
+	e.state.Steps = map[string]*StepState{}
+	if e.state.path == "" {
+		return nil
+	}
+
+	if err := os.Remove(e.state.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (e *Executor) machines() []MachineSpec {
+	machines := append([]MachineSpec{}, e.runbook.Machines...)
+	sort.SliceStable(machines, func(i, j int) bool {
+		return machines[i].BootOrder < machines[j].BootOrder
+	})
+
+	return machines
+}
+
+func (e *Executor) networkID() string {
+	return e.state.output("network", "network_id")
+}
+
+func (e *Executor) steps() []step {
+	steps := []step{e.networkStep()}
+
+	for i, portForward := range e.runbook.Network.PortForwards {
+		steps = append(steps, e.portForwardStep(i, portForward))
+	}
+
+	for _, peer := range e.runbook.Network.WGPeers {
+		steps = append(steps, e.wgPeerStep(peer))
+	}
+
+	for _, machine := range e.machines() {
+		steps = append(steps,
+			e.restoreStep(machine),
+			e.bootStep(machine),
+			e.healthStep(machine),
+		)
+	}
+
+	return steps
+}
+
+func (e *Executor) networkStep() step {
+	spec := e.runbook.Network
+
+	return step{
+		id: "network",
+		describe: func(ctx context.Context) (string, error) {
+			if networkID := e.networkID(); networkID != "" {
+				return fmt.Sprintf("use network %q (%s)", spec.Name, networkID), nil
+			}
+
+			existing, ok, err := e.findNetwork(ctx)
+			if err != nil {
+				return "", err
+			}
+
+			if ok {
+				return fmt.Sprintf("reuse existing network %q (%s)", spec.Name, existing.NetworkID), nil
+			}
+
+			return fmt.Sprintf("create %s network %q with router prefix %s", spec.Type, spec.Name, spec.RouterPrefix), nil
+		},
+		run: func(ctx context.Context, state *StepState) error {
+			existing, ok, err := e.findNetwork(ctx)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				state.Outputs["network_id"] = existing.NetworkID
+				state.Outputs["created"] = "false"
+
+				return nil
+			}
+
+			network, err := e.service.Networks().Create(ctx, goslide.NetworkCreatePayload{
+				Name:           spec.Name,
+				Type:           spec.Type,
+				BridgeDeviceID: spec.BridgeDeviceID,
+				ClientID:       e.runbook.ClientID,
+				Comments:       spec.Comments,
+				DHCP:           goslide.Bool(spec.DHCP),
+				DHCPRangeEnd:   spec.DHCPRangeEnd,
+				DHCPRangeStart: spec.DHCPRangeStart,
+				Internet:       goslide.Bool(spec.Internet),
+				Nameservers:    spec.Nameservers,
+				RouterPrefix:   spec.RouterPrefix,
+			})
+			if err != nil {
+				return err
+			}
+
+			state.Outputs["network_id"] = network.NetworkID
+			state.Outputs["created"] = "true"
+
+			return nil
+		},
+		undo: func(ctx context.Context, state *StepState) error {
+			// Networks that existed before the runbook ran are left alone
+			if state.Outputs["created"] != "true" {
+				return nil
+			}
+
+			return ignoreNotFound(e.service.Networks().Delete(ctx, state.Outputs["network_id"]))
+		},
+	}
+}
+
+func (e *Executor) findNetwork(ctx context.Context) (goslide.Network, bool, error) {
+	found := goslide.Network{}
+	ok := false
+
+	errFound := errors.New("found")
+	err := e.service.Networks().List(ctx, func(response goslide.ListResponse[goslide.Network]) error {
+		for _, network := range response.Data {
+			if network.Name != e.runbook.Network.Name {
+				continue
+			}
+
+			if e.runbook.ClientID != "" && network.ClientID != e.runbook.ClientID {
+				continue
+			}
+
+			found = network
+			ok = true
+
+			return errFound
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return goslide.Network{}, false, err
+	}
+
+	return found, ok, nil
+}
+
+func (e *Executor) portForwardStep(index int, spec PortForwardSpec) step {
+	return step{
+		id: "network/port-forward/" + strconv.Itoa(index),
+		describe: func(ctx context.Context) (string, error) {
+			return fmt.Sprintf("forward a %s port to %s", spec.Proto, spec.Dest), nil
+		},
+		run: func(ctx context.Context, state *StepState) error {
+			networkID := e.networkID()
+
+			portForward, err := e.service.Networks().CreatePortForward(ctx, networkID, goslide.NetworkPortForwardPayload{
+				Dest:      spec.Dest,
+				NetworkID: networkID,
+				Proto:     spec.Proto,
+			})
+			if err != nil {
+				return err
+			}
+
+			state.Outputs["port"] = strconv.FormatUint(uint64(portForward.Port), 10)
+
+			return nil
+		},
+		undo: func(ctx context.Context, state *StepState) error {
+			networkID := e.networkID()
+
+			port, err := strconv.ParseUint(state.Outputs["port"], 10, 0)
+			if err != nil {
+				return err
+			}
+
+			return ignoreNotFound(e.service.Networks().DeletePortForward(ctx, networkID, goslide.NetworkPortForwardDeletePayload{
+				NetworkID: networkID,
+				Proto:     spec.Proto,
+				Port:      uint(port),
+			}))
+		},
+	}
+}
+
+func (e *Executor) wgPeerStep(spec WGPeerSpec) step {
+	return step{
+		id: "network/wg-peer/" + spec.PeerName,
+		describe: func(ctx context.Context) (string, error) {
+			return fmt.Sprintf("add WireGuard peer %q", spec.PeerName), nil
+		},
+		run: func(ctx context.Context, state *StepState) error {
+			networkID := e.networkID()
+
+			peer, err := e.service.Networks().CreateWGPeer(ctx, networkID, goslide.NetworkWGPeerCreatePayload{
+				NetworkID:      networkID,
+				PeerName:       spec.PeerName,
+				RemoteNetworks: spec.RemoteNetworks,
+			})
+			if err != nil {
+				return err
+			}
+
+			state.Outputs["wg_address"] = peer.WGAddress
+			state.Outputs["wg_public_key"] = peer.WGPublicKey
+			state.Outputs["wg_private_key"] = peer.WGPrivateKey
+
+			return nil
+		},
+		undo: func(ctx context.Context, state *StepState) error {
+			return ignoreNotFound(e.service.Networks().DeleteWGPeer(ctx, e.networkID(), state.Outputs["wg_address"]))
+		},
+	}
+}
+
+func (e *Executor) restoreStep(machine MachineSpec) step {
+	id := "machine/" + machine.Name + "/restore"
+
+	return step{
+		id: id,
+		describe: func(ctx context.Context) (string, error) {
+			if virtID := e.state.output(id, "virt_id"); virtID != "" {
+				return fmt.Sprintf("restore %s as virtual machine %s", machine.Name, virtID), nil
+			}
+
+			snapshot, deviceID, err := e.resolveSnapshot(ctx, machine)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf(
+				"restore %s (agent %s) from snapshot %s taken %s on device %s with %d CPUs and %dMB memory",
+				machine.Name,
+				machine.AgentID,
+				snapshot.SnapshotID,
+				snapshot.BackupEndedAt.Format(time.RFC3339),
+				deviceID,
+				machine.CPUCount,
+				machine.MemoryInMB,
+			), nil
+		},
+		run: func(ctx context.Context, state *StepState) error {
+			snapshot, deviceID, err := e.resolveSnapshot(ctx, machine)
+			if err != nil {
+				return err
+			}
+
+			// A virtual machine created by a run whose state was not saved is
+			// taken over rather than created a second time
+			vm, ok, err := e.findVirtualMachine(ctx, id, snapshot.SnapshotID, deviceID)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				state.Outputs["virt_id"] = vm.VirtID
+				state.Outputs["snapshot_id"] = snapshot.SnapshotID
+				state.Outputs["device_id"] = deviceID
+
+				return nil
+			}
+
+			vm, err = e.service.VirtualMachineRestores().Create(ctx, goslide.VirtualMachineRestoreCreatePayload{
+				DeviceID:      deviceID,
+				SnapshotID:    snapshot.SnapshotID,
+				BootMods:      machine.BootMods,
+				CPUCount:      machine.CPUCount,
+				DiskBus:       machine.DiskBus,
+				MemoryInMB:    machine.MemoryInMB,
+				NetworkModel:  machine.NetworkModel,
+				NetworkType:   goslide.VirtualMachineNetworkType_NETWORK_ID,
+				NetworkSource: e.networkID(),
+			})
+			if err != nil {
+				return err
+			}
+
+			state.Outputs["virt_id"] = vm.VirtID
+			state.Outputs["snapshot_id"] = snapshot.SnapshotID
+			state.Outputs["device_id"] = deviceID
+
+			return nil
+		},
+		undo: func(ctx context.Context, state *StepState) error {
+			return ignoreNotFound(e.service.VirtualMachineRestores().Delete(ctx, state.Outputs["virt_id"]))
+		},
+	}
+}
+
+// findVirtualMachine returns the virtual machine on the runbook network that
+// was restored from the snapshot and is not recorded by another step.
+func (e *Executor) findVirtualMachine(ctx context.Context, stepID string, snapshotID string, deviceID string) (goslide.VirtualMachineRestore, bool, error) {
+	network, err := e.service.Networks().Get(ctx, e.networkID())
+	if err != nil {
+		return goslide.VirtualMachineRestore{}, false, err
+	}
+
+	recorded := map[string]bool{}
+	for id, stepState := range e.state.Steps {
+		if id != stepID && stepState.Outputs["virt_id"] != "" {
+			recorded[stepState.Outputs["virt_id"]] = true
+		}
+	}
+
+	for _, virtID := range network.ConnectedVirtIDs {
+		if recorded[virtID] {
+			continue
+		}
+
+		// Virtual machines deleted since the network was read are skipped
+		vm, err := e.service.VirtualMachineRestores().Get(ctx, virtID)
+		if err != nil {
+			if ignoreNotFound(err) == nil {
+				continue
+			}
+
+			return goslide.VirtualMachineRestore{}, false, err
+		}
+
+		if vm.SnapshotID == snapshotID && vm.DeviceID == deviceID {
+			return vm, true, nil
+		}
+	}
+
+	return goslide.VirtualMachineRestore{}, false, nil
+}
+
+func (e *Executor) bootStep(machine MachineSpec) step {
+	restoreID := "machine/" + machine.Name + "/restore"
+
+	return step{
+		id: "machine/" + machine.Name + "/boot",
+		describe: func(ctx context.Context) (string, error) {
+			if machine.BootDelay.Duration > 0 {
+				return fmt.Sprintf("boot %s and wait %s", machine.Name, machine.BootDelay), nil
+			}
+
+			return fmt.Sprintf("boot %s", machine.Name), nil
+		},
+		run: func(ctx context.Context, state *StepState) error {
+			if _, err := e.service.VirtualMachineRestores().Start(
+				ctx,
+				e.state.output(restoreID, "virt_id"),
+				goslide.WithVirtualMachinePollInterval(e.pollInterval),
+			); err != nil {
+				return err
+			}
+
+			return sleep(ctx, machine.BootDelay.Duration)
+		},
+	}
+}
+
+func (e *Executor) healthStep(machine MachineSpec) step {
+	return step{
+		id: "machine/" + machine.Name + "/health",
+		describe: func(ctx context.Context) (string, error) {
+			return fmt.Sprintf("run %d health checks for %s", len(machine.HealthChecks), machine.Name), nil
+		},
+		run: func(ctx context.Context, state *StepState) error {
+			for i, check := range machine.HealthChecks {
+				if err := e.runHealthCheck(ctx, check); err != nil {
+					return fmt.Errorf("health check %d (%s %s) failed - %w", i, check.Type, check.Address, err)
+				}
+
+				state.Outputs["check_"+strconv.Itoa(i)] = "passed at " + e.now().Format(time.RFC3339)
+			}
+
+			return nil
+		},
+	}
+}
+
+// resolveSnapshot applies the machine's snapshot rule, returning the snapshot
+// and the device that holds it.
+func (e *Executor) resolveSnapshot(ctx context.Context, machine MachineSpec) (goslide.Snapshot, string, error) {
+	rule := machine.Snapshot
+
+	location := rule.Location
+	if location == "" {
+		location = goslide.SnapshotLocationType_LOCAL
+	}
+
+	var selected goslide.Snapshot
+	if rule.Select == SnapshotSelection_ID {
+		snapshot, err := e.service.Snapshots().Get(ctx, rule.SnapshotID)
+		if err != nil {
+			return goslide.Snapshot{}, "", err
+		}
+
+		selected = snapshot
+	} else {
+		filter := goslide.SnapshotLocationFilter_EXISTS_LOCAL
+		if location == goslide.SnapshotLocationType_CLOUD {
+			filter = goslide.SnapshotLocationFilter_EXISTS_CLOUD
+		}
+
+		found := false
+		if err := e.service.Snapshots().ListWithQueryParameters(ctx, func(response goslide.ListResponse[goslide.Snapshot]) error {
+			for _, snapshot := range response.Data {
+				if snapshot.AgentID != machine.AgentID || snapshot.Deleted != nil {
+					continue
+				}
+
+				if rule.Select == SnapshotSelection_LATEST_VERIFIED && snapshot.VerifyBootStatus != goslide.SnapshotBootStatus_SUCCESS {
+					continue
+				}
+
+				if rule.MaxAge.Duration > 0 && e.now().Sub(snapshot.BackupEndedAt) > rule.MaxAge.Duration {
+					continue
+				}
+
+				if !found || snapshot.BackupEndedAt.After(selected.BackupEndedAt) {
+					selected = snapshot
+					found = true
+				}
+			}
+
+			return nil
+		},
+			goslide.WithAgentID(machine.AgentID),
+			goslide.WithSnapshotLocationFilter(filter),
+		); err != nil {
+			return goslide.Snapshot{}, "", err
+		}
+
+		if !found {
+			return goslide.Snapshot{}, "", fmt.Errorf("no %s snapshot of agent %s matches the snapshot rule", location, machine.AgentID)
+		}
+	}
+
+	if machine.DeviceID != "" {
+		return selected, machine.DeviceID, nil
+	}
+
+	for _, snapshotLocation := range selected.Locations {
+		if snapshotLocation.Type == location {
+			return selected, snapshotLocation.DeviceID, nil
+		}
+	}
+
+	return goslide.Snapshot{}, "", fmt.Errorf("snapshot %s has no %s location", selected.SnapshotID, location)
+}
+
+// runHealthCheck retries the check on its interval until it passes or times out.
+func (e *Executor) runHealthCheck(ctx context.Context, check HealthCheck) error {
+	timeout := check.Timeout.Duration
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+
+	interval := check.Interval.Duration
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := e.probe(ctx, check)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (e *Executor) probe(ctx context.Context, check HealthCheck) error {
+	switch check.Type {
+	case HealthCheckType_TCP:
+		conn, err := e.dialer.DialContext(ctx, "tcp", check.Address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	case HealthCheckType_HTTP:
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, check.Address, http.NoBody)
+		if err != nil {
+			return err
+		}
+
+		response, err := e.httpClient.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+
+		expected := check.ExpectStatus
+		if expected == 0 {
+			expected = http.StatusOK
+		}
+
+		if response.StatusCode != expected {
+			return fmt.Errorf("expected HTTP status %d, got %d", expected, response.StatusCode)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported health check type %q", check.Type)
+	}
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(duration):
+		return nil
+	}
+}
+
+// ignoreNotFound treats resources that are already gone as removed.
+func ignoreNotFound(err error) error {
+	slideError := &goslide.SlideError{}
+	if errors.As(err, &slideError) && slideError.HTTPStatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return err
+}
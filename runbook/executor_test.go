@@ -0,0 +1,362 @@
+package runbook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/runbook"
+)
+
+func newTestRunbook(t *testing.T) (runbook.Runbook, *fakeslide.Server) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(web.Close)
+
+	now := time.Date(2024, time.August, 23, 12, 0, 0, 0, time.UTC)
+
+	server := fakeslide.NewFleet()
+	server.Now = func() time.Time { return now }
+	server.Snapshots = []goslide.Snapshot{
+		{
+			AgentID:          "a_dc",
+			SnapshotID:       "s_dc_old",
+			BackupEndedAt:    now.Add(-48 * time.Hour),
+			Locations:        []goslide.SnapshotLocation{{DeviceID: "d_acme", Type: goslide.SnapshotLocationType_LOCAL}},
+			VerifyBootStatus: goslide.SnapshotBootStatus_SUCCESS,
+		},
+		{
+			AgentID:          "a_dc",
+			SnapshotID:       "s_dc_new",
+			BackupEndedAt:    now.Add(-time.Hour),
+			Locations:        []goslide.SnapshotLocation{{DeviceID: "d_acme", Type: goslide.SnapshotLocationType_LOCAL}},
+			VerifyBootStatus: goslide.SnapshotBootStatus_SUCCESS,
+		},
+		{
+			AgentID:          "a_app",
+			SnapshotID:       "s_app_verified",
+			BackupEndedAt:    now.Add(-2 * time.Hour),
+			Locations:        []goslide.SnapshotLocation{{DeviceID: "d_acme", Type: goslide.SnapshotLocationType_LOCAL}},
+			VerifyBootStatus: goslide.SnapshotBootStatus_SUCCESS,
+		},
+		{
+			AgentID:          "a_app",
+			SnapshotID:       "s_app_unverified",
+			BackupEndedAt:    now.Add(-time.Hour),
+			Locations:        []goslide.SnapshotLocation{{DeviceID: "d_acme", Type: goslide.SnapshotLocationType_LOCAL}},
+			VerifyBootStatus: goslide.SnapshotBootStatus_ERROR,
+		},
+	}
+
+	book := runbook.Runbook{
+		Name:     "acme-quarterly",
+		ClientID: "c_acme",
+		Network: runbook.NetworkSpec{
+			Name:         "acme-dr",
+			Type:         goslide.NetworkTypeDisaster_STANDARD,
+			RouterPrefix: "10.0.0.1/24",
+			PortForwards: []runbook.PortForwardSpec{
+				{Proto: goslide.NetworkProto_TCP, Dest: "10.0.0.10:3389"},
+			},
+			WGPeers: []runbook.WGPeerSpec{
+				{PeerName: "tech-laptop"},
+			},
+		},
+		Machines: []runbook.MachineSpec{
+			{
+				Name:      "app",
+				AgentID:   "a_app",
+				BootOrder: 2,
+				Snapshot:  runbook.SnapshotRule{Select: runbook.SnapshotSelection_LATEST_VERIFIED},
+				HealthChecks: []runbook.HealthCheck{
+					{Type: runbook.HealthCheckType_HTTP, Address: web.URL, ExpectStatus: http.StatusNoContent},
+				},
+			},
+			{
+				Name:      "dc",
+				AgentID:   "a_dc",
+				BootOrder: 1,
+				HealthChecks: []runbook.HealthCheck{
+					{Type: runbook.HealthCheckType_TCP, Address: listener.Addr().String()},
+				},
+			},
+		},
+	}
+
+	return book, server
+}
+
+func TestExecutor_PlanApplyTeardown(t *testing.T) {
+	ctx := context.Background()
+	book, server := newTestRunbook(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	executor, err := runbook.NewExecutor(server.Service(), book, statePath, runbook.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	planned, err := executor.Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedIDs := []string{
+		"network",
+		"network/port-forward/0",
+		"network/wg-peer/tech-laptop",
+		"machine/dc/restore",
+		"machine/dc/boot",
+		"machine/dc/health",
+		"machine/app/restore",
+		"machine/app/boot",
+		"machine/app/health",
+	}
+
+	if len(planned) != len(expectedIDs) {
+		t.Fatalf("%s expected %d planned steps, got %d", t.Name(), len(expectedIDs), len(planned))
+	}
+
+	for i, expectedID := range expectedIDs {
+		if planned[i].ID != expectedID || planned[i].Status != runbook.StepStatus_PENDING {
+			t.Errorf("%s planned step %d: expected pending %s, got %s %s", t.Name(), i, expectedID, planned[i].Status, planned[i].ID)
+		}
+	}
+
+	if len(server.Networks) != 0 || len(server.VirtualMachines) != 0 {
+		t.Fatalf("%s Plan must not create resources", t.Name())
+	}
+
+	if err := executor.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Networks) != 1 {
+		t.Fatalf("%s expected 1 network, got %d", t.Name(), len(server.Networks))
+	}
+
+	network := server.Networks[0]
+	if network.Name != "acme-dr" || network.ClientID != "c_acme" {
+		t.Errorf("%s unexpected network: %+v", t.Name(), network)
+	}
+
+	if len(network.PortForwards) != 1 || len(network.WGPeers) != 1 {
+		t.Errorf("%s expected 1 port forward and 1 WireGuard peer", t.Name())
+	}
+
+	if len(network.ConnectedVirtIDs) != 2 {
+		t.Errorf("%s expected 2 virtual machines on the network, got %v", t.Name(), network.ConnectedVirtIDs)
+	}
+
+	// Machines are restored in boot order, from the snapshot selected by their rule
+	if len(server.VirtualMachines) != 2 {
+		t.Fatalf("%s expected 2 virtual machines, got %d", t.Name(), len(server.VirtualMachines))
+	}
+
+	for i, expectedSnapshotID := range []string{"s_dc_new", "s_app_verified"} {
+		vm := server.VirtualMachines[i]
+		if vm.SnapshotID != expectedSnapshotID || vm.NetworkType != goslide.VirtualMachineNetworkType_NETWORK_ID {
+			t.Errorf("%s virtual machine %d: expected snapshot %s on a network, got %s %s", t.Name(), i, expectedSnapshotID, vm.SnapshotID, vm.NetworkType)
+		}
+	}
+
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := runbook.State{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expectedID := range expectedIDs {
+		if step, ok := saved.Steps[expectedID]; !ok || step.Status != runbook.StepStatus_DONE {
+			t.Errorf("%s expected step %s to be saved as done", t.Name(), expectedID)
+		}
+	}
+
+	if saved.Steps["network"].Outputs["network_id"] != network.NetworkID {
+		t.Errorf("%s expected the network ID in the state file", t.Name())
+	}
+
+	info, err := os.Stat(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("%s expected state file permissions 0600, got %o", t.Name(), info.Mode().Perm())
+	}
+
+	if err := executor.Teardown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Networks) != 0 || len(server.VirtualMachines) != 0 {
+		t.Errorf("%s expected teardown to remove all resources, %d networks and %d virtual machines remain", t.Name(), len(server.Networks), len(server.VirtualMachines))
+	}
+
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("%s expected teardown to remove the state file, got %v", t.Name(), err)
+	}
+}
+
+func TestExecutor_Resume(t *testing.T) {
+	ctx := context.Background()
+	book, server := newTestRunbook(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	server.FailNext("POST /v1/restore/virt", 1)
+
+	executor, err := runbook.NewExecutor(server.Service(), book, statePath, runbook.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := executor.Apply(ctx); err == nil {
+		t.Fatalf("%s expected the first apply to fail", t.Name())
+	}
+
+	// A new executor picks up the saved state, as it would after a restart
+	executor, err = runbook.NewExecutor(server.Service(), book, statePath, runbook.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status := executor.State().Steps["machine/dc/restore"].Status; status != runbook.StepStatus_FAILED {
+		t.Fatalf("%s expected the failed step to be recorded, got %s", t.Name(), status)
+	}
+
+	if err := executor.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := server.Count("POST /v1/network"); count != 1 {
+		t.Errorf("%s expected the network to be created once, got %d", t.Name(), count)
+	}
+
+	if count := server.Count("POST /v1/network/" + server.Networks[0].NetworkID + "/wg-peers"); count != 1 {
+		t.Errorf("%s expected the WireGuard peer to be created once, got %d", t.Name(), count)
+	}
+
+	if len(server.VirtualMachines) != 2 {
+		t.Errorf("%s expected 2 virtual machines, got %d", t.Name(), len(server.VirtualMachines))
+	}
+}
+
+func TestExecutor_ResumeAfterLostSave(t *testing.T) {
+	ctx := context.Background()
+	book, server := newTestRunbook(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	executor, err := runbook.NewExecutor(server.Service(), book, statePath, runbook.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := executor.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	virtID := executor.State().Steps["machine/dc/restore"].Outputs["virt_id"]
+
+	// The state of the dc machine is lost, as when saving it failed after the
+	// virtual machine was created
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := runbook.State{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"machine/dc/restore", "machine/dc/boot", "machine/dc/health"} {
+		delete(state.Steps, id)
+	}
+
+	data, err = json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(statePath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	executor, err = runbook.NewExecutor(server.Service(), book, statePath, runbook.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := executor.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := server.Count("POST /v1/restore/virt"); count != 2 {
+		t.Errorf("%s expected 2 virtual machines to be created, got %d", t.Name(), count)
+	}
+
+	if actual := executor.State().Steps["machine/dc/restore"].Outputs["virt_id"]; actual != virtID {
+		t.Errorf("%s expected virtual machine %s to be taken over, got %s", t.Name(), virtID, actual)
+	}
+}
+
+func TestExecutor_ReuseExistingNetwork(t *testing.T) {
+	ctx := context.Background()
+	book, server := newTestRunbook(t)
+
+	server.Networks = []goslide.Network{
+		{NetworkID: "net_existing", Name: "acme-dr", ClientID: "c_acme", ConnectedVirtIDs: []string{}},
+	}
+
+	executor, err := runbook.NewExecutor(server.Service(), book, "", runbook.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := executor.Apply(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := executor.Teardown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Networks) != 1 || server.Networks[0].NetworkID != "net_existing" {
+		t.Errorf("%s expected the existing network to be kept, got %+v", t.Name(), server.Networks)
+	}
+
+	if server.Count("POST /v1/network") != 0 {
+		t.Errorf("%s expected no network to be created", t.Name())
+	}
+}
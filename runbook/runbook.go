@@ -0,0 +1,243 @@
+package runbook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"gopkg.in/yaml.v3"
+)
+
+// Runbook is a declarative description of a disaster recovery test: the
+// network the machines are restored onto, and the machines themselves.
+type Runbook struct {
+	Name     string        `json:"name"`
+	ClientID string        `json:"client_id"`
+	Network  NetworkSpec   `json:"network"`
+	Machines []MachineSpec `json:"machines"`
+}
+
+type NetworkSpec struct {
+	Name           string                      `json:"name"`
+	Type           goslide.NetworkTypeDisaster `json:"type"`
+	BridgeDeviceID string                      `json:"bridge_device_id"`
+	Comments       string                      `json:"comments"`
+	DHCP           bool                        `json:"dhcp"`
+	DHCPRangeStart string                      `json:"dhcp_range_start"`
+	DHCPRangeEnd   string                      `json:"dhcp_range_end"`
+	Internet       bool                        `json:"internet"`
+	Nameservers    string                      `json:"nameservers"`
+	RouterPrefix   string                      `json:"router_prefix"`
+	PortForwards   []PortForwardSpec           `json:"port_forwards"`
+	WGPeers        []WGPeerSpec                `json:"wg_peers"`
+}
+
+type PortForwardSpec struct {
+	Proto goslide.NetworkProto `json:"proto"`
+	Dest  string               `json:"dest"`
+}
+
+type WGPeerSpec struct {
+	PeerName       string   `json:"peer_name"`
+	RemoteNetworks []string `json:"remote_networks"`
+}
+
+type MachineSpec struct {
+	Name     string       `json:"name"`
+	AgentID  string       `json:"agent_id"`
+	DeviceID string       `json:"device_id"`
+	Snapshot SnapshotRule `json:"snapshot"`
+
+	CPUCount     uint                               `json:"cpu_count"`
+	MemoryInMB   uint                               `json:"memory_in_mb"`
+	DiskBus      goslide.DiskBus                    `json:"disk_bus"`
+	NetworkModel goslide.VirtualMachineNetworkModel `json:"network_model"`
+	BootMods     []goslide.BootMod                  `json:"boot_mods"`
+
+	// Machines boot in ascending BootOrder, each waiting for the previous
+	// machine's health checks to pass
+	BootOrder    int           `json:"boot_order"`
+	BootDelay    Duration      `json:"boot_delay"`
+	HealthChecks []HealthCheck `json:"health_checks"`
+}
+
+type SnapshotSelection string
+
+const (
+	SnapshotSelection_LATEST          SnapshotSelection = "latest"
+	SnapshotSelection_LATEST_VERIFIED SnapshotSelection = "latest-verified"
+	SnapshotSelection_ID              SnapshotSelection = "id"
+)
+
+type SnapshotRule struct {
+	Select     SnapshotSelection            `json:"select"`
+	SnapshotID string                       `json:"snapshot_id"`
+	Location   goslide.SnapshotLocationType `json:"location"`
+	MaxAge     Duration                     `json:"max_age"`
+}
+
+type HealthCheckType string
+
+const (
+	HealthCheckType_TCP  HealthCheckType = "tcp"
+	HealthCheckType_HTTP HealthCheckType = "http"
+)
+
+type HealthCheck struct {
+	Type HealthCheckType `json:"type"`
+
+	// Address is host:port for TCP checks, and a URL for HTTP checks
+	Address      string   `json:"address"`
+	ExpectStatus int      `json:"expect_status"`
+	Timeout      Duration `json:"timeout"`
+	Interval     Duration `json:"interval"`
+}
+
+// Duration is a time.Duration written as a string such as "90s" or "5m".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5m\" - %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = parsed
+
+	return nil
+}
+
+// Load reads a runbook from a YAML (.yaml, .yml) or JSON file.
+func Load(path string) (Runbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Runbook{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	default:
+		return ParseJSON(data)
+	}
+}
+
+func ParseJSON(data []byte) (Runbook, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	runbook := Runbook{}
+	if err := decoder.Decode(&runbook); err != nil {
+		return Runbook{}, fmt.Errorf("runbook: %w", err)
+	}
+
+	if err := runbook.Validate(); err != nil {
+		return Runbook{}, err
+	}
+
+	return runbook, nil
+}
+
+// ParseYAML converts the YAML document to JSON, so both formats share the
+// field names and validation of the JSON struct tags.
+func ParseYAML(data []byte) (Runbook, error) {
+	document := map[string]any{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return Runbook{}, fmt.Errorf("runbook: %w", err)
+	}
+
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return Runbook{}, fmt.Errorf("runbook: %w", err)
+	}
+
+	return ParseJSON(jsonData)
+}
+
+func (r Runbook) Validate() error {
+	errs := []error{}
+
+	if r.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	if r.Network.Name == "" {
+		errs = append(errs, errors.New("network.name is required"))
+	}
+
+	if len(r.Machines) == 0 {
+		errs = append(errs, errors.New("at least one machine is required"))
+	}
+
+	// Peers are tracked in the state by name
+	peerNames := map[string]bool{}
+	for i, peer := range r.Network.WGPeers {
+		if peer.PeerName == "" {
+			errs = append(errs, fmt.Errorf("network.wg_peers[%d].peer_name is required", i))
+		}
+
+		if peerNames[peer.PeerName] {
+			errs = append(errs, fmt.Errorf("network.wg_peers[%d].peer_name %q is not unique", i, peer.PeerName))
+		}
+		peerNames[peer.PeerName] = true
+	}
+
+	names := map[string]bool{}
+	for i, machine := range r.Machines {
+		if machine.Name == "" {
+			errs = append(errs, fmt.Errorf("machines[%d].name is required", i))
+		}
+
+		if names[machine.Name] {
+			errs = append(errs, fmt.Errorf("machines[%d].name %q is not unique", i, machine.Name))
+		}
+		names[machine.Name] = true
+
+		if machine.AgentID == "" {
+			errs = append(errs, fmt.Errorf("machines[%d].agent_id is required", i))
+		}
+
+		switch machine.Snapshot.Select {
+		case "", SnapshotSelection_LATEST, SnapshotSelection_LATEST_VERIFIED:
+		case SnapshotSelection_ID:
+			if machine.Snapshot.SnapshotID == "" {
+				errs = append(errs, fmt.Errorf("machines[%d].snapshot.snapshot_id is required when selecting by id", i))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("machines[%d].snapshot.select %q is not supported", i, machine.Snapshot.Select))
+		}
+
+		for j, check := range machine.HealthChecks {
+			if check.Type != HealthCheckType_TCP && check.Type != HealthCheckType_HTTP {
+				errs = append(errs, fmt.Errorf("machines[%d].health_checks[%d].type %q is not supported", i, j, check.Type))
+			}
+
+			if check.Address == "" {
+				errs = append(errs, fmt.Errorf("machines[%d].health_checks[%d].address is required", i, j))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("runbook: invalid runbook - %w", errors.Join(errs...))
+	}
+
+	return nil
+}
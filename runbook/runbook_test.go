@@ -0,0 +1,155 @@
+package runbook_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/runbook"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testRunbookYAML = `
+name: acme-quarterly
+client_id: c_0123456789ab
+network:
+  name: acme-dr
+  type: standard
+  router_prefix: 10.0.0.1/24
+  dhcp: true
+  dhcp_range_start: 10.0.0.100
+  dhcp_range_end: 10.0.0.200
+  nameservers: 1.1.1.1
+  port_forwards:
+    - proto: tcp
+      dest: 10.0.0.10:3389
+  wg_peers:
+    - peer_name: tech-laptop
+      remote_networks: [192.168.50.0/24]
+machines:
+  - name: app
+    agent_id: a_app
+    boot_order: 2
+    cpu_count: 2
+    memory_in_mb: 4096
+    disk_bus: virtio
+    network_model: e1000
+    snapshot:
+      select: latest-verified
+      max_age: 72h
+  - name: dc
+    agent_id: a_dc
+    boot_order: 1
+    boot_delay: 30s
+    health_checks:
+      - type: tcp
+        address: 10.0.0.10:389
+        timeout: 10m
+`
+
+func TestLoad_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runbook.yaml")
+	if err := os.WriteFile(path, []byte(testRunbookYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := runbook.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := runbook.Runbook{
+		Name:     "acme-quarterly",
+		ClientID: "c_0123456789ab",
+		Network: runbook.NetworkSpec{
+			Name:           "acme-dr",
+			Type:           goslide.NetworkTypeDisaster_STANDARD,
+			DHCP:           true,
+			DHCPRangeStart: "10.0.0.100",
+			DHCPRangeEnd:   "10.0.0.200",
+			Nameservers:    "1.1.1.1",
+			RouterPrefix:   "10.0.0.1/24",
+			PortForwards: []runbook.PortForwardSpec{
+				{Proto: goslide.NetworkProto_TCP, Dest: "10.0.0.10:3389"},
+			},
+			WGPeers: []runbook.WGPeerSpec{
+				{PeerName: "tech-laptop", RemoteNetworks: []string{"192.168.50.0/24"}},
+			},
+		},
+		Machines: []runbook.MachineSpec{
+			{
+				Name:         "app",
+				AgentID:      "a_app",
+				BootOrder:    2,
+				CPUCount:     2,
+				MemoryInMB:   4096,
+				DiskBus:      goslide.DiskBus_VIRTIO,
+				NetworkModel: goslide.VirtualMachineNetworkModel_E1000,
+				Snapshot: runbook.SnapshotRule{
+					Select: runbook.SnapshotSelection_LATEST_VERIFIED,
+					MaxAge: runbook.Duration{Duration: 72 * time.Hour},
+				},
+			},
+			{
+				Name:      "dc",
+				AgentID:   "a_dc",
+				BootOrder: 1,
+				BootDelay: runbook.Duration{Duration: 30 * time.Second},
+				HealthChecks: []runbook.HealthCheck{
+					{
+						Type:    runbook.HealthCheckType_TCP,
+						Address: "10.0.0.10:389",
+						Timeout: runbook.Duration{Duration: 10 * time.Minute},
+					},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Parsed runbook mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestParseJSON_UnknownField(t *testing.T) {
+	_, err := runbook.ParseJSON([]byte(`{"name": "x", "netwrk": {}}`))
+	if err == nil || !strings.Contains(err.Error(), "netwrk") {
+		t.Fatalf("%s expected an unknown field error, got %v", t.Name(), err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := runbook.Runbook{
+		Network: runbook.NetworkSpec{
+			WGPeers: []runbook.WGPeerSpec{{PeerName: "admin"}, {PeerName: "admin"}, {}},
+		},
+		Machines: []runbook.MachineSpec{
+			{Name: "app", Snapshot: runbook.SnapshotRule{Select: runbook.SnapshotSelection_ID}},
+			{Name: "app", AgentID: "a_app", HealthChecks: []runbook.HealthCheck{{Type: "ping"}}},
+		},
+	}
+
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatalf("%s expected a validation error", t.Name())
+	}
+
+	for _, expected := range []string{
+		"name is required",
+		"network.name is required",
+		"machines[0].agent_id is required",
+		"machines[0].snapshot.snapshot_id is required",
+		`machines[1].name "app" is not unique`,
+		`machines[1].health_checks[0].type "ping" is not supported`,
+		"machines[1].health_checks[0].address is required",
+		`network.wg_peers[1].peer_name "admin" is not unique`,
+		"network.wg_peers[2].peer_name is required",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s expected error to contain %q, got: %s", t.Name(), expected, err)
+		}
+	}
+}
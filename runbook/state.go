@@ -0,0 +1,109 @@
+package runbook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/equalsgibson/goslide/internal/jsonfile"
+)
+
+type StepStatus string
+
+const (
+	StepStatus_PENDING StepStatus = "pending"
+	StepStatus_DONE    StepStatus = "done"
+	StepStatus_FAILED  StepStatus = "failed"
+)
+
+type StepState struct {
+	Status    StepStatus        `json:"status"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// State records the outcome of every step, so an interrupted apply or
+// teardown can be resumed. It may contain WireGuard private keys and is
+// written with owner-only permissions.
+type State struct {
+	Runbook string                `json:"runbook"`
+	Steps   map[string]*StepState `json:"steps"`
+
+	path string
+}
+
+// LoadState reads the state file, returning an empty state if it does not exist yet.
+func LoadState(path string, runbookName string) (*State, error) {
+	state := &State{
+		Runbook: runbookName,
+		Steps:   map[string]*StepState{},
+		path:    path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+
+	if state.Runbook != runbookName {
+		return nil, errors.New("runbook: state file belongs to runbook " + state.Runbook)
+	}
+
+	if state.Steps == nil {
+		state.Steps = map[string]*StepState{}
+	}
+
+	return state, nil
+}
+
+func (s *State) step(id string) *StepState {
+	step, ok := s.Steps[id]
+	if !ok {
+		step = &StepState{
+			Status:  StepStatus_PENDING,
+			Outputs: map[string]string{},
+		}
+		s.Steps[id] = step
+	}
+
+	if step.Outputs == nil {
+		step.Outputs = map[string]string{}
+	}
+
+	return step
+}
+
+func (s *State) done(id string) bool {
+	step, ok := s.Steps[id]
+
+	return ok && step.Status == StepStatus_DONE
+}
+
+func (s *State) output(id, key string) string {
+	step, ok := s.Steps[id]
+	if !ok {
+		return ""
+	}
+
+	return step.Outputs[key]
+}
+
+// save writes the state file, nothing without a path.
+func (s *State) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	return jsonfile.Write(s.path, s)
+}
@@ -0,0 +1,127 @@
+package s3sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// resumableReader streams a HTTP resource, re-requesting the remaining bytes
+// with a Range header when the connection drops part way through.
+type resumableReader struct {
+	ctx        context.Context
+	client     *http.Client
+	uri        string
+	maxRetries int
+	backoff    func(attempt int) time.Duration
+
+	body    io.ReadCloser
+	offset  int64
+	retries int
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			retry, err := r.open()
+			if err != nil {
+				if !retry || r.retries >= r.maxRetries {
+					return 0, err
+				}
+
+				r.retries++
+
+				continue
+			}
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+
+		r.body.Close()
+		r.body = nil
+
+		if r.ctx.Err() != nil {
+			return n, r.ctx.Err()
+		}
+
+		if r.retries >= r.maxRetries {
+			return n, fmt.Errorf("s3sink: download interrupted at byte %d - %w", r.offset, err)
+		}
+
+		r.retries++
+
+		// Hand back what was read, the next call resumes from the new offset
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// open requests the bytes from the offset on, after a backoff when retrying.
+// It reports whether a failure is transient and worth another attempt.
+func (r *resumableReader) open() (bool, error) {
+	if r.retries > 0 {
+		select {
+		case <-r.ctx.Done():
+			return false, r.ctx.Err()
+		case <-time.After(r.backoff(r.retries)):
+		}
+	}
+
+	request, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.uri, http.NoBody)
+	if err != nil {
+		return false, err
+	}
+
+	if r.offset > 0 {
+		request.Header.Set("Range", "bytes="+strconv.FormatInt(r.offset, 10)+"-")
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		if r.ctx.Err() != nil {
+			return false, r.ctx.Err()
+		}
+
+		return true, fmt.Errorf("s3sink: download failed at byte %d - %w", r.offset, err)
+	}
+
+	switch {
+	case r.offset == 0 && response.StatusCode == http.StatusOK:
+	case r.offset > 0 && response.StatusCode == http.StatusPartialContent:
+	case r.offset > 0 && response.StatusCode == http.StatusOK:
+		response.Body.Close()
+
+		return false, fmt.Errorf("s3sink: download server does not support resuming from byte %d", r.offset)
+	default:
+		response.Body.Close()
+
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+
+		return retry, fmt.Errorf("s3sink: download failed at byte %d - HTTP status code %d", r.offset, response.StatusCode)
+	}
+
+	r.body = response.Body
+
+	return false, nil
+}
+
+func (r *resumableReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+
+	err := r.body.Close()
+	r.body = nil
+
+	return err
+}
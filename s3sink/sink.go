@@ -0,0 +1,614 @@
+package s3sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	DefaultPartSize   int64 = 16 * 1024 * 1024
+	DefaultMaxRetries       = 3
+
+	// MinPartSize is the smallest part S3 accepts for every part but the last.
+	MinPartSize int64 = 5 * 1024 * 1024
+)
+
+// Config describes the S3-compatible bucket that image exports are written to.
+// Requests always use path-style addressing (endpoint/bucket/key), which is
+// supported by AWS S3, MinIO and most other S3-compatible stores.
+type Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// KeyPrefix is prepended to every object key, e.g. "slide/exports/"
+	KeyPrefix string
+
+	// PartSize is the size of each multipart upload part, and the only buffer
+	// held in memory for an upload. Defaults to DefaultPartSize, and can not
+	// be less than MinPartSize.
+	PartSize int64
+
+	// MaxRetries is the number of times a failed part upload, or an interrupted
+	// download, is retried before the upload is aborted. Defaults to DefaultMaxRetries.
+	MaxRetries int
+
+	// HTTPClient is used for requests to the object store. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// DownloadClient is used to fetch the image export download URIs. Defaults to http.DefaultClient.
+	DownloadClient *http.Client
+}
+
+type Sink struct {
+	endpoint       *url.URL
+	bucket         string
+	keyPrefix      string
+	partSize       int64
+	maxRetries     int
+	httpClient     *http.Client
+	downloadClient *http.Client
+	signer         signer
+	now            func() time.Time
+	backoff        func(attempt int) time.Duration
+}
+
+// Object is the result of a completed multipart upload.
+type Object struct {
+	Bucket string
+	Key    string
+	ETag   string
+	Size   int64
+	Parts  int
+}
+
+// Tags are attached to each uploaded object both as S3 object tags and as
+// x-amz-meta-* user metadata.
+type Tags struct {
+	AgentID       string
+	DeviceID      string
+	SnapshotID    string
+	ImageExportID string
+	ImageType     goslide.ImageExportType
+	DiskID        string
+}
+
+type S3Error struct {
+	HTTPStatusCode    int
+	HTTPRequestMethod string
+	Code              string `xml:"Code"`
+	Message           string `xml:"Message"`
+	RequestID         string `xml:"RequestId"`
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3 request error: %s returned HTTP %d", e.HTTPRequestMethod, e.HTTPStatusCode)
+	}
+
+	return fmt.Sprintf("s3 request error: %s returned HTTP %d %s - %s", e.HTTPRequestMethod, e.HTTPStatusCode, e.Code, e.Message)
+}
+
+func New(config Config) (*Sink, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("s3sink: endpoint is required")
+	}
+
+	if config.Bucket == "" {
+		return nil, errors.New("s3sink: bucket is required")
+	}
+
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3sink: invalid endpoint - %w", err)
+	}
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("s3sink: endpoint scheme must be http or https, got %q", endpoint.Scheme)
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	if config.PartSize <= 0 {
+		config.PartSize = DefaultPartSize
+	}
+
+	if config.PartSize < MinPartSize {
+		return nil, fmt.Errorf("s3sink: part size must be at least %d bytes, got %d", MinPartSize, config.PartSize)
+	}
+
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+
+	if config.DownloadClient == nil {
+		config.DownloadClient = http.DefaultClient
+	}
+
+	return &Sink{
+		endpoint:       endpoint,
+		bucket:         config.Bucket,
+		keyPrefix:      config.KeyPrefix,
+		partSize:       config.PartSize,
+		maxRetries:     config.MaxRetries,
+		httpClient:     config.HTTPClient,
+		downloadClient: config.DownloadClient,
+		signer: signer{
+			accessKeyID:     config.AccessKeyID,
+			secretAccessKey: config.SecretAccessKey,
+			region:          config.Region,
+			service:         "s3",
+		},
+		now: time.Now,
+		backoff: func(attempt int) time.Duration {
+			return time.Duration(attempt*attempt) * 500 * time.Millisecond
+		},
+	}, nil
+}
+
+// ObjectKey returns the key an image export disk is stored under:
+// <prefix><agent_id>/<snapshot_id>/<disk name>
+func (s *Sink) ObjectKey(restore goslide.ImageExportRestore, disk goslide.ImageExportRestoreData) string {
+	name := disk.Name
+	if name == "" {
+		name = disk.DiskID + "." + string(restore.ImageType)
+	}
+
+	return s.keyPrefix + restore.AgentID + "/" + restore.SnapshotID + "/" + name
+}
+
+// ExportAll streams every disk of the image export restore into the bucket.
+// The preferred download type is used when a disk offers more than one URI.
+func (s *Sink) ExportAll(
+	ctx context.Context,
+	service goslide.ImageExportRestoreService,
+	imageExportID string,
+	preferred goslide.ImageExportDownloadType,
+) ([]Object, error) {
+	restore, err := service.Get(ctx, imageExportID)
+	if err != nil {
+		return nil, err
+	}
+
+	disks := []goslide.ImageExportRestoreData{}
+	if err := service.Browse(ctx, imageExportID, func(response goslide.ListResponse[goslide.ImageExportRestoreData]) error {
+		disks = append(disks, response.Data...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(disks))
+	for _, disk := range disks {
+		downloadURI, ok := SelectDownloadURI(disk.DownloadURIs, preferred)
+		if !ok {
+			return objects, fmt.Errorf("s3sink: disk %s has no download URI", disk.DiskID)
+		}
+
+		object, err := s.StreamImageExport(ctx, restore, disk, downloadURI)
+		if err != nil {
+			return objects, err
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// SelectDownloadURI returns the URI of the preferred type, falling back to the
+// first URI available.
+func SelectDownloadURI(
+	downloadURIs []goslide.ImageExportRestoreDownloadURI,
+	preferred goslide.ImageExportDownloadType,
+) (goslide.ImageExportRestoreDownloadURI, bool) {
+	for _, downloadURI := range downloadURIs {
+		if downloadURI.Type == preferred {
+			return downloadURI, true
+		}
+	}
+
+	if len(downloadURIs) > 0 {
+		return downloadURIs[0], true
+	}
+
+	return goslide.ImageExportRestoreDownloadURI{}, false
+}
+
+// StreamImageExport downloads a single image export disk and writes it to the
+// bucket without buffering more than one part in memory. Interrupted downloads
+// are resumed with a HTTP Range request.
+func (s *Sink) StreamImageExport(
+	ctx context.Context,
+	restore goslide.ImageExportRestore,
+	disk goslide.ImageExportRestoreData,
+	downloadURI goslide.ImageExportRestoreDownloadURI,
+) (Object, error) {
+	source := &resumableReader{
+		ctx:        ctx,
+		client:     s.downloadClient,
+		uri:        downloadURI.URI,
+		maxRetries: s.maxRetries,
+		backoff:    s.backoff,
+	}
+	defer source.Close()
+
+	return s.Upload(ctx, s.ObjectKey(restore, disk), source, Tags{
+		AgentID:       restore.AgentID,
+		DeviceID:      restore.DeviceID,
+		SnapshotID:    restore.SnapshotID,
+		ImageExportID: restore.ImageExportID,
+		ImageType:     restore.ImageType,
+		DiskID:        disk.DiskID,
+	})
+}
+
+// Upload writes the contents of body to key using a multipart upload. Failed
+// parts are retried from the in-memory part buffer; if a part cannot be
+// uploaded the multipart upload is aborted so no partial object remains.
+func (s *Sink) Upload(ctx context.Context, key string, body io.Reader, tags Tags) (Object, error) {
+	uploadID, err := s.createMultipartUpload(ctx, key, tags)
+	if err != nil {
+		return Object{}, err
+	}
+
+	object, err := s.uploadParts(ctx, key, uploadID, body)
+	if err != nil {
+		// Use a fresh context, the upload context may be the reason we failed
+		abortCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if abortErr := s.abortMultipartUpload(abortCtx, key, uploadID); abortErr != nil {
+			return Object{}, errors.Join(err, abortErr)
+		}
+
+		return Object{}, err
+	}
+
+	return object, nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (s *Sink) uploadParts(ctx context.Context, key, uploadID string, body io.Reader) (Object, error) {
+	buffer := make([]byte, s.partSize)
+	parts := []completedPart{}
+
+	var size int64
+	for partNumber := 1; ; partNumber++ {
+		n, readErr := io.ReadFull(body, buffer)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return Object{}, readErr
+		}
+
+		// S3 requires at least one part, even for an empty object
+		if n == 0 && len(parts) > 0 {
+			break
+		}
+
+		etag, err := s.uploadPartWithRetry(ctx, key, uploadID, partNumber, buffer[:n])
+		if err != nil {
+			return Object{}, err
+		}
+
+		parts = append(parts, completedPart{
+			PartNumber: partNumber,
+			ETag:       etag,
+		})
+		size += int64(n)
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	etag, err := s.completeMultipartUpload(ctx, key, uploadID, parts)
+	if err != nil {
+		return Object{}, err
+	}
+
+	return Object{
+		Bucket: s.bucket,
+		Key:    key,
+		ETag:   etag,
+		Size:   size,
+		Parts:  len(parts),
+	}, nil
+}
+
+func (s *Sink) uploadPartWithRetry(ctx context.Context, key, uploadID string, partNumber int, part []byte) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(s.backoff(attempt)):
+			}
+		}
+
+		etag, err := s.uploadPart(ctx, key, uploadID, partNumber, part)
+		if err == nil {
+			return etag, nil
+		}
+
+		if !isRetryable(err) {
+			return "", err
+		}
+
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("s3sink: part %d failed after %d attempts - %w", partNumber, s.maxRetries+1, lastErr)
+}
+
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	s3Error := &S3Error{}
+	if errors.As(err, &s3Error) {
+		return s3Error.HTTPStatusCode >= http.StatusInternalServerError ||
+			s3Error.HTTPStatusCode == http.StatusTooManyRequests ||
+			s3Error.Code == "RequestTimeout"
+	}
+
+	// Transport level errors (connection reset, timeouts) are worth retrying
+	return true
+}
+
+func (s *Sink) createMultipartUpload(ctx context.Context, key string, tags Tags) (string, error) {
+	query := url.Values{}
+	query.Set("uploads", "")
+
+	request, err := s.newRequest(ctx, http.MethodPost, key, query, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("X-Amz-Tagging", tags.encode())
+	for name, value := range tags.metadata() {
+		request.Header.Set("X-Amz-Meta-"+name, value)
+	}
+
+	target := struct {
+		UploadID string `xml:"UploadId"`
+	}{}
+	if err := s.do(request, nil, &target); err != nil {
+		return "", err
+	}
+
+	if target.UploadID == "" {
+		return "", errors.New("s3sink: create multipart upload returned no upload ID")
+	}
+
+	return target.UploadID, nil
+}
+
+func (s *Sink) uploadPart(ctx context.Context, key, uploadID string, partNumber int, part []byte) (string, error) {
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	query.Set("uploadId", uploadID)
+
+	request, err := s.newRequest(ctx, http.MethodPut, key, query, part)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := s.send(request, part)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	etag := response.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("s3sink: part %d upload returned no ETag", partNumber)
+	}
+
+	return etag, nil
+}
+
+func (s *Sink) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []completedPart) (string, error) {
+	payload := struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{
+		Parts: parts,
+	}
+
+	payloadBytes, err := xml.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+
+	request, err := s.newRequest(ctx, http.MethodPost, key, query, payloadBytes)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/xml")
+
+	target := struct {
+		ETag string `xml:"ETag"`
+	}{}
+	if err := s.do(request, payloadBytes, &target); err != nil {
+		return "", err
+	}
+
+	return target.ETag, nil
+}
+
+func (s *Sink) abortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	query := url.Values{}
+	query.Set("uploadId", uploadID)
+
+	request, err := s.newRequest(ctx, http.MethodDelete, key, query, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.do(request, nil, nil)
+}
+
+func (s *Sink) newRequest(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	objectPath := strings.TrimSuffix(s.endpoint.Path, "/") + "/" + s.bucket + "/" + key
+
+	target := *s.endpoint
+	target.Path = objectPath
+	target.RawPath = uriEncode(objectPath, false)
+	target.RawQuery = query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		request.ContentLength = int64(len(body))
+	}
+
+	return request, nil
+}
+
+// send signs the request and performs it, returning an *S3Error for any error response.
+func (s *Sink) send(request *http.Request, body []byte) (*http.Response, error) {
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		payloadHash = sha256Hex(body)
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		request.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	s.signer.sign(request, payloadHash, s.now())
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		defer response.Body.Close()
+
+		return nil, newS3Error(request, response)
+	}
+
+	return response, nil
+}
+
+func (s *Sink) do(request *http.Request, body []byte, target any) error {
+	response, err := s.send(request, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	// CompleteMultipartUpload can fail after the 200 status has been sent
+	if bytes.Contains(bodyBytes, []byte("<Error>")) {
+		s3Error := &S3Error{
+			HTTPStatusCode:    response.StatusCode,
+			HTTPRequestMethod: request.Method,
+		}
+		if err := xml.Unmarshal(bodyBytes, s3Error); err != nil {
+			return err
+		}
+
+		return s3Error
+	}
+
+	if target != nil && len(bodyBytes) > 0 {
+		if err := xml.Unmarshal(bodyBytes, target); err != nil {
+			return fmt.Errorf("s3sink: unable to decode response - %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newS3Error(request *http.Request, response *http.Response) error {
+	s3Error := &S3Error{
+		HTTPStatusCode:    response.StatusCode,
+		HTTPRequestMethod: request.Method,
+	}
+
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	// Error bodies are optional (e.g. HEAD requests), ignore decoding failures
+	_ = xml.Unmarshal(bodyBytes, s3Error)
+
+	return s3Error
+}
+
+func (t Tags) pairs() [][2]string {
+	pairs := [][2]string{}
+	for _, pair := range [][2]string{
+		{"agent-id", t.AgentID},
+		{"device-id", t.DeviceID},
+		{"snapshot-id", t.SnapshotID},
+		{"image-export-id", t.ImageExportID},
+		{"image-type", string(t.ImageType)},
+		{"disk-id", t.DiskID},
+	} {
+		if pair[1] != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+func (t Tags) encode() string {
+	values := url.Values{}
+	for _, pair := range t.pairs() {
+		values.Set(pair[0], pair[1])
+	}
+
+	return values.Encode()
+}
+
+func (t Tags) metadata() map[string]string {
+	metadata := map[string]string{}
+	for _, pair := range t.pairs() {
+		metadata["Goslide-"+pair[0]] = pair[1]
+	}
+
+	return metadata
+}
@@ -0,0 +1,492 @@
+package s3sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeObjectStore is a minimal MinIO-style stand-in, implementing just enough
+// of the S3 multipart upload API to exercise the sink.
+type fakeObjectStore struct {
+	mu sync.Mutex
+
+	// failParts holds the number of times an upload of a given part number should fail
+	failParts map[int]int
+
+	uploads   map[string]*fakeUpload
+	objects   map[string][]byte
+	tags      map[string]url.Values
+	metadata  map[string]http.Header
+	aborted   []string
+	partSizes []int
+	nextID    int
+}
+
+type fakeUpload struct {
+	key   string
+	parts map[int][]byte
+	tags  url.Values
+	meta  http.Header
+}
+
+func newFakeObjectStore() *fakeObjectStore {
+	return &fakeObjectStore{
+		failParts: map[int]int{},
+		uploads:   map[string]*fakeUpload{},
+		objects:   map[string][]byte{},
+		tags:      map[string]url.Values{},
+		metadata:  map[string]http.Header{},
+	}
+}
+
+func (f *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+		f.writeError(w, http.StatusForbidden, "AccessDenied")
+
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.writeError(w, http.StatusBadRequest, "IncompleteBody")
+
+		return
+	}
+
+	if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		f.writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/archive/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		uploadID := "upload-" + strconv.Itoa(f.nextID)
+
+		tags, err := url.ParseQuery(r.Header.Get("X-Amz-Tagging"))
+		if err != nil {
+			f.writeError(w, http.StatusBadRequest, "InvalidTag")
+
+			return
+		}
+
+		meta := http.Header{}
+		for name, values := range r.Header {
+			if strings.HasPrefix(name, "X-Amz-Meta-") {
+				meta[name] = values
+			}
+		}
+
+		f.uploads[uploadID] = &fakeUpload{
+			key:   key,
+			parts: map[int][]byte{},
+			tags:  tags,
+			meta:  meta,
+		}
+
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>archive</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key, uploadID)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			f.writeError(w, http.StatusNotFound, "NoSuchUpload")
+
+			return
+		}
+
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		if f.failParts[partNumber] > 0 {
+			f.failParts[partNumber]--
+			f.writeError(w, http.StatusServiceUnavailable, "SlowDown")
+
+			return
+		}
+
+		upload.parts[partNumber] = body
+		f.partSizes = append(f.partSizes, len(body))
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, partNumber))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			f.writeError(w, http.StatusNotFound, "NoSuchUpload")
+
+			return
+		}
+
+		complete := struct {
+			Parts []completedPart `xml:"Part"`
+		}{}
+		if err := xml.Unmarshal(body, &complete); err != nil {
+			f.writeError(w, http.StatusBadRequest, "MalformedXML")
+
+			return
+		}
+
+		partNumbers := []int{}
+		for _, part := range complete.Parts {
+			if part.ETag != fmt.Sprintf(`"etag-%d"`, part.PartNumber) {
+				f.writeError(w, http.StatusBadRequest, "InvalidPart")
+
+				return
+			}
+			partNumbers = append(partNumbers, part.PartNumber)
+		}
+		sort.Ints(partNumbers)
+
+		object := []byte{}
+		for _, partNumber := range partNumbers {
+			object = append(object, upload.parts[partNumber]...)
+		}
+
+		f.objects[upload.key] = object
+		f.tags[upload.key] = upload.tags
+		f.metadata[upload.key] = upload.meta
+		delete(f.uploads, query.Get("uploadId"))
+
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Key>%s</Key><ETag>"complete-%d"</ETag></CompleteMultipartUploadResult>`, upload.key, len(partNumbers))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		f.aborted = append(f.aborted, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeObjectStore) writeError(w http.ResponseWriter, statusCode int, code string) {
+	w.WriteHeader(statusCode)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>fake error</Message><RequestId>fake</RequestId></Error>", code)
+}
+
+// flakyDownload serves content, dropping the connection part way through the
+// first response to force the reader to resume with a Range request.
+type flakyDownload struct {
+	content   []byte
+	dropAfter int
+	ranges    []string
+
+	// failResumes is the number of Range requests answered with a 503
+	failResumes int
+}
+
+func (d *flakyDownload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.ranges = append(d.ranges, r.Header.Get("Range"))
+
+	if r.Header.Get("Range") != "" && d.failResumes > 0 {
+		d.failResumes--
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		return
+	}
+
+	if r.Header.Get("Range") != "" {
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err != nil {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(d.content)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(d.content[start:])
+
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(d.content)))
+	w.WriteHeader(http.StatusOK)
+
+	if d.dropAfter <= 0 {
+		w.Write(d.content)
+
+		return
+	}
+
+	w.Write(d.content[:d.dropAfter])
+	w.(http.Flusher).Flush()
+
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	conn.Close()
+}
+
+func newTestSink(t *testing.T, endpoint string, partSize int64) *Sink {
+	t.Helper()
+
+	sink, err := New(Config{
+		Endpoint:        endpoint,
+		Bucket:          "archive",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+		KeyPrefix:       "slide/",
+		MaxRetries:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parts below MinPartSize keep the test content small
+	sink.partSize = partSize
+	sink.backoff = func(int) time.Duration {
+		return 0
+	}
+
+	return sink
+}
+
+func testContent(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	return content
+}
+
+func TestSink_StreamImageExport(t *testing.T) {
+	store := newFakeObjectStore()
+	store.failParts[2] = 1
+
+	storeServer := httptest.NewServer(store)
+	defer storeServer.Close()
+
+	content := testContent(2500)
+	download := &flakyDownload{
+		content:   content,
+		dropAfter: 1300,
+	}
+
+	downloadServer := httptest.NewServer(download)
+	defer downloadServer.Close()
+
+	sink := newTestSink(t, storeServer.URL, 1000)
+
+	restore := goslide.ImageExportRestore{
+		AgentID:       "a_0123456789ab",
+		DeviceID:      "d_0123456789ab",
+		ImageExportID: "ie_0123456789ab",
+		ImageType:     goslide.ImageExportType_VHDX,
+		SnapshotID:    "s_0123456789ab",
+	}
+
+	disk := goslide.ImageExportRestoreData{
+		DiskID: "disk_0123456789ab",
+		Name:   "MyWindowsAgent (2024-08-05 20-44-25 EDT, C).vhdx",
+		Size:   uint(len(content)),
+	}
+
+	ctx := context.Background()
+	actual, err := sink.StreamImageExport(ctx, restore, disk, goslide.ImageExportRestoreDownloadURI{
+		Type: goslide.ImageExportDownloadType_CLOUD,
+		URI:  downloadServer.URL + "/disk.vhdx",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKey := "slide/a_0123456789ab/s_0123456789ab/MyWindowsAgent (2024-08-05 20-44-25 EDT, C).vhdx"
+	expected := Object{
+		Bucket: "archive",
+		Key:    expectedKey,
+		ETag:   `"complete-3"`,
+		Size:   2500,
+		Parts:  3,
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Returned object mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if !bytes.Equal(content, store.objects[expectedKey]) {
+		t.Fatalf("%s stored object does not match the downloaded content", t.Name())
+	}
+
+	// Part 2 fails once and is retried, parts are never larger than the part size
+	if diff := cmp.Diff([]int{1000, 1000, 500}, store.partSizes); diff != "" {
+		t.Fatalf("%s Part sizes mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if diff := cmp.Diff([]string{"", "bytes=1300-"}, download.ranges); diff != "" {
+		t.Fatalf("%s Download range requests mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	expectedTags := url.Values{
+		"agent-id":        []string{"a_0123456789ab"},
+		"device-id":       []string{"d_0123456789ab"},
+		"disk-id":         []string{"disk_0123456789ab"},
+		"image-export-id": []string{"ie_0123456789ab"},
+		"image-type":      []string{"vhdx"},
+		"snapshot-id":     []string{"s_0123456789ab"},
+	}
+
+	if diff := cmp.Diff(expectedTags, store.tags[expectedKey]); diff != "" {
+		t.Fatalf("%s Object tags mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if diff := cmp.Diff("a_0123456789ab", store.metadata[expectedKey].Get("X-Amz-Meta-Goslide-Agent-Id")); diff != "" {
+		t.Fatalf("%s Object metadata mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestResumableReader_RetriesReconnect(t *testing.T) {
+	content := testContent(2500)
+	download := &flakyDownload{
+		content:     content,
+		dropAfter:   1300,
+		failResumes: 1,
+	}
+
+	downloadServer := httptest.NewServer(download)
+	defer downloadServer.Close()
+
+	reader := &resumableReader{
+		ctx:        context.Background(),
+		client:     downloadServer.Client(),
+		uri:        downloadServer.URL + "/disk.vhdx",
+		maxRetries: 2,
+		backoff:    func(int) time.Duration { return 0 },
+	}
+	defer reader.Close()
+
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(content, actual) {
+		t.Fatalf("%s read content does not match the download", t.Name())
+	}
+
+	// The first resume fails with a 503 and is tried again
+	if diff := cmp.Diff([]string{"", "bytes=1300-", "bytes=1300-"}, download.ranges); diff != "" {
+		t.Fatalf("%s Download range requests mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	// Without retries left the failed resume is returned
+	download.failResumes = 1
+	reader = &resumableReader{
+		ctx:        context.Background(),
+		client:     downloadServer.Client(),
+		uri:        downloadServer.URL + "/disk.vhdx",
+		maxRetries: 1,
+		backoff:    func(int) time.Duration { return 0 },
+	}
+	defer reader.Close()
+
+	if _, err := io.ReadAll(reader); err == nil || !strings.Contains(err.Error(), "failed at byte 1300 - HTTP status code 503") {
+		t.Fatalf("%s expected the interrupted download to fail, got %v", t.Name(), err)
+	}
+}
+
+func TestSink_Upload_AbortsAfterRetries(t *testing.T) {
+	store := newFakeObjectStore()
+	store.failParts[1] = 10
+
+	storeServer := httptest.NewServer(store)
+	defer storeServer.Close()
+
+	sink := newTestSink(t, storeServer.URL, 1000)
+
+	ctx := context.Background()
+	_, err := sink.Upload(ctx, "slide/failed.raw", bytes.NewReader(testContent(100)), Tags{})
+	if err == nil {
+		t.Fatalf("%s expected an error, got nil", t.Name())
+	}
+
+	if diff := cmp.Diff([]string{"upload-1"}, store.aborted); diff != "" {
+		t.Fatalf("%s Aborted uploads mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if len(store.objects) != 0 {
+		t.Fatalf("%s expected no objects to be stored, got %d", t.Name(), len(store.objects))
+	}
+
+	// The store was asked for the initial attempt plus the configured retries
+	if diff := cmp.Diff(7, store.failParts[1]); diff != "" {
+		t.Fatalf("%s Part upload attempts mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestSink_Upload_Empty(t *testing.T) {
+	store := newFakeObjectStore()
+
+	storeServer := httptest.NewServer(store)
+	defer storeServer.Close()
+
+	sink := newTestSink(t, storeServer.URL, 1000)
+
+	ctx := context.Background()
+	actual, err := sink.Upload(ctx, "slide/empty.raw", bytes.NewReader(nil), Tags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(1, actual.Parts); diff != "" {
+		t.Fatalf("%s Part count mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if _, ok := store.objects["slide/empty.raw"]; !ok {
+		t.Fatalf("%s expected the empty object to be stored", t.Name())
+	}
+}
+
+func TestNew_PartSize(t *testing.T) {
+	config := Config{
+		Endpoint: "https://s3.example.com",
+		Bucket:   "archive",
+		PartSize: MinPartSize - 1,
+	}
+
+	if _, err := New(config); err == nil || !strings.Contains(err.Error(), "part size must be at least") {
+		t.Fatalf("%s expected a part size error, got %v", t.Name(), err)
+	}
+
+	config.PartSize = MinPartSize
+	if _, err := New(config); err != nil {
+		t.Fatalf("%s expected the minimum part size to be accepted, got %v", t.Name(), err)
+	}
+}
+
+func TestSelectDownloadURI(t *testing.T) {
+	downloadURIs := []goslide.ImageExportRestoreDownloadURI{
+		{Type: goslide.ImageExportDownloadType_LOCAL, URI: "https://local.example.com"},
+		{Type: goslide.ImageExportDownloadType_CLOUD, URI: "https://cloud.example.com"},
+	}
+
+	actual, ok := SelectDownloadURI(downloadURIs, goslide.ImageExportDownloadType_CLOUD)
+	if !ok {
+		t.Fatalf("%s expected a download URI", t.Name())
+	}
+
+	if diff := cmp.Diff(downloadURIs[1], actual); diff != "" {
+		t.Fatalf("%s Download URI mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if _, ok := SelectDownloadURI(nil, goslide.ImageExportDownloadType_CLOUD); ok {
+		t.Fatalf("%s expected no download URI", t.Name())
+	}
+}
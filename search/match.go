@@ -0,0 +1,298 @@
+package search
+
+import (
+	"net/netip"
+	"strings"
+	"unicode/utf8"
+)
+
+// Scores rank the kinds of match against each other. A prefix match scores
+// higher the more of the value it covers, and fuzzy matches score lower the
+// further they are from the query.
+const (
+	scoreExact    = 100
+	scorePrefix   = 80
+	scoreCIDR     = 70
+	scoreContains = 60
+	scoreTypo     = 40
+	scoreSparse   = 20
+)
+
+type query struct {
+	text string
+
+	// mac is the query without separators, when it could be part of a MAC
+	// address
+	mac string
+
+	// prefix is set for queries in CIDR notation
+	prefix *netip.Prefix
+}
+
+type result struct {
+	matchType  MatchType
+	score      int
+	start, end int
+}
+
+func newQuery(s string) query {
+	q := query{text: strings.ToLower(strings.TrimSpace(s))}
+
+	if mac := normalizeMAC(q.text); mac != "" && isHex(mac) {
+		q.mac = mac
+	}
+
+	if strings.Contains(q.text, "/") {
+		if prefix, err := netip.ParsePrefix(q.text); err == nil {
+			prefix = prefix.Masked()
+			q.prefix = &prefix
+		}
+	}
+
+	return q
+}
+
+func (q query) match(field Field, value string) (result, bool) {
+	switch field {
+	case Field_IP, Field_PUBLIC_IP:
+		return q.matchIP(value)
+	case Field_MAC:
+		return q.matchMAC(value)
+	case Field_DISPLAY_NAME:
+		if r, ok := q.matchText(value, true); ok {
+			return r, true
+		}
+
+		return q.matchFuzzy(value)
+	case Field_HOSTNAME, Field_SERIAL:
+		return q.matchText(value, true)
+	default:
+		return q.matchText(value, false)
+	}
+}
+
+// matchText matches case-insensitively, anywhere in the value when
+// substrings are allowed.
+func (q query) matchText(value string, substrings bool) (result, bool) {
+	lower := strings.ToLower(value)
+
+	// Offsets in lower are only offsets in value if lowering kept the length
+	if len(lower) != len(value) {
+		lower = value
+	}
+
+	switch {
+	case lower == q.text:
+		return result{matchType: MatchType_EXACT, score: scoreExact, end: len(value)}, true
+	case strings.HasPrefix(lower, q.text):
+		return result{matchType: MatchType_PREFIX, score: scorePrefix + 10*len(q.text)/len(value), end: len(q.text)}, true
+	case substrings:
+		if start := strings.Index(lower, q.text); start >= 0 {
+			return result{matchType: MatchType_CONTAINS, score: scoreContains + 10*len(q.text)/len(value), start: start, end: start + len(q.text)}, true
+		}
+	}
+
+	return result{}, false
+}
+
+// matchIP matches addresses exactly, by prefix such as "10.2." or by CIDR.
+// Values may carry a prefix length, such as 10.2.3.4/24.
+func (q query) matchIP(value string) (result, bool) {
+	addressText, _, _ := strings.Cut(value, "/")
+	address, err := netip.ParseAddr(addressText)
+	if err != nil {
+		return q.matchText(value, false)
+	}
+
+	if q.prefix != nil {
+		if !q.prefix.Contains(address.Unmap()) {
+			return result{}, false
+		}
+
+		// Narrower ranges rank higher
+		return result{matchType: MatchType_CIDR, score: scoreCIDR + 10*q.prefix.Bits()/address.BitLen(), end: len(addressText)}, true
+	}
+
+	if queryAddress, err := netip.ParseAddr(q.text); err == nil {
+		if queryAddress.Unmap() == address.Unmap() {
+			return result{matchType: MatchType_EXACT, score: scoreExact, end: len(addressText)}, true
+		}
+
+		return result{}, false
+	}
+
+	lower := strings.ToLower(addressText)
+	if strings.HasPrefix(lower, q.text) {
+		return result{matchType: MatchType_PREFIX, score: scorePrefix + 10*len(q.text)/len(lower), end: len(q.text)}, true
+	}
+
+	return result{}, false
+}
+
+// matchMAC ignores the separators and case of MAC addresses, so
+// 00:1A:2B:3C:4D:5E is found by 001a.2b3c.4d5e or by 00-1a-2b.
+func (q query) matchMAC(value string) (result, bool) {
+	mac := normalizeMAC(value)
+	if q.mac == "" || mac == "" {
+		return result{}, false
+	}
+
+	switch {
+	case mac == q.mac:
+		return result{matchType: MatchType_EXACT, score: scoreExact, end: len(value)}, true
+	case len(q.mac) >= 4 && strings.HasPrefix(mac, q.mac):
+		return result{matchType: MatchType_PREFIX, score: scorePrefix + 10*len(q.mac)/len(mac), end: macOffset(value, len(q.mac))}, true
+	}
+
+	return result{}, false
+}
+
+// matchFuzzy tolerates typos in a word of the value, such as "recepton" for
+// "Reception PC", and otherwise matches the letters of the query in order,
+// such as "rcpc".
+func (q query) matchFuzzy(value string) (result, bool) {
+	lower := strings.ToLower(value)
+	if len(lower) != len(value) || utf8.RuneCountInString(q.text) < 3 {
+		return result{}, false
+	}
+
+	maxDistance := 1
+	if len(q.text) >= 8 {
+		maxDistance = 2
+	}
+
+	best := result{}
+	for _, word := range words(lower) {
+		distance := levenshtein(q.text, lower[word.start:word.end])
+		if distance > maxDistance {
+			continue
+		}
+
+		if score := scoreTypo - 5*distance; score > best.score {
+			best = result{matchType: MatchType_FUZZY, score: score, start: word.start, end: word.end}
+		}
+	}
+
+	if best.score > 0 {
+		return best, true
+	}
+
+	// Letters spread over most of a long name are a coincidence
+	start, end, ok := subsequence(lower, strings.ReplaceAll(q.text, " ", ""))
+	if !ok || end-start > 3*len(q.text) {
+		return result{}, false
+	}
+
+	// The closer together the letters, the better the match
+	return result{matchType: MatchType_FUZZY, score: scoreSparse + 19*len(q.text)/(end-start), start: start, end: end}, true
+}
+
+type span struct {
+	start, end int
+}
+
+// words returns the byte spans of the words of s, split on spaces and
+// punctuation.
+func words(s string) []span {
+	spans := []span{}
+	start := -1
+	for i, r := range s {
+		separator := strings.ContainsRune(" \t-_.,/()", r)
+		switch {
+		case !separator && start < 0:
+			start = i
+		case separator && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		spans = append(spans, span{start, len(s)})
+	}
+
+	return spans
+}
+
+// subsequence finds the letters of query in order in s, returning the
+// shortest span that starts at the first possible letter.
+func subsequence(s, query string) (int, int, bool) {
+	if query == "" {
+		return 0, 0, false
+	}
+
+	start := strings.IndexByte(s, query[0])
+	if start < 0 {
+		return 0, 0, false
+	}
+
+	position := start + 1
+	for i := 1; i < len(query); i++ {
+		next := strings.IndexByte(s[position:], query[i])
+		if next < 0 {
+			return 0, 0, false
+		}
+
+		position += next + 1
+	}
+
+	return start, position, true
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(rb)]
+}
+
+func normalizeMAC(s string) string {
+	replacer := strings.NewReplacer(":", "", "-", "", ".", "")
+
+	return strings.ToLower(replacer.Replace(s))
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// macOffset returns the offset in value after its first n hex digits.
+func macOffset(value string, n int) int {
+	for i, r := range value {
+		if n == 0 {
+			return i
+		}
+
+		if isHex(strings.ToLower(string(r))) {
+			n--
+		}
+	}
+
+	return len(value)
+}
@@ -0,0 +1,274 @@
+// Package search finds devices and agents by hostname, display name, IP
+// address, MAC address, serial number or ID, for tickets such as "the server
+// at 10.2.3.4".
+package search
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+)
+
+const defaultLimit = 20
+
+type RecordType string
+
+const (
+	RecordType_DEVICE RecordType = "device"
+	RecordType_AGENT  RecordType = "agent"
+)
+
+// Field is the field of a record a query matched.
+type Field string
+
+const (
+	Field_ID           Field = "id"
+	Field_HOSTNAME     Field = "hostname"
+	Field_DISPLAY_NAME Field = "display_name"
+	Field_IP           Field = "ip"
+	Field_PUBLIC_IP    Field = "public_ip"
+	Field_MAC          Field = "mac"
+	Field_SERIAL       Field = "serial_number"
+)
+
+type MatchType string
+
+const (
+	MatchType_EXACT    MatchType = "exact"
+	MatchType_PREFIX   MatchType = "prefix"
+	MatchType_CIDR     MatchType = "cidr"
+	MatchType_CONTAINS MatchType = "contains"
+	MatchType_FUZZY    MatchType = "fuzzy"
+)
+
+// Hit is a record that matched a query, with the field that matched best.
+type Hit struct {
+	Type   RecordType      `json:"type"`
+	ID     string          `json:"id"`
+	Name   string          `json:"name"`
+	Device *goslide.Device `json:"device,omitempty"`
+	Agent  *goslide.Agent  `json:"agent,omitempty"`
+
+	Field Field     `json:"field"`
+	Value string    `json:"value"`
+	Match MatchType `json:"match"`
+	Score int       `json:"score"`
+
+	// Start and End are the byte offsets of the matched part of Value
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Highlight returns Value with the matched part wrapped in before and after,
+// such as ANSI escape codes or brackets.
+func (h Hit) Highlight(before, after string) string {
+	return h.Value[:h.Start] + before + h.Value[h.Start:h.End] + after + h.Value[h.End:]
+}
+
+// entry is a searchable value of a record.
+type entry struct {
+	field Field
+	value string
+}
+
+type record struct {
+	recordType RecordType
+	id         string
+	name       string
+	device     *goslide.Device
+	agent      *goslide.Agent
+	entries    []entry
+}
+
+// Index holds the searchable fields of devices and agents. It is read only
+// once built, so it is safe for concurrent searches.
+type Index struct {
+	records []record
+}
+
+// NewIndex indexes devices and agents, for example from a mirror.
+func NewIndex(devices []goslide.Device, agents []goslide.Agent) *Index {
+	index := &Index{}
+
+	for _, device := range devices {
+		device := device
+		entries := []entry{
+			{Field_ID, device.DeviceID},
+			{Field_HOSTNAME, device.Hostname},
+			{Field_DISPLAY_NAME, device.DisplayName},
+			{Field_PUBLIC_IP, device.PublicIPAddress},
+			{Field_SERIAL, device.SerialNumber},
+		}
+
+		index.records = append(index.records, record{
+			recordType: RecordType_DEVICE,
+			id:         device.DeviceID,
+			name:       firstNonEmpty(device.DisplayName, device.Hostname),
+			device:     &device,
+			entries:    append(entries, addressEntries(device.Addresses)...),
+		})
+	}
+
+	for _, agent := range agents {
+		agent := agent
+		entries := []entry{
+			{Field_ID, agent.AgentID},
+			{Field_HOSTNAME, agent.Hostname},
+			{Field_DISPLAY_NAME, agent.DisplayName},
+			{Field_PUBLIC_IP, agent.PublicIPAddress},
+		}
+
+		index.records = append(index.records, record{
+			recordType: RecordType_AGENT,
+			id:         agent.AgentID,
+			name:       firstNonEmpty(agent.DisplayName, agent.Hostname),
+			agent:      &agent,
+			entries:    append(entries, addressEntries(agent.Addresses)...),
+		})
+	}
+
+	return index
+}
+
+// Build lists the devices and agents of the account and indexes them.
+func Build(ctx context.Context, service goslide.Service) (*Index, error) {
+	devices := []goslide.Device{}
+	if err := service.Devices().List(ctx, func(response goslide.ListResponse[goslide.Device]) error {
+		devices = append(devices, response.Data...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	agents := []goslide.Agent{}
+	if err := service.Agents().List(ctx, func(response goslide.ListResponse[goslide.Agent]) error {
+		agents = append(agents, response.Data...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return NewIndex(devices, agents), nil
+}
+
+type searchOptions struct {
+	limit       int
+	recordTypes []RecordType
+}
+
+type searchOption func(o *searchOptions)
+
+// WithLimit sets the maximum number of hits, 20 by default. Zero returns
+// every hit.
+func WithLimit(limit int) searchOption {
+	return func(o *searchOptions) {
+		o.limit = limit
+	}
+}
+
+// WithRecordTypes limits the search to devices or agents.
+func WithRecordTypes(recordTypes ...RecordType) searchOption {
+	return func(o *searchOptions) {
+		o.recordTypes = recordTypes
+	}
+}
+
+// Search returns the records matching query, best first. Each record is hit
+// once, by its best matching field.
+func (i *Index) Search(query string, options ...searchOption) []Hit {
+	o := &searchOptions{limit: defaultLimit}
+	for _, option := range options {
+		option(o)
+	}
+
+	q := newQuery(query)
+	if q.text == "" {
+		return []Hit{}
+	}
+
+	hits := []Hit{}
+	for _, r := range i.records {
+		if len(o.recordTypes) > 0 && !slices.Contains(o.recordTypes, r.recordType) {
+			continue
+		}
+
+		best := Hit{}
+		for _, e := range r.entries {
+			if e.value == "" {
+				continue
+			}
+
+			result, ok := q.match(e.field, e.value)
+			if !ok || result.score <= best.Score {
+				continue
+			}
+
+			best = Hit{
+				Type:   r.recordType,
+				ID:     r.id,
+				Name:   r.name,
+				Device: r.device,
+				Agent:  r.agent,
+				Field:  e.field,
+				Value:  e.value,
+				Match:  result.matchType,
+				Score:  result.score,
+				Start:  result.start,
+				End:    result.end,
+			}
+		}
+
+		if best.Score > 0 {
+			hits = append(hits, best)
+		}
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+
+		if hits[a].Type != hits[b].Type {
+			return hits[a].Type == RecordType_DEVICE
+		}
+
+		if hits[a].Name != hits[b].Name {
+			return hits[a].Name < hits[b].Name
+		}
+
+		return hits[a].ID < hits[b].ID
+	})
+
+	if o.limit > 0 && len(hits) > o.limit {
+		hits = hits[:o.limit]
+	}
+
+	return hits
+}
+
+func addressEntries(addresses []goslide.Address) []entry {
+	entries := []entry{}
+	for _, address := range addresses {
+		entries = append(entries, entry{Field_MAC, address.MAC})
+		for _, ip := range address.IPs {
+			entries = append(entries, entry{Field_IP, ip})
+		}
+	}
+
+	return entries
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+
+	return ""
+}
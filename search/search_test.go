@@ -0,0 +1,191 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/fakeslide"
+	"github.com/equalsgibson/goslide/search"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	testDevices = []goslide.Device{
+		{
+			DeviceID:        "d_hq",
+			DisplayName:     "Acme HQ",
+			Hostname:        "acme-hq-box",
+			PublicIPAddress: "203.0.113.10",
+			SerialNumber:    "SLD-8842-XQ",
+			Addresses:       []goslide.Address{{MAC: "00:1A:2B:3C:4D:5E", IPs: []string{"10.2.0.2"}}},
+		},
+		{
+			DeviceID:    "d_branch",
+			DisplayName: "Acme Branch",
+			Hostname:    "acme-branch-box",
+			Addresses:   []goslide.Address{{MAC: "00:1A:2B:00:00:01", IPs: []string{"192.168.1.2/24"}}},
+		},
+	}
+	testAgents = []goslide.Agent{
+		{
+			AgentID:     "a_reception",
+			DisplayName: "Reception PC",
+			Hostname:    "DESKTOP-4F2K",
+			Addresses:   []goslide.Address{{MAC: "aa-bb-cc-dd-ee-ff", IPs: []string{"10.2.3.4", "fe80::1"}}},
+		},
+		{
+			AgentID:         "a_sql",
+			DisplayName:     "SQL Server",
+			Hostname:        "sql01",
+			PublicIPAddress: "203.0.113.10",
+			Addresses:       []goslide.Address{{MAC: "aa:bb:cc:00:11:22", IPs: []string{"10.2.3.5"}}},
+		},
+	}
+)
+
+type summary struct {
+	ID        string
+	Field     search.Field
+	Match     search.MatchType
+	Highlight string
+}
+
+func summarize(hits []search.Hit) []summary {
+	summaries := []summary{}
+	for _, hit := range hits {
+		summaries = append(summaries, summary{hit.ID, hit.Field, hit.Match, hit.Highlight("[", "]")})
+	}
+
+	return summaries
+}
+
+func TestSearch(t *testing.T) {
+	index := search.NewIndex(testDevices, testAgents)
+
+	testCases := map[string]struct {
+		query string
+		want  []summary
+	}{
+		"exact IP": {
+			query: "10.2.3.4",
+			want: []summary{
+				{"a_reception", search.Field_IP, search.MatchType_EXACT, "[10.2.3.4]"},
+			},
+		},
+		"IP prefix": {
+			query: "10.2.3.",
+			want: []summary{
+				{"a_reception", search.Field_IP, search.MatchType_PREFIX, "[10.2.3.]4"},
+				{"a_sql", search.Field_IP, search.MatchType_PREFIX, "[10.2.3.]5"},
+			},
+		},
+		"CIDR on an address with a prefix length": {
+			query: "192.168.0.0/16",
+			want: []summary{
+				{"d_branch", search.Field_IP, search.MatchType_CIDR, "[192.168.1.2]/24"},
+			},
+		},
+		"CIDR": {
+			query: "10.2.0.0/16",
+			want: []summary{
+				{"d_hq", search.Field_IP, search.MatchType_CIDR, "[10.2.0.2]"},
+				{"a_reception", search.Field_IP, search.MatchType_CIDR, "[10.2.3.4]"},
+				{"a_sql", search.Field_IP, search.MatchType_CIDR, "[10.2.3.5]"},
+			},
+		},
+		"public IP on a device and an agent": {
+			query: "203.0.113.10",
+			want: []summary{
+				{"d_hq", search.Field_PUBLIC_IP, search.MatchType_EXACT, "[203.0.113.10]"},
+				{"a_sql", search.Field_PUBLIC_IP, search.MatchType_EXACT, "[203.0.113.10]"},
+			},
+		},
+		"MAC in another notation": {
+			query: "001a.2b3c.4d5e",
+			want: []summary{
+				{"d_hq", search.Field_MAC, search.MatchType_EXACT, "[00:1A:2B:3C:4D:5E]"},
+			},
+		},
+		"MAC prefix": {
+			query: "AA:BB:CC",
+			want: []summary{
+				{"a_reception", search.Field_MAC, search.MatchType_PREFIX, "[aa-bb-cc]-dd-ee-ff"},
+				{"a_sql", search.Field_MAC, search.MatchType_PREFIX, "[aa:bb:cc]:00:11:22"},
+			},
+		},
+		"serial number": {
+			query: "sld-8842-xq",
+			want: []summary{
+				{"d_hq", search.Field_SERIAL, search.MatchType_EXACT, "[SLD-8842-XQ]"},
+			},
+		},
+		"hostname prefix": {
+			query: "acme-hq",
+			want: []summary{
+				{"d_hq", search.Field_HOSTNAME, search.MatchType_PREFIX, "[acme-hq]-box"},
+			},
+		},
+		"display name substring": {
+			query: "server",
+			want: []summary{
+				{"a_sql", search.Field_DISPLAY_NAME, search.MatchType_CONTAINS, "SQL [Server]"},
+			},
+		},
+		"display name typo": {
+			query: "recepton",
+			want: []summary{
+				{"a_reception", search.Field_DISPLAY_NAME, search.MatchType_FUZZY, "[Reception] PC"},
+			},
+		},
+		"display name letters": {
+			query: "acbr",
+			want: []summary{
+				{"d_branch", search.Field_DISPLAY_NAME, search.MatchType_FUZZY, "[Acme Br]anch"},
+			},
+		},
+		"no match": {
+			query: "10.9.9.9",
+			want:  []summary{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := summarize(index.Search(testCase.query))
+			if diff := cmp.Diff(testCase.want, got); diff != "" {
+				t.Fatalf("%s (-want +got):\n%s", t.Name(), diff)
+			}
+		})
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	index := search.NewIndex(testDevices, testAgents)
+
+	hits := index.Search("10.2.0.0/16", search.WithRecordTypes(search.RecordType_AGENT), search.WithLimit(1))
+	if diff := cmp.Diff([]summary{
+		{"a_reception", search.Field_IP, search.MatchType_CIDR, "[10.2.3.4]"},
+	}, summarize(hits)); diff != "" {
+		t.Fatalf("%s (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if hits[0].Agent == nil || hits[0].Agent.Hostname != "DESKTOP-4F2K" || hits[0].Device != nil {
+		t.Fatalf("%s expected the hit to carry the agent, got %+v", t.Name(), hits[0])
+	}
+}
+
+func TestBuild(t *testing.T) {
+	server := fakeslide.NewFleet()
+
+	index, err := search.Build(context.Background(), server.Service())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]summary{
+		{"a_mail", search.Field_HOSTNAME, search.MatchType_EXACT, "[globex-mail]"},
+	}, summarize(index.Search("GLOBEX-MAIL"))); diff != "" {
+		t.Fatalf("%s (-want +got):\n%s", t.Name(), diff)
+	}
+}
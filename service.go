@@ -3,6 +3,7 @@ package goslide
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"golang.org/x/oauth2"
 )
@@ -10,6 +11,9 @@ import (
 type serviceConfig struct {
 	roundtripper http.RoundTripper
 	apiURL       string
+	maxRetries   uint
+	retryBackoff time.Duration
+	observer     Observer
 }
 
 type configOption func(s *serviceConfig)
@@ -20,6 +24,24 @@ func WithCustomRoundtripper(roundtripper http.RoundTripper) configOption {
 	}
 }
 
+// WithAPIURL sets the host requests are sent to, such as api.slide.tech.
+func WithAPIURL(host string) configOption {
+	return func(s *serviceConfig) {
+		s.apiURL = host
+	}
+}
+
+// WithRetries sends GET requests that were rate limited or failed with a
+// server error again, up to maxRetries times. The wait starts at backoff and
+// doubles with every attempt, unless the API asks for a longer wait with
+// Retry-After. Requests are not retried by default.
+func WithRetries(maxRetries uint, backoff time.Duration) configOption {
+	return func(s *serviceConfig) {
+		s.maxRetries = maxRetries
+		s.retryBackoff = backoff
+	}
+}
+
 func AddRequestPreProcessor(roundtripper http.RoundTripper) configOption {
 	return func(s *serviceConfig) {
 		s.roundtripper = roundtripper
@@ -53,7 +75,8 @@ func NewService(
 	options ...configOption,
 ) Service {
 	config := &serviceConfig{
-		apiURL: "api.slide.tech",
+		apiURL:   "api.slide.tech",
+		observer: noopObserver{},
 	}
 
 	for _, option := range options {
@@ -70,6 +93,9 @@ func NewService(
 		httpClient: &http.Client{
 			Transport: config.roundtripper,
 		},
+		observer:     config.observer,
+		maxRetries:   config.maxRetries,
+		retryBackoff: config.retryBackoff,
 	}
 
 	return Service{
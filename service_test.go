@@ -1,8 +1,20 @@
 package goslide_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/internal/roundtripper"
+	"github.com/google/go-cmp/cmp"
 )
 
 func generateRFC3389FromString(t *testing.T, timestamp string) time.Time {
@@ -15,3 +27,71 @@ func generateRFC3389FromString(t *testing.T, timestamp string) time.Time {
 
 	return expectedTime
 }
+
+// validateRequestBody returns a request validator that compares the indented
+// request body with the contents of the given testdata file.
+func validateRequestBody(t *testing.T, filePath string) func(r *http.Request) error {
+	t.Helper()
+
+	return func(r *http.Request) error {
+		expectedBody, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("error during test setup - could not read file: %w", err)
+		}
+
+		actualBody, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("error during test setup - could not read request body: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(actualBody))
+
+		var actualBodyFormatted bytes.Buffer
+		if err := json.Indent(&actualBodyFormatted, actualBody, "", "    "); err != nil {
+			return fmt.Errorf("error during test setup - could not format request body: %w", err)
+		}
+
+		if diff := cmp.Diff(string(expectedBody), actualBodyFormatted.String()); diff != "" {
+			t.Fatalf("%s Expected Request Body mismatch (-want +got):\n%s", t.Name(), diff)
+		}
+
+		return nil
+	}
+}
+
+func TestService_WithAPIURL(t *testing.T) {
+	testService := goslide.NewService("fakeToken",
+		goslide.WithAPIURL("api.eu.example.com:8443"),
+		goslide.WithCustomRoundtripper(
+			roundtripper.NetworkQueue(
+				t,
+				[]roundtripper.TestRoundTripFunc{
+					roundtripper.ServeAndValidate(
+						t,
+						&roundtripper.TestResponseFile{
+							StatusCode: http.StatusOK,
+							FilePath:   "testdata/responses/health/is_authenticated_success_200.json",
+						},
+						roundtripper.ExpectedTestRequest{
+							Method: http.MethodGet,
+							Path:   "/v1/user",
+							Query: url.Values{
+								"limit": []string{"1"},
+							},
+							Validator: func(r *http.Request) error {
+								if r.URL.Host != "api.eu.example.com:8443" || r.URL.Scheme != "https" {
+									return fmt.Errorf("unexpected URL %s", r.URL)
+								}
+
+								return nil
+							},
+						},
+					),
+				},
+			),
+		),
+	)
+
+	if _, err := testService.CheckAuthenticationToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -79,7 +79,12 @@ func (s SnapshotService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[Snapshot]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := s.requestClient.startPagination(ctx, s.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
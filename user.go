@@ -44,7 +44,12 @@ func (u UserService) ListWithQueryParameters(
 	ctx context.Context,
 	pageHandler func(response ListResponse[User]) error,
 	options ...paginationQueryParam,
-) error {
+) (err error) {
+	ctx, endPagination := u.requestClient.startPagination(ctx, u.baseEndpoint)
+	defer func() {
+		endPagination(err)
+	}()
+
 	queryParams := url.Values{}
 	for _, option := range options {
 		option(queryParams)
@@ -0,0 +1,295 @@
+package vncbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/equalsgibson/goslide"
+)
+
+const (
+	defaultListenAddress = "127.0.0.1:0"
+	defaultProbeTimeout  = 5 * time.Second
+)
+
+type bridgeConfig struct {
+	listenAddress string
+	probeTimeout  time.Duration
+	httpClient    *http.Client
+	authenticate  bool
+	typeOrder     []goslide.VirtualMachineVNCType
+}
+
+type bridgeOption func(c *bridgeConfig)
+
+// WithListenAddress sets the local TCP address the bridge listens on. Defaults
+// to a random port on the loopback interface.
+func WithListenAddress(address string) bridgeOption {
+	return func(c *bridgeConfig) {
+		c.listenAddress = address
+	}
+}
+
+// WithProbeTimeout sets how long each VNC endpoint is given to accept a
+// WebSocket connection before the next endpoint is tried.
+func WithProbeTimeout(timeout time.Duration) bridgeOption {
+	return func(c *bridgeConfig) {
+		c.probeTimeout = timeout
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) bridgeOption {
+	return func(c *bridgeConfig) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithVNCAuthentication makes the bridge answer the VNC password challenge
+// using the VNCPassword of the virtual machine. Local viewers are then offered
+// the "None" security type and do not need to know the password.
+func WithVNCAuthentication() bridgeOption {
+	return func(c *bridgeConfig) {
+		c.authenticate = true
+	}
+}
+
+// WithPreferredTypes sets the order VNC endpoints are probed in. Defaults to
+// local before cloud, as a reachable local endpoint avoids the cloud relay.
+func WithPreferredTypes(types ...goslide.VirtualMachineVNCType) bridgeOption {
+	return func(c *bridgeConfig) {
+		c.typeOrder = types
+	}
+}
+
+// Bridge accepts raw RFB connections on a local TCP port and proxies each of
+// them to the WebSocket endpoint of a virtual machine restore.
+type Bridge struct {
+	config   bridgeConfig
+	listener net.Listener
+	endpoint goslide.VirtualMachineVNC
+	password string
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Listen selects a reachable VNC endpoint of the virtual machine and starts
+// listening on the local address. Call Serve to start accepting connections.
+func Listen(ctx context.Context, vm goslide.VirtualMachineRestore, options ...bridgeOption) (*Bridge, error) {
+	config := bridgeConfig{
+		listenAddress: defaultListenAddress,
+		probeTimeout:  defaultProbeTimeout,
+		httpClient:    http.DefaultClient,
+		typeOrder: []goslide.VirtualMachineVNCType{
+			goslide.VirtualMachineVNCType_LOCAL,
+			goslide.VirtualMachineVNCType_CLOUD,
+		},
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.authenticate && vm.VNCPassword == "" {
+		return nil, fmt.Errorf("vncbridge: virtual machine %s has no VNC password", vm.VirtID)
+	}
+
+	endpoint, err := SelectEndpoint(ctx, vm.VNC, config.probeTimeout, config.httpClient, config.typeOrder...)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", config.listenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bridge{
+		config:   config,
+		listener: listener,
+		endpoint: endpoint,
+		password: vm.VNCPassword,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Addr returns the local address VNC viewers should connect to.
+func (b *Bridge) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+// Endpoint returns the VNC endpoint selected for the bridge.
+func (b *Bridge) Endpoint() goslide.VirtualMachineVNC {
+	return b.endpoint
+}
+
+// Serve accepts connections until the context is cancelled or Close is called,
+// which also ends the open viewer sessions. Every accepted connection gets its
+// own WebSocket connection to the VM.
+func (b *Bridge) Serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.Close()
+		case <-b.done:
+		}
+	}()
+
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			select {
+			case <-b.done:
+				// Sessions last until the viewer disconnects otherwise
+				cancel()
+				b.wg.Wait()
+
+				return nil
+			default:
+			}
+
+			return err
+		}
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+
+			// Errors from a single viewer session should not stop the bridge
+			_ = b.handle(ctx, conn)
+		}()
+	}
+}
+
+func (b *Bridge) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		err = b.listener.Close()
+	})
+
+	return err
+}
+
+func (b *Bridge) handle(ctx context.Context, local net.Conn) error {
+	defer local.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Closing the viewer connection ends the proxy when the bridge stops
+	stop := context.AfterFunc(ctx, func() { local.Close() })
+	defer stop()
+
+	wsConn, err := dial(ctx, b.endpoint.WebsocketURI, b.config.httpClient)
+	if err != nil {
+		return err
+	}
+
+	remote := websocket.NetConn(ctx, wsConn, websocket.MessageBinary)
+	defer remote.Close()
+
+	if b.config.authenticate {
+		if err := authenticate(local, remote, b.password); err != nil {
+			return err
+		}
+	}
+
+	return proxy(local, remote)
+}
+
+// proxy copies data in both directions until either side closes.
+func proxy(local, remote net.Conn) error {
+	errs := make(chan error, 2)
+
+	go func() {
+		_, err := io.Copy(remote, local)
+		errs <- err
+	}()
+
+	go func() {
+		_, err := io.Copy(local, remote)
+		errs <- err
+	}()
+
+	// Wait for one direction to finish, then close both ends to unblock the other
+	err := <-errs
+	local.Close()
+	remote.Close()
+	<-errs
+
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+func dial(ctx context.Context, uri string, httpClient *http.Client) (*websocket.Conn, error) {
+	wsConn, _, err := websocket.Dial(ctx, uri, &websocket.DialOptions{
+		HTTPClient:   httpClient,
+		Subprotocols: []string{"binary"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wsConn, nil
+}
+
+// SelectEndpoint returns the first VNC endpoint, in type order, that accepts a
+// WebSocket connection within the probe timeout.
+func SelectEndpoint(
+	ctx context.Context,
+	endpoints []goslide.VirtualMachineVNC,
+	probeTimeout time.Duration,
+	httpClient *http.Client,
+	typeOrder ...goslide.VirtualMachineVNCType,
+) (goslide.VirtualMachineVNC, error) {
+	if len(endpoints) == 0 {
+		return goslide.VirtualMachineVNC{}, errors.New("vncbridge: virtual machine has no VNC endpoints")
+	}
+
+	candidates := []goslide.VirtualMachineVNC{}
+	for _, vncType := range typeOrder {
+		for _, endpoint := range endpoints {
+			if endpoint.Type == vncType && endpoint.WebsocketURI != "" {
+				candidates = append(candidates, endpoint)
+			}
+		}
+	}
+
+	errs := []error{}
+	for _, candidate := range candidates {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		wsConn, err := dial(probeCtx, candidate.WebsocketURI, httpClient)
+		cancel()
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s endpoint unreachable - %w", candidate.Type, err))
+
+			continue
+		}
+
+		wsConn.Close(websocket.StatusNormalClosure, "probe")
+
+		return candidate, nil
+	}
+
+	if len(errs) == 0 {
+		return goslide.VirtualMachineVNC{}, errors.New("vncbridge: no VNC endpoint of the requested types")
+	}
+
+	return goslide.VirtualMachineVNC{}, fmt.Errorf("vncbridge: no reachable VNC endpoint - %w", errors.Join(errs...))
+}
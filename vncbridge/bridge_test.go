@@ -0,0 +1,381 @@
+package vncbridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/des"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/equalsgibson/goslide"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newWebsocketServer starts a WebSocket stand-in for the VNC endpoint, handing
+// each connection to the handler as a net.Conn carrying binary frames.
+func newWebsocketServer(t *testing.T, handler func(conn net.Conn)) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wsConn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			Subprotocols: []string{"binary"},
+		})
+		if err != nil {
+			return
+		}
+
+		conn := websocket.NetConn(r.Context(), wsConn, websocket.MessageBinary)
+		defer conn.Close()
+
+		handler(conn)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func echoHandler(conn net.Conn) {
+	_, _ = io.Copy(conn, conn)
+}
+
+func unreachableURI(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	listener.Close()
+
+	return "ws://" + address
+}
+
+func startBridge(t *testing.T, vm goslide.VirtualMachineRestore, options ...bridgeOption) *Bridge {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	options = append([]bridgeOption{WithProbeTimeout(time.Second)}, options...)
+
+	bridge, err := Listen(ctx, vm, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- bridge.Serve(ctx)
+	}()
+
+	t.Cleanup(func() {
+		bridge.Close()
+
+		if err := <-serveErr; err != nil {
+			t.Errorf("bridge serve returned error: %s", err)
+		}
+	})
+
+	return bridge
+}
+
+func TestBridge_Proxy(t *testing.T) {
+	server := newWebsocketServer(t, echoHandler)
+
+	vm := goslide.VirtualMachineRestore{
+		VirtID: "virt_0123456789ab",
+		VNC: []goslide.VirtualMachineVNC{
+			{
+				Host:         "192.168.1.53",
+				Port:         12345,
+				Type:         goslide.VirtualMachineVNCType_LOCAL,
+				WebsocketURI: unreachableURI(t),
+			},
+			{
+				Type:         goslide.VirtualMachineVNCType_CLOUD,
+				WebsocketURI: "ws" + server.URL[len("http"):],
+			},
+		},
+	}
+
+	bridge := startBridge(t, vm)
+
+	if diff := cmp.Diff(goslide.VirtualMachineVNCType_CLOUD, bridge.Endpoint().Type); diff != "" {
+		t.Fatalf("%s Selected endpoint mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	conn, err := net.Dial("tcp", bridge.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	expected := []byte("RFB 003.008\n\x01\x02\x03")
+	if _, err := conn.Write(expected); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]byte, len(expected))
+	if _, err := io.ReadFull(conn, actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("%s Echoed bytes mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestBridge_CloseWithOpenSession(t *testing.T) {
+	server := newWebsocketServer(t, echoHandler)
+
+	vm := goslide.VirtualMachineRestore{
+		VirtID: "virt_0123456789ab",
+		VNC: []goslide.VirtualMachineVNC{
+			{
+				Type:         goslide.VirtualMachineVNCType_CLOUD,
+				WebsocketURI: "ws" + server.URL[len("http"):],
+			},
+		},
+	}
+
+	bridge, err := Listen(context.Background(), vm, WithProbeTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- bridge.Serve(context.Background())
+	}()
+
+	conn, err := net.Dial("tcp", bridge.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// A round trip makes sure the session is open
+	if _, err := conn.Write([]byte("RFB")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bridge.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			t.Fatalf("%s bridge serve returned error: %s", t.Name(), err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s Serve did not return after Close with an open session", t.Name())
+	}
+
+	// The viewer is disconnected
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("%s expected the viewer connection to be closed, got %v", t.Name(), err)
+	}
+}
+
+func TestBridge_VNCAuthentication(t *testing.T) {
+	password := "secret12"
+	challenge := []byte("0123456789abcdef")
+	serverInit := []byte("server-init")
+
+	serverErr := make(chan error, 1)
+	server := newWebsocketServer(t, func(conn net.Conn) {
+		// Endpoint probes disconnect before sending a version, ignore them
+		if err := fakeRFBServer(conn, password, challenge, serverInit); !errors.Is(err, errProbe) {
+			serverErr <- err
+		}
+	})
+
+	vm := goslide.VirtualMachineRestore{
+		VirtID:      "virt_0123456789ab",
+		VNCPassword: password,
+		VNC: []goslide.VirtualMachineVNC{
+			{
+				Type:         goslide.VirtualMachineVNCType_LOCAL,
+				WebsocketURI: "ws" + server.URL[len("http"):],
+			},
+		},
+	}
+
+	bridge := startBridge(t, vm, WithVNCAuthentication())
+
+	conn, err := net.Dial("tcp", bridge.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	version := make([]byte, 12)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(rfbVersion38, string(version)); diff != "" {
+		t.Fatalf("%s Version mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if _, err := conn.Write([]byte(rfbVersion38)); err != nil {
+		t.Fatal(err)
+	}
+
+	securityTypes := make([]byte, 2)
+	if _, err := io.ReadFull(conn, securityTypes); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]byte{1, securityTypeNone}, securityTypes); diff != "" {
+		t.Fatalf("%s Security types mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if _, err := conn.Write([]byte{securityTypeNone}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result uint32
+	if err := binary.Read(conn, binary.BigEndian, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result != securityResultOK {
+		t.Fatalf("%s expected security result OK, got %d", t.Name(), result)
+	}
+
+	// ClientInit (shared flag) is proxied to the server, ServerInit comes back
+	if _, err := conn.Write([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]byte, len(serverInit))
+	if _, err := io.ReadFull(conn, actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(serverInit, actual); diff != "" {
+		t.Fatalf("%s ServerInit mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+
+	if err := <-serverErr; err != nil {
+		t.Fatal(err)
+	}
+}
+
+var errProbe = errors.New("connection closed before the version handshake")
+
+// fakeRFBServer performs the server side of a RFB 3.8 handshake using VNC
+// authentication, then answers the ClientInit message.
+func fakeRFBServer(conn net.Conn, password string, challenge, serverInit []byte) error {
+	if _, err := io.WriteString(conn, rfbVersion38); err != nil {
+		return err
+	}
+
+	version := make([]byte, 12)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return errProbe
+	}
+
+	if _, err := conn.Write([]byte{2, securityTypeNone, securityTypeVNCAuth}); err != nil {
+		return err
+	}
+
+	selected := make([]byte, 1)
+	if _, err := io.ReadFull(conn, selected); err != nil {
+		return err
+	}
+
+	if selected[0] != securityTypeVNCAuth {
+		return errors.New("client did not select VNC authentication")
+	}
+
+	if _, err := conn.Write(challenge); err != nil {
+		return err
+	}
+
+	response := make([]byte, 16)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return err
+	}
+
+	key := make([]byte, 8)
+	copy(key, password)
+	for i, b := range key {
+		key[i] = reverseBits(b)
+	}
+
+	cipher, err := des.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	decrypted := make([]byte, 16)
+	cipher.Decrypt(decrypted[:8], response[:8])
+	cipher.Decrypt(decrypted[8:], response[8:])
+
+	if !bytes.Equal(challenge, decrypted) {
+		return binary.Write(conn, binary.BigEndian, uint32(1))
+	}
+
+	if err := binary.Write(conn, binary.BigEndian, uint32(securityResultOK)); err != nil {
+		return err
+	}
+
+	clientInit := make([]byte, 1)
+	if _, err := io.ReadFull(conn, clientInit); err != nil {
+		return err
+	}
+
+	_, err = conn.Write(serverInit)
+
+	return err
+}
+
+func TestSelectEndpoint_Unreachable(t *testing.T) {
+	endpoints := []goslide.VirtualMachineVNC{
+		{
+			Type:         goslide.VirtualMachineVNCType_LOCAL,
+			WebsocketURI: unreachableURI(t),
+		},
+	}
+
+	ctx := context.Background()
+	_, err := SelectEndpoint(ctx, endpoints, time.Second, http.DefaultClient,
+		goslide.VirtualMachineVNCType_LOCAL,
+		goslide.VirtualMachineVNCType_CLOUD,
+	)
+	if err == nil {
+		t.Fatalf("%s expected an error for an unreachable endpoint", t.Name())
+	}
+}
+
+func TestReverseBits(t *testing.T) {
+	testCases := map[byte]byte{
+		0x00: 0x00,
+		0x01: 0x80,
+		0x0f: 0xf0,
+		0x61: 0x86,
+	}
+
+	for input, expected := range testCases {
+		if actual := reverseBits(input); actual != expected {
+			t.Fatalf("%s reverseBits(%#x) = %#x, expected %#x", t.Name(), input, actual, expected)
+		}
+	}
+}
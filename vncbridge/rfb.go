@@ -0,0 +1,194 @@
+package vncbridge
+
+import (
+	"crypto/des"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+const (
+	rfbVersion38 = "RFB 003.008\n"
+
+	securityTypeInvalid = 0
+	securityTypeNone    = 1
+	securityTypeVNCAuth = 2
+
+	securityResultOK = 0
+)
+
+// authenticate performs the RFB security handshake with the server on behalf
+// of the local viewer, answering the VNC authentication challenge with the
+// password. The viewer is offered the "None" security type, so once this
+// returns both connections are positioned at ClientInit and can be proxied.
+func authenticate(local io.ReadWriter, remote io.ReadWriter, password string) error {
+	serverVersion := make([]byte, len(rfbVersion38))
+	if _, err := io.ReadFull(remote, serverVersion); err != nil {
+		return fmt.Errorf("vncbridge: reading server version - %w", err)
+	}
+
+	if string(serverVersion[:4]) != "RFB " {
+		return fmt.Errorf("vncbridge: unexpected server version %q", serverVersion)
+	}
+
+	// Negotiate 3.8 with the server regardless of what the viewer speaks
+	if _, err := io.WriteString(remote, rfbVersion38); err != nil {
+		return err
+	}
+
+	if err := authenticateServer(remote, password); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(local, rfbVersion38); err != nil {
+		return err
+	}
+
+	clientVersion := make([]byte, len(rfbVersion38))
+	if _, err := io.ReadFull(local, clientVersion); err != nil {
+		return fmt.Errorf("vncbridge: reading viewer version - %w", err)
+	}
+
+	var major, minor int
+	if _, err := fmt.Sscanf(string(clientVersion), "RFB %03d.%03d\n", &major, &minor); err != nil {
+		return fmt.Errorf("vncbridge: unexpected viewer version %q", clientVersion)
+	}
+
+	// RFB 3.3 viewers are told the security type, later versions choose from a list
+	if major == 3 && minor < 7 {
+		return binary.Write(local, binary.BigEndian, uint32(securityTypeNone))
+	}
+
+	if _, err := local.Write([]byte{1, securityTypeNone}); err != nil {
+		return err
+	}
+
+	selected := make([]byte, 1)
+	if _, err := io.ReadFull(local, selected); err != nil {
+		return err
+	}
+
+	if selected[0] != securityTypeNone {
+		return fmt.Errorf("vncbridge: viewer selected unsupported security type %d", selected[0])
+	}
+
+	// RFB 3.7 does not send a SecurityResult for the "None" security type
+	if major == 3 && minor == 7 {
+		return nil
+	}
+
+	return binary.Write(local, binary.BigEndian, uint32(securityResultOK))
+}
+
+func authenticateServer(remote io.ReadWriter, password string) error {
+	count := make([]byte, 1)
+	if _, err := io.ReadFull(remote, count); err != nil {
+		return err
+	}
+
+	if count[0] == securityTypeInvalid {
+		reason, err := readReason(remote)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("vncbridge: server refused connection - %s", reason)
+	}
+
+	securityTypes := make([]byte, count[0])
+	if _, err := io.ReadFull(remote, securityTypes); err != nil {
+		return err
+	}
+
+	switch {
+	case slices.Contains(securityTypes, securityTypeVNCAuth):
+		if _, err := remote.Write([]byte{securityTypeVNCAuth}); err != nil {
+			return err
+		}
+
+		challenge := make([]byte, 16)
+		if _, err := io.ReadFull(remote, challenge); err != nil {
+			return err
+		}
+
+		response, err := vncAuthResponse(challenge, password)
+		if err != nil {
+			return err
+		}
+
+		if _, err := remote.Write(response); err != nil {
+			return err
+		}
+	case slices.Contains(securityTypes, securityTypeNone):
+		if _, err := remote.Write([]byte{securityTypeNone}); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("vncbridge: server offered no supported security type %v", securityTypes)
+	}
+
+	var result uint32
+	if err := binary.Read(remote, binary.BigEndian, &result); err != nil {
+		return err
+	}
+
+	if result != securityResultOK {
+		reason, err := readReason(remote)
+		if err != nil {
+			return errors.New("vncbridge: VNC authentication failed")
+		}
+
+		return fmt.Errorf("vncbridge: VNC authentication failed - %s", reason)
+	}
+
+	return nil
+}
+
+func readReason(r io.Reader) (string, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", err
+	}
+
+	reason := make([]byte, length)
+	if _, err := io.ReadFull(r, reason); err != nil {
+		return "", err
+	}
+
+	return string(reason), nil
+}
+
+// vncAuthResponse encrypts the challenge with DES, using the first 8 bytes of
+// the password as the key. VNC reverses the bit order of each key byte.
+func vncAuthResponse(challenge []byte, password string) ([]byte, error) {
+	key := make([]byte, 8)
+	copy(key, password)
+
+	for i, b := range key {
+		key[i] = reverseBits(b)
+	}
+
+	cipher, err := des.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, len(challenge))
+	for i := 0; i+des.BlockSize <= len(challenge); i += des.BlockSize {
+		cipher.Encrypt(response[i:i+des.BlockSize], challenge[i:i+des.BlockSize])
+	}
+
+	return response, nil
+}
+
+func reverseBits(b byte) byte {
+	var reversed byte
+	for i := 0; i < 8; i++ {
+		reversed = reversed<<1 | b&1
+		b >>= 1
+	}
+
+	return reversed
+}
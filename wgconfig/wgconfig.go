@@ -0,0 +1,171 @@
+// Package wgconfig renders wg-quick configurations for Slide network
+// WireGuard peers, as text or as a QR code for mobile clients.
+package wgconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/skip2/go-qrcode"
+)
+
+const defaultPersistentKeepalive = 25
+
+// Server describes the WireGuard endpoint of the network, which the peer
+// connects to. The API does not return these, so they are supplied by the caller.
+type Server struct {
+	Endpoint  string
+	PublicKey string
+}
+
+type Config struct {
+	PrivateKey string
+	Address    string
+	DNS        []string
+
+	ServerPublicKey     string
+	Endpoint            string
+	AllowedIPs          []string
+	PersistentKeepalive int
+}
+
+type configOption func(c *Config)
+
+// WithPersistentKeepalive sets the keepalive interval in seconds, 0 disables it.
+func WithPersistentKeepalive(seconds int) configOption {
+	return func(c *Config) {
+		c.PersistentKeepalive = seconds
+	}
+}
+
+// New builds the configuration for a peer of the network. The peer can reach
+// the network's router prefix and the peer's remote networks, and uses the
+// network's nameservers.
+func New(network goslide.Network, peer goslide.NetworkWGPeer, server Server, options ...configOption) (Config, error) {
+	config := Config{
+		PrivateKey:          peer.WGPrivateKey,
+		DNS:                 []string{},
+		ServerPublicKey:     server.PublicKey,
+		Endpoint:            server.Endpoint,
+		AllowedIPs:          []string{},
+		PersistentKeepalive: defaultPersistentKeepalive,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	errs := []error{}
+
+	if config.PrivateKey == "" {
+		errs = append(errs, errors.New("the peer has no private key"))
+	}
+
+	if server.Endpoint == "" {
+		errs = append(errs, errors.New("the server endpoint is required"))
+	}
+
+	if server.PublicKey == "" {
+		errs = append(errs, errors.New("the server public key is required"))
+	}
+
+	address, err := peerAddress(peer.WGAddress)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	config.Address = address
+
+	if network.RouterPrefix != "" {
+		prefix, err := netip.ParsePrefix(network.RouterPrefix)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid router prefix %q - %w", network.RouterPrefix, err))
+		} else {
+			config.AllowedIPs = append(config.AllowedIPs, prefix.Masked().String())
+		}
+	}
+
+	for _, remoteNetwork := range peer.RemoteNetworks {
+		prefix, err := netip.ParsePrefix(remoteNetwork)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid remote network %q - %w", remoteNetwork, err))
+
+			continue
+		}
+
+		config.AllowedIPs = append(config.AllowedIPs, prefix.Masked().String())
+	}
+
+	for _, nameserver := range strings.Split(network.Nameservers, ",") {
+		if nameserver = strings.TrimSpace(nameserver); nameserver != "" {
+			config.DNS = append(config.DNS, nameserver)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, fmt.Errorf("wgconfig: %w", err)
+	}
+
+	return config, nil
+}
+
+// peerAddress gives a bare peer address a single host prefix.
+func peerAddress(wgAddress string) (string, error) {
+	if prefix, err := netip.ParsePrefix(wgAddress); err == nil {
+		return prefix.String(), nil
+	}
+
+	address, err := netip.ParseAddr(wgAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid peer address %q - %w", wgAddress, err)
+	}
+
+	return netip.PrefixFrom(address, address.BitLen()).String(), nil
+}
+
+// Marshal renders the configuration in the wg-quick format.
+func (c Config) Marshal() []byte {
+	buffer := &bytes.Buffer{}
+
+	buffer.WriteString("[Interface]\n")
+	fmt.Fprintf(buffer, "PrivateKey = %s\n", c.PrivateKey)
+	fmt.Fprintf(buffer, "Address = %s\n", c.Address)
+
+	if len(c.DNS) > 0 {
+		fmt.Fprintf(buffer, "DNS = %s\n", strings.Join(c.DNS, ", "))
+	}
+
+	buffer.WriteString("\n[Peer]\n")
+	fmt.Fprintf(buffer, "PublicKey = %s\n", c.ServerPublicKey)
+	fmt.Fprintf(buffer, "Endpoint = %s\n", c.Endpoint)
+	fmt.Fprintf(buffer, "AllowedIPs = %s\n", strings.Join(c.AllowedIPs, ", "))
+
+	if c.PersistentKeepalive > 0 {
+		fmt.Fprintf(buffer, "PersistentKeepalive = %d\n", c.PersistentKeepalive)
+	}
+
+	return buffer.Bytes()
+}
+
+func (c Config) String() string {
+	return string(c.Marshal())
+}
+
+// QRCodePNG renders the configuration as a QR code image, size pixels wide,
+// which the WireGuard mobile apps can scan.
+func (c Config) QRCodePNG(size int) ([]byte, error) {
+	return qrcode.Encode(c.String(), qrcode.Medium, size)
+}
+
+// QRCodeASCII renders the configuration as a QR code for display in a terminal.
+func (c Config) QRCodeASCII() (string, error) {
+	code, err := qrcode.New(c.String(), qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+
+	return code.ToSmallString(false), nil
+}
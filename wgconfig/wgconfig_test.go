@@ -0,0 +1,123 @@
+package wgconfig_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/equalsgibson/goslide"
+	"github.com/equalsgibson/goslide/wgconfig"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Alice's key pair from the X25519 test vectors in RFC 7748
+const (
+	testPrivateKey = "dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo="
+	testPublicKey  = "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo="
+)
+
+var (
+	testNetwork = goslide.Network{
+		NetworkID:    "net_012345",
+		Nameservers:  "1.1.1.1, 1.0.0.1",
+		RouterPrefix: "10.0.0.1/24",
+	}
+
+	testPeer = goslide.NetworkWGPeer{
+		NetworkID:      "net_012345",
+		PeerName:       "My PC",
+		RemoteNetworks: []string{"10.80.0.0/24", "192.168.1.7/24"},
+		WGAddress:      "10.0.0.100",
+		WGPrivateKey:   testPrivateKey,
+		WGPublicKey:    testPublicKey,
+	}
+
+	testServer = wgconfig.Server{
+		Endpoint:  "wg.slide.tech:51820",
+		PublicKey: "buRAPDIX5SMlPlzLsLO8WVaWe+1+FXf+TKk26e4Vkm8=",
+	}
+)
+
+func TestNew(t *testing.T) {
+	config, err := wgconfig.New(testNetwork, testPeer, testServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[Interface]
+PrivateKey = dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=
+Address = 10.0.0.100/32
+DNS = 1.1.1.1, 1.0.0.1
+
+[Peer]
+PublicKey = buRAPDIX5SMlPlzLsLO8WVaWe+1+FXf+TKk26e4Vkm8=
+Endpoint = wg.slide.tech:51820
+AllowedIPs = 10.0.0.0/24, 10.80.0.0/24, 192.168.1.0/24
+PersistentKeepalive = 25
+`
+
+	if diff := cmp.Diff(expected, config.String()); diff != "" {
+		t.Fatalf("%s Rendered config mismatch (-want +got):\n%s", t.Name(), diff)
+	}
+}
+
+func TestNew_WithoutKeepalive(t *testing.T) {
+	config, err := wgconfig.New(testNetwork, testPeer, testServer, wgconfig.WithPersistentKeepalive(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(config.String(), "PersistentKeepalive") {
+		t.Errorf("%s expected no keepalive, got:\n%s", t.Name(), config)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	peer := testPeer
+	peer.WGPrivateKey = ""
+	peer.WGAddress = "not-an-address"
+	peer.RemoteNetworks = []string{"10.80.0.0/33"}
+
+	_, err := wgconfig.New(goslide.Network{RouterPrefix: "10.0.0.1"}, peer, wgconfig.Server{})
+	if err == nil {
+		t.Fatalf("%s expected an error", t.Name())
+	}
+
+	for _, expected := range []string{
+		"no private key",
+		"server endpoint is required",
+		"server public key is required",
+		`invalid peer address "not-an-address"`,
+		`invalid router prefix "10.0.0.1"`,
+		`invalid remote network "10.80.0.0/33"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s expected error to contain %q, got: %s", t.Name(), expected, err)
+		}
+	}
+}
+
+func TestConfig_QRCode(t *testing.T) {
+	config, err := wgconfig.New(testNetwork, testPeer, testServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	png, err := config.QRCodePNG(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(png, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Errorf("%s expected a PNG image", t.Name())
+	}
+
+	ascii, err := config.QRCodeASCII()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(strings.Split(ascii, "\n")) < 20 {
+		t.Errorf("%s expected a multi-line QR code, got:\n%s", t.Name(), ascii)
+	}
+}